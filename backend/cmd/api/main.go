@@ -11,8 +11,21 @@ import (
 
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/auth"
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/database"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/earnings"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/export"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/gifts"
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/middleware"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/moderation"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/payouts"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/purchases"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/room"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/schedule"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/subscriptions"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/tickets"
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/video"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/webhooks"
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/config"
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
@@ -48,25 +61,224 @@ func main() {
 	// Initialize repositories
 	authRepo := auth.NewPostgresRepository(db.DB)
 	videoRepo := video.NewPostgresRepository(db.DB)
+	roomRepo := room.NewPostgresRepository(db.DB)
+	moderationRepo := moderation.NewPostgresRepository(db.DB)
+	walletRepo := wallet.NewPostgresRepository(db.DB)
+	giftRepo := gifts.NewPostgresRepository(db.DB)
+	payoutRepo := payouts.NewPostgresRepository(db.DB)
+	purchaseRepo := purchases.NewPostgresRepository(db.DB)
+	webhookRepo := webhooks.NewPostgresRepository(db.DB)
+	subscriptionRepo := subscriptions.NewPostgresRepository(db.DB)
+	ticketRepo := tickets.NewPostgresRepository(db.DB)
+	earningsRepo := earnings.NewPostgresRepository(db.DB)
+	exportRepo := export.NewPostgresRepository(db.DB)
+	scheduleRepo := schedule.NewPostgresRepository(db.DB)
+	notificationRepo := notifications.NewPostgresRepository(db.DB)
+
+	// Initialize the text classifier
+	rejectWords, reviewWords := []string(nil), moderation.DefaultReviewWords
+	if cfg.Moderation.RejectWordsFile != "" {
+		if rejectWords, err = moderation.LoadWordList(cfg.Moderation.RejectWordsFile); err != nil {
+			logger.ErrorLogger.Fatalf("Failed to load reject word list: %v", err)
+		}
+	}
+	if cfg.Moderation.ReviewWordsFile != "" {
+		if reviewWords, err = moderation.LoadWordList(cfg.Moderation.ReviewWordsFile); err != nil {
+			logger.ErrorLogger.Fatalf("Failed to load review word list: %v", err)
+		}
+	}
+	classifier := moderation.NewLocalClassifier(rejectWords, reviewWords)
+
+	// Initialize the payment provider
+	var paymentProvider payouts.PaymentProvider
+	switch cfg.Payments.Provider {
+	case "fake":
+		paymentProvider = payouts.NewFakeProvider()
+	default:
+		logger.ErrorLogger.Fatalf("Unsupported payment provider: %s", cfg.Payments.Provider)
+	}
+
+	// Initialize the app store receipt validator
+	var receiptValidator purchases.ReceiptValidator
+	switch cfg.Payments.ReceiptValidator {
+	case "fake":
+		receiptValidator = purchases.NewFakeValidator()
+	default:
+		logger.ErrorLogger.Fatalf("Unsupported receipt validator: %s", cfg.Payments.ReceiptValidator)
+	}
+
+	// Initialize the notification delivery channels
+	var pushSender notifications.PushSender
+	switch cfg.Notifications.PushSender {
+	case "fake":
+		pushSender = notifications.NewFakePushSender()
+	default:
+		logger.ErrorLogger.Fatalf("Unsupported push sender: %s", cfg.Notifications.PushSender)
+	}
+	var emailSender notifications.EmailSender
+	switch cfg.Notifications.EmailSender {
+	case "fake":
+		emailSender = notifications.NewFakeEmailSender()
+	case "file":
+		emailSender = notifications.NewFileEmailSender(cfg.Notifications.EmailOutboxFile)
+	default:
+		logger.ErrorLogger.Fatalf("Unsupported email sender: %s", cfg.Notifications.EmailSender)
+	}
 
 	// Initialize services
-	authService := auth.NewService(authRepo)
-	videoService := video.NewService(videoRepo, redisClient)
+	notificationService := notifications.NewService(notificationRepo, pushSender, emailSender)
+	moderationService := moderation.NewService(classifier, moderationRepo, notificationService)
+	walletService := wallet.NewService(walletRepo)
+
+	// Initialize the ticket charger
+	var ticketCharger tickets.Charger
+	switch cfg.Payments.TicketCharger {
+	case "wallet":
+		ticketCharger = tickets.NewWalletCharger(walletService, cfg.Monetization.TicketCentsPer100Coins)
+	case "fake":
+		ticketCharger = tickets.NewFakeCharger()
+	default:
+		logger.ErrorLogger.Fatalf("Unsupported ticket charger: %s", cfg.Payments.TicketCharger)
+	}
+	streamControl := video.NewRedisStreamControl(redisClient)
+	ticketService := tickets.NewService(ticketRepo, ticketCharger, streamControl, cfg.Monetization.CoinValueCentsPer100Coins)
+
+	// Load the breached password list
+	var breachedPasswords *auth.BreachedPasswords
+	if cfg.Accounts.BreachedPasswordsDir != "" {
+		if breachedPasswords, err = auth.NewBreachedPasswords(cfg.Accounts.BreachedPasswordsDir); err != nil {
+			logger.ErrorLogger.Fatalf("Failed to load breached password list: %v", err)
+		}
+	}
+
+	subscriptionService := subscriptions.NewService(
+		subscriptionRepo,
+		walletService,
+		cfg.Monetization.SubscriptionCentsPer100Coins,
+		cfg.Monetization.CoinValueCentsPer100Coins,
+		cfg.Monetization.SubscriptionGraceDays,
+	)
+	authService := auth.NewService(authRepo, moderationService, subscriptionService, notificationService, emailSender, auth.NewRedisLoginThrottle(redisClient), auth.Config{
+		PasswordResetURL:           cfg.Accounts.PasswordResetURL,
+		PasswordResetTTL:           time.Duration(cfg.Accounts.PasswordResetTTLMinutes) * time.Minute,
+		EmailVerificationURL:       cfg.Accounts.EmailVerificationURL,
+		EmailChangeURL:             cfg.Accounts.EmailChangeURL,
+		EmailTokenTTL:              time.Duration(cfg.Accounts.EmailLinkTTLHours) * time.Hour,
+		VerificationResendInterval: time.Duration(cfg.Accounts.VerificationResendSeconds) * time.Second,
+		TokenSecret:                cfg.JWT.SecretKey,
+		MFAIssuer:                  cfg.Accounts.MFAIssuer,
+		PasskeyRPID:                cfg.Accounts.WebAuthnRPID,
+		PasskeyRPName:              cfg.Accounts.WebAuthnRPName,
+		PasskeyOrigins:             cfg.Accounts.WebAuthnOrigins,
+		OIDCProviders: []auth.OIDCProvider{
+			auth.GoogleProvider(cfg.Accounts.GoogleClientIDs),
+			auth.AppleProvider(cfg.Accounts.AppleClientIDs),
+		},
+		LoginMaxFailures:    cfg.Accounts.LoginMaxFailures,
+		LoginIPMaxFailures:  cfg.Accounts.LoginIPMaxFailures,
+		LoginLockout:        time.Duration(cfg.Accounts.LoginLockoutMinutes) * time.Minute,
+		MFAMaxFailures:      cfg.Accounts.MFAMaxFailures,
+		SessionTTL:          time.Duration(cfg.JWT.ExpirationHours) * time.Hour,
+		DeletionGracePeriod: time.Duration(cfg.Accounts.DeletionGraceDays) * 24 * time.Hour,
+		PasswordPolicy: auth.PasswordPolicy{
+			MinLength:      cfg.Accounts.PasswordMinLength,
+			MinEntropyBits: float64(cfg.Accounts.PasswordMinEntropyBits),
+			Breached:       breachedPasswords,
+		},
+		PasswordHashing: auth.PasswordHashing{
+			Algorithm:         cfg.Accounts.PasswordHashAlgorithm,
+			BcryptCost:        cfg.Accounts.BcryptCost,
+			Argon2Memory:      uint32(cfg.Accounts.Argon2MemoryKiB),
+			Argon2Iterations:  uint32(cfg.Accounts.Argon2Iterations),
+			Argon2Parallelism: uint8(cfg.Accounts.Argon2Parallelism),
+		},
+	})
+	videoService := video.NewService(videoRepo, redisClient, streamControl, moderationService, subscriptionService, ticketService, notificationService)
+	roomService := room.NewService(roomRepo, redisClient, moderationService)
+	giftService := gifts.NewService(giftRepo, redisClient, walletService, cfg.Monetization.GiftCentsPer100Coins, cfg.Monetization.CoinValueCentsPer100Coins)
+	payoutService := payouts.NewService(payoutRepo, paymentProvider, walletService, cfg.Payments.MinPayoutCents, cfg.Payments.EarningsHoldDays, payouts.OnboardingURLs{
+		ReturnURL:  cfg.Payments.OnboardingReturnURL,
+		RefreshURL: cfg.Payments.OnboardingRefreshURL,
+	})
+	earningsService := earnings.NewService(earningsRepo, walletService)
+	exportService := export.NewService(exportRepo)
+	scheduleService := schedule.NewService(
+		scheduleRepo,
+		notifications.NewStreamNotifier(notificationService),
+		time.Duration(cfg.Streams.ReminderLeadMinutes)*time.Minute,
+		time.Duration(cfg.Streams.ScheduledGraceMinutes)*time.Minute,
+	)
+	purchaseService := purchases.NewService(purchaseRepo, receiptValidator, walletService)
+	webhookService := webhooks.NewService(
+		webhookRepo,
+		cfg.Payments.WebhookSecret,
+		time.Duration(cfg.Payments.WebhookToleranceSeconds)*time.Second,
+		cfg.Payments.WebhookMaxAttempts,
+	)
+	if cfg.Payments.WebhookSecret == "" {
+		logger.WarnLogger.Println("PAYMENT_WEBHOOK_SECRET is not set; payment webhooks will be rejected")
+	}
+
+	// Register payment webhook processors
+	for _, eventType := range payouts.PayoutEventTypes {
+		webhookService.Handle(eventType, payoutService.HandlePayoutEvent)
+	}
+	webhookService.Handle(payouts.AccountEventType, payoutService.HandleAccountEvent)
+	webhookService.Handle(purchases.RefundEventType, purchaseService.HandleRefundEvent)
+	webhookService.Handle(purchases.ChargebackEventType, purchaseService.HandleChargebackEvent)
 
 	// Initialize JWT manager
 	jwtManager := auth.NewJWTManager(cfg.JWT.SecretKey, cfg.JWT.ExpirationHours)
 
+	// Initialize auth middleware
+	requireAuth := middleware.AuthMiddleware(jwtManager, authService)
+	optionalAuth := middleware.OptionalAuthMiddleware(jwtManager, authService)
+	requireVerifiedEmail := middleware.RequireVerifiedEmail(authService)
+	requireRecentMFA := middleware.RequireRecentMFA(time.Duration(cfg.Accounts.RecentMFAMinutes) * time.Minute)
+	requireRecentAuth := middleware.RequireRecentAuth(time.Duration(cfg.Accounts.RecentMFAMinutes) * time.Minute)
+
 	// Initialize handlers
 	authHandler := auth.NewHandler(authService, jwtManager)
 	videoHandler := video.NewHandler(videoService)
+	roomHandler := room.NewHandler(roomService)
+	moderationHandler := moderation.NewHandler(moderationService)
+	walletHandler := wallet.NewHandler(walletService)
+	giftHandler := gifts.NewHandler(giftService)
+	payoutHandler := payouts.NewHandler(payoutService)
+	purchaseHandler := purchases.NewHandler(purchaseService)
+	webhookHandler := webhooks.NewHandler(webhookService)
+	subscriptionHandler := subscriptions.NewHandler(subscriptionService)
+	ticketHandler := tickets.NewHandler(ticketService)
+	earningsHandler := earnings.NewHandler(earningsService)
+	exportHandler := export.NewHandler(exportService)
+	scheduleHandler := schedule.NewHandler(scheduleService)
+	notificationHandler := notifications.NewHandler(notificationService)
+
+	// Start background jobs; they stop when the server shuts down
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go giftService.RunLeaderboardPersister(jobCtx, time.Minute)
+	go payoutService.RunScheduler(jobCtx, time.Hour)
+	go webhookService.RunRetrier(jobCtx, time.Minute)
+	go subscriptionService.RunRenewals(jobCtx, time.Hour)
+	go ticketService.RunRefunds(jobCtx, 10*time.Minute)
+	go scheduleService.RunScheduler(jobCtx, time.Minute)
+	go authService.RunDeletions(jobCtx, time.Hour)
 
 	// Initialize Gin router
 	router := gin.New()
 
+	// Client IPs feed login throttling, sessions and new device alerts, so
+	// forwarded headers are only believed from known proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.ErrorLogger.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Apply global middleware
 	router.Use(gin.Recovery())
 	router.Use(middleware.LoggerMiddleware())
 	router.Use(middleware.CORSMiddleware(cfg.CORS.AllowedOrigins))
+	router.Use(middleware.ClientLocation(cfg.Accounts.LocationHeaders))
 
 	// Initialize rate limiter (1000 requests per second, burst of 2000)
 	rateLimiter := middleware.NewRateLimiter(1000, 2000)
@@ -105,31 +317,173 @@ func main() {
 		{
 			authRoutes.POST("/register", authHandler.Register)
 			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/login/mfa", authHandler.LoginMFA)
+			authRoutes.POST("/passkeys/login/options", authHandler.PasskeyLoginOptions)
+			authRoutes.POST("/passkeys/login", authHandler.LoginWithPasskey)
+			authRoutes.POST("/oidc/:provider", authHandler.LoginWithOIDC)
+			authRoutes.POST("/password/forgot", authHandler.ForgotPassword)
+			authRoutes.POST("/password/reset", authHandler.ResetPassword)
+			authRoutes.POST("/email/verify", authHandler.VerifyEmail)
+			authRoutes.POST("/email/change/confirm", authHandler.ConfirmEmailChange)
 		}
 
 		// Protected auth routes
 		authProtected := v1.Group("/auth")
-		authProtected.Use(middleware.AuthMiddleware(jwtManager))
+		authProtected.Use(requireAuth)
 		{
 			authProtected.GET("/me", authHandler.GetProfile)
+			authProtected.PATCH("/me", authHandler.UpdateProfile)
+			authProtected.POST("/email/verify/resend", authHandler.ResendVerification)
+			authProtected.POST("/email/change", authHandler.ChangeEmail)
+			authProtected.POST("/password", authHandler.ChangePassword)
+			authProtected.POST("/mfa/totp", authHandler.EnrollTOTP)
+			authProtected.POST("/mfa/totp/confirm", authHandler.ConfirmTOTP)
+			authProtected.DELETE("/mfa/totp", requireRecentMFA, authHandler.DisableTOTP)
+			authProtected.POST("/mfa/recovery-codes", requireRecentMFA, authHandler.RegenerateRecoveryCodes)
+			authProtected.POST("/mfa/step-up", authHandler.StepUp)
+			authProtected.GET("/passkeys", authHandler.ListPasskeys)
+			authProtected.POST("/passkeys/register/options", authHandler.PasskeyRegistrationOptions)
+			authProtected.POST("/passkeys/register", authHandler.RegisterPasskey)
+			authProtected.DELETE("/passkeys/:id", authHandler.DeletePasskey)
+			authProtected.GET("/identities", authHandler.ListIdentities)
+			authProtected.POST("/identities/:provider", authHandler.LinkIdentity)
+			authProtected.DELETE("/identities/:provider", authHandler.UnlinkIdentity)
+			authProtected.GET("/sessions", authHandler.ListSessions)
+			authProtected.DELETE("/sessions/:id", authHandler.RevokeSession)
 		}
 
 		// Video routes (some protected, some public)
 		videoRoutes := v1.Group("/videos")
+		videoRoutes.Use(optionalAuth)
 		{
 			videoRoutes.GET("", videoHandler.GetVideos)
 			videoRoutes.GET("/:id", videoHandler.GetVideo)
+			videoRoutes.GET("/:id/playback", videoHandler.GetPlayback)
+			videoRoutes.GET("/:id/gifts/leaderboard", giftHandler.GetLeaderboard)
 		}
 
 		// Protected video routes
 		videoProtected := v1.Group("/videos")
-		videoProtected.Use(middleware.AuthMiddleware(jwtManager))
+		videoProtected.Use(requireAuth)
+		{
+			videoProtected.POST("", requireVerifiedEmail, videoHandler.CreateVideo)
+			videoProtected.PATCH("/:id", videoHandler.UpdateVideo)
+			videoProtected.POST("/:id/engagement/:metric", roomHandler.RequireParticipation, videoHandler.IncrementEngagement)
+			videoProtected.POST("/:id/messages", roomHandler.SendMessage)
+			videoProtected.POST("/:id/gifts", roomHandler.RequireParticipation, giftHandler.SendGift)
+			videoProtected.POST("/:id/tickets", ticketHandler.BuyTicket)
+			videoProtected.POST("/:id/cancel", ticketHandler.CancelEvent)
+			videoProtected.POST("/:id/reminder", scheduleHandler.Remind)
+			videoProtected.DELETE("/:id/reminder", scheduleHandler.CancelReminder)
+
+			// Room moderation by the creator and their channel moderators
+			videoProtected.GET("/:id/room", roomHandler.GetSettings)
+			videoProtected.PUT("/:id/room", roomHandler.UpdateSettings)
+			videoProtected.POST("/:id/room/bans", roomHandler.BanUser)
+			videoProtected.DELETE("/:id/room/bans/:user_id", roomHandler.UnbanUser)
+			videoProtected.POST("/:id/room/moderators", roomHandler.AddModerator)
+			videoProtected.DELETE("/:id/room/moderators/:user_id", roomHandler.RemoveModerator)
+		}
+
+		// Gift catalog
+		v1.GET("/gifts", giftHandler.GetGifts)
+
+		// Coin packages
+		v1.GET("/coin-packages", purchaseHandler.GetPackages)
+
+		// Wallet routes
+		walletRoutes := v1.Group("/wallet")
+		walletRoutes.Use(requireAuth)
+		{
+			walletRoutes.GET("", walletHandler.GetWallet)
+			walletRoutes.GET("/transactions", walletHandler.GetTransactions)
+			walletRoutes.POST("/purchases", purchaseHandler.Purchase)
+		}
+
+		// Payout routes
+		payoutRoutes := v1.Group("/payouts")
+		payoutRoutes.Use(requireAuth)
+		{
+			payoutRoutes.GET("", payoutHandler.GetPayouts)
+			payoutRoutes.GET("/account", payoutHandler.GetAccount)
+			payoutRoutes.POST("/account/onboarding", requireVerifiedEmail, requireRecentMFA, payoutHandler.LinkAccount)
+		}
+
+		// Subscription routes
+		subscriptionRoutes := v1.Group("/subscriptions")
+		subscriptionRoutes.Use(requireAuth)
+		{
+			subscriptionRoutes.GET("", subscriptionHandler.GetSubscriptions)
+			subscriptionRoutes.POST("", subscriptionHandler.Subscribe)
+			subscriptionRoutes.DELETE("/:id", subscriptionHandler.Cancel)
+			subscriptionRoutes.POST("/tiers", subscriptionHandler.CreateTier)
+			subscriptionRoutes.PATCH("/tiers/:id", subscriptionHandler.UpdateTier)
+		}
+
+		// Event tickets
+		v1.GET("/tickets", requireAuth, ticketHandler.GetTickets)
+
+		// Notification routes
+		notificationRoutes := v1.Group("/notifications")
+		notificationRoutes.Use(requireAuth)
 		{
-			videoProtected.POST("/:id/engagement/:metric", videoHandler.IncrementEngagement)
+			notificationRoutes.GET("", notificationHandler.GetInbox)
+			notificationRoutes.POST("/read", notificationHandler.MarkRead)
+			notificationRoutes.GET("/preferences", notificationHandler.GetPreferences)
+			notificationRoutes.PUT("/preferences", notificationHandler.UpdatePreferences)
+			notificationRoutes.GET("/devices", notificationHandler.GetDevices)
+			notificationRoutes.POST("/devices", notificationHandler.RegisterDevice)
+			notificationRoutes.DELETE("/devices/:id", notificationHandler.UnregisterDevice)
 		}
 
-		// User video routes
-		v1.GET("/users/:user_id/videos", videoHandler.GetUserVideos)
+		// Stream reminders
+		v1.GET("/reminders", requireAuth, scheduleHandler.GetReminders)
+
+		// Creator earnings
+		v1.GET("/creator/earnings", requireAuth, earningsHandler.GetEarnings)
+
+		// The user's own account data
+		meRoutes := v1.Group("/me")
+		meRoutes.Use(requireAuth)
+		{
+			meRoutes.POST("/export", exportHandler.ExportData)
+			meRoutes.GET("/deletion", authHandler.GetDeletion)
+			meRoutes.POST("/deletion", requireRecentAuth, authHandler.RequestDeletion)
+			meRoutes.DELETE("/deletion", authHandler.CancelDeletion)
+		}
+
+		// Provider webhooks (authenticated by signature)
+		v1.POST("/webhooks/payments", webhookHandler.ReceivePaymentEvent)
+
+		// Public user routes
+		v1.GET("/users/:user_id", optionalAuth, authHandler.GetPublicProfile)
+		v1.GET("/users/:user_id/videos", optionalAuth, videoHandler.GetUserVideos)
+		v1.GET("/users/:user_id/tiers", subscriptionHandler.GetTiers)
+
+		// Admin routes (each route requires its own permission)
+		adminRoutes := v1.Group("/admin")
+		adminRoutes.Use(requireAuth)
+		{
+			adminRoutes.PUT("/users/:id/role", middleware.RequirePermission(auth.PermRolesManage), authHandler.UpdateUserRole)
+			adminRoutes.POST("/users/:id/permissions", middleware.RequirePermission(auth.PermRolesManage), authHandler.GrantPermission)
+			adminRoutes.DELETE("/users/:id/permissions/:permission", middleware.RequirePermission(auth.PermRolesManage), authHandler.RevokePermission)
+
+			adminRoutes.POST("/users/:id/suspend", middleware.RequirePermission(auth.PermUsersModerate), authHandler.SuspendUser)
+			adminRoutes.POST("/users/:id/shadow-ban", middleware.RequirePermission(auth.PermUsersModerate), authHandler.ShadowBanUser)
+			adminRoutes.POST("/users/:id/reinstate", middleware.RequirePermission(auth.PermUsersModerate), authHandler.ReinstateUser)
+			adminRoutes.POST("/users/:id/ban", middleware.RequirePermission(auth.PermUsersBan), authHandler.BanUser)
+			adminRoutes.GET("/users/:id/actions", middleware.RequirePermission(auth.PermUsersModerate), authHandler.GetAccountActions)
+
+			adminRoutes.POST("/videos/:id/end", middleware.RequirePermission(auth.PermContentModerate), videoHandler.EndStream)
+			adminRoutes.POST("/videos/:id/takedown", middleware.RequirePermission(auth.PermContentModerate), videoHandler.TakedownVideo)
+
+			adminRoutes.GET("/moderation/queue", middleware.RequirePermission(auth.PermContentModerate), moderationHandler.GetQueue)
+			adminRoutes.POST("/moderation/queue/:id/approve", middleware.RequirePermission(auth.PermContentModerate), moderationHandler.ApproveItem)
+			adminRoutes.POST("/moderation/queue/:id/reject", middleware.RequirePermission(auth.PermContentModerate), moderationHandler.RejectItem)
+
+			adminRoutes.GET("/webhooks/dead-letters", middleware.RequirePermission(auth.PermPaymentsManage), webhookHandler.GetDeadLetters)
+			adminRoutes.POST("/webhooks/events/:event_id/replay", middleware.RequirePermission(auth.PermPaymentsManage), webhookHandler.ReplayEvent)
+		}
 	}
 
 	// Create HTTP server with timeouts optimized for high concurrency
@@ -157,6 +511,7 @@ func main() {
 	<-quit
 
 	logger.InfoLogger.Println("Shutting down server...")
+	stopJobs()
 
 	// Give outstanding requests 5 seconds to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
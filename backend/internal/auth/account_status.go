@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+var (
+	ErrAccountSuspended = errors.New("account suspended")
+	ErrAccountBanned    = errors.New("account banned")
+	ErrInvalidUntil     = errors.New("suspension end must be in the future")
+	ErrOutranked        = errors.New("user's role is not below the actor's, or the actor lacks what they are granting")
+	ErrBanRequired      = errors.New("changing a banned account requires the users:ban permission")
+	ErrStatusChanged    = errors.New("account changed while being moderated")
+)
+
+// AccountStatus is the moderation state of an account
+type AccountStatus string
+
+const (
+	StatusActive       AccountStatus = "active"
+	StatusSuspended    AccountStatus = "suspended"
+	StatusBanned       AccountStatus = "banned"
+	StatusShadowBanned AccountStatus = "shadow_banned"
+)
+
+// AccountStateError describes why an account may not be used. It wraps
+// ErrAccountSuspended or ErrAccountBanned.
+type AccountStateError struct {
+	Status AccountStatus
+	Reason string
+	Until  *time.Time
+}
+
+func (e *AccountStateError) Error() string {
+	if e.Until != nil {
+		return fmt.Sprintf("account %s until %s: %s", e.Status, e.Until.Format(time.RFC3339), e.Reason)
+	}
+	return fmt.Sprintf("account %s: %s", e.Status, e.Reason)
+}
+
+func (e *AccountStateError) Unwrap() error {
+	if e.Status == StatusBanned {
+		return ErrAccountBanned
+	}
+	return ErrAccountSuspended
+}
+
+// checkAccountStatus returns an AccountStateError if the user may not log in
+// or use existing tokens. Shadow-banned users are deliberately let through.
+// Suspensions whose end has passed are treated as lifted.
+func checkAccountStatus(user *models.User, now time.Time) error {
+	switch AccountStatus(user.AccountStatus) {
+	case StatusBanned:
+		return &AccountStateError{Status: StatusBanned, Reason: user.StatusReason}
+	case StatusSuspended:
+		if user.SuspendedUntil == nil || user.SuspendedUntil.After(now) {
+			return &AccountStateError{Status: StatusSuspended, Reason: user.StatusReason, Until: user.SuspendedUntil}
+		}
+	}
+	return nil
+}
+
+// CheckAccess verifies that the account behind a token may still be used
+// and that the token's session has not been revoked, recording the session
+// being used by the client. It returns the token's claims with the user's
+// current details, role and permissions in place of those copied at login,
+// so role changes and revoked grants apply to tokens already issued.
+func (s *Service) CheckAccess(ctx context.Context, claims *Claims, client Client) (*Claims, error) {
+	user, err := s.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSession(user, claims); err != nil {
+		return nil, err
+	}
+	if err := checkAccountStatus(user, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.checkActiveSession(ctx, claims, client); err != nil {
+		return nil, err
+	}
+
+	live, err := s.Claims(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	live.AMR, live.AuthTime, live.SessionID = claims.AMR, claims.AuthTime, claims.SessionID
+	live.RegisteredClaims = claims.RegisteredClaims
+	return live, nil
+}
+
+// SuspendUser suspends an account until the given time
+func (s *Service) SuspendUser(ctx context.Context, userID, moderatorID int64, reason string, until time.Time) (*models.AccountAction, error) {
+	if !until.After(time.Now()) {
+		return nil, ErrInvalidUntil
+	}
+	return s.setAccountStatus(ctx, userID, moderatorID, StatusSuspended, reason, &until)
+}
+
+// BanUser permanently bans an account
+func (s *Service) BanUser(ctx context.Context, userID, moderatorID int64, reason string) (*models.AccountAction, error) {
+	return s.setAccountStatus(ctx, userID, moderatorID, StatusBanned, reason, nil)
+}
+
+// ShadowBanUser hides an account's content from everyone but its owner
+func (s *Service) ShadowBanUser(ctx context.Context, userID, moderatorID int64, reason string) (*models.AccountAction, error) {
+	return s.setAccountStatus(ctx, userID, moderatorID, StatusShadowBanned, reason, nil)
+}
+
+// ReinstateUser returns an account to the active state
+func (s *Service) ReinstateUser(ctx context.Context, userID, moderatorID int64, reason string) (*models.AccountAction, error) {
+	return s.setAccountStatus(ctx, userID, moderatorID, StatusActive, reason, nil)
+}
+
+// GetAccountActions retrieves the moderation history of an account
+func (s *Service) GetAccountActions(ctx context.Context, userID int64) ([]*models.AccountAction, error) {
+	if _, err := s.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAccountActions(ctx, userID)
+}
+
+// setAccountStatus applies a moderation action. Moderators may only act on
+// users whose role ranks below their own, and only those who may ban may
+// change the status of a banned account, so a ban cannot be lifted or
+// turned into a suspension with the lesser moderation permission.
+func (s *Service) setAccountStatus(ctx context.Context, userID, moderatorID int64, status AccountStatus, reason string, until *time.Time) (*models.AccountAction, error) {
+	user, moderator, err := s.checkOutranks(ctx, userID, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	if AccountStatus(user.AccountStatus) == StatusBanned {
+		permissions, err := s.Permissions(ctx, moderator)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(permissions, PermUsersBan) {
+			return nil, ErrBanRequired
+		}
+	}
+
+	action := &models.AccountAction{
+		UserID:      userID,
+		Status:      string(status),
+		Reason:      reason,
+		ModeratorID: &moderatorID,
+		ExpiresAt:   until,
+	}
+
+	// The update only applies if the role and status checked above still hold
+	if err := s.repo.SetAccountStatus(ctx, action, user.Role, user.AccountStatus); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrStatusChanged
+		}
+		return nil, fmt.Errorf("failed to set account status: %w", err)
+	}
+
+	return action, nil
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// moderationRepo is an in-memory Repository for moderation actions
+type moderationRepo struct {
+	Repository
+	users  map[int64]*models.User
+	grants map[int64][]string
+}
+
+func (r *moderationRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *moderationRepo) GetUserPermissions(ctx context.Context, userID int64) ([]string, error) {
+	return r.grants[userID], nil
+}
+
+func (r *moderationRepo) SetAccountStatus(ctx context.Context, action *models.AccountAction, fromRole, fromStatus string) error {
+	user := r.users[action.UserID]
+	if user.Role != fromRole || user.AccountStatus != fromStatus {
+		return sql.ErrNoRows
+	}
+	user.AccountStatus = action.Status
+	return nil
+}
+
+func (r *moderationRepo) SetUserRole(ctx context.Context, userID int64, role string, changedBy int64) error {
+	r.users[userID].Role = role
+	return nil
+}
+
+func (r *moderationRepo) GrantPermission(ctx context.Context, userID int64, permission string, grantedBy int64) error {
+	r.grants[userID] = append(r.grants[userID], permission)
+	return nil
+}
+
+func TestSetAccountStatus(t *testing.T) {
+	const (
+		admin = iota + 1
+		moderator
+		trustedModerator
+		otherModerator
+		user
+		bannedUser
+	)
+
+	tests := []struct {
+		name        string
+		moderatorID int64
+		userID      int64
+		apply       func(s *Service, userID, moderatorID int64) error
+		wantErr     error
+	}{
+		{"moderator suspends user", moderator, user, suspend, nil},
+		{"moderator shadow-bans user", moderator, user, shadowBan, nil},
+		{"moderator suspends moderator", moderator, otherModerator, suspend, ErrOutranked},
+		{"moderator suspends self", moderator, moderator, suspend, ErrOutranked},
+		{"moderator reinstates admin", moderator, admin, reinstate, ErrOutranked},
+		{"moderator reinstates banned user", moderator, bannedUser, reinstate, ErrBanRequired},
+		{"moderator suspends banned user", moderator, bannedUser, suspend, ErrBanRequired},
+		{"moderator with ban grant reinstates banned user", trustedModerator, bannedUser, reinstate, nil},
+		{"admin reinstates banned user", admin, bannedUser, reinstate, nil},
+		{"admin bans moderator", admin, otherModerator, ban, nil},
+		{"missing user", admin, 99, suspend, ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &moderationRepo{
+				users: map[int64]*models.User{
+					admin:            {ID: admin, Role: string(RoleAdmin), AccountStatus: "active"},
+					moderator:        {ID: moderator, Role: string(RoleModerator), AccountStatus: "active"},
+					trustedModerator: {ID: trustedModerator, Role: string(RoleModerator), AccountStatus: "active"},
+					otherModerator:   {ID: otherModerator, Role: string(RoleModerator), AccountStatus: "active"},
+					user:             {ID: user, Role: string(RoleUser), AccountStatus: "active"},
+					bannedUser:       {ID: bannedUser, Role: string(RoleUser), AccountStatus: "banned"},
+				},
+				grants: map[int64][]string{trustedModerator: {string(PermUsersBan)}},
+			}
+			svc := NewService(repo, nil, nil, nil, nil, nil, Config{})
+
+			if err := tt.apply(svc, tt.userID, tt.moderatorID); err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func suspend(s *Service, userID, moderatorID int64) error {
+	_, err := s.SuspendUser(context.Background(), userID, moderatorID, "spam", time.Now().Add(time.Hour))
+	return err
+}
+
+func shadowBan(s *Service, userID, moderatorID int64) error {
+	_, err := s.ShadowBanUser(context.Background(), userID, moderatorID, "spam")
+	return err
+}
+
+func ban(s *Service, userID, moderatorID int64) error {
+	_, err := s.BanUser(context.Background(), userID, moderatorID, "spam")
+	return err
+}
+
+func reinstate(s *Service, userID, moderatorID int64) error {
+	_, err := s.ReinstateUser(context.Background(), userID, moderatorID, "appeal")
+	return err
+}
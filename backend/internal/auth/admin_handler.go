@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateUserRole handles changing a user's role
+// @Summary Change a user's role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.UpdateRoleRequest true "Role"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/role [put]
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.SetUserRole(c.Request.Context(), userID, Role(req.Role), c.GetInt64("user_id")); err != nil {
+		respondAdminError(c, err, "Failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Role updated successfully",
+	})
+}
+
+// GrantPermission handles granting an individual permission to a user
+// @Summary Grant a permission to a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.GrantPermissionRequest true "Permission"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/permissions [post]
+func (h *Handler) GrantPermission(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.GrantPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	actorID := c.GetInt64("user_id")
+	if err := h.service.GrantPermission(c.Request.Context(), userID, Permission(req.Permission), actorID); err != nil {
+		respondAdminError(c, err, "Failed to grant permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Permission granted successfully",
+	})
+}
+
+// RevokePermission handles revoking an individually granted permission
+// @Summary Revoke a permission from a user
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param permission path string true "Permission"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/permissions/{permission} [delete]
+func (h *Handler) RevokePermission(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokePermission(c.Request.Context(), userID, Permission(c.Param("permission")), c.GetInt64("user_id")); err != nil {
+		respondAdminError(c, err, "Failed to revoke permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Permission revoked successfully",
+	})
+}
+
+// SuspendUser handles suspending an account until a given time
+// @Summary Suspend a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.AccountActionRequest true "Reason and end of suspension"
+// @Success 200 {object} models.AccountAction
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id}/suspend [post]
+func (h *Handler) SuspendUser(c *gin.Context) {
+	h.applyAccountAction(c, func(userID, moderatorID int64, req *models.AccountActionRequest) (*models.AccountAction, error) {
+		if req.Until == nil {
+			return nil, ErrInvalidUntil
+		}
+		return h.service.SuspendUser(c.Request.Context(), userID, moderatorID, req.Reason, *req.Until)
+	})
+}
+
+// BanUser handles permanently banning an account
+// @Summary Ban a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.AccountActionRequest true "Reason"
+// @Success 200 {object} models.AccountAction
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id}/ban [post]
+func (h *Handler) BanUser(c *gin.Context) {
+	h.applyAccountAction(c, func(userID, moderatorID int64, req *models.AccountActionRequest) (*models.AccountAction, error) {
+		return h.service.BanUser(c.Request.Context(), userID, moderatorID, req.Reason)
+	})
+}
+
+// ShadowBanUser handles shadow-banning an account
+// @Summary Shadow-ban a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.AccountActionRequest true "Reason"
+// @Success 200 {object} models.AccountAction
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id}/shadow-ban [post]
+func (h *Handler) ShadowBanUser(c *gin.Context) {
+	h.applyAccountAction(c, func(userID, moderatorID int64, req *models.AccountActionRequest) (*models.AccountAction, error) {
+		return h.service.ShadowBanUser(c.Request.Context(), userID, moderatorID, req.Reason)
+	})
+}
+
+// ReinstateUser handles returning an account to the active state
+// @Summary Reinstate a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.AccountActionRequest true "Reason"
+// @Success 200 {object} models.AccountAction
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/users/{id}/reinstate [post]
+func (h *Handler) ReinstateUser(c *gin.Context) {
+	h.applyAccountAction(c, func(userID, moderatorID int64, req *models.AccountActionRequest) (*models.AccountAction, error) {
+		return h.service.ReinstateUser(c.Request.Context(), userID, moderatorID, req.Reason)
+	})
+}
+
+// GetAccountActions handles listing the moderation history of an account
+// @Summary Get a user's moderation history
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} models.AccountAction
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/users/{id}/actions [get]
+func (h *Handler) GetAccountActions(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	actions, err := h.service.GetAccountActions(c.Request.Context(), userID)
+	if err != nil {
+		respondAdminError(c, err, "Failed to get account actions")
+		return
+	}
+
+	c.JSON(http.StatusOK, actions)
+}
+
+// applyAccountAction binds an AccountActionRequest and runs a moderation action
+func (h *Handler) applyAccountAction(c *gin.Context, apply func(userID, moderatorID int64, req *models.AccountActionRequest) (*models.AccountAction, error)) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.AccountActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	action, err := apply(userID, c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondAdminError(c, err, "Failed to update account status")
+		return
+	}
+
+	c.JSON(http.StatusOK, action)
+}
+
+// parseUserIDParam parses the :id path parameter, writing a 400 response on failure
+func parseUserIDParam(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid user ID",
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+// respondAdminError maps service errors from admin operations to HTTP responses
+func respondAdminError(c *gin.Context, err error, message string) {
+	switch err {
+	case ErrUserNotFound:
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: "User not found",
+		})
+	case ErrInvalidRole:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_role",
+			Message: "Unknown role",
+		})
+	case ErrInvalidPermission:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_permission",
+			Message: "Unknown permission",
+		})
+	case ErrInvalidUntil:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_until",
+			Message: "A suspension end in the future is required",
+		})
+	case ErrOutranked:
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "You can only manage users whose role is below yours, with roles and permissions you hold",
+		})
+	case ErrBanRequired:
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Message: "Changing a banned account requires the users:ban permission",
+		})
+	case ErrStatusChanged:
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "status_changed",
+			Message: "The account changed while being moderated; try again",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: message,
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+var (
+	ErrDeletionPending   = errors.New("account deletion is already scheduled")
+	ErrNoDeletionPending = errors.New("no account deletion is scheduled")
+)
+
+// deletionBatch bounds the accounts erased per run
+const deletionBatch = 100
+
+// RequestDeletion schedules a user's account to be erased once the grace
+// period has passed, and emails them how to cancel
+func (s *Service) RequestDeletion(ctx context.Context, userID int64) (*models.AccountDeletion, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledAt := time.Now().Add(s.config.DeletionGracePeriod)
+	if err := s.repo.ScheduleDeletion(ctx, userID, scheduledAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrDeletionPending
+		}
+		return nil, err
+	}
+
+	err = s.mailer.Send(ctx, &notifications.EmailMessage{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf(
+			"We received a request to delete your account. It will be deleted for good on %s, along with your profile and videos.\n\nTo keep your account, sign in and cancel the deletion before then. If this wasn't you, cancel it and change your password.",
+			scheduledAt.UTC().Format("January 2, 2006 at 15:04 MST"),
+		),
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to send deletion email to user %d: %v", userID, err)
+	}
+
+	return &models.AccountDeletion{ScheduledAt: scheduledAt}, nil
+}
+
+// GetDeletion returns a user's pending deletion
+func (s *Service) GetDeletion(ctx context.Context, userID int64) (*models.AccountDeletion, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt == nil {
+		return nil, ErrNoDeletionPending
+	}
+	return &models.AccountDeletion{ScheduledAt: *user.DeletionScheduledAt}, nil
+}
+
+// CancelDeletion keeps a user's account that was scheduled for deletion
+func (s *Service) CancelDeletion(ctx context.Context, userID int64) error {
+	if err := s.repo.CancelDeletion(ctx, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNoDeletionPending
+		}
+		return err
+	}
+	return nil
+}
+
+// EraseDueAccounts erases the accounts whose deletion is due. A failure to
+// erase one account is logged and retried on the next run.
+func (s *Service) EraseDueAccounts(ctx context.Context) error {
+	now := time.Now()
+	ids, err := s.repo.GetDueDeletions(ctx, now, deletionBatch)
+	if err != nil {
+		return fmt.Errorf("failed to get due deletions: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := s.repo.EraseUser(ctx, id, now); err != nil {
+			// Canceled since it was listed
+			if err == sql.ErrNoRows {
+				continue
+			}
+			logger.ErrorLogger.Printf("Failed to erase user %d: %v", id, err)
+			continue
+		}
+		logger.InfoLogger.Printf("Erased user %d", id)
+	}
+	return nil
+}
+
+// RunDeletions erases due accounts every interval until ctx is cancelled
+func (s *Service) RunDeletions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.EraseDueAccounts(ctx); err != nil {
+				logger.ErrorLogger.Printf("Failed to erase accounts: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestDeletion handles scheduling the user's account for deletion
+// @Summary Delete your account
+// @Description Schedules the account to be erased after a grace period, during which the request can be canceled. Needs a recent sign-in.
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.AccountDeletion
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /me/deletion [post]
+func (h *Handler) RequestDeletion(c *gin.Context) {
+	deletion, err := h.service.RequestDeletion(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondDeletionError(c, err, "Failed to schedule deletion")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// GetDeletion handles getting the user's pending account deletion
+// @Summary Get your pending account deletion
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.AccountDeletion
+// @Failure 404 {object} models.ErrorResponse
+// @Router /me/deletion [get]
+func (h *Handler) GetDeletion(c *gin.Context) {
+	deletion, err := h.service.GetDeletion(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondDeletionError(c, err, "Failed to get deletion")
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
+
+// CancelDeletion handles keeping an account scheduled for deletion
+// @Summary Cancel your account deletion
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /me/deletion [delete]
+func (h *Handler) CancelDeletion(c *gin.Context) {
+	if err := h.service.CancelDeletion(c.Request.Context(), c.GetInt64("user_id")); err != nil {
+		respondDeletionError(c, err, "Failed to cancel deletion")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Account deletion canceled",
+	})
+}
+
+// respondDeletionError maps account deletion errors to responses
+func respondDeletionError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrNoDeletionPending):
+		status, code = http.StatusNotFound, "no_deletion_pending"
+	case errors.Is(err, ErrDeletionPending):
+		status, code = http.StatusConflict, "deletion_pending"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
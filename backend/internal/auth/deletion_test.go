@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// deletionRepo is an in-memory Repository for account deletion
+type deletionRepo struct {
+	Repository
+	user   *models.User
+	erased bool
+}
+
+func (r *deletionRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	user := *r.user
+	return &user, nil
+}
+
+func (r *deletionRepo) ScheduleDeletion(ctx context.Context, userID int64, at time.Time) error {
+	if r.user.DeletionScheduledAt != nil || r.erased {
+		return sql.ErrNoRows
+	}
+	r.user.DeletionScheduledAt = &at
+	return nil
+}
+
+func (r *deletionRepo) CancelDeletion(ctx context.Context, userID int64) error {
+	if r.user.DeletionScheduledAt == nil || r.erased {
+		return sql.ErrNoRows
+	}
+	r.user.DeletionScheduledAt = nil
+	return nil
+}
+
+func (r *deletionRepo) GetDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	if r.user.DeletionScheduledAt == nil || r.user.DeletionScheduledAt.After(now) || r.erased {
+		return nil, nil
+	}
+	return []int64{r.user.ID}, nil
+}
+
+func (r *deletionRepo) EraseUser(ctx context.Context, userID int64, now time.Time) error {
+	r.erased = true
+	return nil
+}
+
+func TestAccountDeletion(t *testing.T) {
+	logger.Init()
+	repo := &deletionRepo{user: &models.User{ID: 1, Email: "ada@example.com", AccountStatus: "active"}}
+	mailer := notifications.NewFakeEmailSender()
+	svc := NewService(repo, nil, nil, nil, mailer, nil, Config{DeletionGracePeriod: 30 * 24 * time.Hour})
+	ctx := context.Background()
+
+	if _, err := svc.GetDeletion(ctx, 1); err != ErrNoDeletionPending {
+		t.Errorf("Expected %v, got %v", ErrNoDeletionPending, err)
+	}
+
+	deletion, err := svc.RequestDeletion(ctx, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if days := time.Until(deletion.ScheduledAt).Hours() / 24; days < 29.9 || days > 30 {
+		t.Errorf("Expected deletion in 30 days, got %.1f", days)
+	}
+	if sent := mailer.Sent(); len(sent) != 1 || sent[0].To != "ada@example.com" || !strings.Contains(sent[0].Body, "cancel") {
+		t.Errorf("Expected an email on how to cancel, got %v", sent)
+	}
+	if _, err := svc.RequestDeletion(ctx, 1); err != ErrDeletionPending {
+		t.Errorf("Expected %v, got %v", ErrDeletionPending, err)
+	}
+
+	// Nothing is erased before the grace period ends
+	if err := svc.EraseDueAccounts(ctx); err != nil || repo.erased {
+		t.Fatalf("Expected no account to be erased yet, got %v", err)
+	}
+
+	if err := svc.CancelDeletion(ctx, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.CancelDeletion(ctx, 1); err != ErrNoDeletionPending {
+		t.Errorf("Expected %v, got %v", ErrNoDeletionPending, err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	repo.user.DeletionScheduledAt = &past
+	if err := svc.EraseDueAccounts(ctx); err != nil || !repo.erased {
+		t.Errorf("Expected a due account to be erased, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Client identifies the device a request came from
+type Client struct {
+	IP        string
+	UserAgent string
+	// DeviceName is the name apps report for the device, if any
+	DeviceName string
+	// Location is the approximate location of the IP, when known
+	Location string
+}
+
+// ClientOf returns the client making a request. Apps name the device in the
+// X-Device-Name header; the location is set by middleware.ClientLocation.
+func ClientOf(c *gin.Context) Client {
+	return Client{
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		DeviceName: c.GetHeader("X-Device-Name"),
+		Location:   c.GetString("client_location"),
+	}
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, msg *notifications.Message) error
+}
+
+// deviceHash identifies a device by its user agent
+func deviceHash(client Client) string {
+	sum := sha256.Sum256([]byte(client.UserAgent))
+	return hex.EncodeToString(sum[:])
+}
+
+// rememberDevice records the device a user signed in from. When alert is
+// set, the user is told about devices they have not used before; their
+// first device is never reported. Failures are logged so they never block
+// signing in.
+func (s *Service) rememberDevice(ctx context.Context, user *models.User, client Client, alert bool) {
+	isNew, err := s.repo.RecordLoginDevice(ctx, user.ID, deviceHash(client), client.UserAgent, client.IP)
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to record login device for user %d: %v", user.ID, err)
+		return
+	}
+	if !isNew || !alert {
+		return
+	}
+
+	userAgent := client.UserAgent
+	if userAgent == "" {
+		userAgent = "an unknown device"
+	}
+	err = s.notifier.Notify(ctx, user.ID, &notifications.Message{
+		Type:  notifications.TypeNewLogin,
+		Title: "New login to your account",
+		Body:  fmt.Sprintf("Your account was signed in to from %s (IP %s). If this wasn't you, change your password.", userAgent, client.IP),
+		Data: map[string]string{
+			"ip":         client.IP,
+			"user_agent": client.UserAgent,
+		},
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to send new login alert to user %d: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidEmailToken     = errors.New("email link is invalid or has expired")
+	ErrEmailAlreadyVerified  = errors.New("email is already verified")
+	ErrVerificationThrottled = errors.New("a verification email was sent recently")
+	ErrEmailNotVerified      = errors.New("a verified email is required")
+	ErrEmailTaken            = errors.New("email is already in use")
+	ErrSameEmail             = errors.New("new email is the current email")
+)
+
+// Email link purposes
+const (
+	purposeVerifyEmail = "verify_email"
+	purposeEmailChange = "email_change"
+)
+
+// Addresses that confirm an email change
+const (
+	changeOldAddress = "old"
+	changeNewAddress = "new"
+)
+
+// emailClaims are carried by the signed tokens in email links. A token is
+// bound to the address it was sent to, so it stops working if the account's
+// email changes first.
+type emailClaims struct {
+	Purpose  string `json:"purpose"`
+	UserID   int64  `json:"user_id"`
+	Email    string `json:"email"`
+	ChangeID int64  `json:"change_id,omitempty"`
+	Address  string `json:"address,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// emailKey derives the key email link tokens are signed with from the token
+// secret, so they can never be accepted as session tokens
+func emailKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("email-links"))
+	return mac.Sum(nil)
+}
+
+// ResendVerification emails a new verification link to a user whose email
+// is not verified, at most once per resend interval
+func (s *Service) ResendVerification(ctx context.Context, userID int64) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	notBefore := time.Now().Add(-s.config.VerificationResendInterval)
+	if err := s.repo.ClaimVerificationEmail(ctx, user.ID, notBefore); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrVerificationThrottled
+		}
+		return fmt.Errorf("failed to claim verification email: %w", err)
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// VerifyEmail marks an email verified with the token from a verification
+// link
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := s.parseEmailToken(token, purposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.MarkEmailVerified(ctx, claims.UserID, claims.Email); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidEmailToken
+		}
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	return nil
+}
+
+// RequestEmailChange starts moving an account to a new address. A
+// confirmation link is sent to both the current and the new address; the
+// change is applied once both have been followed.
+func (s *Service) RequestEmailChange(ctx context.Context, userID int64, req *models.ChangeEmailRequest) (*models.EmailChange, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.checkPassword(user, req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	if req.NewEmail == user.Email {
+		return nil, ErrSameEmail
+	}
+
+	existing, err := s.repo.GetUserByEmail(ctx, req.NewEmail)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrEmailTaken
+	}
+
+	change := &models.EmailChange{
+		UserID:    user.ID,
+		NewEmail:  req.NewEmail,
+		ExpiresAt: time.Now().Add(s.config.EmailTokenTTL),
+	}
+	if err := s.repo.CreateEmailChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("failed to create email change: %w", err)
+	}
+
+	confirmations := []struct {
+		address string
+		to      string
+		body    string
+	}{
+		{changeOldAddress, user.Email, "Someone asked to change the email of your account to %s. To allow it, open this link:\n\n%s\n\nIf this wasn't you, ignore this email and change your password."},
+		{changeNewAddress, req.NewEmail, "To use %s for your account, open this link:\n\n%s"},
+	}
+	for _, confirmation := range confirmations {
+		token, err := s.signEmailToken(&emailClaims{
+			Purpose:  purposeEmailChange,
+			UserID:   user.ID,
+			Email:    confirmation.to,
+			ChangeID: change.ID,
+			Address:  confirmation.address,
+		}, change.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		err = s.mailer.Send(ctx, &notifications.EmailMessage{
+			To:      confirmation.to,
+			Subject: "Confirm your new email address",
+			Body:    fmt.Sprintf(confirmation.body, req.NewEmail, link(s.config.EmailChangeURL, token)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to send email change confirmation: %w", err)
+		}
+	}
+
+	return change, nil
+}
+
+// ConfirmEmailChange records the confirmation from one address with the
+// token from an email change link, applying the change once both addresses
+// have confirmed it
+func (s *Service) ConfirmEmailChange(ctx context.Context, token string) (*models.EmailChange, error) {
+	claims, err := s.parseEmailToken(token, purposeEmailChange)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := s.repo.ConfirmEmailChange(ctx, claims.ChangeID, claims.UserID, claims.Address, time.Now())
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			return nil, ErrInvalidEmailToken
+		case errors.Is(err, ErrEmailTaken):
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to confirm email change: %w", err)
+	}
+	return change, nil
+}
+
+// CheckEmailVerified returns ErrEmailNotVerified unless the user has
+// verified their email
+func (s *Service) CheckEmailVerified(ctx context.Context, userID int64) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// startVerification sends a new user their first verification link.
+// Failures are logged so they never block signing up; the user can ask for
+// the link again.
+func (s *Service) startVerification(ctx context.Context, user *models.User) {
+	if err := s.repo.ClaimVerificationEmail(ctx, user.ID, time.Now()); err != nil {
+		logger.ErrorLogger.Printf("Failed to claim verification email for user %d: %v", user.ID, err)
+		return
+	}
+	if err := s.sendVerification(ctx, user); err != nil {
+		logger.ErrorLogger.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+}
+
+// sendVerification emails a verification link to a user's current address
+func (s *Service) sendVerification(ctx context.Context, user *models.User) error {
+	token, err := s.signEmailToken(&emailClaims{
+		Purpose: purposeVerifyEmail,
+		UserID:  user.ID,
+		Email:   user.Email,
+	}, time.Now().Add(s.config.EmailTokenTTL))
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, &notifications.EmailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("To verify your email address, open this link:\n\n%s", link(s.config.EmailVerificationURL, token)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// signEmailToken signs the claims of an email link, expiring at the given
+// time
+func (s *Service) signEmailToken(claims *emailClaims, expiresAt time.Time) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.emailKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign email token: %w", err)
+	}
+	return token, nil
+}
+
+// parseEmailToken verifies the token of an email link for the given purpose
+func (s *Service) parseEmailToken(token, purpose string) (*emailClaims, error) {
+	claims := &emailClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.emailKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.Purpose != purpose {
+		return nil, ErrInvalidEmailToken
+	}
+	return claims, nil
+}
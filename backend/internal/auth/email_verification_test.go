@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// emailRepo is an in-memory Repository for the email flows; the embedded
+// interface panics on methods they do not use
+type emailRepo struct {
+	Repository
+	users   map[int64]*models.User
+	changes map[int64]*models.EmailChange
+}
+
+func (r *emailRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *emailRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *emailRepo) MarkEmailVerified(ctx context.Context, userID int64, email string) error {
+	user, ok := r.users[userID]
+	if !ok || user.Email != email {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return nil
+}
+
+func (r *emailRepo) CreateEmailChange(ctx context.Context, change *models.EmailChange) error {
+	change.ID = int64(len(r.changes) + 1)
+	for id, pending := range r.changes {
+		if pending.UserID == change.UserID {
+			delete(r.changes, id)
+		}
+	}
+	copied := *change
+	r.changes[change.ID] = &copied
+	return nil
+}
+
+func (r *emailRepo) ConfirmEmailChange(ctx context.Context, changeID, userID int64, address string, now time.Time) (*models.EmailChange, error) {
+	change, ok := r.changes[changeID]
+	if !ok || change.UserID != userID || !change.ExpiresAt.After(now) {
+		return nil, sql.ErrNoRows
+	}
+	if address == changeOldAddress {
+		change.OldConfirmedAt = &now
+	} else {
+		change.NewConfirmedAt = &now
+	}
+	copied := *change
+	if change.OldConfirmedAt != nil && change.NewConfirmedAt != nil {
+		r.users[userID].Email = change.NewEmail
+		r.users[userID].EmailVerifiedAt = &now
+		delete(r.changes, changeID)
+		copied.Completed = true
+	}
+	return &copied, nil
+}
+
+func TestEmailChange(t *testing.T) {
+	logger.Init()
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo := &emailRepo{
+		users: map[int64]*models.User{
+			1: {ID: 1, Email: "old@example.com", PasswordHash: string(hash)},
+			2: {ID: 2, Email: "taken@example.com"},
+		},
+		changes: make(map[int64]*models.EmailChange),
+	}
+	mailer := notifications.NewFakeEmailSender()
+	svc := NewService(repo, nil, nil, nil, mailer, nil, Config{
+		EmailChangeURL: "https://halo.example/confirm-email",
+		EmailTokenTTL:  time.Hour,
+		TokenSecret:    "secret",
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		newEmail string
+		password string
+		wantErr  error
+	}{
+		{"wrong password", "new@example.com", "wrong", ErrInvalidCredentials},
+		{"same email", "old@example.com", "password", ErrSameEmail},
+		{"taken email", "taken@example.com", "password", ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.RequestEmailChange(ctx, 1, &models.ChangeEmailRequest{NewEmail: tt.newEmail, Password: tt.password})
+			if err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := svc.RequestEmailChange(ctx, 1, &models.ChangeEmailRequest{NewEmail: "new@example.com", Password: "password"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	tokens := make(map[string]string)
+	for _, msg := range mailer.Sent() {
+		tokens[msg.To] = tokenFromLink(t, msg.Body)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("Expected confirmations to both addresses, got %v", tokens)
+	}
+
+	if err := svc.VerifyEmail(ctx, tokens["new@example.com"]); err != ErrInvalidEmailToken {
+		t.Errorf("Expected a change token not to verify email, got %v", err)
+	}
+
+	change, err := svc.ConfirmEmailChange(ctx, tokens["new@example.com"])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if change.Completed || repo.users[1].Email != "old@example.com" {
+		t.Errorf("Expected the change to wait for the old address")
+	}
+
+	change, err = svc.ConfirmEmailChange(ctx, tokens["old@example.com"])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !change.Completed || repo.users[1].Email != "new@example.com" || repo.users[1].EmailVerifiedAt == nil {
+		t.Errorf("Expected the new address to be applied and verified")
+	}
+
+	if _, err := svc.ConfirmEmailChange(ctx, tokens["old@example.com"]); err != ErrInvalidEmailToken {
+		t.Errorf("Expected error %v, got %v", ErrInvalidEmailToken, err)
+	}
+}
+
+func TestEmailTokensAreNotSessionTokens(t *testing.T) {
+	svc := NewService(&emailRepo{}, nil, nil, nil, nil, nil, Config{TokenSecret: "secret"})
+	token, err := svc.signEmailToken(&emailClaims{Purpose: purposeVerifyEmail, UserID: 1, Email: "a@example.com"}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := NewJWTManager("secret", 1).ValidateToken(token); err == nil {
+		t.Errorf("Expected an email token to be refused as a session token")
+	}
+	if _, err := svc.parseEmailToken(token, purposeEmailChange); err != ErrInvalidEmailToken {
+		t.Errorf("Expected a token for another purpose to be refused, got %v", err)
+	}
+
+	expired, err := svc.signEmailToken(&emailClaims{Purpose: purposeVerifyEmail, UserID: 1}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := svc.parseEmailToken(expired, purposeVerifyEmail); err != ErrInvalidEmailToken {
+		t.Errorf("Expected an expired token to be refused, got %v", err)
+	}
+}
+
+// tokenFromLink extracts the token from the first link in an email body
+func tokenFromLink(t *testing.T, body string) string {
+	t.Helper()
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "https://") {
+			link, err := url.Parse(line)
+			if err != nil {
+				t.Fatalf("Expected a valid link, got %v", err)
+			}
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("Expected a link in %q", body)
+	return ""
+}
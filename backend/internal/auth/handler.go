@@ -2,9 +2,14 @@ package auth
 
 import (
 	"database/sql"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/moderation"
 	"github.com/gin-gonic/gin"
 )
 
@@ -42,8 +47,14 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Register(c.Request.Context(), &req)
+	user, err := h.service.Register(c.Request.Context(), &req, ClientOf(c))
 	if err != nil {
+		if moderation.RespondRejected(c, err) {
+			return
+		}
+		if respondPasswordRejected(c, err) {
+			return
+		}
 		if err == ErrUserExists {
 			c.JSON(http.StatusConflict, models.ErrorResponse{
 				Error:   "user_exists",
@@ -59,7 +70,7 @@ func (h *Handler) Register(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, err := h.jwtManager.GenerateToken(user.ID, user.Email, user.Username)
+	token, err := h.issueToken(c, user, []string{MethodPassword})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "internal_error",
@@ -76,13 +87,17 @@ func (h *Handler) Register(c *gin.Context) {
 
 // Login handles user login
 // @Summary Login a user
+// @Description Accounts with two-factor authentication get an MFA challenge instead of a token; answer it at /auth/login/mfa
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body models.LoginRequest true "Login request"
 // @Success 200 {object} models.AuthResponse
+// @Success 200 {object} models.MFAChallenge
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Router /auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
 	var req models.LoginRequest
@@ -94,7 +109,7 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Login(c.Request.Context(), &req)
+	user, challenge, err := h.service.Login(c.Request.Context(), &req, ClientOf(c))
 	if err != nil {
 		if err == ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
@@ -103,15 +118,25 @@ func (h *Handler) Login(c *gin.Context) {
 			})
 			return
 		}
+		if respondThrottled(c, err) {
+			return
+		}
+		if RespondAccountState(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "internal_error",
 			Message: "Failed to authenticate user",
 		})
 		return
 	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
 
 	// Generate JWT token
-	token, err := h.jwtManager.GenerateToken(user.ID, user.Email, user.Username)
+	token, err := h.issueToken(c, user, []string{MethodPassword})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "internal_error",
@@ -126,6 +151,227 @@ func (h *Handler) Login(c *gin.Context) {
 	})
 }
 
+// ForgotPassword handles requests for a password reset link
+// @Summary Request a password reset link
+// @Description The response is the same whether or not an account uses the email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to request password reset",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Message: "If an account exists for this email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+// @Summary Reset a password
+// @Description Redeems a reset token and signs the account out everywhere
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if err == ErrInvalidResetToken {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_token",
+				Message: err.Error(),
+			})
+			return
+		}
+		if respondPasswordRejected(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to reset password",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Your password has been reset; sign in with the new password",
+	})
+}
+
+// ChangePassword handles changing the password of the current user
+// @Summary Change password
+// @Description Signs the account out on other devices
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /auth/password [post]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := h.service.ChangePassword(c.Request.Context(), c.GetInt64("user_id"), c.GetInt64("session_id"), &req, ClientOf(c))
+	if err != nil {
+		respondPasswordChangeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Your password has been changed",
+	})
+}
+
+// VerifyEmail handles verification links
+// @Summary Verify an email address
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailTokenRequest true "Token from the verification link"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /auth/email/verify [post]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req models.EmailTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		respondEmailError(c, err, "Failed to verify email")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Your email has been verified",
+	})
+}
+
+// ResendVerification handles requests for a new verification link
+// @Summary Resend the verification email
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.SuccessResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /auth/email/verify/resend [post]
+func (h *Handler) ResendVerification(c *gin.Context) {
+	if err := h.service.ResendVerification(c.Request.Context(), c.GetInt64("user_id")); err != nil {
+		respondEmailError(c, err, "Failed to send verification email")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Message: "A verification link has been sent",
+	})
+}
+
+// ChangeEmail handles requests to move an account to a new email address
+// @Summary Change email address
+// @Description Sends a confirmation link to both the current and the new address; the change is applied once both are followed
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChangeEmailRequest true "New email and current password"
+// @Success 202 {object} models.EmailChange
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/email/change [post]
+func (h *Handler) ChangeEmail(c *gin.Context) {
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	change, err := h.service.RequestEmailChange(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondEmailError(c, err, "Failed to change email")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, change)
+}
+
+// ConfirmEmailChange handles email change confirmation links
+// @Summary Confirm an email change
+// @Description Records the confirmation from the address the link was sent to; completed is set once both addresses have confirmed and the new one applied
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailTokenRequest true "Token from the confirmation link"
+// @Success 200 {object} models.EmailChange
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/email/change/confirm [post]
+func (h *Handler) ConfirmEmailChange(c *gin.Context) {
+	var req models.EmailTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	change, err := h.service.ConfirmEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		respondEmailError(c, err, "Failed to confirm email change")
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
 // GetProfile handles getting the current user's profile
 // @Summary Get current user profile
 // @Tags auth
@@ -163,3 +409,256 @@ func (h *Handler) GetProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// GetPublicProfile handles getting another user's public profile
+// @Summary Get a user's public profile
+// @Description Pass creator_id to include the user's subscriber badge in that creator's channel
+// @Tags users
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Param creator_id query int false "Creator whose channel the profile is shown in"
+// @Success 200 {object} models.PublicProfile
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /users/{user_id} [get]
+func (h *Handler) GetPublicProfile(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid user ID",
+		})
+		return
+	}
+
+	var creatorID int64
+	if raw := c.Query("creator_id"); raw != "" {
+		creatorID, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_id",
+				Message: "Invalid creator ID",
+			})
+			return
+		}
+	}
+
+	profile, err := h.service.GetPublicProfile(c.Request.Context(), userID, c.GetInt64("user_id"), creatorID)
+	if err != nil {
+		if err == ErrUserNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get user profile",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile handles updating the current user's profile
+// @Summary Update current user profile
+// @Description A display name or bio held for review is listed in pending_review and the current value is kept until it is approved
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateProfileRequest true "Profile changes"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Router /auth/me [patch]
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.service.UpdateProfile(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		if moderation.RespondRejected(c, err) {
+			return
+		}
+		switch err {
+		case ErrUserNotFound:
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "User not found",
+			})
+		case ErrNotAdult:
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "not_adult",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to update profile",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// issueToken starts a session for a user on the requesting device and signs
+// a token for it
+func (h *Handler) issueToken(c *gin.Context, user *models.User, methods []string) (string, error) {
+	session, err := h.service.StartSession(c.Request.Context(), user.ID, ClientOf(c))
+	if err != nil {
+		return "", err
+	}
+	return h.signToken(c, user, methods, session.ID)
+}
+
+// signToken generates a JWT for a session carrying the user's role and
+// permissions and the methods they just authenticated with
+func (h *Handler) signToken(c *gin.Context, user *models.User, methods []string, sessionID int64) (string, error) {
+	claims, err := h.service.Claims(c.Request.Context(), user)
+	if err != nil {
+		return "", err
+	}
+	claims.AMR = methods
+	claims.AuthTime = time.Now().Unix()
+	claims.SessionID = sessionID
+	return h.jwtManager.GenerateTokenWithClaims(claims)
+}
+
+// respondEmailError maps email verification and change errors to responses
+func respondEmailError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrInvalidEmailToken):
+		status, code = http.StatusBadRequest, "invalid_token"
+	case errors.Is(err, ErrSameEmail):
+		status, code = http.StatusBadRequest, "same_email"
+	case errors.Is(err, ErrInvalidCredentials):
+		status, code = http.StatusUnauthorized, "invalid_credentials"
+	case errors.Is(err, ErrUserNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrEmailAlreadyVerified):
+		status, code = http.StatusConflict, "already_verified"
+	case errors.Is(err, ErrEmailTaken):
+		status, code = http.StatusConflict, "email_taken"
+	case errors.Is(err, ErrVerificationThrottled):
+		status, code = http.StatusTooManyRequests, "verification_throttled"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
+
+// respondThrottled writes a 429 response with a Retry-After header for
+// blocked logins and second factors and reports whether err was a
+// ThrottledError
+func respondThrottled(c *gin.Context, err error) bool {
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+		Error:   "too_many_attempts",
+		Message: err.Error(),
+	})
+	return true
+}
+
+// respondPasswordChangeError maps password change errors to responses
+func respondPasswordChangeError(c *gin.Context, err error) {
+	if respondThrottled(c, err) || respondPasswordRejected(c, err) {
+		return
+	}
+
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrInvalidCredentials):
+		status, code = http.StatusUnauthorized, "invalid_credentials"
+	case errors.Is(err, ErrSamePassword):
+		status, code = http.StatusBadRequest, "same_password"
+	case errors.Is(err, ErrUserNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: "Failed to change password",
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
+
+// respondPasswordRejected writes a 400 response naming the password policy
+// rule a new password broke and reports whether err was such an error
+func respondPasswordRejected(c *gin.Context, err error) bool {
+	var code string
+	switch {
+	case errors.Is(err, ErrPasswordTooShort):
+		code = "password_too_short"
+	case errors.Is(err, ErrPasswordTooLong):
+		code = "password_too_long"
+	case errors.Is(err, ErrPasswordTooWeak):
+		code = "password_too_weak"
+	case errors.Is(err, ErrPasswordPersonal):
+		code = "password_contains_personal_info"
+	case errors.Is(err, ErrPasswordBreached):
+		code = "password_breached"
+	default:
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+	return true
+}
+
+// RespondAccountState writes a 403 response describing a suspended or banned
+// account and reports whether err was such an error
+func RespondAccountState(c *gin.Context, err error) bool {
+	var stateErr *AccountStateError
+	if !errors.As(err, &stateErr) {
+		return false
+	}
+
+	code := "account_suspended"
+	if stateErr.Status == StatusBanned {
+		code = "account_banned"
+	}
+
+	c.JSON(http.StatusForbidden, models.ErrorResponse{
+		Error:   code,
+		Message: stateErr.Error(),
+	})
+	return true
+}
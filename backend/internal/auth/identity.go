@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/moderation"
+)
+
+var (
+	ErrUnknownProvider         = errors.New("unknown sign-in provider")
+	ErrInvalidIDToken          = errors.New("invalid ID token")
+	ErrProviderEmailUnverified = errors.New("the provider has not verified this email")
+	ErrAccountExists           = errors.New("an account already uses this email; sign in to it and link the provider")
+	ErrIdentityInUse           = errors.New("this provider account is linked to another user")
+	ErrProviderLinked          = errors.New("another account at this provider is already linked")
+	ErrIdentityNotFound        = errors.New("provider is not linked")
+	ErrLastSignInMethod        = errors.New("set a password or add a passkey before unlinking your only sign-in method")
+)
+
+// MethodFederated is the amr value of a login through an identity provider
+const MethodFederated = "fed"
+
+const (
+	// usernameAttempts bounds the generated usernames tried for a new account
+	usernameAttempts = 5
+	maxUsernameBase  = 20
+)
+
+// LoginWithOIDC signs a user in with an ID token from a provider. Unknown
+// identities are linked to the account with the same email when both the
+// provider and the account have verified it, or get a new account. Users
+// with two-factor authentication get a challenge instead, as with Login.
+func (s *Service) LoginWithOIDC(ctx context.Context, provider string, req *models.OIDCLoginRequest, client Client) (*models.User, *models.MFAChallenge, error) {
+	claims, err := s.verifyIDToken(ctx, provider, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user *models.User
+	created := false
+	identity, err := s.repo.GetIdentity(ctx, provider, claims.Subject)
+	switch {
+	case err == nil:
+		if user, err = s.GetUserByID(ctx, identity.UserID); err != nil {
+			return nil, nil, err
+		}
+	case err == sql.ErrNoRows:
+		if user, created, err = s.userForIdentity(ctx, provider, claims); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	if err := checkAccountStatus(user, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	if user.MFAEnabled {
+		challenge, err := s.newMFAChallenge(ctx, user, MethodFederated)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	s.rememberDevice(ctx, user, client, !created)
+	return user, nil, nil
+}
+
+// ListIdentities returns the provider accounts linked to a user
+func (s *Service) ListIdentities(ctx context.Context, userID int64) ([]*models.UserIdentity, error) {
+	identities, err := s.repo.GetIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identities: %w", err)
+	}
+	return identities, nil
+}
+
+// LinkIdentity links the provider account an ID token is for to a
+// signed-in user
+func (s *Service) LinkIdentity(ctx context.Context, userID int64, provider string, req *models.OIDCLoginRequest) (*models.UserIdentity, error) {
+	claims, err := s.verifyIDToken(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityInUse
+		}
+		return existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	identity := newIdentity(userID, provider, claims)
+	if err := s.repo.CreateIdentity(ctx, identity); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrProviderLinked
+		}
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+	return identity, nil
+}
+
+// UnlinkIdentity unlinks a provider from a user, as long as they keep
+// another way to sign in
+func (s *Service) UnlinkIdentity(ctx context.Context, userID int64, provider string) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	identities, err := s.repo.GetIdentities(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get identities: %w", err)
+	}
+	passkeys, err := s.repo.GetPasskeys(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get passkeys: %w", err)
+	}
+
+	linked := false
+	for _, identity := range identities {
+		linked = linked || identity.Provider == provider
+	}
+	if !linked {
+		return ErrIdentityNotFound
+	}
+	if user.PasswordHash == "" && len(identities) == 1 && len(passkeys) == 0 {
+		return ErrLastSignInMethod
+	}
+
+	if err := s.repo.DeleteIdentity(ctx, userID, provider); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrIdentityNotFound
+		}
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	return nil
+}
+
+// verifyIDToken verifies an ID token with the named provider's verifier
+func (s *Service) verifyIDToken(ctx context.Context, provider string, req *models.OIDCLoginRequest) (*idTokenClaims, error) {
+	verifier, ok := s.oidc[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	claims, err := verifier.verify(ctx, req.IDToken, req.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	return claims, nil
+}
+
+// userForIdentity links a new identity to the account with its email, or
+// creates an account for it, and reports whether the account is new. An
+// account whose email is unverified is not linked, since whoever created
+// it may not own the address.
+func (s *Service) userForIdentity(ctx context.Context, provider string, claims *idTokenClaims) (*models.User, bool, error) {
+	if claims.Email == "" || !bool(claims.EmailVerified) {
+		return nil, false, ErrProviderEmailUnverified
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, claims.Email)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		user, err = s.createOIDCUser(ctx, provider, claims)
+		return user, err == nil, err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return nil, false, ErrAccountExists
+	}
+	if err := s.repo.CreateIdentity(ctx, newIdentity(user.ID, provider, claims)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, ErrProviderLinked
+		}
+		return nil, false, fmt.Errorf("failed to link identity: %w", err)
+	}
+	return user, false, nil
+}
+
+// createOIDCUser creates an account for a new identity. It has a generated
+// username, a verified email and no password; the display name comes from
+// the provider when moderation allows it.
+func (s *Service) createOIDCUser(ctx context.Context, provider string, claims *idTokenClaims) (*models.User, error) {
+	now := time.Now()
+	user := &models.User{
+		Email:           claims.Email,
+		Role:            string(RoleUser),
+		AccountStatus:   string(StatusActive),
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	var err error
+	for i := 0; i < usernameAttempts; i++ {
+		if user.Username, err = newUsername(claims.Email); err != nil {
+			return nil, err
+		}
+		user.DisplayName = user.Username
+		identity := newIdentity(0, provider, claims)
+		if err = s.repo.CreateUserWithIdentity(ctx, user, identity); err != sql.ErrNoRows {
+			break
+		}
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserExists
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.adoptProviderName(ctx, user, claims.Name)
+	return user, nil
+}
+
+// adoptProviderName makes the name a provider knows a new user by their
+// display name, if moderation allows it. Held names are queued and shown
+// once approved; failures keep the generated name.
+func (s *Service) adoptProviderName(ctx context.Context, user *models.User, name string) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 50 {
+		return
+	}
+	verdict, err := s.moderation.Check(ctx, moderation.FieldDisplayName, name)
+	if err != nil {
+		return
+	}
+	switch verdict.Decision {
+	case moderation.Allow:
+		user.DisplayName = name
+		if err := s.repo.UpdateUser(ctx, user); err != nil {
+			user.DisplayName = user.Username
+		}
+	case moderation.Hold:
+		if err := s.moderation.Hold(ctx, moderation.FieldDisplayName, user.ID, user.ID, name, verdict); err == nil {
+			user.PendingReview = []string{moderation.FieldDisplayName.Name()}
+		}
+	}
+}
+
+func newIdentity(userID int64, provider string, claims *idTokenClaims) *models.UserIdentity {
+	return &models.UserIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+}
+
+// newUsername derives a username from an email's local part with a random
+// number appended
+func newUsername(email string) (string, error) {
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	var base strings.Builder
+	for _, r := range local {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			base.WriteRune(r)
+		}
+		if base.Len() == maxUsernameBase {
+			break
+		}
+	}
+	if base.Len() < 3 {
+		base.WriteString("user")
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate username: %w", err)
+	}
+	return fmt.Sprintf("%s%04d", base.String(), n.Int64()), nil
+}
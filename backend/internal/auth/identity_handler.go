@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginWithOIDC handles signing in with an identity provider
+// @Summary Sign in with Apple or Google
+// @Description Verifies an ID token the app got from the provider. New identities are linked to the account with the same verified email or get a new account. Users with two-factor authentication get a challenge instead of a token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider (apple, google)"
+// @Param request body models.OIDCLoginRequest true "ID token"
+// @Success 200 {object} models.AuthResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/oidc/{provider} [post]
+func (h *Handler) LoginWithOIDC(c *gin.Context) {
+	var req models.OIDCLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, challenge, err := h.service.LoginWithOIDC(c.Request.Context(), c.Param("provider"), &req, ClientOf(c))
+	if err != nil {
+		if RespondAccountState(c, err) {
+			return
+		}
+		respondIdentityError(c, err, "Failed to authenticate user")
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	token, err := h.issueToken(c, user, []string{MethodFederated})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthResponse{
+		Token: token,
+		User:  user,
+	})
+}
+
+// ListIdentities handles listing the providers linked to the user
+// @Summary List linked providers
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.UserIdentity
+// @Router /auth/identities [get]
+func (h *Handler) ListIdentities(c *gin.Context) {
+	identities, err := h.service.ListIdentities(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondIdentityError(c, err, "Failed to get linked providers")
+		return
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// LinkIdentity handles linking a provider to the user
+// @Summary Link a provider
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider (apple, google)"
+// @Param request body models.OIDCLoginRequest true "ID token"
+// @Success 200 {object} models.UserIdentity
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/identities/{provider} [post]
+func (h *Handler) LinkIdentity(c *gin.Context) {
+	var req models.OIDCLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	identity, err := h.service.LinkIdentity(c.Request.Context(), c.GetInt64("user_id"), c.Param("provider"), &req)
+	if err != nil {
+		respondIdentityError(c, err, "Failed to link provider")
+		return
+	}
+
+	c.JSON(http.StatusOK, identity)
+}
+
+// UnlinkIdentity handles unlinking a provider from the user
+// @Summary Unlink a provider
+// @Description Refused when the provider is the user's only way to sign in
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider (apple, google)"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/identities/{provider} [delete]
+func (h *Handler) UnlinkIdentity(c *gin.Context) {
+	if err := h.service.UnlinkIdentity(c.Request.Context(), c.GetInt64("user_id"), c.Param("provider")); err != nil {
+		respondIdentityError(c, err, "Failed to unlink provider")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Provider unlinked",
+	})
+}
+
+// respondIdentityError maps identity provider errors to responses
+func respondIdentityError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrInvalidIDToken):
+		status, code = http.StatusUnauthorized, "invalid_id_token"
+	case errors.Is(err, ErrProviderEmailUnverified):
+		status, code = http.StatusForbidden, "provider_email_unverified"
+	case errors.Is(err, ErrUnknownProvider):
+		status, code = http.StatusNotFound, "unknown_provider"
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrIdentityNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrAccountExists):
+		status, code = http.StatusConflict, "account_exists"
+	case errors.Is(err, ErrUserExists):
+		status, code = http.StatusConflict, "user_exists"
+	case errors.Is(err, ErrIdentityInUse):
+		status, code = http.StatusConflict, "identity_in_use"
+	case errors.Is(err, ErrProviderLinked):
+		status, code = http.StatusConflict, "provider_linked"
+	case errors.Is(err, ErrLastSignInMethod):
+		status, code = http.StatusConflict, "last_sign_in_method"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
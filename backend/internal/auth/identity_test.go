@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeIssuer is an OpenID Connect provider that publishes its signing key
+// from a local server
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	issuer := &fakeIssuer{key: key, kid: "test-key"}
+	issuer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": issuer.kid,
+				"use": "sig",
+				"n":   passkeyEncoding.EncodeToString(key.N.Bytes()),
+				"e":   passkeyEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (f *fakeIssuer) provider(name string) OIDCProvider {
+	return OIDCProvider{
+		Name:      name,
+		Issuers:   []string{f.server.URL},
+		JWKSURL:   f.server.URL,
+		ClientIDs: []string{"halo-app"},
+	}
+}
+
+// token signs an ID token, overriding the default claims with extra
+func (f *fakeIssuer) token(t *testing.T, subject, email string, extra jwt.MapClaims) *models.OIDCLoginRequest {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            f.server.URL,
+		"aud":            "halo-app",
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = f.kid
+	signed, err := token.SignedString(f.key)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return &models.OIDCLoginRequest{IDToken: signed}
+}
+
+// identityRepo is an in-memory Repository for users and their identities
+type identityRepo struct {
+	Repository
+	users      []*models.User
+	identities []*models.UserIdentity
+}
+
+func (r *identityRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *identityRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *identityRepo) RecordLoginDevice(ctx context.Context, userID int64, deviceHash, userAgent, ip string) (bool, error) {
+	return false, nil
+}
+
+func (r *identityRepo) GetPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error) {
+	return nil, nil
+}
+
+func (r *identityRepo) GetIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *identityRepo) GetIdentities(ctx context.Context, userID int64) ([]*models.UserIdentity, error) {
+	var identities []*models.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *identityRepo) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	for _, existing := range r.identities {
+		if existing.Provider == identity.Provider && (existing.Subject == identity.Subject || existing.UserID == identity.UserID) {
+			return sql.ErrNoRows
+		}
+	}
+	identity.ID = int64(len(r.identities) + 1)
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *identityRepo) CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	if _, err := r.GetUserByEmail(ctx, user.Email); err == nil {
+		return sql.ErrNoRows
+	}
+	user.ID = int64(len(r.users) + 1)
+	r.users = append(r.users, user)
+	identity.UserID = user.ID
+	return r.CreateIdentity(ctx, identity)
+}
+
+func (r *identityRepo) DeleteIdentity(ctx context.Context, userID int64, provider string) error {
+	for i, identity := range r.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func TestLoginWithOIDC(t *testing.T) {
+	verified := time.Now()
+	repo := &identityRepo{users: []*models.User{
+		{ID: 1, Email: "ada@example.com", Username: "ada", PasswordHash: "hash", AccountStatus: "active", EmailVerifiedAt: &verified},
+		{ID: 2, Email: "grace@example.com", Username: "grace", PasswordHash: "hash", AccountStatus: "active"},
+	}}
+	google, apple := newFakeIssuer(t), newFakeIssuer(t)
+	svc := NewService(repo, nil, nil, nil, nil, nil, Config{
+		OIDCProviders: []OIDCProvider{google.provider("google"), apple.provider("apple")},
+	})
+	ctx := context.Background()
+
+	user, _, err := svc.LoginWithOIDC(ctx, "google", google.token(t, "g-1", "ada@example.com", nil), Client{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.ID != 1 {
+		t.Errorf("Expected the verified account with the email to be linked, got user %d", user.ID)
+	}
+
+	if _, _, err := svc.LoginWithOIDC(ctx, "google", google.token(t, "g-2", "grace@example.com", nil), Client{}); err != ErrAccountExists {
+		t.Errorf("Expected an unverified account not to be linked, got %v", err)
+	}
+
+	// Apple sends email_verified as a string
+	created, _, err := svc.LoginWithOIDC(ctx, "apple", apple.token(t, "a-1", "new.user@example.com", jwt.MapClaims{"email_verified": "true"}), Client{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(created.Username, "newuser") || created.EmailVerifiedAt == nil || created.PasswordHash != "" {
+		t.Errorf("Unexpected new account %+v", created)
+	}
+	again, _, err := svc.LoginWithOIDC(ctx, "apple", apple.token(t, "a-1", "new.user@example.com", nil), Client{})
+	if err != nil || again.ID != created.ID {
+		t.Errorf("Expected the linked account back, got %v, %v", again, err)
+	}
+
+	if _, _, err := svc.LoginWithOIDC(ctx, "google", google.token(t, "g-3", "eve@example.com", jwt.MapClaims{"email_verified": false}), Client{}); err != ErrProviderEmailUnverified {
+		t.Errorf("Expected %v, got %v", ErrProviderEmailUnverified, err)
+	}
+	if _, _, err := svc.LoginWithOIDC(ctx, "github", google.token(t, "g-1", "ada@example.com", nil), Client{}); err != ErrUnknownProvider {
+		t.Errorf("Expected %v, got %v", ErrUnknownProvider, err)
+	}
+
+	tests := []struct {
+		name string
+		req  *models.OIDCLoginRequest
+	}{
+		{"other audience", google.token(t, "g-1", "ada@example.com", jwt.MapClaims{"aud": "other-app"})},
+		{"other issuer", google.token(t, "g-1", "ada@example.com", jwt.MapClaims{"iss": "https://evil.example"})},
+		{"expired", google.token(t, "g-1", "ada@example.com", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})},
+		{"other provider's key", apple.token(t, "g-1", "ada@example.com", jwt.MapClaims{"iss": google.server.URL})},
+		{"nonce mismatch", func() *models.OIDCLoginRequest {
+			req := google.token(t, "g-1", "ada@example.com", jwt.MapClaims{"nonce": "abc"})
+			req.Nonce = "xyz"
+			return req
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := svc.LoginWithOIDC(ctx, "google", tt.req, Client{}); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("Expected %v, got %v", ErrInvalidIDToken, err)
+			}
+		})
+	}
+}
+
+func TestLinkIdentity(t *testing.T) {
+	repo := &identityRepo{users: []*models.User{
+		{ID: 1, Email: "ada@example.com", PasswordHash: "", AccountStatus: "active"},
+		{ID: 2, Email: "grace@example.com", PasswordHash: "hash", AccountStatus: "active"},
+	}}
+	repo.identities = []*models.UserIdentity{{ID: 1, UserID: 1, Provider: "google", Subject: "g-1"}}
+	google, apple := newFakeIssuer(t), newFakeIssuer(t)
+	svc := NewService(repo, nil, nil, nil, nil, nil, Config{
+		OIDCProviders: []OIDCProvider{google.provider("google"), apple.provider("apple")},
+	})
+	ctx := context.Background()
+
+	if err := svc.UnlinkIdentity(ctx, 1, "google"); err != ErrLastSignInMethod {
+		t.Errorf("Expected %v, got %v", ErrLastSignInMethod, err)
+	}
+	if _, err := svc.LinkIdentity(ctx, 2, "google", google.token(t, "g-1", "ada@example.com", nil)); err != ErrIdentityInUse {
+		t.Errorf("Expected %v, got %v", ErrIdentityInUse, err)
+	}
+	if _, err := svc.LinkIdentity(ctx, 1, "google", google.token(t, "g-9", "ada@example.com", nil)); err != ErrProviderLinked {
+		t.Errorf("Expected %v, got %v", ErrProviderLinked, err)
+	}
+
+	if _, err := svc.LinkIdentity(ctx, 1, "apple", apple.token(t, "a-1", "relay@privaterelay.appleid.com", nil)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.UnlinkIdentity(ctx, 1, "google"); err != nil {
+		t.Errorf("Expected unlinking with another provider left to work, got %v", err)
+	}
+	if err := svc.UnlinkIdentity(ctx, 1, "google"); err != ErrIdentityNotFound {
+		t.Errorf("Expected %v, got %v", ErrIdentityNotFound, err)
+	}
+}
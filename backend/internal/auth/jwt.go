@@ -14,12 +14,52 @@ var (
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID   int64  `json:"user_id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
+	UserID      int64    `json:"user_id"`
+	Email       string   `json:"email"`
+	Username    string   `json:"username"`
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// AMR lists the methods the user authenticated with (RFC 8176)
+	AMR []string `json:"amr,omitempty"`
+	// AuthTime is when the user last authenticated, in unix seconds
+	AuthTime int64 `json:"auth_time,omitempty"`
+	// SessionID names the session the token belongs to
+	SessionID int64 `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasPermission reports whether the claims carry the given permission
+func (c *Claims) HasPermission(permission Permission) bool {
+	for _, p := range c.Permissions {
+		if p == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMethod reports whether the amr claim lists a method
+func (c *Claims) hasMethod(method string) bool {
+	for _, m := range c.AMR {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// RecentAuth reports whether the claims come from authenticating, with any
+// method, within maxAge of now
+func (c *Claims) RecentAuth(maxAge time.Duration, now time.Time) bool {
+	return c.AuthTime != 0 && now.Sub(time.Unix(c.AuthTime, 0)) <= maxAge
+}
+
+// RecentMFA reports whether the claims come from authenticating with a
+// second factor within maxAge of now
+func (c *Claims) RecentMFA(maxAge time.Duration, now time.Time) bool {
+	return c.hasMethod(MethodMFA) && now.Sub(time.Unix(c.AuthTime, 0)) <= maxAge
+}
+
 // JWTManager handles JWT token operations
 type JWTManager struct {
 	secretKey       string
@@ -36,15 +76,19 @@ func NewJWTManager(secretKey string, expirationHours int) *JWTManager {
 
 // GenerateToken generates a new JWT token for a user
 func (m *JWTManager) GenerateToken(userID int64, email, username string) (string, error) {
-	claims := &Claims{
+	return m.GenerateTokenWithClaims(&Claims{
 		UserID:   userID,
 		Email:    email,
 		Username: username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(m.expirationHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+	})
+}
+
+// GenerateTokenWithClaims signs the given claims, setting the expiry and issue times
+func (m *JWTManager) GenerateTokenWithClaims(claims *Claims) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(m.expirationHours) * time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
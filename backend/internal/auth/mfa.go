@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFANotPending     = errors.New("no two-factor enrollment is pending")
+	ErrInvalidMFACode    = errors.New("invalid two-factor code")
+	ErrInvalidChallenge  = errors.New("login challenge is invalid or has expired")
+)
+
+// Authentication methods, as listed in the amr claim (RFC 8176)
+const (
+	MethodPassword     = "pwd"
+	MethodOTP          = "otp"
+	MethodRecoveryCode = "rec"
+	// MethodMFA marks a session authenticated with a second factor
+	MethodMFA = "mfa"
+)
+
+const (
+	mfaChallengeTTL = 5 * time.Minute
+	// mfaMaxAttempts bounds the codes that may be tried against one
+	// challenge; MFAMaxFailures bounds those across all of a user's
+	mfaMaxAttempts    = 5
+	recoveryCodeCount = 10
+)
+
+// recoveryAlphabet has 32 characters, none easily confused with another
+// when copied by hand
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+
+// EnrollTOTP starts TOTP enrollment with a new secret, replacing any pending
+// one. Enrollment completes once a code from the secret is confirmed.
+func (s *Service) EnrollTOTP(ctx context.Context, userID int64) (*models.TOTPEnrollment, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	sealed, err := sealTOTPSecret(s.totpKey, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+	if err := s.repo.SavePendingTOTP(ctx, user.ID, sealed); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	return &models.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totpURI(s.config.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables TOTP with a code from the pending secret and returns
+// the user's first recovery codes
+func (s *Service) ConfirmTOTP(ctx context.Context, userID int64, code string) (*models.RecoveryCodes, error) {
+	totp, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrMFANotPending
+		}
+		return nil, fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+	if totp.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, err := s.matchCode(totp, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrMFANotPending
+		}
+		return nil, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// DisableTOTP turns off two-factor authentication and discards the user's
+// recovery codes
+func (s *Service) DisableTOTP(ctx context.Context, userID int64) error {
+	if err := s.repo.DisableTOTP(ctx, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrMFANotEnabled
+		}
+		return fmt.Errorf("failed to disable TOTP: %w", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes with new ones
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64) (*models.RecoveryCodes, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// CompleteMFALogin answers a login challenge with a TOTP code or recovery
+// code and returns the user with the methods they authenticated with
+func (s *Service) CompleteMFALogin(ctx context.Context, req *models.MFALoginRequest, client Client) (*models.User, []string, error) {
+	tokenHash := hashToken(req.ChallengeToken)
+	userID, firstMethod, err := s.repo.AttemptMFAChallenge(ctx, tokenHash, mfaMaxAttempts, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, fmt.Errorf("failed to check MFA challenge: %w", err)
+	}
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkAccountStatus(user, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	attempts, err := s.startMFAAttempt(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var method string
+	switch {
+	case req.Code != "":
+		method, err = MethodOTP, s.verifyTOTP(ctx, user.ID, req.Code)
+	case req.RecoveryCode != "":
+		method, err = MethodRecoveryCode, s.useRecoveryCode(ctx, user.ID, req.RecoveryCode)
+	default:
+		err = ErrInvalidMFACode
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.failMFAAttempt(ctx, user, attempts)
+		}
+		return nil, nil, err
+	}
+
+	if err := s.repo.CompleteMFAChallenge(ctx, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, fmt.Errorf("failed to complete MFA challenge: %w", err)
+	}
+
+	s.resetMFAFailures(ctx, user.ID)
+	s.resetLoginFailures(ctx, user.Email)
+	s.rememberDevice(ctx, user, client, true)
+	return user, []string{firstMethod, method, MethodMFA}, nil
+}
+
+// StepUp verifies a TOTP code from a signed-in user, so their session can be
+// renewed as recently authenticated with a second factor. Wrong codes count
+// towards the same lockout as those at login challenges.
+func (s *Service) StepUp(ctx context.Context, userID int64, code string) (*models.User, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.startMFAAttempt(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyTOTP(ctx, user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.failMFAAttempt(ctx, user, attempts)
+		}
+		return nil, err
+	}
+	s.resetMFAFailures(ctx, user.ID)
+	return user, nil
+}
+
+// newMFAChallenge issues a login challenge to a user who passed a first
+// factor, recording which
+func (s *Service) newMFAChallenge(ctx context.Context, user *models.User, method string) (*models.MFAChallenge, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MFA challenge: %w", err)
+	}
+	expiresAt := time.Now().Add(mfaChallengeTTL)
+	if err := s.repo.CreateMFAChallenge(ctx, user.ID, hashToken(token), method, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+
+	return &models.MFAChallenge{
+		MFARequired:    true,
+		ChallengeToken: token,
+		ExpiresAt:      expiresAt,
+		Methods:        []string{MethodOTP, MethodRecoveryCode},
+	}, nil
+}
+
+// verifyTOTP checks a code against a user's enabled TOTP secret, refusing
+// codes that were already used
+func (s *Service) verifyTOTP(ctx context.Context, userID int64, code string) error {
+	totp, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrMFANotEnabled
+		}
+		return fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+	if totp.EnabledAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	step, err := s.matchCode(totp, code)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UseTOTPStep(ctx, userID, step); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidMFACode
+		}
+		return fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+	return nil
+}
+
+// matchCode returns the time step a code from a TOTP secret is valid for
+func (s *Service) matchCode(totp *models.UserTOTP, code string) (int64, error) {
+	secret, err := openTOTPSecret(s.totpKey, totp.Secret)
+	if err != nil {
+		return 0, err
+	}
+	step, ok := matchTOTP(secret, code, time.Now())
+	if !ok || step <= totp.LastUsedStep {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+// useRecoveryCode redeems one of a user's recovery codes
+func (s *Service) useRecoveryCode(ctx context.Context, userID int64, code string) error {
+	if err := s.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code)); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidMFACode
+		}
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return nil
+}
+
+// newRecoveryCodes generates a set of recovery codes and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		for j := range b {
+			b[j] = recoveryAlphabet[b[j]&31]
+		}
+		codes[i] = string(b[:5]) + "-" + string(b[5:])
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode returns the hex SHA-256 of a recovery code, ignoring case,
+// spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
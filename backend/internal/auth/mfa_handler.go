@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginMFA handles answering a login challenge with a second factor
+// @Summary Complete a login with a second factor
+// @Description Pass either a TOTP code or a recovery code. Each challenge allows a few attempts.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.MFALoginRequest true "Challenge token and second factor"
+// @Success 200 {object} models.AuthResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /auth/login/mfa [post]
+func (h *Handler) LoginMFA(c *gin.Context) {
+	var req models.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, methods, err := h.service.CompleteMFALogin(c.Request.Context(), &req, ClientOf(c))
+	if err != nil {
+		if RespondAccountState(c, err) {
+			return
+		}
+		respondMFAError(c, err, "Failed to authenticate user")
+		return
+	}
+
+	token, err := h.issueToken(c, user, methods)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthResponse{
+		Token: token,
+		User:  user,
+	})
+}
+
+// EnrollTOTP handles starting TOTP enrollment
+// @Summary Start TOTP enrollment
+// @Description Returns a new secret and otpauth URI for an authenticator app; confirm a code to enable it
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TOTPEnrollment
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/mfa/totp [post]
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	enrollment, err := h.service.EnrollTOTP(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondMFAError(c, err, "Failed to start enrollment")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTOTP handles confirming TOTP enrollment with a first code
+// @Summary Enable TOTP
+// @Description Returns recovery codes, which are only shown once
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} models.RecoveryCodes
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/mfa/totp/confirm [post]
+func (h *Handler) ConfirmTOTP(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTP(c.Request.Context(), c.GetInt64("user_id"), req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableTOTP handles turning off two-factor authentication
+// @Summary Disable TOTP
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/mfa/totp [delete]
+func (h *Handler) DisableTOTP(c *gin.Context) {
+	if err := h.service.DisableTOTP(c.Request.Context(), c.GetInt64("user_id")); err != nil {
+		respondMFAError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes handles replacing a user's recovery codes
+// @Summary Regenerate recovery codes
+// @Description Invalidates the previous codes
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.RecoveryCodes
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/mfa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondMFAError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, codes)
+}
+
+// StepUp handles re-verifying a second factor during a session
+// @Summary Re-verify a second factor
+// @Description Returns a new token for the same session marked as recently authenticated with a second factor, for routes that demand one
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MFACodeRequest true "Code from the authenticator app"
+// @Success 200 {object} models.AuthResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /auth/mfa/step-up [post]
+func (h *Handler) StepUp(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, err := h.service.StepUp(c.Request.Context(), c.GetInt64("user_id"), req.Code)
+	if err != nil {
+		respondMFAError(c, err, "Failed to verify code")
+		return
+	}
+
+	methods := withMethods(c.GetStringSlice("user_amr"), MethodOTP, MethodMFA)
+	token, err := h.signToken(c, user, methods, c.GetInt64("session_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthResponse{
+		Token: token,
+		User:  user,
+	})
+}
+
+// withMethods adds authentication methods to a list, skipping ones already
+// in it
+func withMethods(methods []string, added ...string) []string {
+	merged := append([]string(nil), methods...)
+	for _, method := range added {
+		found := false
+		for _, m := range merged {
+			if m == method {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, method)
+		}
+	}
+	return merged
+}
+
+// respondMFAError maps two-factor authentication errors to responses
+func respondMFAError(c *gin.Context, err error, message string) {
+	if respondThrottled(c, err) {
+		return
+	}
+
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrInvalidChallenge):
+		status, code = http.StatusUnauthorized, "invalid_challenge"
+	case errors.Is(err, ErrInvalidMFACode):
+		status, code = http.StatusUnauthorized, "invalid_code"
+	case errors.Is(err, ErrUserNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrMFAAlreadyEnabled):
+		status, code = http.StatusConflict, "mfa_already_enabled"
+	case errors.Is(err, ErrMFANotEnabled):
+		status, code = http.StatusConflict, "mfa_not_enabled"
+	case errors.Is(err, ErrMFANotPending):
+		status, code = http.StatusConflict, "mfa_not_pending"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
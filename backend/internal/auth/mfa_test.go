@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mfaRepo is an in-memory Repository for two-factor authentication; the
+// embedded interface panics on methods it does not use
+type mfaRepo struct {
+	Repository
+	user       *models.User
+	totp       *models.UserTOTP
+	codes      map[string]bool
+	challenges map[string]int
+}
+
+func (r *mfaRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if email != r.user.Email {
+		return nil, sql.ErrNoRows
+	}
+	return r.GetUserByID(ctx, r.user.ID)
+}
+
+func (r *mfaRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	user := *r.user
+	user.MFAEnabled = r.totp != nil && r.totp.EnabledAt != nil
+	return &user, nil
+}
+
+func (r *mfaRepo) UpdatePasswordHash(ctx context.Context, userID int64, oldHash, newHash string) error {
+	if r.user.PasswordHash != oldHash {
+		return sql.ErrNoRows
+	}
+	r.user.PasswordHash = newHash
+	return nil
+}
+
+func (r *mfaRepo) RecordLoginDevice(ctx context.Context, userID int64, deviceHash, userAgent, ip string) (bool, error) {
+	return false, nil
+}
+
+func (r *mfaRepo) GetTOTP(ctx context.Context, userID int64) (*models.UserTOTP, error) {
+	if r.totp == nil {
+		return nil, sql.ErrNoRows
+	}
+	copied := *r.totp
+	return &copied, nil
+}
+
+func (r *mfaRepo) SavePendingTOTP(ctx context.Context, userID int64, secret []byte) error {
+	if r.totp != nil && r.totp.EnabledAt != nil {
+		return sql.ErrNoRows
+	}
+	r.totp = &models.UserTOTP{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *mfaRepo) EnableTOTP(ctx context.Context, userID, step int64, codeHashes []string) error {
+	now := time.Now()
+	r.totp.EnabledAt = &now
+	r.totp.LastUsedStep = step
+	r.codes = make(map[string]bool)
+	for _, hash := range codeHashes {
+		r.codes[hash] = false
+	}
+	return nil
+}
+
+func (r *mfaRepo) UseTOTPStep(ctx context.Context, userID, step int64) error {
+	if r.totp.LastUsedStep >= step {
+		return sql.ErrNoRows
+	}
+	r.totp.LastUsedStep = step
+	return nil
+}
+
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	used, ok := r.codes[codeHash]
+	if !ok || used {
+		return sql.ErrNoRows
+	}
+	r.codes[codeHash] = true
+	return nil
+}
+
+func (r *mfaRepo) CreateMFAChallenge(ctx context.Context, userID int64, tokenHash, method string, expiresAt time.Time) error {
+	r.challenges[tokenHash] = 0
+	return nil
+}
+
+func (r *mfaRepo) AttemptMFAChallenge(ctx context.Context, tokenHash string, maxAttempts int, now time.Time) (int64, string, error) {
+	attempts, ok := r.challenges[tokenHash]
+	if !ok || attempts >= maxAttempts {
+		return 0, "", sql.ErrNoRows
+	}
+	r.challenges[tokenHash] = attempts + 1
+	return r.user.ID, MethodPassword, nil
+}
+
+func (r *mfaRepo) CompleteMFAChallenge(ctx context.Context, tokenHash string) error {
+	delete(r.challenges, tokenHash)
+	return nil
+}
+
+func TestMFALogin(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo := &mfaRepo{
+		user:       &models.User{ID: 1, Email: "ada@example.com", PasswordHash: string(hash), AccountStatus: "active"},
+		challenges: make(map[string]int),
+	}
+	svc := NewService(repo, nil, nil, nil, nil, nil, Config{TokenSecret: "secret", MFAIssuer: "HALO"})
+	ctx := context.Background()
+	login := &models.LoginRequest{Email: "ada@example.com", Password: "password"}
+
+	enrollment, err := svc.EnrollTOTP(ctx, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, challenge, err := svc.Login(ctx, login, Client{}); err != nil || challenge != nil {
+		t.Fatalf("Expected a pending enrollment not to require a second factor, got %v, %v", challenge, err)
+	}
+
+	// Confirm with the previous period's code so the current one is still
+	// unused for the login below
+	previous, _ := totpCode(enrollment.Secret, totpStep(time.Now())-1)
+	codes, err := svc.ConfirmTOTP(ctx, 1, previous)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(codes.Codes) != recoveryCodeCount {
+		t.Fatalf("Expected %d recovery codes, got %d", recoveryCodeCount, len(codes.Codes))
+	}
+
+	user, challenge, err := svc.Login(ctx, login, Client{})
+	if err != nil || user != nil || challenge == nil {
+		t.Fatalf("Expected a challenge instead of a user, got %v, %v, %v", user, challenge, err)
+	}
+
+	answer := func(code, recoveryCode string) ([]string, error) {
+		_, methods, err := svc.CompleteMFALogin(ctx, &models.MFALoginRequest{
+			ChallengeToken: challenge.ChallengeToken,
+			Code:           code,
+			RecoveryCode:   recoveryCode,
+		}, Client{})
+		return methods, err
+	}
+
+	if _, err := answer(previous, ""); err != ErrInvalidMFACode {
+		t.Errorf("Expected a used code to be refused, got %v", err)
+	}
+	current, _ := totpCode(enrollment.Secret, totpStep(time.Now()))
+	methods, err := answer(current, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(methods) != 3 || methods[1] != MethodOTP || methods[2] != MethodMFA {
+		t.Errorf("Unexpected methods %v", methods)
+	}
+	if _, err := answer(current, ""); err != ErrInvalidChallenge {
+		t.Errorf("Expected an answered challenge to be refused, got %v", err)
+	}
+
+	_, challenge, _ = svc.Login(ctx, login, Client{})
+	if _, err := answer("", codes.Codes[0]); err != nil {
+		t.Fatalf("Expected a recovery code to work, got %v", err)
+	}
+	_, challenge, _ = svc.Login(ctx, login, Client{})
+	if _, err := answer("", codes.Codes[0]); err != ErrInvalidMFACode {
+		t.Errorf("Expected a used recovery code to be refused, got %v", err)
+	}
+	for i := 1; i < mfaMaxAttempts; i++ {
+		answer("000000", "")
+	}
+	if _, err := answer("", codes.Codes[1]); err != ErrInvalidChallenge {
+		t.Errorf("Expected the challenge to run out of attempts, got %v", err)
+	}
+}
+
+func TestMFAThrottle(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo := &mfaRepo{
+		user:       &models.User{ID: 1, Email: "ada@example.com", PasswordHash: string(hash), AccountStatus: "active"},
+		challenges: make(map[string]int),
+	}
+	throttle, notifier := newMemThrottle(), &recordingNotifier{}
+	svc := NewService(repo, nil, nil, notifier, nil, throttle, Config{
+		TokenSecret:        "secret",
+		LoginMaxFailures:   10,
+		LoginIPMaxFailures: 100,
+		LoginLockout:       15 * time.Minute,
+		MFAMaxFailures:     5,
+	})
+	ctx := context.Background()
+	login := &models.LoginRequest{Email: "ada@example.com", Password: "password"}
+
+	enrollment, err := svc.EnrollTOTP(ctx, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	previous, _ := totpCode(enrollment.Secret, totpStep(time.Now())-1)
+	if _, err := svc.ConfirmTOTP(ctx, 1, previous); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Wrong codes at login challenges and step-ups count together, however
+	// many challenges the password gets
+	for i := 0; i < 5; i++ {
+		delete(throttle.blocks, mfaKey(1))
+		if i%2 == 0 {
+			if _, err := svc.StepUp(ctx, 1, "000000"); err != ErrInvalidMFACode {
+				t.Fatalf("Expected %v, got %v", ErrInvalidMFACode, err)
+			}
+			continue
+		}
+		_, challenge, err := svc.Login(ctx, login, Client{IP: "203.0.113.7"})
+		if err != nil || challenge == nil {
+			t.Fatalf("Expected a challenge, got %v, %v", challenge, err)
+		}
+		_, _, err = svc.CompleteMFALogin(ctx, &models.MFALoginRequest{ChallengeToken: challenge.ChallengeToken, Code: "000000"}, Client{})
+		if err != ErrInvalidMFACode {
+			t.Fatalf("Expected %v, got %v", ErrInvalidMFACode, err)
+		}
+	}
+	if throttle.failures[accountKey("ada@example.com")] != 2 {
+		t.Errorf("Expected logins without a second factor to stay counted, got %d", throttle.failures[accountKey("ada@example.com")])
+	}
+	if len(notifier.messages) != 1 || notifier.messages[0].Type != notifications.TypeAccountLocked {
+		t.Errorf("Expected one lockout notification, got %v", notifier.messages)
+	}
+
+	current, _ := totpCode(enrollment.Secret, totpStep(time.Now()))
+	var throttled *ThrottledError
+	if _, err := svc.StepUp(ctx, 1, current); !errors.As(err, &throttled) || throttled.RetryAfter != 15*time.Minute {
+		t.Fatalf("Expected a 15 minute lockout, got %v", err)
+	}
+
+	delete(throttle.blocks, mfaKey(1))
+	_, challenge, err := svc.Login(ctx, login, Client{IP: "203.0.113.7"})
+	if err != nil || challenge == nil {
+		t.Fatalf("Expected a challenge, got %v, %v", challenge, err)
+	}
+	if _, _, err := svc.CompleteMFALogin(ctx, &models.MFALoginRequest{ChallengeToken: challenge.ChallengeToken, Code: current}, Client{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if throttle.failures[mfaKey(1)] != 0 || throttle.failures[accountKey("ada@example.com")] != 0 {
+		t.Errorf("Expected a completed login to clear the user's attempts")
+	}
+}
@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksTTL is how long a provider's signing keys are cached
+	jwksTTL = time.Hour
+	// jwksMinRefresh is the shortest time between two fetches, so tokens
+	// with unknown key IDs cannot make us hammer a provider
+	jwksMinRefresh = time.Minute
+	// idTokenLeeway tolerates clock drift between us and a provider
+	idTokenLeeway = time.Minute
+)
+
+// OIDCProvider is an OpenID Connect provider users can sign in with. ID
+// tokens must be issued by one of Issuers to one of ClientIDs, the apps'
+// client IDs at the provider, and signed with a key from JWKSURL.
+type OIDCProvider struct {
+	Name      string
+	Issuers   []string
+	JWKSURL   string
+	ClientIDs []string
+}
+
+// GoogleProvider returns Sign in with Google for the given client IDs
+func GoogleProvider(clientIDs []string) OIDCProvider {
+	return OIDCProvider{
+		Name:      "google",
+		Issuers:   []string{"https://accounts.google.com", "accounts.google.com"},
+		JWKSURL:   "https://www.googleapis.com/oauth2/v3/certs",
+		ClientIDs: clientIDs,
+	}
+}
+
+// AppleProvider returns Sign in with Apple for the given client IDs: app
+// bundle IDs and services IDs
+func AppleProvider(clientIDs []string) OIDCProvider {
+	return OIDCProvider{
+		Name:      "apple",
+		Issuers:   []string{"https://appleid.apple.com"},
+		JWKSURL:   "https://appleid.apple.com/auth/keys",
+		ClientIDs: clientIDs,
+	}
+}
+
+// idTokenClaims are the ID token claims used to sign a user in
+type idTokenClaims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Nonce         string   `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// flexBool reads a boolean claim that some providers, Apple among them,
+// send as a string
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value {
+	case true, "true":
+		*b = true
+	case false, "false", nil:
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+// oidcVerifier verifies ID tokens from one provider
+type oidcVerifier struct {
+	provider OIDCProvider
+	keys     *jwks
+}
+
+func newOIDCVerifier(provider OIDCProvider, client *http.Client) *oidcVerifier {
+	return &oidcVerifier{
+		provider: provider,
+		keys:     &jwks{url: provider.JWKSURL, client: client},
+	}
+}
+
+// verify checks an ID token's signature, issuer, audience, lifetime and,
+// if nonce is set, nonce, and returns its claims
+func (v *oidcVerifier) verify(ctx context.Context, token, nonce string) (*idTokenClaims, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !contains(v.provider.Issuers, claims.Issuer) {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	audience := false
+	for _, aud := range claims.Audience {
+		audience = audience || contains(v.provider.ClientIDs, aud)
+	}
+	if !audience {
+		return nil, errors.New("token was issued to another client")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, errors.New("nonce does not match")
+	}
+	return claims, nil
+}
+
+// jwks caches the signing keys a provider publishes as a JSON Web Key Set
+type jwks struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// key returns the signing key with an ID, fetching the key set when the
+// cache is stale or, at most once a minute, when the key is unknown, as
+// providers rotate keys
+func (j *jwks) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, ok := j.keys[kid]
+	age := time.Since(j.fetchedAt)
+	if (ok && age < jwksTTL) || (!ok && age < jwksMinRefresh) {
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	}
+
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		if ok {
+			// Keep using a stale key while the provider is unreachable
+			return key, nil
+		}
+		return nil, err
+	}
+	j.keys, j.fetchedAt = keys, time.Now()
+
+	if key, ok = keys[kid]; !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// fetch downloads the key set, skipping keys it cannot use
+func (j *jwks) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signing keys: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// jsonWebKey is an RSA or P-256 public key (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := passkeyEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := passkeyEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return newRSAKey(n, e)
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := passkeyEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := passkeyEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return newP256Key(x, y)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+var (
+	ErrInvalidPasskey  = errors.New("passkey could not be verified")
+	ErrPasskeyExists   = errors.New("passkey is already registered")
+	ErrPasskeyNotFound = errors.New("passkey not found")
+)
+
+// MethodPasskey is the amr value of a passkey login: proof of possession
+// of a key held by an authenticator
+const MethodPasskey = "hwk"
+
+// WebAuthn ceremonies, as named in clientDataJSON
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// Purposes of a passkey challenge
+const (
+	passkeyRegister = "register"
+	passkeyLogin    = "login"
+)
+
+const (
+	passkeyChallengeTTL = 5 * time.Minute
+	defaultPasskeyName  = "Passkey"
+)
+
+var passkeyEncoding = base64.RawURLEncoding
+
+// PasskeyRegistrationOptions starts registering a passkey for a user
+func (s *Service) PasskeyRegistrationOptions(ctx context.Context, userID int64) (*models.PasskeyCreationOptions, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	passkeys, err := s.repo.GetPasskeys(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+	challenge, err := s.newPasskeyChallenge(ctx, user.ID, passkeyRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &models.PasskeyCreationOptions{
+		Challenge: challenge,
+		RP: models.PasskeyRP{
+			ID:   s.config.PasskeyRPID,
+			Name: s.config.PasskeyRPName,
+		},
+		User: models.PasskeyUser{
+			ID:          passkeyEncoding.EncodeToString(passkeyUserHandle(user.ID)),
+			Name:        user.Email,
+			DisplayName: user.DisplayName,
+		},
+		Timeout: passkeyChallengeTTL.Milliseconds(),
+		// Keep the user from registering an authenticator twice
+		ExcludeCredentials: make([]models.PasskeyDescriptor, 0, len(passkeys)),
+		AuthenticatorSelection: models.PasskeyAuthenticatorSelection{
+			ResidentKey:      "required",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}
+	for _, alg := range passkeyAlgorithms {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, models.PasskeyParam{Type: "public-key", Alg: alg})
+	}
+	for _, passkey := range passkeys {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, models.PasskeyDescriptor{
+			Type: "public-key",
+			ID:   passkeyEncoding.EncodeToString(passkey.CredentialID),
+		})
+	}
+	return options, nil
+}
+
+// RegisterPasskey verifies a credential created from registration options
+// and saves it as one of the user's passkeys
+func (s *Service) RegisterPasskey(ctx context.Context, userID int64, req *models.PasskeyRegistrationRequest) (*models.Passkey, error) {
+	clientDataJSON, err := passkeyEncoding.DecodeString(req.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+	if err := s.usePasskeyChallenge(ctx, clientDataJSON, ceremonyCreate, passkeyRegister, userID); err != nil {
+		return nil, err
+	}
+
+	attestationObject, err := passkeyEncoding.DecodeString(req.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+	data, err := parseAttestationObject(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+	if err := checkAuthenticatorData(data, s.config.PasskeyRPID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+	if req.ID != passkeyEncoding.EncodeToString(data.credentialID) {
+		return nil, fmt.Errorf("%w: credential ID does not match", ErrInvalidPasskey)
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(data.publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode passkey public key: %w", err)
+	}
+	name := req.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	passkey := &models.Passkey{
+		UserID:       userID,
+		CredentialID: data.credentialID,
+		PublicKey:    publicKey,
+		SignCount:    int64(data.signCount),
+		AAGUID:       data.aaguid,
+		Name:         name,
+	}
+	if err := s.repo.CreatePasskey(ctx, passkey); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPasskeyExists
+		}
+		return nil, fmt.Errorf("failed to save passkey: %w", err)
+	}
+	return passkey, nil
+}
+
+// ListPasskeys returns a user's passkeys
+func (s *Service) ListPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error) {
+	passkeys, err := s.repo.GetPasskeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+	return passkeys, nil
+}
+
+// DeletePasskey removes one of a user's passkeys
+func (s *Service) DeletePasskey(ctx context.Context, userID, passkeyID int64) error {
+	if err := s.repo.DeletePasskey(ctx, userID, passkeyID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPasskeyNotFound
+		}
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+	return nil
+}
+
+// PasskeyLoginOptions starts a passkey login. No credentials are listed, so
+// the authenticator offers the user the passkeys it holds for the site.
+func (s *Service) PasskeyLoginOptions(ctx context.Context) (*models.PasskeyRequestOptions, error) {
+	challenge, err := s.newPasskeyChallenge(ctx, 0, passkeyLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PasskeyRequestOptions{
+		Challenge:        challenge,
+		RPID:             s.config.PasskeyRPID,
+		Timeout:          passkeyChallengeTTL.Milliseconds(),
+		AllowCredentials: []models.PasskeyDescriptor{},
+		UserVerification: "required",
+	}, nil
+}
+
+// LoginWithPasskey verifies a passkey assertion and returns its user with
+// the methods they authenticated with. A passkey with user verification is
+// two factors on its own, so no TOTP challenge follows.
+func (s *Service) LoginWithPasskey(ctx context.Context, req *models.PasskeyLoginRequest, client Client) (*models.User, []string, error) {
+	clientDataJSON, err := passkeyEncoding.DecodeString(req.Response.ClientDataJSON)
+	if err != nil {
+		return nil, nil, ErrInvalidPasskey
+	}
+	if err := s.usePasskeyChallenge(ctx, clientDataJSON, ceremonyGet, passkeyLogin, 0); err != nil {
+		return nil, nil, err
+	}
+
+	credentialID, err := passkeyEncoding.DecodeString(req.ID)
+	if err != nil {
+		return nil, nil, ErrInvalidPasskey
+	}
+	authData, err := passkeyEncoding.DecodeString(req.Response.AuthenticatorData)
+	if err != nil {
+		return nil, nil, ErrInvalidPasskey
+	}
+	signature, err := passkeyEncoding.DecodeString(req.Response.Signature)
+	if err != nil {
+		return nil, nil, ErrInvalidPasskey
+	}
+	userHandle, err := passkeyEncoding.DecodeString(req.Response.UserHandle)
+	if err != nil {
+		return nil, nil, ErrInvalidPasskey
+	}
+
+	passkey, err := s.repo.GetPasskeyByCredentialID(ctx, credentialID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrInvalidPasskey
+		}
+		return nil, nil, fmt.Errorf("failed to get passkey: %w", err)
+	}
+	if len(userHandle) > 0 && !bytes.Equal(userHandle, passkeyUserHandle(passkey.UserID)) {
+		return nil, nil, fmt.Errorf("%w: user handle does not match", ErrInvalidPasskey)
+	}
+
+	data, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+	if err := checkAuthenticatorData(data, s.config.PasskeyRPID); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+	if err := verifyAssertion(passkey.PublicKey, authData, clientDataJSON, signature); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+
+	// A counter that does not move forward means the credential may have
+	// been copied to another authenticator. Authenticators that do not
+	// count always report zero.
+	signCount := int64(data.signCount)
+	if signCount != 0 || passkey.SignCount != 0 {
+		if signCount <= passkey.SignCount {
+			logger.WarnLogger.Printf("Passkey %d of user %d reported sign count %d after %d; it may be cloned", passkey.ID, passkey.UserID, signCount, passkey.SignCount)
+			return nil, nil, fmt.Errorf("%w: sign count did not increase", ErrInvalidPasskey)
+		}
+	}
+	if err := s.repo.UsePasskey(ctx, passkey.ID, signCount); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("%w: sign count did not increase", ErrInvalidPasskey)
+		}
+		return nil, nil, fmt.Errorf("failed to record passkey use: %w", err)
+	}
+
+	user, err := s.GetUserByID(ctx, passkey.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkAccountStatus(user, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	s.rememberDevice(ctx, user, client, true)
+	return user, []string{MethodPasskey, MethodMFA}, nil
+}
+
+// newPasskeyChallenge issues a challenge for a passkey ceremony. Login
+// challenges have no user.
+func (s *Service) newPasskeyChallenge(ctx context.Context, userID int64, purpose string) (string, error) {
+	challenge, err := newToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate passkey challenge: %w", err)
+	}
+	expiresAt := time.Now().Add(passkeyChallengeTTL)
+	if err := s.repo.CreatePasskeyChallenge(ctx, userID, hashToken(challenge), purpose, expiresAt); err != nil {
+		return "", fmt.Errorf("failed to create passkey challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// usePasskeyChallenge checks clientDataJSON came from the expected ceremony
+// on an allowed origin and redeems the challenge it answers, which must have
+// been issued to userID
+func (s *Service) usePasskeyChallenge(ctx context.Context, clientDataJSON []byte, ceremony, purpose string, userID int64) error {
+	data, err := parseClientData(clientDataJSON, ceremony, s.config.PasskeyOrigins)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+
+	issuedTo, err := s.repo.ConsumePasskeyChallenge(ctx, hashToken(data.Challenge), purpose, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: unknown or expired challenge", ErrInvalidPasskey)
+		}
+		return fmt.Errorf("failed to check passkey challenge: %w", err)
+	}
+	if issuedTo != userID {
+		return fmt.Errorf("%w: challenge was issued to another user", ErrInvalidPasskey)
+	}
+	return nil
+}
+
+// passkeyUserHandle returns the user handle passkeys are created with
+func passkeyUserHandle(userID int64) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
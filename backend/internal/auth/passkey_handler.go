@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// PasskeyRegistrationOptions handles starting passkey registration
+// @Summary Start registering a passkey
+// @Description Returns options for navigator.credentials.create; they expire after five minutes
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.PasskeyCreationOptions
+// @Router /auth/passkeys/register/options [post]
+func (h *Handler) PasskeyRegistrationOptions(c *gin.Context) {
+	options, err := h.service.PasskeyRegistrationOptions(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondPasskeyError(c, err, "Failed to start passkey registration")
+		return
+	}
+
+	c.JSON(http.StatusOK, options)
+}
+
+// RegisterPasskey handles saving a passkey created from registration options
+// @Summary Register a passkey
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PasskeyRegistrationRequest true "Credential from navigator.credentials.create"
+// @Success 201 {object} models.Passkey
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /auth/passkeys/register [post]
+func (h *Handler) RegisterPasskey(c *gin.Context) {
+	var req models.PasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	passkey, err := h.service.RegisterPasskey(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondPasskeyError(c, err, "Failed to register passkey")
+		return
+	}
+
+	c.JSON(http.StatusCreated, passkey)
+}
+
+// ListPasskeys handles listing the user's passkeys
+// @Summary List passkeys
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Passkey
+// @Router /auth/passkeys [get]
+func (h *Handler) ListPasskeys(c *gin.Context) {
+	passkeys, err := h.service.ListPasskeys(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondPasskeyError(c, err, "Failed to get passkeys")
+		return
+	}
+
+	c.JSON(http.StatusOK, passkeys)
+}
+
+// DeletePasskey handles removing one of the user's passkeys
+// @Summary Delete a passkey
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Passkey ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /auth/passkeys/{id} [delete]
+func (h *Handler) DeletePasskey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid passkey ID",
+		})
+		return
+	}
+
+	if err := h.service.DeletePasskey(c.Request.Context(), c.GetInt64("user_id"), id); err != nil {
+		respondPasskeyError(c, err, "Failed to delete passkey")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Passkey deleted",
+	})
+}
+
+// PasskeyLoginOptions handles starting a passkey login
+// @Summary Start a passkey login
+// @Description Returns options for navigator.credentials.get; they expire after five minutes
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.PasskeyRequestOptions
+// @Router /auth/passkeys/login/options [post]
+func (h *Handler) PasskeyLoginOptions(c *gin.Context) {
+	options, err := h.service.PasskeyLoginOptions(c.Request.Context())
+	if err != nil {
+		respondPasskeyError(c, err, "Failed to start passkey login")
+		return
+	}
+
+	c.JSON(http.StatusOK, options)
+}
+
+// LoginWithPasskey handles logging in with a passkey
+// @Summary Log in with a passkey
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.PasskeyLoginRequest true "Credential from navigator.credentials.get"
+// @Success 200 {object} models.AuthResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /auth/passkeys/login [post]
+func (h *Handler) LoginWithPasskey(c *gin.Context) {
+	var req models.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	user, methods, err := h.service.LoginWithPasskey(c.Request.Context(), &req, ClientOf(c))
+	if err != nil {
+		if RespondAccountState(c, err) {
+			return
+		}
+		if errors.Is(err, ErrInvalidPasskey) {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error:   "invalid_passkey",
+				Message: err.Error(),
+			})
+			return
+		}
+		respondPasskeyError(c, err, "Failed to authenticate user")
+		return
+	}
+
+	token, err := h.issueToken(c, user, methods)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthResponse{
+		Token: token,
+		User:  user,
+	})
+}
+
+// respondPasskeyError maps passkey errors to responses
+func respondPasskeyError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrInvalidPasskey):
+		status, code = http.StatusBadRequest, "invalid_passkey"
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrPasskeyNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrPasskeyExists):
+		status, code = http.StatusConflict, "passkey_exists"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,363 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+const (
+	testRPID   = "halo.example"
+	testOrigin = "https://halo.example"
+)
+
+// encodeCBOR encodes the values a software authenticator sends, with map
+// keys in a fixed order
+func encodeCBOR(v interface{}) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		case n < 1<<16:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		}
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	}
+
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case map[interface{}]interface{}:
+		var keys [][]byte
+		entries := map[string][]byte{}
+		for key, value := range v {
+			k := encodeCBOR(key)
+			keys = append(keys, k)
+			entries[string(k)] = encodeCBOR(value)
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+		out := head(5, uint64(len(v)))
+		for _, k := range keys {
+			out = append(append(out, k...), entries[string(k)]...)
+		}
+		return out
+	}
+	panic("unsupported CBOR value")
+}
+
+// softAuthenticator is a passkey authenticator holding a P-256 key in memory
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &softAuthenticator{key: key, credentialID: id, origin: testOrigin}
+}
+
+func (a *softAuthenticator) clientData(ceremony, challenge string) []byte {
+	data, _ := json.Marshal(clientData{Type: ceremony, Challenge: challenge, Origin: a.origin})
+	return data
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+// create makes a credential from registration options, like
+// navigator.credentials.create
+func (a *softAuthenticator) create(t *testing.T, options *models.PasskeyCreationOptions) *models.PasskeyRegistrationRequest {
+	handle, err := passkeyEncoding.DecodeString(options.User.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	a.userHandle = handle
+
+	x, y := make([]byte, 32), make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	coseKey := encodeCBOR(map[interface{}]interface{}{1: 2, 3: coseES256, -1: 1, -2: x, -3: y})
+
+	attested := make([]byte, 16) // zero AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(append(attested, a.credentialID...), coseKey...)
+	authData := a.authData(options.RP.ID, flagUserPresent|flagUserVerified|flagAttestedData, attested)
+
+	attestation := encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": authData,
+	})
+
+	return &models.PasskeyRegistrationRequest{
+		ID:   passkeyEncoding.EncodeToString(a.credentialID),
+		Type: "public-key",
+		Response: models.PasskeyAttestationResponse{
+			ClientDataJSON:    passkeyEncoding.EncodeToString(a.clientData(ceremonyCreate, options.Challenge)),
+			AttestationObject: passkeyEncoding.EncodeToString(attestation),
+		},
+		Name: "Test key",
+	}
+}
+
+// get signs in with the credential, like navigator.credentials.get
+func (a *softAuthenticator) get(t *testing.T, options *models.PasskeyRequestOptions) *models.PasskeyLoginRequest {
+	a.signCount++
+	clientDataJSON := a.clientData(ceremonyGet, options.Challenge)
+	authData := a.authData(options.RPID, flagUserPresent|flagUserVerified, nil)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	return &models.PasskeyLoginRequest{
+		ID:   passkeyEncoding.EncodeToString(a.credentialID),
+		Type: "public-key",
+		Response: models.PasskeyAssertionResponse{
+			ClientDataJSON:    passkeyEncoding.EncodeToString(clientDataJSON),
+			AuthenticatorData: passkeyEncoding.EncodeToString(authData),
+			Signature:         passkeyEncoding.EncodeToString(signature),
+			UserHandle:        passkeyEncoding.EncodeToString(a.userHandle),
+		},
+	}
+}
+
+type passkeyChallenge struct {
+	userID  int64
+	purpose string
+}
+
+// passkeyRepo is an in-memory Repository for passkeys
+type passkeyRepo struct {
+	Repository
+	user       *models.User
+	passkeys   []*models.Passkey
+	challenges map[string]passkeyChallenge
+}
+
+func (r *passkeyRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	if id != r.user.ID {
+		return nil, sql.ErrNoRows
+	}
+	user := *r.user
+	return &user, nil
+}
+
+func (r *passkeyRepo) RecordLoginDevice(ctx context.Context, userID int64, deviceHash, userAgent, ip string) (bool, error) {
+	return false, nil
+}
+
+func (r *passkeyRepo) CreatePasskeyChallenge(ctx context.Context, userID int64, challengeHash, purpose string, expiresAt time.Time) error {
+	r.challenges[challengeHash] = passkeyChallenge{userID: userID, purpose: purpose}
+	return nil
+}
+
+func (r *passkeyRepo) ConsumePasskeyChallenge(ctx context.Context, challengeHash, purpose string, now time.Time) (int64, error) {
+	challenge, ok := r.challenges[challengeHash]
+	if !ok || challenge.purpose != purpose {
+		return 0, sql.ErrNoRows
+	}
+	delete(r.challenges, challengeHash)
+	return challenge.userID, nil
+}
+
+func (r *passkeyRepo) CreatePasskey(ctx context.Context, passkey *models.Passkey) error {
+	for _, existing := range r.passkeys {
+		if bytes.Equal(existing.CredentialID, passkey.CredentialID) {
+			return sql.ErrNoRows
+		}
+	}
+	passkey.ID = int64(len(r.passkeys) + 1)
+	r.passkeys = append(r.passkeys, passkey)
+	return nil
+}
+
+func (r *passkeyRepo) GetPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error) {
+	return r.passkeys, nil
+}
+
+func (r *passkeyRepo) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*models.Passkey, error) {
+	for _, passkey := range r.passkeys {
+		if bytes.Equal(passkey.CredentialID, credentialID) {
+			copied := *passkey
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *passkeyRepo) UsePasskey(ctx context.Context, passkeyID, signCount int64) error {
+	passkey := r.passkeys[passkeyID-1]
+	if signCount <= passkey.SignCount && (signCount != 0 || passkey.SignCount != 0) {
+		return sql.ErrNoRows
+	}
+	passkey.SignCount = signCount
+	return nil
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	logger.Init()
+	repo := &passkeyRepo{
+		user:       &models.User{ID: 7, Email: "ada@example.com", DisplayName: "Ada", AccountStatus: "active"},
+		challenges: make(map[string]passkeyChallenge),
+	}
+	svc := NewService(repo, nil, nil, nil, nil, nil, Config{
+		PasskeyRPID:    testRPID,
+		PasskeyRPName:  "HALO",
+		PasskeyOrigins: []string{testOrigin},
+	})
+	ctx := context.Background()
+	authenticator := newSoftAuthenticator(t)
+
+	creation, err := svc.PasskeyRegistrationOptions(ctx, 7)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	registration := authenticator.create(t, creation)
+	passkey, err := svc.RegisterPasskey(ctx, 7, registration)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if passkey.Name != "Test key" {
+		t.Errorf("Expected name Test key, got %s", passkey.Name)
+	}
+	if _, err := svc.RegisterPasskey(ctx, 7, registration); !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("Expected a used registration challenge to be refused, got %v", err)
+	}
+
+	creation, _ = svc.PasskeyRegistrationOptions(ctx, 7)
+	if len(creation.ExcludeCredentials) != 1 || creation.ExcludeCredentials[0].ID != registration.ID {
+		t.Errorf("Expected the registered passkey to be excluded, got %v", creation.ExcludeCredentials)
+	}
+	if _, err := svc.RegisterPasskey(ctx, 7, authenticator.create(t, creation)); err != ErrPasskeyExists {
+		t.Errorf("Expected %v, got %v", ErrPasskeyExists, err)
+	}
+
+	options, err := svc.PasskeyLoginOptions(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	assertion := authenticator.get(t, options)
+	user, methods, err := svc.LoginWithPasskey(ctx, assertion, Client{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.ID != 7 {
+		t.Errorf("Expected user 7, got %d", user.ID)
+	}
+	if len(methods) != 2 || methods[0] != MethodPasskey || methods[1] != MethodMFA {
+		t.Errorf("Unexpected methods %v", methods)
+	}
+	if _, _, err := svc.LoginWithPasskey(ctx, assertion, Client{}); !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("Expected a replayed assertion to be refused, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(a *softAuthenticator, options *models.PasskeyRequestOptions)
+	}{
+		{"wrong origin", func(a *softAuthenticator, options *models.PasskeyRequestOptions) {
+			a.origin = "https://evil.example"
+		}},
+		{"wrong relying party", func(a *softAuthenticator, options *models.PasskeyRequestOptions) {
+			options.RPID = "evil.example"
+		}},
+		{"unknown challenge", func(a *softAuthenticator, options *models.PasskeyRequestOptions) {
+			options.Challenge = "bm90LWlzc3VlZA"
+		}},
+		{"sign count went back", func(a *softAuthenticator, options *models.PasskeyRequestOptions) {
+			a.signCount = 0
+		}},
+		{"another user's handle", func(a *softAuthenticator, options *models.PasskeyRequestOptions) {
+			a.userHandle = passkeyUserHandle(8)
+		}},
+		{"another key", func(a *softAuthenticator, options *models.PasskeyRequestOptions) {
+			a.key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := *authenticator
+			options, err := svc.PasskeyLoginOptions(ctx)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			tt.modify(&copied, options)
+			if _, _, err := svc.LoginWithPasskey(ctx, copied.get(t, options), Client{}); !errors.Is(err, ErrInvalidPasskey) {
+				t.Errorf("Expected %v, got %v", ErrInvalidPasskey, err)
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated byte string", []byte{0x42, 0x01}},
+		{"huge array", []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"indefinite length", []byte{0x5f, 0x41, 0x01, 0xff}},
+		{"duplicate key", []byte{0xa2, 0x01, 0x01, 0x01, 0x02}},
+		{"too deep", bytes.Repeat([]byte{0x81}, maxCBORDepth+2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeCBOR(tt.data); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+
+	value, rest, err := decodeCBOR(append(encodeCBOR(map[interface{}]interface{}{"a": -300, 1: []byte{2}}), 0xf6))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	decoded := value.(map[interface{}]interface{})
+	if decoded["a"] != int64(-300) || !bytes.Equal(decoded[int64(1)].([]byte), []byte{2}) {
+		t.Errorf("Unexpected value %v", decoded)
+	}
+	if !bytes.Equal(rest, []byte{0xf6}) {
+		t.Errorf("Expected the following bytes back, got %v", rest)
+	}
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hash algorithms
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltBytes = 16
+	argon2KeyBytes  = 32
+)
+
+// PasswordHashing chooses how new passwords are hashed. Hashes record their
+// algorithm and parameters, argon2id ones in the PHC string format
+// ($argon2id$v=19$m=...,t=...,p=...$salt$key) and bcrypt ones in their own
+// ($2a$cost$...), so hashes made with other settings still verify and are
+// replaced on the user's next login. Zero fields take the defaults.
+type PasswordHashing struct {
+	// Algorithm is HashArgon2id, the default, or HashBcrypt
+	Algorithm  string
+	BcryptCost int
+	// Argon2Memory is in KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// withDefaults fills in unset fields with the OWASP recommended minimums
+func (h PasswordHashing) withDefaults() PasswordHashing {
+	if h.Algorithm == "" {
+		h.Algorithm = HashArgon2id
+	}
+	if h.BcryptCost == 0 {
+		h.BcryptCost = bcrypt.DefaultCost
+	}
+	if h.Argon2Memory == 0 {
+		h.Argon2Memory = 19 * 1024
+	}
+	if h.Argon2Iterations == 0 {
+		h.Argon2Iterations = 2
+	}
+	if h.Argon2Parallelism == 0 {
+		h.Argon2Parallelism = 1
+	}
+	return h
+}
+
+// argon2Params are the parameters recorded in an argon2id hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// Hash hashes a password with the configured algorithm
+func (h PasswordHashing) Hash(password string) (string, error) {
+	switch h.Algorithm {
+	case HashBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	case HashArgon2id:
+		salt := make([]byte, argon2SaltBytes)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, h.Argon2Iterations, h.Argon2Memory, h.Argon2Parallelism, argon2KeyBytes)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, h.Argon2Memory, h.Argon2Iterations, h.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	default:
+		return "", fmt.Errorf("unknown password hash algorithm %q", h.Algorithm)
+	}
+}
+
+// NeedsRehash reports whether a hash was made with another algorithm or
+// other parameters than the configured ones
+func (h PasswordHashing) NeedsRehash(hash string) bool {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		params, err := parseArgon2Hash(hash)
+		return err != nil || h.Algorithm != HashArgon2id ||
+			params.memory != h.Argon2Memory || params.iterations != h.Argon2Iterations ||
+			params.parallelism != h.Argon2Parallelism || len(params.key) != argon2KeyBytes
+	default:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || h.Algorithm != HashBcrypt || cost != h.BcryptCost
+	}
+}
+
+// verifyPasswordHash reports whether a password matches a hash made with
+// any supported algorithm and parameters
+func verifyPasswordHash(hash, password string) bool {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	params, err := parseArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1
+}
+
+// parseArgon2Hash reads the parameters of an argon2id hash in the PHC
+// string format
+func parseArgon2Hash(hash string) (*argon2Params, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != HashArgon2id {
+		return nil, fmt.Errorf("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("malformed argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	params := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	if params.memory == 0 || params.iterations == 0 || params.parallelism == 0 {
+		return nil, fmt.Errorf("malformed argon2id parameters")
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("malformed argon2id key: %w", err)
+	}
+	if len(params.key) == 0 {
+		return nil, fmt.Errorf("malformed argon2id key")
+	}
+	return params, nil
+}
+
+// hashPassword hashes a password a user is setting
+func (s *Service) hashPassword(password string) (string, error) {
+	hash, err := s.config.PasswordHashing.Hash(password)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hash, nil
+}
+
+// checkPassword reports whether a password is the user's. A hash is always
+// compared, even without a user or password, so the time taken does not
+// reveal whether an email is registered.
+func (s *Service) checkPassword(user *models.User, password string) bool {
+	s.dummyHashOnce.Do(func() {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		s.dummyHash, _ = s.config.PasswordHashing.Hash(base64.RawStdEncoding.EncodeToString(secret))
+	})
+
+	hash, real := s.dummyHash, user != nil && user.PasswordHash != ""
+	if real {
+		hash = user.PasswordHash
+	}
+	ok := verifyPasswordHash(hash, password)
+	return real && ok
+}
+
+// upgradePasswordHash rehashes a user's password with the current algorithm
+// and parameters if their hash was made with others. It is called with the
+// password just verified; failures are logged and retried next login.
+func (s *Service) upgradePasswordHash(ctx context.Context, user *models.User, password string) {
+	if !s.config.PasswordHashing.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	hash, err := s.hashPassword(password)
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+	// A password changed since it was checked is left alone
+	if err := s.repo.UpdatePasswordHash(ctx, user.ID, user.PasswordHash, hash); err != nil {
+		logger.ErrorLogger.Printf("Failed to store rehashed password of user %d: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = hash
+}
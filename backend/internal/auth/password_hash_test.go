@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHashing(t *testing.T) {
+	argon := PasswordHashing{Algorithm: HashArgon2id, Argon2Memory: 1024, Argon2Iterations: 1, Argon2Parallelism: 1}.withDefaults()
+	stronger := argon
+	stronger.Argon2Iterations = 2
+	bcryptHashing := PasswordHashing{Algorithm: HashBcrypt, BcryptCost: bcrypt.MinCost}.withDefaults()
+
+	argonHash, err := argon.Hash("secret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(argonHash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Expected a PHC string with the parameters, got %s", argonHash)
+	}
+	bcryptHash, err := bcryptHashing.Hash("secret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hashing    PasswordHashing
+		hash       string
+		password   string
+		wantMatch  bool
+		wantRehash bool
+	}{
+		{"argon2id", argon, argonHash, "secret", true, false},
+		{"argon2id wrong password", argon, argonHash, "guess", false, false},
+		{"argon2id parameters raised", stronger, argonHash, "secret", true, true},
+		{"bcrypt", bcryptHashing, bcryptHash, "secret", true, false},
+		{"bcrypt to argon2id", argon, bcryptHash, "secret", true, true},
+		{"argon2id to bcrypt", bcryptHashing, argonHash, "secret", true, true},
+		{"malformed", argon, "$argon2id$v=19$m=1024$c2FsdA$a2V5", "secret", false, true},
+		{"other version", argon, strings.Replace(argonHash, "v=19", "v=16", 1), "secret", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyPasswordHash(tt.hash, tt.password); got != tt.wantMatch {
+				t.Errorf("Expected match %v, got %v", tt.wantMatch, got)
+			}
+			if got := tt.hashing.NeedsRehash(tt.hash); got != tt.wantRehash {
+				t.Errorf("Expected rehash %v, got %v", tt.wantRehash, got)
+			}
+		})
+	}
+}
+
+func TestLoginRehashesPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo := &mfaRepo{user: &models.User{ID: 1, Email: "ada@example.com", PasswordHash: string(hash), AccountStatus: "active"}}
+	svc := NewService(repo, nil, nil, nil, nil, nil, Config{
+		PasswordHashing: PasswordHashing{Argon2Memory: 1024, Argon2Iterations: 1},
+	})
+	ctx := context.Background()
+	login := &models.LoginRequest{Email: "ada@example.com", Password: "password"}
+
+	if _, _, err := svc.Login(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "wrong"}, Client{}); err != ErrInvalidCredentials {
+		t.Fatalf("Expected %v, got %v", ErrInvalidCredentials, err)
+	}
+	if repo.user.PasswordHash != string(hash) {
+		t.Fatalf("Expected a failed login to keep the hash")
+	}
+
+	if _, _, err := svc.Login(ctx, login, Client{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rehashed := repo.user.PasswordHash
+	if !strings.HasPrefix(rehashed, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("Expected the bcrypt hash to be replaced with argon2id, got %s", rehashed)
+	}
+
+	if _, _, err := svc.Login(ctx, login, Client{}); err != nil {
+		t.Fatalf("Expected the rehashed password to work, got %v", err)
+	}
+	if repo.user.PasswordHash != rehashed {
+		t.Errorf("Expected a current hash to be kept")
+	}
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+var (
+	ErrPasswordTooShort = errors.New("password is too short")
+	ErrPasswordTooLong  = errors.New("password is too long")
+	ErrPasswordTooWeak  = errors.New("password is too easy to guess")
+	ErrPasswordPersonal = errors.New("password contains your username or email")
+	ErrPasswordBreached = errors.New("password has appeared in a data breach")
+)
+
+// maxPasswordBytes is the longest password bcrypt hashes in full
+const maxPasswordBytes = 72
+
+// personalMinLength is the shortest username or email part a password may
+// not contain; shorter ones would forbid too many passwords by chance
+const personalMinLength = 3
+
+// PasswordPolicy is what new passwords must satisfy. The zero value only
+// keeps out the user's own username and email.
+type PasswordPolicy struct {
+	MinLength int
+	// MinEntropyBits is the least estimated entropy, see passwordEntropy
+	MinEntropyBits float64
+	// Breached screens passwords against known breaches; nil skips it
+	Breached *BreachedPasswords
+}
+
+// Check returns an error wrapping one of the ErrPassword errors if the
+// password does not satisfy the policy for a user with the given username
+// and email
+func (p PasswordPolicy) Check(password, username, email string) error {
+	if n := utf8.RuneCountInString(password); n < p.MinLength {
+		return fmt.Errorf("%w: use at least %d characters", ErrPasswordTooShort, p.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: use at most %d bytes", ErrPasswordTooLong, maxPasswordBytes)
+	}
+	if containsPersonal(password, username, email) {
+		return ErrPasswordPersonal
+	}
+	if passwordEntropy(password) < p.MinEntropyBits {
+		return fmt.Errorf("%w: make it longer or mix in other kinds of characters", ErrPasswordTooWeak)
+	}
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return fmt.Errorf("failed to screen password: %w", err)
+		}
+		if breached {
+			return fmt.Errorf("%w: choose a different one", ErrPasswordBreached)
+		}
+	}
+	return nil
+}
+
+// checkNewPassword applies the password policy to a password a user is
+// setting
+func (s *Service) checkNewPassword(user *models.User, password string) error {
+	return s.config.PasswordPolicy.Check(password, user.Username, user.Email)
+}
+
+// containsPersonal reports whether a password contains the username, the
+// email or the email's local part, ignoring case
+func containsPersonal(password, username, email string) bool {
+	password = strings.ToLower(password)
+	email = strings.ToLower(email)
+	local, _, _ := strings.Cut(email, "@")
+
+	for _, part := range []string{strings.ToLower(username), email, local} {
+		if utf8.RuneCountInString(part) >= personalMinLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// passwordEntropy estimates the bits of entropy of a password from the kinds
+// of characters it uses. A character that repeats the one before it or
+// continues a run such as abc or 321 adds a single bit.
+func passwordEntropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r <= unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, kind := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if kind.used {
+			pool += kind.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	perChar := math.Log2(float64(pool))
+
+	var bits float64
+	prev := rune(-1)
+	for _, r := range password {
+		d := unicode.ToLower(r) - unicode.ToLower(prev)
+		if prev >= 0 && d >= -1 && d <= 1 {
+			bits++
+		} else {
+			bits += perChar
+		}
+		prev = r
+	}
+	return bits
+}
+
+// BreachedPasswords looks passwords up in a local copy of a breached
+// password list in the k-anonymity range format: a directory with a file
+// per five hex digit prefix of the passwords' SHA-1 hashes, named like
+// 21BD1.txt, whose lines are the rest of the hashes and their counts, like
+// 0018A45C4D1DEF81644B54AB7F969B88D65:21
+type BreachedPasswords struct {
+	dir string
+}
+
+// NewBreachedPasswords opens a breached password list directory
+func NewBreachedPasswords(dir string) (*BreachedPasswords, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached password list %s is not a directory", dir)
+	}
+	return &BreachedPasswords{dir: dir}, nil
+}
+
+// Contains reports whether a password is in the list. A missing prefix
+// file means no listed password has that prefix.
+func (b *BreachedPasswords) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	f, err := os.Open(filepath.Join(b.dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open breached password range: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breached password range: %w", err)
+	}
+	return false, nil
+}
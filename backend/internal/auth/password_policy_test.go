@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// writeBreachedRange writes a prefix file listing passwords, the way a
+// downloaded breached password list is laid out
+func writeBreachedRange(t *testing.T, dir string, passwords ...string) {
+	t.Helper()
+	for _, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		line := "0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n" + hash[5:] + ":52579\r\n"
+		if err := os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(line), 0o644); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	dir := t.TempDir()
+	writeBreachedRange(t, dir, "correct horse battery staple")
+	breached, err := NewBreachedPasswords(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	policy := PasswordPolicy{MinLength: 10, MinEntropyBits: 40, Breached: breached}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{"strong", "Tq8#vL2!mZ", nil},
+		{"passphrase", "violet kettle drums", nil},
+		{"too short", "Tq8#vL2", ErrPasswordTooShort},
+		{"too long", strings.Repeat("Tq8#vL2!mZ", 8), ErrPasswordTooLong},
+		{"username", "xCountess-Tq8#", ErrPasswordPersonal},
+		{"email local part", "ADA.lovelace!2024", ErrPasswordPersonal},
+		{"repeated", "aaaaaaaaaaaaaa", ErrPasswordTooWeak},
+		{"sequence", "abcdefgh1234", ErrPasswordTooWeak},
+		{"breached", "correct horse battery staple", ErrPasswordBreached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.password, "countess", "ada@example.com")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := NewBreachedPasswords(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected a missing list to fail to load")
+	}
+}
+
+// passwordRepo is an in-memory Repository for password changes
+type passwordRepo struct {
+	Repository
+	user        *models.User
+	keptSession int64
+}
+
+func (r *passwordRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	return r.user, nil
+}
+
+func (r *passwordRepo) ChangePassword(ctx context.Context, userID int64, passwordHash string, keepSessionID int64, now time.Time) error {
+	r.user.PasswordHash = passwordHash
+	r.keptSession = keepSessionID
+	return nil
+}
+
+func TestChangePassword(t *testing.T) {
+	logger.Init()
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-Tq8#vL2!mZ"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo := &passwordRepo{user: &models.User{ID: 1, Username: "countess", Email: "ada@example.com", PasswordHash: string(hash)}}
+	mailer := notifications.NewFakeEmailSender()
+	svc := NewService(repo, nil, nil, nil, mailer, nil, Config{
+		PasswordPolicy: PasswordPolicy{MinLength: 10, MinEntropyBits: 40},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		req     models.ChangePasswordRequest
+		wantErr error
+	}{
+		{"wrong current password", models.ChangePasswordRequest{CurrentPassword: "guess", NewPassword: "new-Tq8#vL2!mZ"}, ErrInvalidCredentials},
+		{"unchanged", models.ChangePasswordRequest{CurrentPassword: "old-Tq8#vL2!mZ", NewPassword: "old-Tq8#vL2!mZ"}, ErrSamePassword},
+		{"weak", models.ChangePasswordRequest{CurrentPassword: "old-Tq8#vL2!mZ", NewPassword: "aaaaaaaaaaaa"}, ErrPasswordTooWeak},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.ChangePassword(ctx, 1, 7, &tt.req, Client{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	if repo.user.PasswordHash != string(hash) || len(mailer.Sent()) != 0 {
+		t.Fatalf("Expected refused changes to leave the password alone")
+	}
+
+	err = svc.ChangePassword(ctx, 1, 7, &models.ChangePasswordRequest{CurrentPassword: "old-Tq8#vL2!mZ", NewPassword: "new-Tq8#vL2!mZ"}, Client{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !svc.checkPassword(repo.user, "new-Tq8#vL2!mZ") {
+		t.Errorf("Expected the new password to be set")
+	}
+	if repo.keptSession != 7 {
+		t.Errorf("Expected the current session to be kept, got %d", repo.keptSession)
+	}
+	if sent := mailer.Sent(); len(sent) != 1 || sent[0].To != "ada@example.com" {
+		t.Errorf("Expected the user to be emailed about the change, got %d emails", len(sent))
+	}
+}
+
+func TestChangePasswordThrottle(t *testing.T) {
+	logger.Init()
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-Tq8#vL2!mZ"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo := &passwordRepo{user: &models.User{ID: 1, Username: "countess", Email: "ada@example.com", PasswordHash: string(hash)}}
+	throttle, notifier := newMemThrottle(), &recordingNotifier{}
+	svc := NewService(repo, nil, nil, notifier, notifications.NewFakeEmailSender(), throttle, Config{
+		PasswordPolicy:     PasswordPolicy{MinLength: 10, MinEntropyBits: 40},
+		LoginMaxFailures:   5,
+		LoginIPMaxFailures: 100,
+		LoginLockout:       15 * time.Minute,
+	})
+	ctx := context.Background()
+	client := Client{IP: "203.0.113.7"}
+
+	// Guesses with a stolen token count against the account like logins
+	for i := 0; i < 5; i++ {
+		delete(throttle.blocks, accountKey("ada@example.com"))
+		req := &models.ChangePasswordRequest{CurrentPassword: "guess", NewPassword: "new-Tq8#vL2!mZ"}
+		if err := svc.ChangePassword(ctx, 1, 7, req, client); err != ErrInvalidCredentials {
+			t.Fatalf("Expected %v, got %v", ErrInvalidCredentials, err)
+		}
+	}
+	if len(notifier.messages) != 1 || notifier.messages[0].Type != notifications.TypeAccountLocked {
+		t.Errorf("Expected one lockout notification, got %v", notifier.messages)
+	}
+
+	req := &models.ChangePasswordRequest{CurrentPassword: "old-Tq8#vL2!mZ", NewPassword: "new-Tq8#vL2!mZ"}
+	var throttled *ThrottledError
+	if err := svc.ChangePassword(ctx, 1, 7, req, client); !errors.As(err, &throttled) || throttled.RetryAfter != 15*time.Minute {
+		t.Fatalf("Expected a 15 minute lockout, got %v", err)
+	}
+	if repo.user.PasswordHash != string(hash) {
+		t.Errorf("Expected a blocked change to leave the password alone")
+	}
+	if _, _, err := svc.Login(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "old-Tq8#vL2!mZ"}, client); !errors.As(err, &throttled) {
+		t.Errorf("Expected the account's logins to be blocked too, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("reset token is invalid or has expired")
+	ErrSessionRevoked    = errors.New("session has been revoked")
+	ErrSamePassword      = errors.New("new password must differ from the current one")
+)
+
+// tokenBytes is the length of password reset and login challenge tokens
+// before encoding
+const tokenBytes = 32
+
+// RequestPasswordReset emails a single-use reset link to the account with
+// the given email. Nothing tells the caller whether the account exists:
+// unknown addresses are silently ignored and failures to issue or send the
+// link are only logged.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to generate reset token for user %d: %v", user.ID, err)
+		return nil
+	}
+	expiresAt := time.Now().Add(s.config.PasswordResetTTL)
+	if err := s.repo.CreateResetToken(ctx, user.ID, hashToken(token), expiresAt); err != nil {
+		logger.ErrorLogger.Printf("Failed to store reset token for user %d: %v", user.ID, err)
+		return nil
+	}
+
+	err = s.mailer.Send(ctx, &notifications.EmailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password for your account. To choose a new password, open this link within %d minutes:\n\n%s\n\nIf this wasn't you, you can ignore this email.",
+			int(s.config.PasswordResetTTL.Minutes()), link(s.config.PasswordResetURL, token),
+		),
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to send reset email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token. The token and any
+// other outstanding tokens are used up, and every existing session is
+// signed out.
+func (s *Service) ResetPassword(ctx context.Context, token, password string) error {
+	// Stored timestamps are compared with token issue times, which have
+	// second precision
+	now := time.Now().Truncate(time.Second)
+
+	userID, err := s.repo.GetResetTokenUser(ctx, hashToken(token), now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to get reset token: %w", err)
+	}
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkNewPassword(user, password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.ResetPassword(ctx, hashToken(token), hashedPassword, now); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	return nil
+}
+
+// ChangePassword sets a new password for a user who knows their current one.
+// Checks of the current password count against the account and IP like
+// logins do, so a stolen token can't be used to guess it. The user's other
+// sessions are signed out and they are emailed about the change.
+func (s *Service) ChangePassword(ctx context.Context, userID, sessionID int64, req *models.ChangePasswordRequest, client Client) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	attempt, err := s.startLoginAttempt(ctx, user.Email, client)
+	if err != nil {
+		return err
+	}
+	if !s.checkPassword(user, req.CurrentPassword) {
+		s.failLoginAttempt(ctx, attempt, user, client)
+		return ErrInvalidCredentials
+	}
+	s.passLoginAttempt(ctx, client)
+	if req.NewPassword == req.CurrentPassword {
+		return ErrSamePassword
+	}
+	if err := s.checkNewPassword(user, req.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.ChangePassword(ctx, userID, hashedPassword, sessionID, time.Now()); err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, &notifications.EmailMessage{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Body:    "The password for your account was just changed, and your other devices were signed out.\n\nIf this wasn't you, reset your password right away.",
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to send password change email to user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// link builds the link to a page for a token, keeping the page's own query
+func link(page, token string) string {
+	u, err := url.Parse(page)
+	if err != nil {
+		return page + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// checkSession returns ErrSessionRevoked for tokens issued before the user's
+// sessions were revoked. Token issue times have second precision, so a token
+// issued in the second of the revocation is kept.
+func checkSession(user *models.User, claims *Claims) error {
+	if user.SessionsRevokedAt == nil || claims.IssuedAt == nil {
+		return nil
+	}
+	if claims.IssuedAt.Time.Before(user.SessionsRevokedAt.Truncate(time.Second)) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// newToken generates a random URL-safe token
+func newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 of a token, as stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
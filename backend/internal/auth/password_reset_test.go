@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// resetRepo is an in-memory Repository for the password reset flow; the
+// embedded interface panics on methods the flow does not use
+type resetRepo struct {
+	Repository
+	users  map[string]*models.User
+	tokens map[string]*resetToken
+}
+
+type resetToken struct {
+	userID    int64
+	expiresAt time.Time
+	used      bool
+}
+
+func (r *resetRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, ok := r.users[email]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (r *resetRepo) CreateResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	r.tokens[tokenHash] = &resetToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *resetRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *resetRepo) GetResetTokenUser(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.used || !token.expiresAt.After(now) {
+		return 0, sql.ErrNoRows
+	}
+	return token.userID, nil
+}
+
+func (r *resetRepo) ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (int64, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok || token.used || !token.expiresAt.After(now) {
+		return 0, sql.ErrNoRows
+	}
+	for _, user := range r.users {
+		if user.ID == token.userID {
+			user.PasswordHash = passwordHash
+			user.SessionsRevokedAt = &now
+		}
+	}
+	for _, t := range r.tokens {
+		if t.userID == token.userID {
+			t.used = true
+		}
+	}
+	return token.userID, nil
+}
+
+func TestPasswordReset(t *testing.T) {
+	logger.Init()
+	repo := &resetRepo{
+		users:  map[string]*models.User{"ada@example.com": {ID: 1, Email: "ada@example.com"}},
+		tokens: make(map[string]*resetToken),
+	}
+	mailer := notifications.NewFakeEmailSender()
+	svc := NewService(repo, nil, nil, nil, mailer, nil, Config{
+		PasswordResetURL: "https://halo.example/reset?lang=en",
+		PasswordResetTTL: 30 * time.Minute,
+	})
+	ctx := context.Background()
+
+	if err := svc.RequestPasswordReset(ctx, "nobody@example.com"); err != nil {
+		t.Fatalf("Expected no error for an unknown email, got %v", err)
+	}
+	if len(mailer.Sent()) != 0 {
+		t.Fatalf("Expected no email for an unknown address, got %d", len(mailer.Sent()))
+	}
+
+	if err := svc.RequestPasswordReset(ctx, "ada@example.com"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sent := mailer.Sent()
+	if len(sent) != 1 || sent[0].To != "ada@example.com" {
+		t.Fatalf("Expected one email to the account, got %d", len(sent))
+	}
+	if !strings.Contains(sent[0].Body, "lang=en") {
+		t.Errorf("Expected the reset URL's query to be kept")
+	}
+	token := tokenFromLink(t, sent[0].Body)
+	if _, ok := repo.tokens[token]; ok {
+		t.Errorf("Expected only the token hash to be stored")
+	}
+
+	if err := svc.ResetPassword(ctx, "not-a-token", "new-password"); err != ErrInvalidResetToken {
+		t.Errorf("Expected error %v, got %v", ErrInvalidResetToken, err)
+	}
+	if err := svc.ResetPassword(ctx, token, "ada-password"); !errors.Is(err, ErrPasswordPersonal) {
+		t.Errorf("Expected error %v, got %v", ErrPasswordPersonal, err)
+	}
+	if err := svc.ResetPassword(ctx, token, "new-password"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if repo.users["ada@example.com"].SessionsRevokedAt == nil {
+		t.Errorf("Expected sessions to be revoked")
+	}
+	if err := svc.ResetPassword(ctx, token, "another-password"); err != ErrInvalidResetToken {
+		t.Errorf("Expected a used token to be refused, got %v", err)
+	}
+}
+
+func TestCheckSession(t *testing.T) {
+	revokedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	revokedMidSecond := revokedAt.Add(400 * time.Millisecond)
+	issued := func(at time.Time) *Claims {
+		return &Claims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(at)}}
+	}
+
+	tests := []struct {
+		name    string
+		user    *models.User
+		claims  *Claims
+		wantErr error
+	}{
+		{"NeverRevoked", &models.User{}, issued(revokedAt), nil},
+		{"IssuedBefore", &models.User{SessionsRevokedAt: &revokedAt}, issued(revokedAt.Add(-time.Minute)), ErrSessionRevoked},
+		{"IssuedAfter", &models.User{SessionsRevokedAt: &revokedAt}, issued(revokedAt.Add(time.Minute)), nil},
+		{"IssuedSameSecond", &models.User{SessionsRevokedAt: &revokedMidSecond}, issued(revokedAt), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSession(tt.user, tt.claims); err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
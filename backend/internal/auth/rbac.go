@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+var (
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+// Role is a coarse-grained set of permissions assigned to a user
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Permission is a single capability that can be required by an endpoint
+type Permission string
+
+const (
+	// PermUsersModerate allows suspending and shadow-banning accounts
+	PermUsersModerate Permission = "users:moderate"
+	// PermUsersBan allows permanently banning accounts
+	PermUsersBan Permission = "users:ban"
+	// PermContentModerate allows acting on streams and videos
+	PermContentModerate Permission = "content:moderate"
+	// PermRolesManage allows changing roles and permission grants
+	PermRolesManage Permission = "roles:manage"
+	// PermPaymentsManage allows inspecting and replaying payment webhooks
+	PermPaymentsManage Permission = "payments:manage"
+)
+
+// roleRanks orders roles by authority; unknown roles rank lowest
+var roleRanks = map[Role]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// rolePermissions maps each role to the permissions it implies
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {},
+	RoleModerator: {
+		PermUsersModerate,
+		PermContentModerate,
+	},
+	RoleAdmin: {
+		PermUsersModerate,
+		PermUsersBan,
+		PermContentModerate,
+		PermRolesManage,
+		PermPaymentsManage,
+	},
+}
+
+// knownPermissions is the set of permissions that can be granted individually
+var knownPermissions = map[Permission]bool{
+	PermUsersModerate:   true,
+	PermUsersBan:        true,
+	PermContentModerate: true,
+	PermRolesManage:     true,
+	PermPaymentsManage:  true,
+}
+
+// Valid reports whether the role is one of the defined roles
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Rank orders roles by authority, higher ranking roles outranking lower ones
+func (r Role) Rank() int {
+	return roleRanks[r]
+}
+
+// Permissions returns the permissions implied by the role
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// Valid reports whether the permission is one of the defined permissions
+func (p Permission) Valid() bool {
+	return knownPermissions[p]
+}
+
+// mergePermissions combines role permissions with individual grants, without duplicates
+func mergePermissions(role Role, grants []Permission) []Permission {
+	seen := make(map[Permission]bool)
+	result := make([]Permission, 0, len(role.Permissions())+len(grants))
+	for _, list := range [][]Permission{role.Permissions(), grants} {
+		for _, p := range list {
+			if !seen[p] {
+				seen[p] = true
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
+
+// Permissions returns the effective permissions of a user: those implied by
+// their role plus any granted individually
+func (s *Service) Permissions(ctx context.Context, user *models.User) ([]Permission, error) {
+	granted, err := s.repo.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user permissions: %w", err)
+	}
+
+	grants := make([]Permission, len(granted))
+	for i, p := range granted {
+		grants[i] = Permission(p)
+	}
+
+	return mergePermissions(Role(user.Role), grants), nil
+}
+
+// Claims builds the JWT claims for a user, including role and permissions
+func (s *Service) Claims(ctx context.Context, user *models.User) (*Claims, error) {
+	permissions, err := s.Permissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Username:    user.Username,
+		Role:        user.Role,
+		Permissions: make([]string, len(permissions)),
+	}
+	for i, p := range permissions {
+		claims.Permissions[i] = string(p)
+	}
+
+	return claims, nil
+}
+
+// checkOutranks loads a user and the actor managing them, returning
+// ErrOutranked unless the actor's role ranks above the user's
+func (s *Service) checkOutranks(ctx context.Context, userID, actorID int64) (user, actor *models.User, err error) {
+	if user, err = s.GetUserByID(ctx, userID); err != nil {
+		return nil, nil, err
+	}
+	if actor, err = s.GetUserByID(ctx, actorID); err != nil {
+		return nil, nil, err
+	}
+	if Role(user.Role).Rank() >= Role(actor.Role).Rank() {
+		return nil, nil, ErrOutranked
+	}
+	return user, actor, nil
+}
+
+// SetUserRole changes the role of a user. Actors may only change the roles
+// of users below them, and to no role above their own.
+func (s *Service) SetUserRole(ctx context.Context, userID int64, role Role, changedBy int64) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+
+	_, actor, err := s.checkOutranks(ctx, userID, changedBy)
+	if err != nil {
+		return err
+	}
+	if role.Rank() > Role(actor.Role).Rank() {
+		return ErrOutranked
+	}
+
+	if err := s.repo.SetUserRole(ctx, userID, string(role), changedBy); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GrantPermission grants a single permission to a user on top of their role.
+// Actors may only grant permissions they hold, to users below them.
+func (s *Service) GrantPermission(ctx context.Context, userID int64, permission Permission, grantedBy int64) error {
+	if !permission.Valid() {
+		return ErrInvalidPermission
+	}
+
+	_, actor, err := s.checkOutranks(ctx, userID, grantedBy)
+	if err != nil {
+		return err
+	}
+	held, err := s.Permissions(ctx, actor)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(held, permission) {
+		return ErrOutranked
+	}
+
+	return s.repo.GrantPermission(ctx, userID, string(permission), grantedBy)
+}
+
+// RevokePermission removes an individually granted permission from a user
+// below the actor
+func (s *Service) RevokePermission(ctx context.Context, userID int64, permission Permission, revokedBy int64) error {
+	if !permission.Valid() {
+		return ErrInvalidPermission
+	}
+
+	if _, _, err := s.checkOutranks(ctx, userID, revokedBy); err != nil {
+		return err
+	}
+
+	return s.repo.RevokePermission(ctx, userID, string(permission))
+}
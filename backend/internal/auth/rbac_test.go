@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+func TestRBAC(t *testing.T) {
+	t.Run("RolePermissions", func(t *testing.T) {
+		if len(RoleUser.Permissions()) != 0 {
+			t.Errorf("Expected no permissions for user role, got %v", RoleUser.Permissions())
+		}
+
+		for _, p := range RoleModerator.Permissions() {
+			found := false
+			for _, ap := range RoleAdmin.Permissions() {
+				if p == ap {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected admin to have moderator permission %s", p)
+			}
+		}
+	})
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		if Role("superuser").Valid() {
+			t.Error("Expected unknown role to be invalid")
+		}
+		if Permission("everything").Valid() {
+			t.Error("Expected unknown permission to be invalid")
+		}
+	})
+
+	t.Run("MergePermissions", func(t *testing.T) {
+		merged := mergePermissions(RoleModerator, []Permission{PermUsersModerate, PermRolesManage})
+
+		if len(merged) != len(RoleModerator.Permissions())+1 {
+			t.Errorf("Expected duplicates to be removed, got %v", merged)
+		}
+	})
+
+	t.Run("ClaimsHasPermission", func(t *testing.T) {
+		manager := NewJWTManager("test-secret-key", 1)
+		token, err := manager.GenerateTokenWithClaims(&Claims{
+			UserID:      1,
+			Role:        string(RoleModerator),
+			Permissions: []string{string(PermUsersModerate)},
+		})
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+
+		claims, err := manager.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("Failed to validate token: %v", err)
+		}
+
+		if !claims.HasPermission(PermUsersModerate) {
+			t.Error("Expected claims to carry users:moderate")
+		}
+		if claims.HasPermission(PermRolesManage) {
+			t.Error("Expected claims not to carry roles:manage")
+		}
+	})
+}
+
+func TestManageRoles(t *testing.T) {
+	const (
+		admin = iota + 1
+		otherAdmin
+		roleManager
+		moderator
+		user
+	)
+
+	tests := []struct {
+		name    string
+		apply   func(s *Service) error
+		wantErr error
+	}{
+		{"admin promotes user to moderator", func(s *Service) error {
+			return s.SetUserRole(context.Background(), user, RoleModerator, admin)
+		}, nil},
+		{"admin demotes moderator", func(s *Service) error {
+			return s.SetUserRole(context.Background(), moderator, RoleUser, admin)
+		}, nil},
+		{"admin demotes peer", func(s *Service) error {
+			return s.SetUserRole(context.Background(), otherAdmin, RoleUser, admin)
+		}, ErrOutranked},
+		{"granted user promotes self", func(s *Service) error {
+			return s.SetUserRole(context.Background(), roleManager, RoleAdmin, roleManager)
+		}, ErrOutranked},
+		{"granted user promotes peer", func(s *Service) error {
+			return s.SetUserRole(context.Background(), user, RoleModerator, roleManager)
+		}, ErrOutranked},
+		{"moderator promotes user above self", func(s *Service) error {
+			return s.SetUserRole(context.Background(), user, RoleAdmin, moderator)
+		}, ErrOutranked},
+		{"admin grants held permission", func(s *Service) error {
+			return s.GrantPermission(context.Background(), user, PermPaymentsManage, admin)
+		}, nil},
+		{"granted user grants self", func(s *Service) error {
+			return s.GrantPermission(context.Background(), roleManager, PermUsersBan, roleManager)
+		}, ErrOutranked},
+		{"moderator grants permission not held", func(s *Service) error {
+			return s.GrantPermission(context.Background(), user, PermUsersBan, moderator)
+		}, ErrOutranked},
+		{"moderator grants held permission", func(s *Service) error {
+			return s.GrantPermission(context.Background(), user, PermContentModerate, moderator)
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &moderationRepo{
+				users: map[int64]*models.User{
+					admin:       {ID: admin, Role: string(RoleAdmin)},
+					otherAdmin:  {ID: otherAdmin, Role: string(RoleAdmin)},
+					roleManager: {ID: roleManager, Role: string(RoleUser)},
+					moderator:   {ID: moderator, Role: string(RoleModerator)},
+					user:        {ID: user, Role: string(RoleUser)},
+				},
+				grants: map[int64][]string{roleManager: {string(PermRolesManage)}},
+			}
+			svc := NewService(repo, nil, nil, nil, nil, nil, Config{})
+
+			if err := tt.apply(svc); err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
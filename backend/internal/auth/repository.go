@@ -4,10 +4,52 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
 )
 
+// userColumns is the column list matching scanUser
+const userColumns = `id, email, username, password_hash, display_name, bio, avatar_url, is_adult, adult_mode, role,
+	account_status, suspended_until, status_reason, sessions_revoked_at, email_verified_at, deletion_scheduled_at,
+	created_at, updated_at,
+	EXISTS (SELECT 1 FROM user_totp WHERE user_totp.user_id = users.id AND enabled_at IS NOT NULL)`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a row selected with userColumns into a user
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Username,
+		&user.PasswordHash,
+		&user.DisplayName,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.IsAdult,
+		&user.AdultMode,
+		&user.Role,
+		&user.AccountStatus,
+		&user.SuspendedUntil,
+		&user.StatusReason,
+		&user.SessionsRevokedAt,
+		&user.EmailVerifiedAt,
+		&user.DeletionScheduledAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.MFAEnabled,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // PostgresRepository implements the Repository interface for PostgreSQL
 type PostgresRepository struct {
 	db *sql.DB
@@ -21,8 +63,8 @@ func NewPostgresRepository(db *sql.DB) *PostgresRepository {
 // CreateUser creates a new user in the database
 func (r *PostgresRepository) CreateUser(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (email, username, password_hash, display_name, bio, avatar_url, is_adult, adult_mode, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO users (email, username, password_hash, display_name, bio, avatar_url, is_adult, adult_mode, role, account_status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
 
@@ -37,6 +79,8 @@ func (r *PostgresRepository) CreateUser(ctx context.Context, user *models.User)
 		user.AvatarURL,
 		user.IsAdult,
 		user.AdultMode,
+		user.Role,
+		user.AccountStatus,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID)
@@ -50,69 +94,21 @@ func (r *PostgresRepository) CreateUser(ctx context.Context, user *models.User)
 
 // GetUserByEmail retrieves a user by email
 func (r *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `
-		SELECT id, email, username, password_hash, display_name, bio, avatar_url, is_adult, adult_mode, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`
-
-	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Username,
-		&user.PasswordHash,
-		&user.DisplayName,
-		&user.Bio,
-		&user.AvatarURL,
-		&user.IsAdult,
-		&user.AdultMode,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return user, nil
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return scanUser(r.db.QueryRowContext(ctx, query, email))
 }
 
 // GetUserByID retrieves a user by ID
 func (r *PostgresRepository) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
-	query := `
-		SELECT id, email, username, password_hash, display_name, bio, avatar_url, is_adult, adult_mode, created_at, updated_at
-		FROM users
-		WHERE id = $1
-	`
-
-	user := &models.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Username,
-		&user.PasswordHash,
-		&user.DisplayName,
-		&user.Bio,
-		&user.AvatarURL,
-		&user.IsAdult,
-		&user.AdultMode,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return user, nil
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return scanUser(r.db.QueryRowContext(ctx, query, id))
 }
 
 // UpdateUser updates a user in the database
 func (r *PostgresRepository) UpdateUser(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET email = $1, username = $2, display_name = $3, bio = $4, avatar_url = $5, 
+		SET email = $1, username = $2, display_name = $3, bio = $4, avatar_url = $5,
 		    is_adult = $6, adult_mode = $7, updated_at = $8
 		WHERE id = $9
 	`
@@ -137,3 +133,1141 @@ func (r *PostgresRepository) UpdateUser(ctx context.Context, user *models.User)
 
 	return nil
 }
+
+// SetUserRole changes a user's role, recording who changed it
+func (r *PostgresRepository) SetUserRole(ctx context.Context, userID int64, role string, changedBy int64) error {
+	query := `UPDATE users SET role = $1, role_changed_by = $2, role_changed_at = NOW() WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, role, changedBy, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// GetUserPermissions retrieves the permissions granted individually to a user
+func (r *PostgresRepository) GetUserPermissions(ctx context.Context, userID int64) ([]string, error) {
+	query := `SELECT permission FROM user_permissions WHERE user_id = $1 ORDER BY permission`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// GrantPermission grants a permission to a user, ignoring duplicate grants
+func (r *PostgresRepository) GrantPermission(ctx context.Context, userID int64, permission string, grantedBy int64) error {
+	query := `
+		INSERT INTO user_permissions (user_id, permission, granted_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, permission) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, permission, grantedBy); err != nil {
+		return fmt.Errorf("failed to grant permission: %w", err)
+	}
+
+	return nil
+}
+
+// RevokePermission removes a permission grant from a user
+func (r *PostgresRepository) RevokePermission(ctx context.Context, userID int64, permission string) error {
+	query := `DELETE FROM user_permissions WHERE user_id = $1 AND permission = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, permission); err != nil {
+		return fmt.Errorf("failed to revoke permission: %w", err)
+	}
+
+	return nil
+}
+
+// SetAccountStatus applies a moderation action to a user and records it in
+// the account history, in a single transaction. It returns sql.ErrNoRows
+// unless the user still has the given role and status.
+func (r *PostgresRepository) SetAccountStatus(ctx context.Context, action *models.AccountAction, fromRole, fromStatus string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET account_status = $1, suspended_until = $2, status_reason = $3
+		WHERE id = $4 AND role = $5 AND account_status = $6
+	`, action.Status, action.ExpiresAt, action.Reason, action.UserID, fromRole, fromStatus)
+	if err != nil {
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+	if err := expectRow(result); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO account_actions (user_id, status, reason, moderator_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, action.UserID, action.Status, action.Reason, action.ModeratorID, action.ExpiresAt).Scan(&action.ID, &action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record account action: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetAccountActions retrieves the moderation history of a user, newest first
+func (r *PostgresRepository) GetAccountActions(ctx context.Context, userID int64) ([]*models.AccountAction, error) {
+	query := `
+		SELECT id, user_id, status, reason, moderator_id, expires_at, created_at
+		FROM account_actions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []*models.AccountAction
+	for rows.Next() {
+		action := &models.AccountAction{}
+		err := rows.Scan(
+			&action.ID,
+			&action.UserID,
+			&action.Status,
+			&action.Reason,
+			&action.ModeratorID,
+			&action.ExpiresAt,
+			&action.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+// expectRow returns sql.ErrNoRows when an update matched nothing
+func expectRow(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RecordLoginDevice records a login from a device and reports whether the
+// device is new for a user who had signed in from other devices before
+func (r *PostgresRepository) RecordLoginDevice(ctx context.Context, userID int64, deviceHash, userAgent, ip string) (bool, error) {
+	query := `
+		WITH known AS (
+			SELECT EXISTS (SELECT 1 FROM login_devices WHERE user_id = $1) AS any_device
+		), recorded AS (
+			INSERT INTO login_devices (user_id, device_hash, user_agent, last_ip)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, device_hash) DO UPDATE
+			SET last_ip = EXCLUDED.last_ip, last_seen_at = NOW()
+			RETURNING (xmax = 0) AS inserted
+		)
+		SELECT recorded.inserted AND known.any_device FROM recorded, known
+	`
+
+	var isNew bool
+	err := r.db.QueryRowContext(ctx, query, userID, deviceHash, userAgent, ip).Scan(&isNew)
+	return isNew, err
+}
+
+// CreateResetToken stores the hash of a password reset token
+func (r *PostgresRepository) CreateResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to insert reset token: %w", err)
+	}
+
+	return nil
+}
+
+// GetResetTokenUser returns the user of an unused, unexpired reset token
+func (r *PostgresRepository) GetResetTokenUser(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	query := `
+		SELECT user_id FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+	`
+
+	var userID int64
+	if err := r.db.QueryRowContext(ctx, query, tokenHash, now).Scan(&userID); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// ResetPassword redeems an unused, unexpired reset token: it sets the user's
+// password, revokes their sessions and invalidates their other reset tokens,
+// in a single transaction. It returns sql.ErrNoRows if the token cannot be
+// redeemed.
+func (r *PostgresRepository) ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING user_id
+	`, tokenHash, now).Scan(&userID)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET password_hash = $1, sessions_revoked_at = $2, updated_at = $2
+		WHERE id = $3
+	`, passwordHash, now, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+	if err := expectRow(result); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = $2
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	return userID, tx.Commit()
+}
+
+// ChangePassword sets a user's password, revokes their sessions other than
+// keepSessionID and invalidates their reset tokens, in a single transaction
+func (r *PostgresRepository) ChangePassword(ctx context.Context, userID int64, passwordHash string, keepSessionID int64, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3
+	`, passwordHash, now, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if err := expectRow(result); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = $3
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`, userID, keepSessionID, now)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = $2
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdatePasswordHash replaces a user's password hash, unless it has changed
+// from oldHash
+func (r *PostgresRepository) UpdatePasswordHash(ctx context.Context, userID int64, oldHash, newHash string) error {
+	query := `UPDATE users SET password_hash = $3 WHERE id = $1 AND password_hash = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, oldHash, newHash)
+	if err != nil {
+		return fmt.Errorf("failed to update password hash: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// ClaimVerificationEmail records that a verification email is being sent to
+// a user, unless one was sent at or after notBefore, in which case it
+// returns sql.ErrNoRows
+func (r *PostgresRepository) ClaimVerificationEmail(ctx context.Context, userID int64, notBefore time.Time) error {
+	query := `
+		UPDATE users SET email_verification_sent_at = NOW()
+		WHERE id = $1 AND (email_verification_sent_at IS NULL OR email_verification_sent_at < $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, notBefore)
+	if err != nil {
+		return fmt.Errorf("failed to record verification email: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// MarkEmailVerified marks a user's email verified, provided it is still the
+// given address
+func (r *PostgresRepository) MarkEmailVerified(ctx context.Context, userID int64, email string) error {
+	query := `
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1 AND email = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, email)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// CreateEmailChange stores a pending email change, replacing any the user
+// already had
+func (r *PostgresRepository) CreateEmailChange(ctx context.Context, change *models.EmailChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM email_changes WHERE user_id = $1`, change.UserID); err != nil {
+		return fmt.Errorf("failed to replace email change: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO email_changes (user_id, new_email, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, change.UserID, change.NewEmail, change.ExpiresAt).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert email change: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// confirmColumns maps the address confirming an email change to the column
+// recording it
+var confirmColumns = map[string]string{
+	changeOldAddress: "old_confirmed_at",
+	changeNewAddress: "new_confirmed_at",
+}
+
+// ConfirmEmailChange records that one address confirmed a pending, unexpired
+// email change. Once both have, the new address is applied as verified and
+// the change removed, in the same transaction. It returns sql.ErrNoRows if
+// the change no longer exists and ErrEmailTaken if the new address has
+// been taken since the change was requested.
+func (r *PostgresRepository) ConfirmEmailChange(ctx context.Context, changeID, userID int64, address string, now time.Time) (*models.EmailChange, error) {
+	column, ok := confirmColumns[address]
+	if !ok {
+		return nil, fmt.Errorf("unknown address %q", address)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	change := &models.EmailChange{}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE email_changes SET `+column+` = COALESCE(`+column+`, $3)
+		WHERE id = $1 AND user_id = $2 AND expires_at > $3
+		RETURNING id, user_id, new_email, old_confirmed_at, new_confirmed_at, expires_at, created_at
+	`, changeID, userID, now).Scan(
+		&change.ID,
+		&change.UserID,
+		&change.NewEmail,
+		&change.OldConfirmedAt,
+		&change.NewConfirmedAt,
+		&change.ExpiresAt,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if change.OldConfirmedAt == nil || change.NewConfirmedAt == nil {
+		return change, tx.Commit()
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET email = $1, email_verified_at = $2, updated_at = $2
+		WHERE id = $3 AND NOT EXISTS (SELECT 1 FROM users WHERE email = $1)
+	`, change.NewEmail, now, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+	if err := expectRow(result); err != nil {
+		return nil, ErrEmailTaken
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM email_changes WHERE id = $1`, change.ID); err != nil {
+		return nil, fmt.Errorf("failed to remove email change: %w", err)
+	}
+
+	change.Completed = true
+	return change, tx.Commit()
+}
+
+// GetTOTP retrieves a user's TOTP secret, pending or enabled
+func (r *PostgresRepository) GetTOTP(ctx context.Context, userID int64) (*models.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM user_totp WHERE user_id = $1
+	`
+
+	totp := &models.UserTOTP{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&totp.UserID,
+		&totp.Secret,
+		&totp.EnabledAt,
+		&totp.LastUsedStep,
+		&totp.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return totp, nil
+}
+
+// SavePendingTOTP stores a new TOTP secret awaiting confirmation, replacing
+// any pending one. It returns sql.ErrNoRows if TOTP is already enabled.
+func (r *PostgresRepository) SavePendingTOTP(ctx context.Context, userID int64, secret []byte) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+		WHERE user_totp.enabled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// EnableTOTP confirms a pending TOTP secret, recording the step of the code
+// that confirmed it, and replaces the user's recovery codes, in a single
+// transaction. It returns sql.ErrNoRows if no secret is pending.
+func (r *PostgresRepository) EnableTOTP(ctx context.Context, userID, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_totp SET enabled_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND enabled_at IS NULL
+	`, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+	if err := expectRow(result); err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records a code being used for a time step. It returns
+// sql.ErrNoRows if TOTP is not enabled or a code for this or a later step
+// was already used.
+func (r *PostgresRepository) UseTOTPStep(ctx context.Context, userID, step int64) error {
+	query := `
+		UPDATE user_totp SET last_used_step = $2
+		WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// DisableTOTP removes a user's TOTP secret and recovery codes
+func (r *PostgresRepository) DisableTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1 AND enabled_at IS NOT NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to disable TOTP: %w", err)
+	}
+	if err := expectRow(result); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes replaces all of a user's recovery codes
+func (r *PostgresRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO recovery_codes (user_id, code_hash)
+		SELECT $1, unnest($2::text[])
+	`, userID, codeHashes)
+	if err != nil {
+		return fmt.Errorf("failed to insert recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks one of a user's unused recovery codes used. It
+// returns sql.ErrNoRows if the user has no such unused code.
+func (r *PostgresRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	query := `
+		UPDATE recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// CreateMFAChallenge stores the hash of a login challenge token and the
+// method the user signed in with first, clearing the user's finished
+// challenges
+func (r *PostgresRepository) CreateMFAChallenge(ctx context.Context, userID int64, tokenHash, method string, expiresAt time.Time) error {
+	query := `
+		WITH cleared AS (
+			DELETE FROM mfa_challenges
+			WHERE user_id = $1 AND (used_at IS NOT NULL OR expires_at < NOW())
+		)
+		INSERT INTO mfa_challenges (user_id, token_hash, method, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, tokenHash, method, expiresAt); err != nil {
+		return fmt.Errorf("failed to insert MFA challenge: %w", err)
+	}
+
+	return nil
+}
+
+// AttemptMFAChallenge counts an attempt to answer an open challenge and
+// returns the user it was issued to and their first method. It returns
+// sql.ErrNoRows if the challenge is unknown, answered, expired or out of
+// attempts.
+func (r *PostgresRepository) AttemptMFAChallenge(ctx context.Context, tokenHash string, maxAttempts int, now time.Time) (int64, string, error) {
+	query := `
+		UPDATE mfa_challenges SET attempts = attempts + 1
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2 AND attempts < $3
+		RETURNING user_id, method
+	`
+
+	var userID int64
+	var method string
+	err := r.db.QueryRowContext(ctx, query, tokenHash, now, maxAttempts).Scan(&userID, &method)
+	return userID, method, err
+}
+
+// CompleteMFAChallenge marks a challenge answered so it cannot be reused
+func (r *PostgresRepository) CompleteMFAChallenge(ctx context.Context, tokenHash string) error {
+	query := `UPDATE mfa_challenges SET used_at = NOW() WHERE token_hash = $1 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to complete MFA challenge: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// CreatePasskeyChallenge stores the hash of a passkey ceremony challenge,
+// clearing expired ones. A userID of zero stores a login challenge, which
+// has no user.
+func (r *PostgresRepository) CreatePasskeyChallenge(ctx context.Context, userID int64, challengeHash, purpose string, expiresAt time.Time) error {
+	query := `
+		WITH cleared AS (
+			DELETE FROM passkey_challenges WHERE expires_at < NOW()
+		)
+		INSERT INTO passkey_challenges (user_id, challenge_hash, purpose, expires_at)
+		VALUES (NULLIF($1::bigint, 0), $2, $3, $4)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, challengeHash, purpose, expiresAt); err != nil {
+		return fmt.Errorf("failed to insert passkey challenge: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumePasskeyChallenge redeems a challenge for a purpose and returns the
+// user it was issued to, or zero for login challenges. It returns
+// sql.ErrNoRows if the challenge is unknown, used or expired.
+func (r *PostgresRepository) ConsumePasskeyChallenge(ctx context.Context, challengeHash, purpose string, now time.Time) (int64, error) {
+	query := `
+		DELETE FROM passkey_challenges
+		WHERE challenge_hash = $1 AND purpose = $2 AND expires_at > $3
+		RETURNING COALESCE(user_id, 0)
+	`
+
+	var userID int64
+	err := r.db.QueryRowContext(ctx, query, challengeHash, purpose, now).Scan(&userID)
+	return userID, err
+}
+
+// CreatePasskey saves a passkey. It returns sql.ErrNoRows if the credential
+// is already registered.
+func (r *PostgresRepository) CreatePasskey(ctx context.Context, passkey *models.Passkey) error {
+	query := `
+		INSERT INTO passkeys (user_id, credential_id, public_key, sign_count, aaguid, name)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (credential_id) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		passkey.UserID,
+		passkey.CredentialID,
+		passkey.PublicKey,
+		passkey.SignCount,
+		passkey.AAGUID,
+		passkey.Name,
+	).Scan(&passkey.ID, &passkey.CreatedAt)
+}
+
+// passkeyColumns is the column list matching scanPasskey
+const passkeyColumns = `id, user_id, credential_id, public_key, sign_count, aaguid, name, last_used_at, created_at`
+
+// scanPasskey scans a row selected with passkeyColumns into a passkey
+func scanPasskey(row rowScanner) (*models.Passkey, error) {
+	passkey := &models.Passkey{}
+	err := row.Scan(
+		&passkey.ID,
+		&passkey.UserID,
+		&passkey.CredentialID,
+		&passkey.PublicKey,
+		&passkey.SignCount,
+		&passkey.AAGUID,
+		&passkey.Name,
+		&passkey.LastUsedAt,
+		&passkey.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+// GetPasskeys retrieves a user's passkeys, newest first
+func (r *PostgresRepository) GetPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error) {
+	query := `SELECT ` + passkeyColumns + ` FROM passkeys WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passkeys := []*models.Passkey{}
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, passkey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return passkeys, nil
+}
+
+// GetPasskeyByCredentialID retrieves a passkey by its authenticator's ID
+func (r *PostgresRepository) GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*models.Passkey, error) {
+	query := `SELECT ` + passkeyColumns + ` FROM passkeys WHERE credential_id = $1`
+
+	return scanPasskey(r.db.QueryRowContext(ctx, query, credentialID))
+}
+
+// UsePasskey records a login with a passkey and its new sign count. It
+// returns sql.ErrNoRows if the count did not move forward, unless the
+// authenticator does not count.
+func (r *PostgresRepository) UsePasskey(ctx context.Context, passkeyID, signCount int64) error {
+	query := `
+		UPDATE passkeys SET sign_count = $2, last_used_at = NOW()
+		WHERE id = $1 AND (sign_count < $2 OR (sign_count = 0 AND $2 = 0))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, passkeyID, signCount)
+	if err != nil {
+		return fmt.Errorf("failed to update passkey: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// DeletePasskey removes one of a user's passkeys
+func (r *PostgresRepository) DeletePasskey(ctx context.Context, userID, passkeyID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM passkeys WHERE id = $1 AND user_id = $2`, passkeyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// identityColumns is the column list matching scanIdentity
+const identityColumns = `id, user_id, provider, subject, email, created_at`
+
+// scanIdentity scans a row selected with identityColumns into an identity
+func scanIdentity(row rowScanner) (*models.UserIdentity, error) {
+	identity := &models.UserIdentity{}
+	err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// GetIdentity retrieves the identity for an account at a provider
+func (r *PostgresRepository) GetIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE provider = $1 AND subject = $2`
+
+	return scanIdentity(r.db.QueryRowContext(ctx, query, provider, subject))
+}
+
+// GetIdentities retrieves the identities linked to a user
+func (r *PostgresRepository) GetIdentities(ctx context.Context, userID int64) ([]*models.UserIdentity, error) {
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE user_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []*models.UserIdentity{}
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// CreateIdentity links an identity to a user. It returns sql.ErrNoRows if
+// the identity is linked already or the user has one at the provider.
+func (r *PostgresRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email).
+		Scan(&identity.ID, &identity.CreatedAt)
+}
+
+// CreateUserWithIdentity creates a user with a verified email and no
+// password, linked to an identity. It returns sql.ErrNoRows if the username
+// or email is taken.
+func (r *PostgresRepository) CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (email, username, password_hash, display_name, is_adult, adult_mode, role, account_status,
+		                   email_verified_at, created_at, updated_at)
+		VALUES ($1, $2, '', $3, FALSE, FALSE, $4, $5, $6, $7, $8)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`,
+		user.Email,
+		user.Username,
+		user.DisplayName,
+		user.Role,
+		user.AccountStatus,
+		user.EmailVerifiedAt,
+		user.CreatedAt,
+		user.UpdatedAt,
+	).Scan(&user.ID)
+	if err != nil {
+		return err
+	}
+
+	identity.UserID = user.ID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, identity.UserID, identity.Provider, identity.Subject, identity.Email).Scan(&identity.ID, &identity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert identity: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteIdentity unlinks a user's identity at a provider
+func (r *PostgresRepository) DeleteIdentity(ctx context.Context, userID int64, provider string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+const sessionColumns = `id, user_id, device_name, platform, ip, location, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row rowScanner) (*models.Session, error) {
+	session := &models.Session{}
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.DeviceName,
+		&session.Platform,
+		&session.IP,
+		&session.Location,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// CreateSession stores a new session
+func (r *PostgresRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (user_id, device_name, platform, ip, location, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, last_seen_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		session.UserID,
+		session.DeviceName,
+		session.Platform,
+		session.IP,
+		session.Location,
+		session.ExpiresAt,
+	).Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession retrieves a session by ID
+func (r *PostgresRepository) GetSession(ctx context.Context, id int64) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+	return scanSession(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetSessions retrieves a user's sessions that are neither expired nor
+// revoked, most recently seen first. Sessions started before a password
+// reset revoked them all are left out.
+func (r *PostgresRepository) GetSessions(ctx context.Context, userID int64, now time.Time) ([]*models.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions s
+		WHERE s.user_id = $1 AND s.revoked_at IS NULL AND s.expires_at > $2
+		  AND s.created_at >= COALESCE((SELECT sessions_revoked_at FROM users WHERE id = $1), s.created_at)
+		ORDER BY s.last_seen_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// TouchSession records a session being used from an IP, keeping its
+// location when the new one is unknown
+func (r *PostgresRepository) TouchSession(ctx context.Context, id int64, ip, location string, now time.Time) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = $2, ip = $3, location = COALESCE(NULLIF($4, ''), location)
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, now, ip, location); err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession revokes one of a user's sessions
+func (r *PostgresRepository) RevokeSession(ctx context.Context, userID, sessionID int64, now time.Time) error {
+	query := `UPDATE sessions SET revoked_at = $3 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, sessionID, userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// ScheduleDeletion schedules a user's account to be erased at a time
+func (r *PostgresRepository) ScheduleDeletion(ctx context.Context, userID int64, at time.Time) error {
+	query := `
+		UPDATE users SET deletion_scheduled_at = $2
+		WHERE id = $1 AND deletion_scheduled_at IS NULL AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// CancelDeletion cancels a scheduled deletion that has not run yet
+func (r *PostgresRepository) CancelDeletion(ctx context.Context, userID int64) error {
+	query := `
+		UPDATE users SET deletion_scheduled_at = NULL
+		WHERE id = $1 AND deletion_scheduled_at IS NOT NULL AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	return expectRow(result)
+}
+
+// GetDueDeletions retrieves up to limit users whose deletion is due,
+// longest due first
+func (r *PostgresRepository) GetDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	query := `
+		SELECT id FROM users
+		WHERE deletion_scheduled_at <= $1 AND deleted_at IS NULL
+		ORDER BY deletion_scheduled_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// personalTables hold rows that are only about one user, in user_id,
+// and are deleted when the account is erased
+var personalTables = []string{
+	"gift_leaderboards",
+	"stream_reminders",
+	"notifications",
+	"notification_preferences",
+	"push_devices",
+	"login_devices",
+	"sessions",
+	"user_permissions",
+	"password_reset_tokens",
+	"email_changes",
+	"user_totp",
+	"recovery_codes",
+	"mfa_challenges",
+	"passkeys",
+	"passkey_challenges",
+	"user_identities",
+}
+
+// EraseUser erases a user whose deletion is due. The row is kept for the
+// wallet, gift and payout records that point at it, with everything
+// identifying the user replaced, so it can no longer sign in.
+func (r *PostgresRepository) EraseUser(ctx context.Context, userID int64, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Claim the deletion first, so a cancellation racing the job either
+	// wins or finds nothing to cancel
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET email = 'deleted-' || id || '@deleted.invalid', username = 'deleted_' || id,
+		    display_name = 'Deleted user', bio = '', avatar_url = '', password_hash = '',
+		    is_adult = FALSE, adult_mode = FALSE, role = 'user', status_reason = '',
+		    email_verified_at = NULL, email_verification_sent_at = NULL,
+		    sessions_revoked_at = $2, deleted_at = $2, updated_at = $2
+		WHERE id = $1 AND deletion_scheduled_at <= $2 AND deleted_at IS NULL
+	`, userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if err := expectRow(result); err != nil {
+		return err
+	}
+
+	// Upcoming and live events are canceled, so the ticket refund job pays
+	// back their tickets. Videos that received gifts or sold tickets are
+	// kept for those records but scrubbed and taken down; the others go
+	// along with their rooms and reminders.
+	type statement struct {
+		query string
+		args  []interface{}
+	}
+	statements := []statement{
+		{`UPDATE videos SET canceled_at = $2, cancel_reason = 'account deleted', is_live = FALSE
+		  WHERE user_id = $1 AND canceled_at IS NULL AND (status = 'scheduled' OR is_live)`, []interface{}{userID, now}},
+		{`DELETE FROM videos v WHERE v.user_id = $1
+		    AND NOT EXISTS (SELECT 1 FROM gift_transactions g WHERE g.video_id = v.id)
+		    AND NOT EXISTS (SELECT 1 FROM event_tickets t WHERE t.video_id = v.id)`, []interface{}{userID}},
+		{`UPDATE videos
+		  SET title = 'Deleted video', description = '', thumbnail_url = '', stream_url = '',
+		      taken_down_at = COALESCE(taken_down_at, $2), takedown_reason = 'account deleted'
+		  WHERE user_id = $1`, []interface{}{userID, now}},
+		// Subscriptions to and by the user end at once
+		{`UPDATE subscriptions SET status = 'expired', updated_at = $2
+		  WHERE (subscriber_id = $1 OR creator_id = $1) AND status <> 'expired'`, []interface{}{userID, now}},
+		{`UPDATE subscription_tiers SET active = FALSE WHERE creator_id = $1`, []interface{}{userID}},
+		{`DELETE FROM channel_moderators WHERE channel_id = $1 OR user_id = $1`, []interface{}{userID}},
+		{`DELETE FROM channel_bans WHERE channel_id = $1 OR user_id = $1`, []interface{}{userID}},
+		{`DELETE FROM moderation_queue WHERE author_id = $1`, []interface{}{userID}},
+	}
+	for _, table := range personalTables {
+		statements = append(statements, statement{`DELETE FROM ` + table + ` WHERE user_id = $1`, []interface{}{userID}})
+	}
+
+	for _, st := range statements {
+		if _, err := tx.ExecContext(ctx, st.query, st.args...); err != nil {
+			return fmt.Errorf("failed to erase user data: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -5,16 +5,20 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/moderation"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
 )
 
 var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserExists         = errors.New("user already exists")
+	ErrNotAdult           = errors.New("adult mode requires an adult account")
 )
 
 // Repository defines the interface for user data access
@@ -23,20 +27,192 @@ type Repository interface {
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByID(ctx context.Context, id int64) (*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
+	SetUserRole(ctx context.Context, userID int64, role string, changedBy int64) error
+	GetUserPermissions(ctx context.Context, userID int64) ([]string, error)
+	GrantPermission(ctx context.Context, userID int64, permission string, grantedBy int64) error
+	RevokePermission(ctx context.Context, userID int64, permission string) error
+	SetAccountStatus(ctx context.Context, action *models.AccountAction, fromRole, fromStatus string) error
+	GetAccountActions(ctx context.Context, userID int64) ([]*models.AccountAction, error)
+	// RecordLoginDevice records a login from a device and reports whether the
+	// device is new for a user who had signed in from other devices before
+	RecordLoginDevice(ctx context.Context, userID int64, deviceHash, userAgent, ip string) (bool, error)
+	CreateResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error
+	// GetResetTokenUser returns the user of an unused, unexpired reset token,
+	// or sql.ErrNoRows
+	GetResetTokenUser(ctx context.Context, tokenHash string, now time.Time) (int64, error)
+	// ResetPassword redeems a reset token, setting the password and revoking
+	// the user's sessions. It returns sql.ErrNoRows for tokens that are
+	// unknown, used or expired.
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string, now time.Time) (int64, error)
+	// ChangePassword sets a user's password and revokes their sessions
+	// other than keepSessionID
+	ChangePassword(ctx context.Context, userID int64, passwordHash string, keepSessionID int64, now time.Time) error
+	// UpdatePasswordHash replaces a user's password hash with another of the
+	// same password, unless the hash has changed from oldHash
+	UpdatePasswordHash(ctx context.Context, userID int64, oldHash, newHash string) error
+	// ClaimVerificationEmail records a verification email being sent, or
+	// returns sql.ErrNoRows if one was sent at or after notBefore
+	ClaimVerificationEmail(ctx context.Context, userID int64, notBefore time.Time) error
+	MarkEmailVerified(ctx context.Context, userID int64, email string) error
+	CreateEmailChange(ctx context.Context, change *models.EmailChange) error
+	ConfirmEmailChange(ctx context.Context, changeID, userID int64, address string, now time.Time) (*models.EmailChange, error)
+	GetTOTP(ctx context.Context, userID int64) (*models.UserTOTP, error)
+	// SavePendingTOTP returns sql.ErrNoRows if TOTP is already enabled
+	SavePendingTOTP(ctx context.Context, userID int64, secret []byte) error
+	EnableTOTP(ctx context.Context, userID, step int64, codeHashes []string) error
+	// UseTOTPStep returns sql.ErrNoRows if a code for the step, or a later
+	// one, was already used
+	UseTOTPStep(ctx context.Context, userID, step int64) error
+	DisableTOTP(ctx context.Context, userID int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error
+	// CreateMFAChallenge stores a challenge with the method the user signed
+	// in with first
+	CreateMFAChallenge(ctx context.Context, userID int64, tokenHash, method string, expiresAt time.Time) error
+	// AttemptMFAChallenge counts an attempt at an open challenge and returns
+	// its user and first method, or sql.ErrNoRows once it is answered,
+	// expired or out of attempts
+	AttemptMFAChallenge(ctx context.Context, tokenHash string, maxAttempts int, now time.Time) (int64, string, error)
+	CompleteMFAChallenge(ctx context.Context, tokenHash string) error
+	CreatePasskeyChallenge(ctx context.Context, userID int64, challengeHash, purpose string, expiresAt time.Time) error
+	// ConsumePasskeyChallenge redeems a challenge and returns its user, zero
+	// for login challenges, or sql.ErrNoRows if it is unknown or expired
+	ConsumePasskeyChallenge(ctx context.Context, challengeHash, purpose string, now time.Time) (int64, error)
+	// CreatePasskey returns sql.ErrNoRows if the credential is registered
+	CreatePasskey(ctx context.Context, passkey *models.Passkey) error
+	GetPasskeys(ctx context.Context, userID int64) ([]*models.Passkey, error)
+	GetPasskeyByCredentialID(ctx context.Context, credentialID []byte) (*models.Passkey, error)
+	// UsePasskey returns sql.ErrNoRows if the sign count did not increase
+	UsePasskey(ctx context.Context, passkeyID, signCount int64) error
+	DeletePasskey(ctx context.Context, userID, passkeyID int64) error
+	GetIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	GetIdentities(ctx context.Context, userID int64) ([]*models.UserIdentity, error)
+	// CreateIdentity returns sql.ErrNoRows if the identity is linked or the
+	// user has one at the provider
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+	// CreateUserWithIdentity returns sql.ErrNoRows if the username or email
+	// is taken
+	CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error
+	DeleteIdentity(ctx context.Context, userID int64, provider string) error
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, id int64) (*models.Session, error)
+	GetSessions(ctx context.Context, userID int64, now time.Time) ([]*models.Session, error)
+	TouchSession(ctx context.Context, id int64, ip, location string, now time.Time) error
+	// RevokeSession returns sql.ErrNoRows if the user has no such session
+	// or it is already revoked
+	RevokeSession(ctx context.Context, userID, sessionID int64, now time.Time) error
+	// ScheduleDeletion returns sql.ErrNoRows if a deletion is already
+	// scheduled
+	ScheduleDeletion(ctx context.Context, userID int64, at time.Time) error
+	// CancelDeletion returns sql.ErrNoRows if no deletion is pending
+	CancelDeletion(ctx context.Context, userID int64) error
+	GetDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	// EraseUser returns sql.ErrNoRows if the user's deletion is not due
+	EraseUser(ctx context.Context, userID int64, now time.Time) error
+}
+
+// Config holds the settings of the account email flows. The URLs are the
+// pages email links open, with the token in the token query parameter.
+type Config struct {
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+
+	EmailVerificationURL string
+	EmailChangeURL       string
+	// EmailTokenTTL is how long verification and email change links work
+	EmailTokenTTL time.Duration
+	// VerificationResendInterval is the shortest time between two
+	// verification emails to the same user
+	VerificationResendInterval time.Duration
+	// TokenSecret signs the tokens in verification and email change links
+	// and encrypts TOTP secrets
+	TokenSecret string
+	// MFAIssuer names the service in authenticator apps
+	MFAIssuer string
+
+	// PasskeyRPID is the domain passkeys are bound to, and PasskeyOrigins
+	// the origins ceremonies may run on
+	PasskeyRPID    string
+	PasskeyRPName  string
+	PasskeyOrigins []string
+
+	// OIDCProviders are the identity providers users can sign in with;
+	// providers without client IDs are off
+	OIDCProviders []OIDCProvider
+
+	// LoginMaxFailures failed logins to an account, or LoginIPMaxFailures
+	// from an IP, lock logins out for LoginLockout
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+	LoginLockout       time.Duration
+	// MFAMaxFailures wrong second factors from a user, across login
+	// challenges and step-ups, lock their second factor out for LoginLockout
+	MFAMaxFailures int
+
+	// SessionTTL is how long sessions last, the lifetime of tokens
+	SessionTTL time.Duration
+
+	// DeletionGracePeriod is how long after a deletion request an account
+	// is erased, during which the request can be canceled
+	DeletionGracePeriod time.Duration
+
+	// PasswordPolicy is checked when passwords are set
+	PasswordPolicy PasswordPolicy
+	// PasswordHashing is how passwords are hashed when set or rehashed
+	PasswordHashing PasswordHashing
 }
 
 // Service handles authentication business logic
 type Service struct {
-	repo Repository
+	repo       Repository
+	moderation *moderation.Service
+	badges     SubscriberBadges
+	notifier   Notifier
+	mailer     notifications.EmailSender
+	throttle   LoginThrottle
+	config     Config
+	emailKey   []byte
+	totpKey    []byte
+	oidc       map[string]*oidcVerifier
+
+	// dummyHash is compared against when a login has no password hash
+	dummyHashOnce sync.Once
+	dummyHash     string
+}
+
+// SubscriberBadges looks up the badge a user shows in a creator's channel
+type SubscriberBadges interface {
+	Badge(ctx context.Context, creatorID, userID int64) (*models.SubscriberBadge, error)
 }
 
 // NewService creates a new authentication service
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, moderation *moderation.Service, badges SubscriberBadges, notifier Notifier, mailer notifications.EmailSender, throttle LoginThrottle, config Config) *Service {
+	config.PasswordHashing = config.PasswordHashing.withDefaults()
+	client := &http.Client{Timeout: 10 * time.Second}
+	verifiers := make(map[string]*oidcVerifier)
+	for _, provider := range config.OIDCProviders {
+		if len(provider.ClientIDs) > 0 {
+			verifiers[provider.Name] = newOIDCVerifier(provider, client)
+		}
+	}
+
+	return &Service{
+		repo:       repo,
+		moderation: moderation,
+		badges:     badges,
+		notifier:   notifier,
+		mailer:     mailer,
+		throttle:   throttle,
+		config:     config,
+		emailKey:   emailKey(config.TokenSecret),
+		totpKey:    totpKey(config.TokenSecret),
+		oidc:       verifiers,
+	}
 }
 
-// Register creates a new user account
-func (s *Service) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
+// Register creates a new user account, remembering the client as the user's
+// first device and emailing a verification link
+func (s *Service) Register(ctx context.Context, req *models.RegisterRequest, client Client) (*models.User, error) {
 	// Check if user already exists
 	existingUser, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil && err != sql.ErrNoRows {
@@ -46,50 +222,151 @@ func (s *Service) Register(ctx context.Context, req *models.RegisterRequest) (*m
 		return nil, ErrUserExists
 	}
 
+	if err := s.config.PasswordPolicy.Check(req.Password, req.Username, req.Email); err != nil {
+		return nil, err
+	}
+
+	// Screen the display name; if it is held, the username stands in for it
+	// until it is approved
+	verdict, err := s.moderation.Check(ctx, moderation.FieldDisplayName, req.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	displayName := req.DisplayName
+	if verdict.Decision == moderation.Hold {
+		displayName = req.Username
+	}
+
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hashPassword(req.Password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, err
 	}
 
 	// Create user
 	user := &models.User{
-		Email:        req.Email,
-		Username:     req.Username,
-		PasswordHash: string(hashedPassword),
-		DisplayName:  req.DisplayName,
-		IsAdult:      req.IsAdult,
-		AdultMode:    false, // Default to false, user can enable later
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		Email:         req.Email,
+		Username:      req.Username,
+		PasswordHash:  hashedPassword,
+		DisplayName:   displayName,
+		IsAdult:       req.IsAdult,
+		AdultMode:     false, // Default to false, user can enable later
+		Role:          string(RoleUser),
+		AccountStatus: string(StatusActive),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	if err := s.repo.CreateUser(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	if verdict.Decision == moderation.Hold {
+		if err := s.moderation.Hold(ctx, moderation.FieldDisplayName, user.ID, user.ID, req.DisplayName, verdict); err != nil {
+			return nil, fmt.Errorf("failed to hold display name: %w", err)
+		}
+		user.PendingReview = []string{moderation.FieldDisplayName.Name()}
+	}
+
+	s.rememberDevice(ctx, user, client, false)
+	s.startVerification(ctx, user)
 	return user, nil
 }
 
-// Login authenticates a user with email and password
-func (s *Service) Login(ctx context.Context, req *models.LoginRequest) (*models.User, error) {
-	// Get user by email
-	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+// UpdateProfile applies changes to a user's own profile. The display name and
+// bio are screened first: rejected text fails the update, while held text is
+// queued for review and the current value kept in the meantime.
+func (s *Service) UpdateProfile(ctx context.Context, userID int64, req *models.UpdateProfileRequest) (*models.User, error) {
+	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrInvalidCredentials
+		return nil, err
+	}
+
+	if req.AdultMode != nil && *req.AdultMode && !user.IsAdult {
+		return nil, ErrNotAdult
+	}
+
+	screened := []struct {
+		field moderation.Field
+		text  *string
+		value *string
+	}{
+		{moderation.FieldDisplayName, req.DisplayName, &user.DisplayName},
+		{moderation.FieldBio, req.Bio, &user.Bio},
+	}
+	var held []string
+	for _, f := range screened {
+		if f.text == nil {
+			continue
 		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		value, isHeld, err := s.moderation.Screen(ctx, f.field, user.ID, user.ID, *f.text, *f.value)
+		if err != nil {
+			return nil, err
+		}
+		*f.value = value
+		if isHeld {
+			held = append(held, f.field.Name())
+		}
+	}
+
+	if req.AvatarURL != nil {
+		user.AvatarURL = *req.AvatarURL
+	}
+	if req.AdultMode != nil {
+		user.AdultMode = *req.AdultMode
 	}
+	user.UpdatedAt = time.Now()
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+	if err := s.repo.UpdateUser(ctx, user); err != nil {
+		return nil, err
 	}
 
+	user.PendingReview = held
 	return user, nil
 }
 
+// Login authenticates a user with email and password. Users with two-factor
+// authentication get a challenge to answer with CompleteMFALogin instead.
+// Users are alerted to logins from devices they have not used before.
+func (s *Service) Login(ctx context.Context, req *models.LoginRequest, client Client) (*models.User, *models.MFAChallenge, error) {
+	attempt, err := s.startLoginAttempt(ctx, req.Email, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Unknown emails go through the same steps as wrong passwords, so
+	// neither the response nor its timing reveals which emails are registered
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !s.checkPassword(user, req.Password) {
+		s.failLoginAttempt(ctx, attempt, user, client)
+		return nil, nil, ErrInvalidCredentials
+	}
+	s.passLoginAttempt(ctx, client)
+	s.upgradePasswordHash(ctx, user, req.Password)
+
+	// Only reveal the account state once the password is known to be correct
+	if err := checkAccountStatus(user, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	// The account's attempts are only cleared once the second factor is
+	// answered, so a known password can't buy unlimited challenges
+	if user.MFAEnabled {
+		challenge, err := s.newMFAChallenge(ctx, user, MethodPassword)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+	s.resetLoginFailures(ctx, req.Email)
+
+	s.rememberDevice(ctx, user, client, true)
+	return user, nil, nil
+}
+
 // GetUserByID retrieves a user by ID
 func (s *Service) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
 	user, err := s.repo.GetUserByID(ctx, id)
@@ -101,3 +378,42 @@ func (s *Service) GetUserByID(ctx context.Context, id int64) (*models.User, erro
 	}
 	return user, nil
 }
+
+// GetPublicProfile retrieves what viewerID may see of a user. If creatorID is
+// not zero, the profile carries the user's subscriber badge in that creator's
+// channel. Banned accounts are not found, and shadow-banned accounts are only
+// visible to themselves.
+func (s *Service) GetPublicProfile(ctx context.Context, userID, viewerID, creatorID int64) (*models.PublicProfile, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch AccountStatus(user.AccountStatus) {
+	case StatusBanned:
+		return nil, ErrUserNotFound
+	case StatusShadowBanned:
+		if viewerID != userID {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	profile := &models.PublicProfile{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		CreatedAt:   user.CreatedAt,
+	}
+
+	if creatorID != 0 && s.badges != nil {
+		badge, err := s.badges.Badge(ctx, creatorID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subscriber badge: %w", err)
+		}
+		profile.IsSubscriber = badge != nil
+		profile.SubscriberBadge = badge
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+const (
+	// sessionTouchInterval is how often a session's last-seen time is
+	// updated while it is in use
+	sessionTouchInterval = 5 * time.Minute
+	maxDeviceName        = 100
+	maxLocation          = 100
+)
+
+// StartSession records a new session for a user on the client's device
+func (s *Service) StartSession(ctx context.Context, userID int64, client Client) (*models.Session, error) {
+	name, platform := describeDevice(client)
+	session := &models.Session{
+		UserID:     userID,
+		DeviceName: name,
+		Platform:   platform,
+		IP:         client.IP,
+		Location:   clip(client.Location, maxLocation),
+		ExpiresAt:  time.Now().Add(s.config.SessionTTL),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ListSessions returns the devices a user is signed in on, marking the one
+// with the session currentID
+func (s *Service) ListSessions(ctx context.Context, userID, currentID int64) ([]*models.Session, error) {
+	sessions, err := s.repo.GetSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs a user out on one device. Its tokens stop working
+// right away.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	if err := s.repo.RevokeSession(ctx, userID, sessionID, time.Now()); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkActiveSession returns ErrSessionRevoked unless a token's session is
+// the user's and still open, and records the session being seen. Tokens
+// without a session, issued before sessions were recorded, are refused, so
+// none escape being signed out.
+func (s *Service) checkActiveSession(ctx context.Context, claims *Claims, client Client) error {
+	if claims.SessionID == 0 {
+		return ErrSessionRevoked
+	}
+
+	session, err := s.repo.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrSessionRevoked
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	now := time.Now()
+	if session.UserID != claims.UserID || session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+		return ErrSessionRevoked
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval || session.IP != client.IP {
+		if err := s.repo.TouchSession(ctx, session.ID, client.IP, clip(client.Location, maxLocation), now); err != nil {
+			logger.ErrorLogger.Printf("Failed to update session %d: %v", session.ID, err)
+		}
+	}
+	return nil
+}
+
+// describeDevice names a client's device and platform. Apps report a name;
+// for browsers one is made up from the user agent.
+func describeDevice(client Client) (string, string) {
+	ua := client.UserAgent
+	var platform string
+	switch {
+	case strings.Contains(ua, "iPhone"):
+		platform = "iOS"
+	case strings.Contains(ua, "iPad"):
+		platform = "iPadOS"
+	case strings.Contains(ua, "Android"):
+		platform = "Android"
+	case strings.Contains(ua, "Windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "CrOS"):
+		platform = "ChromeOS"
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		platform = "macOS"
+	case strings.Contains(ua, "Linux"):
+		platform = "Linux"
+	}
+
+	if name := strings.TrimSpace(client.DeviceName); name != "" {
+		return clip(name, maxDeviceName), platform
+	}
+
+	// Checked in this order since most browsers also claim to be the ones
+	// they are built on
+	var browser string
+	switch {
+	case strings.Contains(ua, "Edg/"), strings.Contains(ua, "EdgiOS/"), strings.Contains(ua, "EdgA/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"), strings.Contains(ua, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform, platform
+	case browser != "":
+		return browser, platform
+	case platform != "":
+		return platform + " device", platform
+	}
+	return "Unknown device", platform
+}
+
+// clip shortens s to at most max runes
+func clip(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
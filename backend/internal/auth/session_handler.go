@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions handles listing the devices the user is signed in on
+// @Summary List active sessions
+// @Description The session of the token making the request is marked current
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Session
+// @Router /auth/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	sessions, err := h.service.ListSessions(c.Request.Context(), c.GetInt64("user_id"), c.GetInt64("session_id"))
+	if err != nil {
+		respondSessionError(c, err, "Failed to get sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession handles signing the user out on a device
+// @Summary Revoke a session
+// @Description Tokens of the session stop working right away. Revoking the current session signs out.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /auth/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid session ID",
+		})
+		return
+	}
+
+	if err := h.service.RevokeSession(c.Request.Context(), c.GetInt64("user_id"), id); err != nil {
+		respondSessionError(c, err, "Failed to revoke session")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Session revoked",
+	})
+}
+
+// respondSessionError maps session errors to responses
+func respondSessionError(c *gin.Context, err error, message string) {
+	if errors.Is(err, ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   "internal_error",
+		Message: message,
+	})
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// sessionRepo is an in-memory Repository for users' sessions
+type sessionRepo struct {
+	Repository
+	user     *models.User
+	grants   []string
+	sessions []*models.Session
+	touches  int
+}
+
+func (r *sessionRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	return r.user, nil
+}
+
+func (r *sessionRepo) GetUserPermissions(ctx context.Context, userID int64) ([]string, error) {
+	return r.grants, nil
+}
+
+func (r *sessionRepo) CreateSession(ctx context.Context, session *models.Session) error {
+	session.ID = int64(len(r.sessions) + 1)
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = session.CreatedAt
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *sessionRepo) GetSession(ctx context.Context, id int64) (*models.Session, error) {
+	for _, session := range r.sessions {
+		if session.ID == id {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *sessionRepo) GetSessions(ctx context.Context, userID int64, now time.Time) ([]*models.Session, error) {
+	var sessions []*models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepo) TouchSession(ctx context.Context, id int64, ip, location string, now time.Time) error {
+	r.touches++
+	session := r.sessions[id-1]
+	session.IP, session.LastSeenAt = ip, now
+	return nil
+}
+
+func (r *sessionRepo) RevokeSession(ctx context.Context, userID, sessionID int64, now time.Time) error {
+	for _, session := range r.sessions {
+		if session.ID == sessionID && session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func TestSessions(t *testing.T) {
+	repo := &sessionRepo{user: &models.User{ID: 1, AccountStatus: "active"}}
+	svc := NewService(repo, nil, nil, nil, nil, nil, Config{SessionTTL: time.Hour})
+	ctx := context.Background()
+	phone := Client{IP: "203.0.113.7", UserAgent: "HALO/2.1 okhttp/4.12", DeviceName: "Ada's Pixel"}
+	laptop := Client{IP: "198.51.100.1", UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"}
+
+	first, err := svc.StartSession(ctx, 1, phone)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := svc.StartSession(ctx, 1, laptop)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sessions, err := svc.ListSessions(ctx, 1, second.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Current || !sessions[1].Current {
+		t.Errorf("Expected two sessions with the second current, got %+v", sessions)
+	}
+
+	claims := &Claims{UserID: 1, SessionID: first.ID}
+	if _, err := svc.CheckAccess(ctx, claims, phone); err != nil {
+		t.Errorf("Expected an open session to be accepted, got %v", err)
+	}
+	if repo.touches != 0 {
+		t.Errorf("Expected a session seen just now not to be updated, got %d updates", repo.touches)
+	}
+
+	// A demoted moderator's token loses the role's permissions at once,
+	// while a grant made after login applies at once
+	repo.user.Role, repo.grants = string(RoleUser), []string{string(PermPaymentsManage)}
+	stale := &Claims{UserID: 1, SessionID: first.ID, Role: string(RoleModerator), Permissions: []string{string(PermUsersModerate)}}
+	live, err := svc.CheckAccess(ctx, stale, phone)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if live.Role != string(RoleUser) || live.HasPermission(PermUsersModerate) || !live.HasPermission(PermPaymentsManage) {
+		t.Errorf("Expected the user's current role and permissions, got %s %v", live.Role, live.Permissions)
+	}
+	if live.SessionID != first.ID {
+		t.Errorf("Expected the token's session to be kept, got %d", live.SessionID)
+	}
+	if _, err := svc.CheckAccess(ctx, claims, Client{IP: "192.0.2.55"}); err != nil || repo.touches != 1 {
+		t.Errorf("Expected a new IP to be recorded, got %v and %d updates", err, repo.touches)
+	}
+
+	if err := svc.RevokeSession(ctx, 2, first.ID); err != ErrSessionNotFound {
+		t.Errorf("Expected another user's session not to be found, got %v", err)
+	}
+	if err := svc.RevokeSession(ctx, 1, first.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := svc.CheckAccess(ctx, claims, phone); err != ErrSessionRevoked {
+		t.Errorf("Expected %v, got %v", ErrSessionRevoked, err)
+	}
+	if _, err := svc.CheckAccess(ctx, &Claims{UserID: 2, SessionID: second.ID}, laptop); err != ErrSessionRevoked {
+		t.Errorf("Expected a session to work only for its user, got %v", err)
+	}
+	if err := svc.RevokeSession(ctx, 1, first.ID); err != ErrSessionNotFound {
+		t.Errorf("Expected %v, got %v", ErrSessionNotFound, err)
+	}
+	if _, err := svc.CheckAccess(ctx, &Claims{UserID: 1}, laptop); err != ErrSessionRevoked {
+		t.Errorf("Expected a token without a session to be refused, got %v", err)
+	}
+}
+
+func TestDescribeDevice(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   Client
+		device   string
+		platform string
+	}{
+		{"app", Client{UserAgent: "HALO/2.1 (iPhone; iOS 17.5)", DeviceName: " Ada's iPhone "}, "Ada's iPhone", "iOS"},
+		{"chrome on windows", Client{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"}, "Chrome on Windows", "Windows"},
+		{"edge", Client{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0"}, "Edge on Windows", "Windows"},
+		{"safari on iphone", Client{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"}, "Safari on iOS", "iOS"},
+		{"firefox on android", Client{UserAgent: "Mozilla/5.0 (Android 14; Mobile; rv:127.0) Gecko/127.0 Firefox/127.0"}, "Firefox on Android", "Android"},
+		{"unknown", Client{UserAgent: "curl/8.5.0"}, "Unknown device", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device, platform := describeDevice(tt.client)
+			if device != tt.device || platform != tt.platform {
+				t.Errorf("Expected %q on %q, got %q on %q", tt.device, tt.platform, device, platform)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/database"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// loginFailureWindow is how long a login attempt counts against an
+	// account or IP after the last one
+	loginFailureWindow = time.Hour
+	// Failed logins beyond the free ones are each followed by a delay that
+	// doubles from loginBaseDelay up to loginMaxDelay
+	loginBaseDelay = time.Second
+	loginMaxDelay  = 30 * time.Second
+	// freeAccountFailures are the failed logins to an account allowed before
+	// delays start
+	freeAccountFailures = 3
+)
+
+// ThrottledError reports that logins, or second factors, are blocked for a
+// while after too many failed attempts
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return "too many failed attempts; try again later"
+}
+
+// LoginThrottle counts login attempts and blocks further ones, under keys
+// for accounts and client IPs
+type LoginThrottle interface {
+	// Attempt counts an attempt under a key, kept for window, unless
+	// attempts under it are blocked. The block schedule lists for the count
+	// reached is applied at once, before the attempt is checked, so a burst
+	// of concurrent attempts can't all get in ahead of it. It returns the
+	// attempts counted, or how much longer attempts are blocked.
+	Attempt(ctx context.Context, key string, schedule []time.Duration, window time.Duration) (int64, time.Duration, error)
+	// Refund uncounts an attempt that turned out to succeed
+	Refund(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+// RedisLoginThrottle keeps login attempts and blocks in Redis, so they hold
+// across API instances
+type RedisLoginThrottle struct {
+	redis *database.RedisClient
+}
+
+// NewRedisLoginThrottle creates a login throttle backed by Redis
+func NewRedisLoginThrottle(redis *database.RedisClient) *RedisLoginThrottle {
+	return &RedisLoginThrottle{redis: redis}
+}
+
+// attemptScript checks a key's block, counts an attempt and sets the block
+// that follows it in one step. KEYS are the key's attempt counter and block;
+// ARGV is the window and the schedule, in milliseconds.
+var attemptScript = redis.NewScript(`
+local blocked = redis.call("PTTL", KEYS[2])
+if blocked > 0 then
+	return {0, blocked}
+end
+local count = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[1])
+local delay = tonumber(ARGV[math.min(count, #ARGV - 1) + 1])
+if delay > 0 then
+	redis.call("SET", KEYS[2], 1, "PX", delay)
+end
+return {count, 0}
+`)
+
+// Attempt counts an attempt and applies the block that follows it, unless
+// attempts are blocked
+func (t *RedisLoginThrottle) Attempt(ctx context.Context, key string, schedule []time.Duration, window time.Duration) (int64, time.Duration, error) {
+	args := []interface{}{window.Milliseconds()}
+	for _, delay := range schedule {
+		args = append(args, delay.Milliseconds())
+	}
+	result, err := attemptScript.Run(ctx, t.redis, []string{"login_failures:" + key, "login_block:" + key}, args...).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return result[0], time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Refund uncounts an attempt
+func (t *RedisLoginThrottle) Refund(ctx context.Context, key string) error {
+	return t.redis.Decr(ctx, "login_failures:"+key).Err()
+}
+
+// Reset clears a key's attempts and block
+func (t *RedisLoginThrottle) Reset(ctx context.Context, key string) error {
+	return t.redis.Del(ctx, "login_failures:"+key, "login_block:"+key).Err()
+}
+
+// loginLimits governs failed logins under one kind of key
+type loginLimits struct {
+	// free failures are allowed before delays start
+	free int64
+	// max failures lock attempts out for the lockout period
+	max     int64
+	lockout time.Duration
+}
+
+// delay returns how long attempts are blocked after a number of failures
+func (l loginLimits) delay(failures int64) time.Duration {
+	if l.max > 0 && failures >= l.max {
+		return l.lockout
+	}
+	if failures <= l.free {
+		return 0
+	}
+	delay := loginBaseDelay
+	for i := l.free + 1; i < failures && delay < loginMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > loginMaxDelay {
+		delay = loginMaxDelay
+	}
+	return delay
+}
+
+// schedule lists the block after each attempt: the nth entry follows the
+// nth attempt and the last follows any later one
+func (l loginLimits) schedule() []time.Duration {
+	var schedule []time.Duration
+	for n := int64(1); ; n++ {
+		delay := l.delay(n)
+		schedule = append(schedule, delay)
+		if l.max > 0 && n >= l.max || l.max <= 0 && n > l.free && delay == loginMaxDelay {
+			return schedule
+		}
+	}
+}
+
+func (s *Service) accountLimits() loginLimits {
+	return loginLimits{free: freeAccountFailures, max: int64(s.config.LoginMaxFailures), lockout: s.config.LoginLockout}
+}
+
+// IPs get delays once a fifth of their allowance is used, since one IP may
+// be shared by many users
+func (s *Service) ipLimits() loginLimits {
+	max := int64(s.config.LoginIPMaxFailures)
+	return loginLimits{free: max / 5, max: max, lockout: s.config.LoginLockout}
+}
+
+// accountKey is the throttle key for logins to an email. It does not depend
+// on whether the email is registered.
+func accountKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "account:" + hex.EncodeToString(sum[:])
+}
+
+func ipKey(client Client) string {
+	return "ip:" + client.IP
+}
+
+// loginAttempt is a login counted against the throttle before its
+// credentials are checked
+type loginAttempt struct {
+	email string
+	// accountCount is the attempts counted against the email
+	accountCount int64
+}
+
+// startLoginAttempt counts a login against the email and the client's IP,
+// or returns a ThrottledError while either is blocked. Throttle failures are
+// logged and let the login through.
+func (s *Service) startLoginAttempt(ctx context.Context, email string, client Client) (*loginAttempt, error) {
+	attempt := &loginAttempt{email: email}
+	if s.throttle == nil {
+		return attempt, nil
+	}
+
+	keys := []struct {
+		key    string
+		limits loginLimits
+	}{
+		{accountKey(email), s.accountLimits()},
+		{ipKey(client), s.ipLimits()},
+	}
+	for _, k := range keys {
+		count, retryAfter, err := s.throttle.Attempt(ctx, k.key, k.limits.schedule(), loginFailureWindow)
+		if err != nil {
+			logger.ErrorLogger.Printf("Failed to check login throttle: %v", err)
+			continue
+		}
+		if retryAfter > 0 {
+			return nil, &ThrottledError{RetryAfter: retryAfter}
+		}
+		if k.key == accountKey(email) {
+			attempt.accountCount = count
+		}
+	}
+	return attempt, nil
+}
+
+// failLoginAttempt tells the account's owner, if any, when a failed login
+// locks it out. The failure was already counted.
+func (s *Service) failLoginAttempt(ctx context.Context, attempt *loginAttempt, user *models.User, client Client) {
+	if user != nil && s.config.LoginMaxFailures > 0 && attempt.accountCount == int64(s.config.LoginMaxFailures) {
+		s.notifyLockout(ctx, user, client)
+	}
+}
+
+// passLoginAttempt uncounts a login whose password was right from the
+// client's IP. The IP's other failures are left to expire, so one valid
+// account cannot clear the way for guessing at others. The account's
+// attempts are kept until any second factor is answered too, see
+// resetLoginFailures.
+func (s *Service) passLoginAttempt(ctx context.Context, client Client) {
+	if s.throttle == nil {
+		return
+	}
+	if err := s.throttle.Refund(ctx, ipKey(client)); err != nil {
+		logger.ErrorLogger.Printf("Failed to refund login attempt: %v", err)
+	}
+}
+
+// resetLoginFailures clears an account's login attempts once the user has
+// signed in with every factor they need
+func (s *Service) resetLoginFailures(ctx context.Context, email string) {
+	if s.throttle == nil {
+		return
+	}
+	if err := s.throttle.Reset(ctx, accountKey(email)); err != nil {
+		logger.ErrorLogger.Printf("Failed to reset login failures: %v", err)
+	}
+}
+
+func (s *Service) mfaLimits() loginLimits {
+	return loginLimits{free: freeAccountFailures, max: int64(s.config.MFAMaxFailures), lockout: s.config.LoginLockout}
+}
+
+func mfaKey(userID int64) string {
+	return "mfa:" + strconv.FormatInt(userID, 10)
+}
+
+// startMFAAttempt counts an attempt at a user's second factor, whether at
+// a login challenge or a step-up, and returns the attempts counted, or a
+// ThrottledError while they are blocked. Throttle failures are logged and
+// let the attempt through.
+func (s *Service) startMFAAttempt(ctx context.Context, userID int64) (int64, error) {
+	if s.throttle == nil {
+		return 0, nil
+	}
+	count, retryAfter, err := s.throttle.Attempt(ctx, mfaKey(userID), s.mfaLimits().schedule(), loginFailureWindow)
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to check MFA throttle: %v", err)
+		return 0, nil
+	}
+	if retryAfter > 0 {
+		return 0, &ThrottledError{RetryAfter: retryAfter}
+	}
+	return count, nil
+}
+
+// failMFAAttempt tells a user when a wrong second factor locks it out. The
+// failure was already counted.
+func (s *Service) failMFAAttempt(ctx context.Context, user *models.User, count int64) {
+	if s.config.MFAMaxFailures > 0 && count == int64(s.config.MFAMaxFailures) {
+		s.notifyMFALockout(ctx, user)
+	}
+}
+
+// resetMFAFailures clears a user's attempts at their second factor after a
+// right one
+func (s *Service) resetMFAFailures(ctx context.Context, userID int64) {
+	if s.throttle == nil {
+		return
+	}
+	if err := s.throttle.Reset(ctx, mfaKey(userID)); err != nil {
+		logger.ErrorLogger.Printf("Failed to reset MFA failures: %v", err)
+	}
+}
+
+func (s *Service) notifyLockout(ctx context.Context, user *models.User, client Client) {
+	minutes := int(s.config.LoginLockout.Minutes())
+	err := s.notifier.Notify(ctx, user.ID, &notifications.Message{
+		Type:  notifications.TypeAccountLocked,
+		Title: "Logins to your account are paused",
+		Body: fmt.Sprintf("After %d failed login attempts, the last from IP %s, logins to your account are paused for %d minutes. If this wasn't you, change your password.",
+			s.config.LoginMaxFailures, client.IP, minutes),
+		Data: map[string]string{
+			"ip": client.IP,
+		},
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to send lockout alert to user %d: %v", user.ID, err)
+	}
+}
+
+// notifyMFALockout warns a user that their second factor is locked out
+func (s *Service) notifyMFALockout(ctx context.Context, user *models.User) {
+	minutes := int(s.config.LoginLockout.Minutes())
+	err := s.notifier.Notify(ctx, user.ID, &notifications.Message{
+		Type:  notifications.TypeAccountLocked,
+		Title: "Two-factor codes for your account are paused",
+		Body: fmt.Sprintf("After %d wrong two-factor codes, codes for your account are paused for %d minutes. Someone who tried them knew your password or was signed in; if this wasn't you, change your password.",
+			s.config.MFAMaxFailures, minutes),
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to send MFA lockout alert to user %d: %v", user.ID, err)
+	}
+}
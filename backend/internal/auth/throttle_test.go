@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memThrottle is an in-memory LoginThrottle whose blocks can be lifted by
+// the test
+type memThrottle struct {
+	failures map[string]int64
+	blocks   map[string]time.Duration
+}
+
+func newMemThrottle() *memThrottle {
+	return &memThrottle{failures: make(map[string]int64), blocks: make(map[string]time.Duration)}
+}
+
+func (t *memThrottle) Attempt(ctx context.Context, key string, schedule []time.Duration, window time.Duration) (int64, time.Duration, error) {
+	if t.blocks[key] > 0 {
+		return 0, t.blocks[key], nil
+	}
+	t.failures[key]++
+	count := t.failures[key]
+	if delay := schedule[min(count, int64(len(schedule)))-1]; delay > 0 {
+		t.blocks[key] = delay
+	}
+	return count, 0, nil
+}
+
+func (t *memThrottle) Refund(ctx context.Context, key string) error {
+	t.failures[key]--
+	return nil
+}
+
+func (t *memThrottle) Reset(ctx context.Context, key string) error {
+	delete(t.failures, key)
+	delete(t.blocks, key)
+	return nil
+}
+
+type recordingNotifier struct {
+	messages []*notifications.Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, userID int64, msg *notifications.Message) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func TestLoginThrottle(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo := &mfaRepo{user: &models.User{ID: 1, Email: "ada@example.com", PasswordHash: string(hash), AccountStatus: "active"}}
+	throttle, notifier := newMemThrottle(), &recordingNotifier{}
+	svc := NewService(repo, nil, nil, notifier, nil, throttle, Config{
+		LoginMaxFailures:   5,
+		LoginIPMaxFailures: 100,
+		LoginLockout:       15 * time.Minute,
+	})
+	ctx := context.Background()
+	client := Client{IP: "203.0.113.7"}
+
+	tests := []struct {
+		name  string
+		delay time.Duration
+	}{
+		{"first free failure", 0},
+		{"second free failure", 0},
+		{"third free failure", 0},
+		{"first delay", time.Second},
+		{"lockout", 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delete(throttle.blocks, accountKey("ada@example.com"))
+			_, _, err := svc.Login(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "wrong"}, client)
+			if err != ErrInvalidCredentials {
+				t.Fatalf("Expected %v, got %v", ErrInvalidCredentials, err)
+			}
+			if delay := throttle.blocks[accountKey("ada@example.com")]; delay != tt.delay {
+				t.Errorf("Expected a block of %v, got %v", tt.delay, delay)
+			}
+		})
+	}
+
+	if len(notifier.messages) != 1 || notifier.messages[0].Type != notifications.TypeAccountLocked {
+		t.Errorf("Expected one lockout notification, got %v", notifier.messages)
+	}
+
+	// The right password does not get through a lockout, and a differently
+	// written email is the same account
+	var throttled *ThrottledError
+	_, _, err = svc.Login(ctx, &models.LoginRequest{Email: " ADA@example.com", Password: "correct horse"}, client)
+	if !errors.As(err, &throttled) || throttled.RetryAfter != 15*time.Minute {
+		t.Fatalf("Expected a 15 minute lockout, got %v", err)
+	}
+
+	// Unknown emails are counted like known ones
+	for i := 0; i < 4; i++ {
+		svc.Login(ctx, &models.LoginRequest{Email: "nobody@example.com", Password: "wrong"}, Client{IP: "198.51.100.1"})
+	}
+	if throttle.blocks[accountKey("nobody@example.com")] != time.Second {
+		t.Errorf("Expected unknown emails to be delayed, got %v", throttle.blocks[accountKey("nobody@example.com")])
+	}
+
+	delete(throttle.blocks, accountKey("ada@example.com"))
+	if _, _, err := svc.Login(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "correct horse"}, client); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if throttle.failures[accountKey("ada@example.com")] != 0 {
+		t.Errorf("Expected a successful login to clear the account's failures")
+	}
+	if throttle.failures[ipKey(client)] != 5 {
+		t.Errorf("Expected the IP's failures to be kept, got %d", throttle.failures[ipKey(client)])
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	svc := NewService(nil, nil, nil, nil, nil, nil, Config{})
+	argonHash, err := svc.hashPassword("secret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		user     *models.User
+		password string
+		want     bool
+	}{
+		{"right password", &models.User{PasswordHash: string(hash)}, "secret", true},
+		{"wrong password", &models.User{PasswordHash: string(hash)}, "guess", false},
+		{"argon2id", &models.User{PasswordHash: argonHash}, "secret", true},
+		{"wrong argon2id password", &models.User{PasswordHash: argonHash}, "guess", false},
+		{"no user", nil, "secret", false},
+		{"no password", &models.User{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := svc.checkPassword(tt.user, tt.password); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoginLimitsSchedule(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits loginLimits
+		want   []time.Duration
+	}{
+		{"lockout", loginLimits{free: 2, max: 5, lockout: time.Hour}, []time.Duration{0, 0, time.Second, 2 * time.Second, time.Hour}},
+		{"no lockout", loginLimits{free: 1}, []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, loginMaxDelay}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.limits.schedule()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestIPKeyTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		forwarded  string
+		wantKey    string
+	}{
+		{"spoofed header without trusted proxies", nil, "203.0.113.7:51000", "198.51.100.1", "ip:203.0.113.7"},
+		{"spoofed header from untrusted peer", []string{"10.0.0.0/8"}, "203.0.113.7:51000", "198.51.100.1", "ip:203.0.113.7"},
+		{"header from trusted proxy", []string{"10.0.0.0/8"}, "10.0.0.5:51000", "198.51.100.1", "ip:198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var key string
+			router.GET("/", func(c *gin.Context) {
+				key = ipKey(ClientOf(c))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if key != tt.wantKey {
+				t.Errorf("Expected %s, got %s", tt.wantKey, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1, truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := totpCode(secret, totpStep(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected code %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	codeAt := func(d time.Duration) string {
+		code, err := totpCode(secret, totpStep(now.Add(d)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name  string
+		code  string
+		match bool
+	}{
+		{"current", codeAt(0), true},
+		{"previous period", codeAt(-totpPeriod), true},
+		{"next period", codeAt(totpPeriod), true},
+		{"too old", codeAt(-3 * totpPeriod), false},
+		{"wrong length", "12345", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := matchTOTP(secret, tt.code, now); ok != tt.match {
+				t.Errorf("Expected match %v, got %v", tt.match, ok)
+			}
+		})
+	}
+}
+
+func TestSealTOTPSecret(t *testing.T) {
+	key := totpKey("secret")
+	sealed, err := sealTOTPSecret(key, "JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(string(sealed), "JBSWY3DPEHPK3PXP") {
+		t.Errorf("Expected the secret to be encrypted")
+	}
+
+	opened, err := openTOTPSecret(key, sealed)
+	if err != nil || opened != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Expected the secret back, got %q, %v", opened, err)
+	}
+	if _, err := openTOTPSecret(totpKey("other"), sealed); err == nil {
+		t.Errorf("Expected another key to fail")
+	}
+}
+
+func TestRecentMFA(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name   string
+		claims *Claims
+		want   bool
+	}{
+		{"recent second factor", &Claims{AMR: []string{MethodPassword, MethodOTP, MethodMFA}, AuthTime: now.Add(-time.Minute).Unix()}, true},
+		{"password only", &Claims{AMR: []string{MethodPassword}, AuthTime: now.Unix()}, false},
+		{"stale second factor", &Claims{AMR: []string{MethodPassword, MethodOTP, MethodMFA}, AuthTime: now.Add(-time.Hour).Unix()}, false},
+		{"tokens from before amr", &Claims{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.RecentMFA(15*time.Minute, now); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithms passkeys may use (RFC 9053)
+const (
+	coseES256 = -7
+	coseEdDSA = -8
+	coseRS256 = -257
+)
+
+// passkeyAlgorithms lists the algorithms offered to authenticators, in order
+// of preference
+var passkeyAlgorithms = []int{coseES256, coseEdDSA, coseRS256}
+
+// Authenticator data flags (WebAuthn §6.1)
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+// minRSABits is the smallest RSA key accepted from a passkey or identity
+// provider
+const minRSABits = 2048
+
+// clientData is the part of clientDataJSON the relying party checks
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// authenticatorData is the data an authenticator signs. The credential
+// fields are only set when a credential is created.
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    crypto.PublicKey
+}
+
+// parseClientData decodes clientDataJSON and checks it was made by a
+// ceremony of the given type on one of the allowed origins
+func parseClientData(raw []byte, ceremony string, origins []string) (*clientData, error) {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("invalid client data: %w", err)
+	}
+	if data.Type != ceremony {
+		return nil, fmt.Errorf("client data is for %q, not %q", data.Type, ceremony)
+	}
+	if data.CrossOrigin {
+		return nil, errors.New("cross-origin ceremonies are not allowed")
+	}
+	for _, origin := range origins {
+		if data.Origin == origin {
+			return &data, nil
+		}
+	}
+	return nil, fmt.Errorf("origin %q is not allowed", data.Origin)
+}
+
+// parseAttestationObject decodes an attestationObject and returns its
+// authenticator data, which must carry the new credential. The attestation
+// statement is not verified: passkeys are requested with attestation
+// "none", so the authenticator's make is not vouched for.
+func parseAttestationObject(raw []byte) (*authenticatorData, error) {
+	decoded, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+	object, ok := decoded.(map[interface{}]interface{})
+	if !ok || len(rest) != 0 {
+		return nil, errors.New("invalid attestation object")
+	}
+	authData, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object has no authenticator data")
+	}
+
+	data, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if data.credentialID == nil {
+		return nil, errors.New("authenticator data has no credential")
+	}
+	return data, nil
+}
+
+// parseAuthenticatorData decodes authenticator data (WebAuthn §6.1)
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+	data := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	if data.flags&flagAttestedData == 0 {
+		return data, nil
+	}
+
+	rest := raw[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data is too short")
+	}
+	data.aaguid = rest[:16]
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+		return nil, errors.New("invalid credential ID")
+	}
+	data.credentialID = rest[:idLength]
+
+	key, _, err := decodeCBOR(rest[idLength:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %w", err)
+	}
+	if data.publicKey, err = parseCOSEKey(key); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// parseCOSEKey converts a decoded COSE key (RFC 9053) for one of the
+// passkeyAlgorithms to a public key
+func parseCOSEKey(decoded interface{}) (crypto.PublicKey, error) {
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("credential public key is not a COSE key")
+	}
+	param := func(label int64) []byte {
+		value, _ := key[label].([]byte)
+		return value
+	}
+	kty, _ := key[int64(1)].(int64)
+	alg, _ := key[int64(3)].(int64)
+	crv, _ := key[int64(-1)].(int64)
+
+	switch {
+	case alg == coseES256 && kty == 2 && crv == 1:
+		return newP256Key(param(-2), param(-3))
+	case alg == coseEdDSA && kty == 1 && crv == 6:
+		x := param(-2)
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	case alg == coseRS256 && kty == 3:
+		return newRSAKey(param(-1), param(-2))
+	}
+	return nil, fmt.Errorf("unsupported credential algorithm %d", alg)
+}
+
+// newP256Key returns the P-256 public key with the given coordinates,
+// checking the point is on the curve
+func newP256Key(x, y []byte) (*ecdsa.PublicKey, error) {
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid P-256 key")
+	}
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid P-256 key: %w", err)
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+// newRSAKey returns the RSA public key with the given big-endian modulus
+// and exponent
+func newRSAKey(modulus, exponent []byte) (*rsa.PublicKey, error) {
+	n, e := new(big.Int).SetBytes(modulus), new(big.Int).SetBytes(exponent)
+	if n.BitLen() < minRSABits || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA key")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// checkAuthenticatorData checks authenticator data was made for the relying
+// party, with the user present and verified
+func checkAuthenticatorData(data *authenticatorData, rpID string) error {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(data.rpIDHash, rpIDHash[:]) {
+		return errors.New("authenticator data is for another relying party")
+	}
+	if data.flags&flagUserPresent == 0 {
+		return errors.New("user was not present")
+	}
+	if data.flags&flagUserVerified == 0 {
+		return errors.New("user was not verified")
+	}
+	return nil
+}
+
+// verifyAssertion checks a passkey's signature over authenticator data and
+// the hash of clientDataJSON. publicKey is in PKIX form.
+func verifyAssertion(publicKey, authData, clientDataJSON, signature []byte) error {
+	key, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid stored public key: %w", err)
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(key, digest[:], signature) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(key, signed, signature) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+	default:
+		return errors.New("unsupported stored public key")
+	}
+	return errors.New("invalid signature")
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -100,6 +101,15 @@ func (rc *RedisClient) GetMultipleEngagements(ctx context.Context, videoID int64
 	return result, nil
 }
 
+// PublishEvent publishes a JSON-encoded event on a pub/sub channel
+func (rc *RedisClient) PublishEvent(ctx context.Context, channel string, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return rc.Publish(ctx, channel, payload).Err()
+}
+
 // HealthCheck checks if Redis is healthy
 func (rc *RedisClient) HealthCheck(ctx context.Context) error {
 	return rc.Ping(ctx).Err()
@@ -0,0 +1,92 @@
+package earnings
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles earnings HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new earnings handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetEarnings handles getting the current creator's earnings report
+// @Summary Get my earnings
+// @Description Revenue from gifts, subscriptions and tickets per day, week or month, with the creator's share, platform fees and current balances. Pass format=csv to download the buckets as CSV.
+// @Tags creator
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param period query string false "day, week or month" default(day)
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Param format query string false "json or csv" default(json)
+// @Success 200 {object} models.EarningsReport
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /creator/earnings [get]
+func (h *Handler) GetEarnings(c *gin.Context) {
+	report, err := h.service.GetReport(
+		c.Request.Context(),
+		c.GetInt64("user_id"),
+		c.DefaultQuery("period", PeriodDay),
+		c.Query("from"),
+		c.Query("to"),
+	)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPeriod) || errors.Is(err, ErrInvalidRange) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get earnings",
+		})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeCSV(c, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// writeCSV streams a report's buckets as a CSV attachment
+func writeCSV(c *gin.Context, report *models.EarningsReport) {
+	filename := fmt.Sprintf("earnings-%s-%s-%s.csv", report.Period, report.From, report.To)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"period_start", "source", "sales", "gross_coins", "gross_cents", "creator_cents", "platform_fee_cents", "creator_share_percent"})
+	for _, b := range report.Buckets {
+		w.Write([]string{
+			b.Start,
+			b.Source,
+			strconv.FormatInt(b.Sales, 10),
+			strconv.FormatInt(b.GrossCoins, 10),
+			strconv.FormatInt(b.GrossCents, 10),
+			strconv.FormatInt(b.CreatorCents, 10),
+			strconv.FormatInt(b.PlatformFeeCents, 10),
+			strconv.FormatFloat(b.CreatorSharePercent, 'f', 1, 64),
+		})
+	}
+	w.Flush()
+}
@@ -0,0 +1,59 @@
+package earnings
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetBuckets retrieves a creator's rollups for the period whose buckets start
+// between from and to, oldest first
+func (r *PostgresRepository) GetBuckets(ctx context.Context, creatorID int64, period string, from, to time.Time) ([]*models.EarningsBucket, error) {
+	query := `
+		SELECT bucket_start, source, sales, gross_coins, gross_cents, creator_cents, platform_fee_cents
+		FROM revenue_rollups
+		WHERE creator_id = $1 AND granularity = $2 AND bucket_start BETWEEN $3::date AND $4::date
+		ORDER BY bucket_start, source
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, creatorID, period, from.Format(DateLayout), to.Format(DateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]*models.EarningsBucket, 0)
+	for rows.Next() {
+		bucket := &models.EarningsBucket{}
+		var start time.Time
+		err := rows.Scan(
+			&start,
+			&bucket.Source,
+			&bucket.Sales,
+			&bucket.GrossCoins,
+			&bucket.GrossCents,
+			&bucket.CreatorCents,
+			&bucket.PlatformFeeCents,
+		)
+		if err != nil {
+			return nil, err
+		}
+		bucket.Start = start.Format(DateLayout)
+		bucket.CreatorSharePercent = SharePercent(bucket.CreatorCents, bucket.GrossCents)
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
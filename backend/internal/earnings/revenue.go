@@ -0,0 +1,96 @@
+package earnings
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// Revenue sources
+const (
+	SourceGift         = "gift"
+	SourceSubscription = "subscription"
+	SourceTicket       = "ticket"
+)
+
+// NewEvent builds the revenue event for coins spent with a creator.
+// coinValueCentsPer100 is what 100 coins are worth; whatever the creator does
+// not earn of that value is the platform's fee.
+func NewEvent(creatorID int64, source, ref string, coins, creatorCents, coinValueCentsPer100 int64) *models.RevenueEvent {
+	gross := coins * coinValueCentsPer100 / 100
+	return &models.RevenueEvent{
+		CreatorID:        creatorID,
+		Source:           source,
+		SourceRef:        ref,
+		GrossCoins:       coins,
+		GrossCents:       gross,
+		CreatorCents:     creatorCents,
+		PlatformFeeCents: gross - creatorCents,
+	}
+}
+
+// rollup adds the event returned by the CTE "e" to the day, week and month
+// rollups. Reversals subtract a sale.
+const rollup = `
+	INSERT INTO revenue_rollups (creator_id, granularity, bucket_start, source, sales, gross_coins, gross_cents, creator_cents, platform_fee_cents)
+	SELECT e.creator_id, g.granularity, date_trunc(g.granularity, e.occurred_at)::date, e.source,
+		SIGN(e.gross_coins), e.gross_coins, e.gross_cents, e.creator_cents, e.platform_fee_cents
+	FROM e CROSS JOIN unnest(ARRAY['day', 'week', 'month']) AS g(granularity)
+	ON CONFLICT (creator_id, granularity, bucket_start, source) DO UPDATE SET
+		sales = revenue_rollups.sales + EXCLUDED.sales,
+		gross_coins = revenue_rollups.gross_coins + EXCLUDED.gross_coins,
+		gross_cents = revenue_rollups.gross_cents + EXCLUDED.gross_cents,
+		creator_cents = revenue_rollups.creator_cents + EXCLUDED.creator_cents,
+		platform_fee_cents = revenue_rollups.platform_fee_cents + EXCLUDED.platform_fee_cents
+`
+
+// Record appends a revenue event within tx and adds it to the rollups.
+// Recording an event again with the same source and ref has no effect.
+// Monetized actions call this in the transaction that moves the money.
+func Record(ctx context.Context, tx *sql.Tx, event *models.RevenueEvent) error {
+	query := `
+		WITH e AS (
+			INSERT INTO revenue_events (creator_id, source, source_ref, gross_coins, gross_cents, creator_cents, platform_fee_cents)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (source, source_ref) DO NOTHING
+			RETURNING *
+		)` + rollup
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		event.CreatorID,
+		event.Source,
+		event.SourceRef,
+		event.GrossCoins,
+		event.GrossCents,
+		event.CreatorCents,
+		event.PlatformFeeCents,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record revenue event: %w", err)
+	}
+	return nil
+}
+
+// Reverse records a negative copy of the event with the given source and ref
+// under reversalRef, within tx. Reversing again, or reversing an event that
+// was never recorded, has no effect.
+func Reverse(ctx context.Context, tx *sql.Tx, source, ref, reversalRef string) error {
+	query := `
+		WITH e AS (
+			INSERT INTO revenue_events (creator_id, source, source_ref, gross_coins, gross_cents, creator_cents, platform_fee_cents)
+			SELECT creator_id, source, $3, -gross_coins, -gross_cents, -creator_cents, -platform_fee_cents
+			FROM revenue_events
+			WHERE source = $1 AND source_ref = $2
+			ON CONFLICT (source, source_ref) DO NOTHING
+			RETURNING *
+		)` + rollup
+
+	if _, err := tx.ExecContext(ctx, query, source, ref, reversalRef); err != nil {
+		return fmt.Errorf("failed to reverse revenue event: %w", err)
+	}
+	return nil
+}
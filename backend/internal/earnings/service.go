@@ -0,0 +1,195 @@
+package earnings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+)
+
+// Report periods, matching the rollup granularities
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// DateLayout is the format of report dates
+const DateLayout = "2006-01-02"
+
+// maxBuckets bounds how many periods one report may span
+const maxBuckets = 366
+
+var (
+	ErrInvalidPeriod = errors.New("period must be day, week or month")
+	ErrInvalidRange  = errors.New("invalid date range")
+)
+
+// Repository defines the interface for revenue data access
+type Repository interface {
+	GetBuckets(ctx context.Context, creatorID int64, period string, from, to time.Time) ([]*models.EarningsBucket, error)
+}
+
+// Service reports creator earnings
+type Service struct {
+	repo   Repository
+	wallet *wallet.Service
+}
+
+// NewService creates a new earnings service
+func NewService(repo Repository, wallet *wallet.Service) *Service {
+	return &Service{
+		repo:   repo,
+		wallet: wallet,
+	}
+}
+
+// GetReport aggregates a creator's revenue per period between from and to
+// (inclusive dates; empty for the default window), along with their current
+// earnings balances
+func (s *Service) GetReport(ctx context.Context, creatorID int64, period, from, to string) (*models.EarningsReport, error) {
+	start, end, err := Window(period, from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.repo.GetBuckets(ctx, creatorID, period, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue: %w", err)
+	}
+
+	pending, err := s.wallet.Account(ctx, creatorID, wallet.AccountEarningsPending, wallet.CurrencyUSD)
+	if err != nil {
+		return nil, err
+	}
+	available, err := s.wallet.Account(ctx, creatorID, wallet.AccountEarningsAvailable, wallet.CurrencyUSD)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, bySource := Summarize(buckets)
+	return &models.EarningsReport{
+		Period: period,
+		From:   start.Format(DateLayout),
+		To:     end.Format(DateLayout),
+		Balances: models.EarningsBalances{
+			Currency:       wallet.CurrencyUSD,
+			PendingCents:   pending.Balance,
+			AvailableCents: available.Balance,
+		},
+		Totals:   totals,
+		BySource: bySource,
+		Buckets:  buckets,
+	}, nil
+}
+
+// Window resolves a report's date range. from is moved back to the start of
+// its period. Without dates, reports cover the 30 days, 12 weeks or 12
+// months up to now.
+func Window(period, from, to string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	end := today
+	if to != "" {
+		t, err := time.Parse(DateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		end = t
+	}
+
+	var start time.Time
+	switch period {
+	case PeriodDay:
+		start = end.AddDate(0, 0, -29)
+	case PeriodWeek:
+		start = end.AddDate(0, 0, -7*11)
+	case PeriodMonth:
+		start = end.AddDate(0, -11, 0)
+	default:
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+	if from != "" {
+		t, err := time.Parse(DateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidRange
+		}
+		start = t
+	}
+	start = truncate(start, period)
+
+	if end.Before(start) || buckets(start, end, period) > maxBuckets {
+		return time.Time{}, time.Time{}, ErrInvalidRange
+	}
+	return start, end, nil
+}
+
+// Summarize totals buckets overall and per source, in the order sources
+// first appear
+func Summarize(buckets []*models.EarningsBucket) (models.EarningsAmounts, []*models.EarningsSourceTotal) {
+	var totals models.EarningsAmounts
+	bySource := make([]*models.EarningsSourceTotal, 0)
+	index := make(map[string]*models.EarningsSourceTotal)
+
+	for _, bucket := range buckets {
+		source, ok := index[bucket.Source]
+		if !ok {
+			source = &models.EarningsSourceTotal{Source: bucket.Source}
+			index[bucket.Source] = source
+			bySource = append(bySource, source)
+		}
+		add(&source.EarningsAmounts, &bucket.EarningsAmounts)
+		add(&totals, &bucket.EarningsAmounts)
+	}
+
+	totals.CreatorSharePercent = SharePercent(totals.CreatorCents, totals.GrossCents)
+	for _, source := range bySource {
+		source.CreatorSharePercent = SharePercent(source.CreatorCents, source.GrossCents)
+	}
+	return totals, bySource
+}
+
+// SharePercent returns part as a percentage of whole, to one decimal place
+func SharePercent(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(whole)) / 10
+}
+
+func add(sum, amounts *models.EarningsAmounts) {
+	sum.Sales += amounts.Sales
+	sum.GrossCoins += amounts.GrossCoins
+	sum.GrossCents += amounts.GrossCents
+	sum.CreatorCents += amounts.CreatorCents
+	sum.PlatformFeeCents += amounts.PlatformFeeCents
+}
+
+// truncate returns the start of the period containing t; weeks start on
+// Monday, as in Postgres
+func truncate(t time.Time, period string) time.Time {
+	switch period {
+	case PeriodWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset)
+	case PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// buckets counts the periods from start to end
+func buckets(start, end time.Time, period string) int {
+	days := int(end.Sub(start).Hours()/24) + 1
+	switch period {
+	case PeriodWeek:
+		return (days + 6) / 7
+	case PeriodMonth:
+		return (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1
+	}
+	return days
+}
@@ -0,0 +1,77 @@
+package earnings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+func TestWindow(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2026, 3, 18, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		period    string
+		from      string
+		to        string
+		wantStart string
+		wantEnd   string
+		wantErr   error
+	}{
+		{"default days", PeriodDay, "", "", "2026-02-17", "2026-03-18", nil},
+		{"default weeks start on monday", PeriodWeek, "", "", "2025-12-29", "2026-03-18", nil},
+		{"default months", PeriodMonth, "", "", "2025-04-01", "2026-03-18", nil},
+		{"from truncated to month", PeriodMonth, "2026-01-15", "2026-02-10", "2026-01-01", "2026-02-10", nil},
+		{"invalid period", "year", "", "", "", "", ErrInvalidPeriod},
+		{"invalid date", PeriodDay, "03/01/2026", "", "", "", ErrInvalidRange},
+		{"end before start", PeriodDay, "2026-03-10", "2026-03-01", "", "", ErrInvalidRange},
+		{"too many days", PeriodDay, "2024-01-01", "2026-01-01", "", "", ErrInvalidRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := Window(tt.period, tt.from, tt.to, now)
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if got := start.Format(DateLayout); got != tt.wantStart {
+				t.Errorf("Expected start %s, got %s", tt.wantStart, got)
+			}
+			if got := end.Format(DateLayout); got != tt.wantEnd {
+				t.Errorf("Expected end %s, got %s", tt.wantEnd, got)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	buckets := []*models.EarningsBucket{
+		{Start: "2026-03-01", Source: SourceGift, EarningsAmounts: models.EarningsAmounts{Sales: 2, GrossCoins: 300, GrossCents: 300, CreatorCents: 150, PlatformFeeCents: 150}},
+		{Start: "2026-03-01", Source: SourceTicket, EarningsAmounts: models.EarningsAmounts{Sales: 1, GrossCoins: 100, GrossCents: 100, CreatorCents: 70, PlatformFeeCents: 30}},
+		{Start: "2026-03-02", Source: SourceGift, EarningsAmounts: models.EarningsAmounts{Sales: 1, GrossCoins: 200, GrossCents: 200, CreatorCents: 100, PlatformFeeCents: 100}},
+	}
+
+	totals, bySource := Summarize(buckets)
+
+	if totals.Sales != 4 || totals.GrossCents != 600 || totals.CreatorCents != 320 || totals.PlatformFeeCents != 280 {
+		t.Errorf("Unexpected totals %+v", totals)
+	}
+	if totals.CreatorSharePercent != 53.3 {
+		t.Errorf("Expected creator share 53.3, got %v", totals.CreatorSharePercent)
+	}
+
+	if len(bySource) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(bySource))
+	}
+	if bySource[0].Source != SourceGift || bySource[0].GrossCents != 500 || bySource[0].CreatorSharePercent != 50 {
+		t.Errorf("Unexpected gift total %+v", bySource[0])
+	}
+	if bySource[1].Source != SourceTicket || bySource[1].CreatorSharePercent != 70 {
+		t.Errorf("Unexpected ticket total %+v", bySource[1])
+	}
+}
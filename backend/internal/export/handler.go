@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles data export HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new export handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ExportData handles downloading a copy of the user's data
+// @Summary Export your data
+// @Description A ZIP archive with one JSON file each for the profile, videos, gifts sent and received, subscriptions, tickets, stream reminders and coin purchases
+// @Tags account
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 401 {object} models.ErrorResponse
+// @Router /me/export [post]
+func (h *Handler) ExportData(c *gin.Context) {
+	userID := c.GetInt64("user_id")
+
+	// Assemble the archive before responding, so a failure can still be
+	// reported as an error rather than a truncated download
+	var buf bytes.Buffer
+	if err := h.service.Export(c.Request.Context(), userID, &buf); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to export data",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("halo-data-%d-%s.zip", userID, time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// sectionQueries select a user's records in each section, by user ID. They
+// name their columns, so secrets such as password hashes never leave.
+var sectionQueries = map[string]string{
+	SectionProfile: `
+		SELECT id, email, username, display_name, bio, avatar_url, is_adult, adult_mode, role,
+		       email_verified_at, created_at, updated_at
+		FROM users WHERE id = $1`,
+	SectionVideos: `
+		SELECT id, title, description, thumbnail_url, status, scheduled_at, is_adult_content, subscribers_only,
+		       ticket_price_coins, view_count, canceled_at, taken_down_at, created_at, updated_at
+		FROM videos WHERE user_id = $1 ORDER BY created_at`,
+	SectionGiftsSent: `
+		SELECT t.id, t.video_id, g.name AS gift, t.recipient_id, t.quantity, t.coins, t.created_at
+		FROM gift_transactions t JOIN gifts g ON g.id = t.gift_id
+		WHERE t.sender_id = $1 ORDER BY t.created_at`,
+	SectionGiftsReceived: `
+		SELECT t.id, t.video_id, g.name AS gift, t.sender_id, t.quantity, t.coins, t.earnings_cents, t.created_at
+		FROM gift_transactions t JOIN gifts g ON g.id = t.gift_id
+		WHERE t.recipient_id = $1 ORDER BY t.created_at`,
+	SectionSubscriptions: `
+		SELECT s.id, s.creator_id, t.name AS tier, s.status, s.periods, s.current_period_start,
+		       s.current_period_end, s.canceled_at, s.created_at
+		FROM subscriptions s JOIN subscription_tiers t ON t.id = s.tier_id
+		WHERE s.subscriber_id = $1 ORDER BY s.created_at`,
+	SectionTickets: `
+		SELECT id, video_id, price_coins, status, created_at, refunded_at
+		FROM event_tickets WHERE user_id = $1 ORDER BY created_at`,
+	SectionStreamReminders: `
+		SELECT video_id, created_at
+		FROM stream_reminders WHERE user_id = $1 ORDER BY created_at`,
+	SectionCoinPurchases: `
+		SELECT id, store, product_id, coins, price_cents, currency, status, reversed_coins, purchased_at, reversed_at
+		FROM coin_purchases WHERE user_id = $1 ORDER BY purchased_at`,
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetRecords retrieves a user's records in a section as rows keyed by
+// column name
+func (r *PostgresRepository) GetRecords(ctx context.Context, section string, userID int64) ([]map[string]interface{}, error) {
+	query, ok := sectionQueries[section]
+	if !ok {
+		return nil, fmt.Errorf("unknown section %q", section)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		record := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			// Text can come back as bytes, which JSON would encode as base64
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			record[column] = values[i]
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,79 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Sections of an export, each written to a JSON file of the same name
+const (
+	SectionProfile         = "profile"
+	SectionVideos          = "videos"
+	SectionGiftsSent       = "gifts_sent"
+	SectionGiftsReceived   = "gifts_received"
+	SectionSubscriptions   = "subscriptions"
+	SectionTickets         = "tickets"
+	SectionStreamReminders = "stream_reminders"
+	SectionCoinPurchases   = "coin_purchases"
+)
+
+// sections lists the sections in the order they are written
+var sections = []string{
+	SectionProfile,
+	SectionVideos,
+	SectionGiftsSent,
+	SectionGiftsReceived,
+	SectionSubscriptions,
+	SectionTickets,
+	SectionStreamReminders,
+	SectionCoinPurchases,
+}
+
+// Repository defines the interface for reading a user's data
+type Repository interface {
+	GetRecords(ctx context.Context, section string, userID int64) ([]map[string]interface{}, error)
+}
+
+// Service assembles copies of the data kept about users
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new export service
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Export writes a ZIP archive of a user's profile, videos and engagement
+// history to w, one JSON file per section
+func (s *Service) Export(ctx context.Context, userID int64, w io.Writer) error {
+	archive := zip.NewWriter(w)
+	now := time.Now()
+
+	for _, section := range sections {
+		records, err := s.repo.GetRecords(ctx, section, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", section, err)
+		}
+
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     section + ".json",
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", section, err)
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			return fmt.Errorf("failed to write %s: %w", section, err)
+		}
+	}
+
+	return archive.Close()
+}
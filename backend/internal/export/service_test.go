@@ -0,0 +1,86 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// fakeRepo returns canned records per section
+type fakeRepo struct {
+	records map[string][]map[string]interface{}
+	err     error
+}
+
+func (r *fakeRepo) GetRecords(ctx context.Context, section string, userID int64) ([]map[string]interface{}, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[section]
+	if !ok {
+		return []map[string]interface{}{}, nil
+	}
+	return records, nil
+}
+
+func TestExport(t *testing.T) {
+	repo := &fakeRepo{records: map[string][]map[string]interface{}{
+		SectionProfile: {{"id": 1, "username": "ada"}},
+		SectionVideos:  {{"id": 7, "title": "First stream"}, {"id": 8, "title": "Second stream"}},
+	}}
+	svc := NewService(repo)
+
+	var buf bytes.Buffer
+	if err := svc.Export(context.Background(), 1, &buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected a valid ZIP, got %v", err)
+	}
+	if len(archive.File) != len(sections) {
+		t.Fatalf("Expected %d files, got %d", len(sections), len(archive.File))
+	}
+
+	tests := []struct {
+		file  string
+		count int
+	}{
+		{"profile.json", 1},
+		{"videos.json", 2},
+		{"gifts_sent.json", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file, err := archive.Open(tt.file)
+			if err != nil {
+				t.Fatalf("Expected %s in the archive, got %v", tt.file, err)
+			}
+			defer file.Close()
+
+			var records []map[string]interface{}
+			if err := json.NewDecoder(file).Decode(&records); err != nil {
+				t.Fatalf("Expected a JSON array, got %v", err)
+			}
+			if len(records) != tt.count {
+				t.Errorf("Expected %d records, got %d", tt.count, len(records))
+			}
+		})
+	}
+
+	for _, section := range sections {
+		if _, ok := sectionQueries[section]; !ok {
+			t.Errorf("Expected a query for section %s", section)
+		}
+	}
+
+	repo.err = errors.New("connection refused")
+	if err := svc.Export(context.Background(), 1, &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected an error when records cannot be read")
+	}
+}
@@ -0,0 +1,159 @@
+package gifts
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles gift HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new gift handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetGifts handles getting the gift catalog
+// @Summary Get the gift catalog
+// @Tags gifts
+// @Produce json
+// @Success 200 {array} models.Gift
+// @Router /gifts [get]
+func (h *Handler) GetGifts(c *gin.Context) {
+	gifts, err := h.service.GetGifts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get gifts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gifts)
+}
+
+// SendGift handles sending a gift during a live stream
+// @Summary Send a gift
+// @Description Send the same Idempotency-Key header when retrying so the gift is only paid for once
+// @Tags gifts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Param request body models.SendGiftRequest true "Gift"
+// @Success 201 {object} models.GiftTransaction
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 402 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /videos/{id}/gifts [post]
+func (h *Handler) SendGift(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SendGiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	gift, err := h.service.SendGift(
+		c.Request.Context(),
+		videoID,
+		c.GetInt64("user_id"),
+		c.GetString("user_username"),
+		&req,
+		c.GetHeader("Idempotency-Key"),
+	)
+	if err != nil {
+		respondError(c, err, "Failed to send gift")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gift)
+}
+
+// GetLeaderboard handles getting a stream's top gifters
+// @Summary Get a stream's gift leaderboard
+// @Tags gifts
+// @Produce json
+// @Param id path int true "Video ID"
+// @Param limit query int false "Limit" default(10)
+// @Success 200 {array} models.GiftLeaderboardEntry
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /videos/{id}/gifts/leaderboard [get]
+func (h *Handler) GetLeaderboard(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	entries, err := h.service.GetLeaderboard(c.Request.Context(), videoID, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get leaderboard")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func parseVideoID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid video ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps gift service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrVideoNotFound), errors.Is(err, ErrGiftNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrStreamNotLive):
+		status, code = http.StatusConflict, "not_live"
+	case errors.Is(err, ErrSelfGift):
+		status, code = http.StatusBadRequest, "self_gift"
+	case errors.Is(err, wallet.ErrInsufficientFunds):
+		status, code = http.StatusPaymentRequired, "insufficient_coins"
+	case errors.Is(err, wallet.ErrIdempotencyConflict):
+		status, code = http.StatusConflict, "idempotency_conflict"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
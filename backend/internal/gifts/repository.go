@@ -0,0 +1,277 @@
+package gifts
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/earnings"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+)
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetGifts retrieves the active gift catalog
+func (r *PostgresRepository) GetGifts(ctx context.Context) ([]*models.Gift, error) {
+	query := `
+		SELECT id, code, name, coin_price, icon_url, sort_order
+		FROM gifts
+		WHERE active = TRUE
+		ORDER BY sort_order, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gifts := make([]*models.Gift, 0)
+	for rows.Next() {
+		gift := &models.Gift{}
+		if err := rows.Scan(&gift.ID, &gift.Code, &gift.Name, &gift.CoinPrice, &gift.IconURL, &gift.SortOrder); err != nil {
+			return nil, err
+		}
+		gifts = append(gifts, gift)
+	}
+
+	return gifts, rows.Err()
+}
+
+// GetGift retrieves an active gift by ID
+func (r *PostgresRepository) GetGift(ctx context.Context, id int64) (*models.Gift, error) {
+	query := `
+		SELECT id, code, name, coin_price, icon_url, sort_order
+		FROM gifts
+		WHERE id = $1 AND active = TRUE
+	`
+
+	gift := &models.Gift{}
+	err := r.db.QueryRowContext(ctx, query, id).
+		Scan(&gift.ID, &gift.Code, &gift.Name, &gift.CoinPrice, &gift.IconURL, &gift.SortOrder)
+	if err != nil {
+		return nil, err
+	}
+	return gift, nil
+}
+
+// GetStream retrieves the creator of a video and whether it is live
+func (r *PostgresRepository) GetStream(ctx context.Context, videoID int64) (int64, bool, error) {
+	query := `SELECT user_id, is_live AND taken_down_at IS NULL FROM videos WHERE id = $1`
+
+	var creatorID int64
+	var isLive bool
+	err := r.db.QueryRowContext(ctx, query, videoID).Scan(&creatorID, &isLive)
+	return creatorID, isLive, err
+}
+
+// CreateGiftTransaction posts a gift's journal entry and records the gift and
+// its revenue event in one transaction, so coins never leave a viewer without
+// the creator being credited. If the entry was already posted, gift is filled
+// from the existing record and replayed is true.
+func (r *PostgresRepository) CreateGiftTransaction(ctx context.Context, gift *models.GiftTransaction, entry *models.JournalEntry, revenue *models.RevenueEvent) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	replayed, err := wallet.Post(ctx, tx, entry)
+	if err != nil {
+		return false, err
+	}
+
+	if replayed {
+		query := `
+			SELECT id, video_id, gift_id, sender_id, recipient_id, quantity, coins, earnings_cents, entry_id, created_at
+			FROM gift_transactions
+			WHERE entry_id = $1
+		`
+		err := tx.QueryRowContext(ctx, query, entry.ID).Scan(
+			&gift.ID,
+			&gift.VideoID,
+			&gift.GiftID,
+			&gift.SenderID,
+			&gift.RecipientID,
+			&gift.Quantity,
+			&gift.Coins,
+			&gift.EarningsCents,
+			&gift.EntryID,
+			&gift.CreatedAt,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to get replayed gift: %w", err)
+		}
+		return true, nil
+	}
+
+	gift.EntryID = entry.ID
+	query := `
+		INSERT INTO gift_transactions (video_id, gift_id, sender_id, recipient_id, quantity, coins, earnings_cents, entry_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		gift.VideoID,
+		gift.GiftID,
+		gift.SenderID,
+		gift.RecipientID,
+		gift.Quantity,
+		gift.Coins,
+		gift.EarningsCents,
+		gift.EntryID,
+	).Scan(&gift.ID, &gift.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert gift: %w", err)
+	}
+
+	if err := earnings.Record(ctx, tx, revenue); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit gift: %w", err)
+	}
+
+	return false, nil
+}
+
+// GetUsernames retrieves usernames for a set of users
+func (r *PostgresRepository) GetUsernames(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, username FROM users WHERE id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usernames := make(map[int64]string, len(userIDs))
+	for rows.Next() {
+		var id int64
+		var username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		usernames[id] = username
+	}
+
+	return usernames, rows.Err()
+}
+
+// GetLeaderboard retrieves a stream's persisted final standings, or standings
+// computed from its gifts if they have not been persisted yet
+func (r *PostgresRepository) GetLeaderboard(ctx context.Context, videoID int64, limit int) ([]*models.GiftLeaderboardEntry, error) {
+	query := `
+		SELECT l.rank, l.user_id, u.username, l.coins
+		FROM gift_leaderboards l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.video_id = $1
+		ORDER BY l.rank
+		LIMIT $2
+	`
+
+	entries, err := r.queryLeaderboard(ctx, query, videoID, limit)
+	if err != nil || len(entries) > 0 {
+		return entries, err
+	}
+
+	query = `
+		SELECT RANK() OVER (ORDER BY SUM(g.coins) DESC)::INTEGER, g.sender_id, u.username, SUM(g.coins)::BIGINT
+		FROM gift_transactions g
+		JOIN users u ON u.id = g.sender_id
+		WHERE g.video_id = $1
+		GROUP BY g.sender_id, u.username
+		ORDER BY 1, g.sender_id
+		LIMIT $2
+	`
+	return r.queryLeaderboard(ctx, query, videoID, limit)
+}
+
+func (r *PostgresRepository) queryLeaderboard(ctx context.Context, query string, args ...interface{}) ([]*models.GiftLeaderboardEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*models.GiftLeaderboardEntry, 0)
+	for rows.Next() {
+		entry := &models.GiftLeaderboardEntry{}
+		if err := rows.Scan(&entry.Rank, &entry.UserID, &entry.Username, &entry.Coins); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetEndedStreamsToPersist retrieves streams that are no longer live and have
+// received gifts since their leaderboard was last persisted
+func (r *PostgresRepository) GetEndedStreamsToPersist(ctx context.Context) ([]int64, error) {
+	query := `
+		SELECT g.video_id
+		FROM gift_transactions g
+		JOIN videos v ON v.id = g.video_id
+		WHERE v.is_live = FALSE
+		GROUP BY g.video_id
+		HAVING MAX(g.created_at) > COALESCE(
+			(SELECT MAX(l.persisted_at) FROM gift_leaderboards l WHERE l.video_id = g.video_id),
+			'-infinity'::TIMESTAMP
+		)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var videoIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		videoIDs = append(videoIDs, id)
+	}
+
+	return videoIDs, rows.Err()
+}
+
+// PersistLeaderboard replaces a stream's final standings with totals computed
+// from its gifts
+func (r *PostgresRepository) PersistLeaderboard(ctx context.Context, videoID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM gift_leaderboards WHERE video_id = $1`, videoID); err != nil {
+		return fmt.Errorf("failed to clear leaderboard: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO gift_leaderboards (video_id, user_id, rank, coins)
+		SELECT video_id, sender_id, RANK() OVER (ORDER BY SUM(coins) DESC), SUM(coins)
+		FROM gift_transactions
+		WHERE video_id = $1
+		GROUP BY video_id, sender_id
+	`, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to persist leaderboard: %w", err)
+	}
+
+	return tx.Commit()
+}
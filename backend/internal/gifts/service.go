@@ -0,0 +1,321 @@
+package gifts
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/database"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/earnings"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// EntryKind is the journal entry kind for gifts
+const EntryKind = "gift"
+
+var (
+	ErrGiftNotFound  = errors.New("gift not found")
+	ErrVideoNotFound = errors.New("video not found")
+	ErrStreamNotLive = errors.New("gifts can only be sent during a live stream")
+	ErrSelfGift      = errors.New("creators cannot gift their own stream")
+)
+
+// Repository defines the interface for gift data access
+type Repository interface {
+	GetGifts(ctx context.Context) ([]*models.Gift, error)
+	GetGift(ctx context.Context, id int64) (*models.Gift, error)
+	GetStream(ctx context.Context, videoID int64) (int64, bool, error)
+	CreateGiftTransaction(ctx context.Context, gift *models.GiftTransaction, entry *models.JournalEntry, revenue *models.RevenueEvent) (bool, error)
+	GetUsernames(ctx context.Context, userIDs []int64) (map[int64]string, error)
+	GetLeaderboard(ctx context.Context, videoID int64, limit int) ([]*models.GiftLeaderboardEntry, error)
+	GetEndedStreamsToPersist(ctx context.Context) ([]int64, error)
+	PersistLeaderboard(ctx context.Context, videoID int64) error
+}
+
+// Service handles gifting during live streams
+type Service struct {
+	repo                 Repository
+	redis                *database.RedisClient
+	wallet               *wallet.Service
+	giftCentsPer100Coins int64
+	coinValueCentsPer100 int64
+}
+
+// NewService creates a new gift service. giftCentsPer100Coins is what the
+// creator earns, in cents, per 100 coins of gifts, out of the
+// coinValueCentsPer100 those coins are worth.
+func NewService(repo Repository, redis *database.RedisClient, wallet *wallet.Service, giftCentsPer100Coins, coinValueCentsPer100 int) *Service {
+	return &Service{
+		repo:                 repo,
+		redis:                redis,
+		wallet:               wallet,
+		giftCentsPer100Coins: int64(giftCentsPer100Coins),
+		coinValueCentsPer100: int64(coinValueCentsPer100),
+	}
+}
+
+// GetGifts retrieves the gift catalog
+func (s *Service) GetGifts(ctx context.Context) ([]*models.Gift, error) {
+	gifts, err := s.repo.GetGifts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gifts: %w", err)
+	}
+	return gifts, nil
+}
+
+// SendGift debits the sender's coins and credits the creator's pending
+// earnings in one journal entry, then announces the gift to the stream. A
+// retried request with the same idempotency key returns the original gift
+// and is not announced again. Without a key every request is a new gift.
+func (s *Service) SendGift(ctx context.Context, videoID, senderID int64, senderUsername string, req *models.SendGiftRequest, idempotencyKey string) (*models.GiftTransaction, error) {
+	creatorID, isLive, err := s.repo.GetStream(ctx, videoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	if !isLive {
+		return nil, ErrStreamNotLive
+	}
+	if creatorID == senderID {
+		return nil, ErrSelfGift
+	}
+
+	gift, err := s.repo.GetGift(ctx, req.GiftID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGiftNotFound
+		}
+		return nil, fmt.Errorf("failed to get gift: %w", err)
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	coins := gift.CoinPrice * int64(quantity)
+
+	if idempotencyKey == "" {
+		idempotencyKey, err = randomKey()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx := &models.GiftTransaction{
+		VideoID:       videoID,
+		GiftID:        gift.ID,
+		SenderID:      senderID,
+		RecipientID:   creatorID,
+		Quantity:      quantity,
+		Coins:         coins,
+		EarningsCents: Earnings(coins, s.giftCentsPer100Coins),
+	}
+
+	entry, err := s.giftEntry(ctx, tx, gift, fmt.Sprintf("gift:%d:%s", senderID, idempotencyKey))
+	if err != nil {
+		return nil, err
+	}
+
+	revenue := earnings.NewEvent(creatorID, earnings.SourceGift, entry.IdempotencyKey, coins, tx.EarningsCents, s.coinValueCentsPer100)
+	replayed, err := s.repo.CreateGiftTransaction(ctx, tx, entry, revenue)
+	if err != nil {
+		return nil, err
+	}
+	if replayed {
+		return tx, nil
+	}
+
+	s.announce(ctx, tx, gift, senderUsername)
+	return tx, nil
+}
+
+// GetLeaderboard ranks viewers by coins gifted to a stream. Live streams are
+// ranked from Redis; ended streams from their persisted standings.
+func (s *Service) GetLeaderboard(ctx context.Context, videoID int64, limit int) ([]*models.GiftLeaderboardEntry, error) {
+	_, isLive, err := s.repo.GetStream(ctx, videoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	if !isLive {
+		entries, err := s.repo.GetLeaderboard(ctx, videoID, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+		}
+		return entries, nil
+	}
+
+	scores, err := s.redis.ZRevRangeWithScores(ctx, leaderboardKey(videoID), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live leaderboard: %w", err)
+	}
+
+	return s.rank(ctx, scores)
+}
+
+// PersistEndedLeaderboards saves final standings for streams that have ended
+// and drops their live leaderboards from Redis
+func (s *Service) PersistEndedLeaderboards(ctx context.Context) error {
+	videoIDs, err := s.repo.GetEndedStreamsToPersist(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get ended streams: %w", err)
+	}
+
+	for _, videoID := range videoIDs {
+		if err := s.repo.PersistLeaderboard(ctx, videoID); err != nil {
+			return fmt.Errorf("failed to persist leaderboard for video %d: %w", videoID, err)
+		}
+		if err := s.redis.Del(ctx, leaderboardKey(videoID)).Err(); err != nil {
+			logger.WarnLogger.Printf("Failed to drop live leaderboard for video %d: %v", videoID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunLeaderboardPersister persists leaderboards of ended streams every
+// interval until ctx is cancelled
+func (s *Service) RunLeaderboardPersister(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.PersistEndedLeaderboards(ctx); err != nil {
+				logger.ErrorLogger.Printf("Failed to persist gift leaderboards: %v", err)
+			}
+		}
+	}
+}
+
+// Earnings converts gifted coins to the creator's earnings in cents, rounding
+// down; the remainder stays with the platform
+func Earnings(coins, centsPer100Coins int64) int64 {
+	return coins * centsPer100Coins / 100
+}
+
+// giftEntry builds the journal entry for a gift: coins move from the sender to
+// the platform, and the creator's earnings are funded by the platform
+func (s *Service) giftEntry(ctx context.Context, tx *models.GiftTransaction, gift *models.Gift, key string) (*models.JournalEntry, error) {
+	senderCoins, err := s.wallet.Account(ctx, tx.SenderID, wallet.AccountCoins, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, err
+	}
+	redeemed, err := s.wallet.PlatformAccount(ctx, wallet.PlatformCoinsRedeemed, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.JournalEntry{
+		IdempotencyKey: key,
+		Kind:           EntryKind,
+		Description:    fmt.Sprintf("%d x %s on video %d", tx.Quantity, gift.Name, tx.VideoID),
+		Postings: []*models.LedgerPosting{
+			{AccountID: senderCoins.ID, Currency: wallet.CurrencyCoin, Amount: -tx.Coins},
+			{AccountID: redeemed.ID, Currency: wallet.CurrencyCoin, Amount: tx.Coins},
+		},
+	}
+
+	if tx.EarningsCents > 0 {
+		pending, err := s.wallet.Account(ctx, tx.RecipientID, wallet.AccountEarningsPending, wallet.CurrencyUSD)
+		if err != nil {
+			return nil, err
+		}
+		funding, err := s.wallet.PlatformAccount(ctx, wallet.PlatformCreatorEarnings, wallet.CurrencyUSD)
+		if err != nil {
+			return nil, err
+		}
+		entry.Postings = append(entry.Postings,
+			&models.LedgerPosting{AccountID: funding.ID, Currency: wallet.CurrencyUSD, Amount: -tx.EarningsCents},
+			&models.LedgerPosting{AccountID: pending.ID, Currency: wallet.CurrencyUSD, Amount: tx.EarningsCents},
+		)
+	}
+
+	return entry, nil
+}
+
+// announce publishes a gift for overlays and adds it to the live leaderboard.
+// The gift is already paid for, so failures are logged rather than returned.
+func (s *Service) announce(ctx context.Context, tx *models.GiftTransaction, gift *models.Gift, senderUsername string) {
+	event := &models.GiftEvent{
+		VideoID:        tx.VideoID,
+		GiftID:         gift.ID,
+		GiftCode:       gift.Code,
+		GiftName:       gift.Name,
+		SenderID:       tx.SenderID,
+		SenderUsername: senderUsername,
+		Quantity:       tx.Quantity,
+		Coins:          tx.Coins,
+		SentAt:         tx.CreatedAt.Unix(),
+	}
+	if err := s.redis.PublishEvent(ctx, fmt.Sprintf("video:%d:gifts", tx.VideoID), event); err != nil {
+		logger.WarnLogger.Printf("Failed to publish gift %d: %v", tx.ID, err)
+	}
+
+	if err := s.redis.ZIncrBy(ctx, leaderboardKey(tx.VideoID), float64(tx.Coins), strconv.FormatInt(tx.SenderID, 10)).Err(); err != nil {
+		logger.WarnLogger.Printf("Failed to update leaderboard for video %d: %v", tx.VideoID, err)
+	}
+}
+
+// rank turns leaderboard scores into entries; viewers with equal totals share
+// a rank
+func (s *Service) rank(ctx context.Context, scores []redis.Z) ([]*models.GiftLeaderboardEntry, error) {
+	entries := make([]*models.GiftLeaderboardEntry, 0, len(scores))
+	userIDs := make([]int64, 0, len(scores))
+	for i, z := range scores {
+		member, _ := z.Member.(string)
+		userID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leaderboard member %q: %w", member, err)
+		}
+
+		entry := &models.GiftLeaderboardEntry{Rank: i + 1, UserID: userID, Coins: int64(z.Score)}
+		if i > 0 && entries[i-1].Coins == entry.Coins {
+			entry.Rank = entries[i-1].Rank
+		}
+		entries = append(entries, entry)
+		userIDs = append(userIDs, userID)
+	}
+
+	if len(userIDs) == 0 {
+		return entries, nil
+	}
+
+	usernames, err := s.repo.GetUsernames(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usernames: %w", err)
+	}
+	for _, entry := range entries {
+		entry.Username = usernames[entry.UserID]
+	}
+
+	return entries, nil
+}
+
+func leaderboardKey(videoID int64) string {
+	return fmt.Sprintf("video:%d:gift_leaderboard", videoID)
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
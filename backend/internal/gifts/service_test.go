@@ -0,0 +1,26 @@
+package gifts
+
+import "testing"
+
+func TestEarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		coins    int64
+		rate     int64
+		expected int64
+	}{
+		{"HundredCoins", 100, 50, 50},
+		{"RoundsDown", 99, 50, 49},
+		{"SingleCoin", 1, 50, 0},
+		{"LargeGift", 1000000, 50, 500000},
+		{"NoShare", 500, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Earnings(tt.coins, tt.rate); got != tt.expected {
+				t.Errorf("Expected %d cents, got %d", tt.expected, got)
+			}
+		})
+	}
+}
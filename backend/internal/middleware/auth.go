@@ -9,8 +9,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// AuthMiddleware validates JWT tokens and rejects tokens belonging to
+// suspended or banned accounts or to revoked sessions
+func AuthMiddleware(jwtManager *auth.JWTManager, authService *auth.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -44,11 +45,54 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_username", claims.Username)
+		// Existing tokens stop working as soon as an account is suspended or
+		// banned, and carry the user's current role and permissions
+		claims, err = authService.CheckAccess(c.Request.Context(), claims, auth.ClientOf(c))
+		if err != nil {
+			if !auth.RespondAccountState(c, err) {
+				c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+					Error:   "unauthorized",
+					Message: "Invalid or expired token",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		setClaims(c, claims)
+
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware identifies the caller when a valid token is present
+// but lets anonymous requests through, for public routes whose results
+// depend on who is asking
+func OptionalAuthMiddleware(jwtManager *auth.JWTManager, authService *auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			claims, err := jwtManager.ValidateToken(parts[1])
+			if err == nil {
+				claims, err = authService.CheckAccess(c.Request.Context(), claims, auth.ClientOf(c))
+			}
+			if err == nil {
+				setClaims(c, claims)
+			}
+		}
 
 		c.Next()
 	}
 }
+
+// setClaims sets user information from the checked claims in the context
+func setClaims(c *gin.Context, claims *auth.Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_username", claims.Username)
+	c.Set("user_role", claims.Role)
+	c.Set("user_permissions", claims.Permissions)
+	c.Set("user_amr", claims.AMR)
+	c.Set("user_auth_time", claims.AuthTime)
+	c.Set("session_id", claims.SessionID)
+}
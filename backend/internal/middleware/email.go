@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/auth"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail rejects requests from users who have not verified
+// their email. It must run after AuthMiddleware.
+func RequireVerifiedEmail(authService *auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := authService.CheckEmailVerified(c.Request.Context(), c.GetInt64("user_id"))
+		if err == auth.ErrEmailNotVerified {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "email_not_verified",
+				Message: "Verify your email address to continue",
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to check email verification",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
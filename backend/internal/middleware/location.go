@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientLocation records the approximate location of the client's IP, as
+// reported by a CDN or proxy in headers such as CF-IPCity and CF-IPCountry,
+// for auth.ClientOf. Non-empty header values are joined in order; nothing
+// is recorded without headers, since clients could set them themselves.
+func ClientLocation(headers []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var parts []string
+		for _, header := range headers {
+			value := strings.TrimSpace(c.GetHeader(header))
+			// Some CDNs URL-encode names with non-ASCII letters
+			if decoded, err := url.PathUnescape(value); err == nil {
+				value = decoded
+			}
+			if value != "" {
+				parts = append(parts, value)
+			}
+		}
+		if len(parts) > 0 {
+			c.Set("client_location", strings.Join(parts, ", "))
+		}
+
+		c.Next()
+	}
+}
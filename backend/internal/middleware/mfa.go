@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/auth"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRecentMFA rejects requests whose token was not issued for a login
+// or step-up with a second factor within maxAge. It must run after
+// AuthMiddleware.
+func RequireRecentMFA(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := &auth.Claims{
+			AMR:      c.GetStringSlice("user_amr"),
+			AuthTime: c.GetInt64("user_auth_time"),
+		}
+		if !claims.RecentMFA(maxAge, time.Now()) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "mfa_required",
+				Message: "Verify a second factor to continue",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRecentAuth rejects requests whose token was not issued for a login
+// or step-up within maxAge, so a stolen token alone cannot be used for
+// irreversible actions. It must run after AuthMiddleware.
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := &auth.Claims{
+			AuthTime: c.GetInt64("user_auth_time"),
+		}
+		if !claims.RecentAuth(maxAge, time.Now()) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "reauth_required",
+				Message: "Sign in again to continue",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/auth"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission rejects requests from users who do not currently hold
+// every given permission. It must run after AuthMiddleware, which loads the
+// user's live permissions rather than those in the token.
+func RequirePermission(permissions ...auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := make(map[string]bool)
+		for _, p := range c.GetStringSlice("user_permissions") {
+			granted[p] = true
+		}
+
+		for _, required := range permissions {
+			if !granted[string(required)] {
+				c.JSON(http.StatusForbidden, models.ErrorResponse{
+					Error:   "forbidden",
+					Message: "Insufficient permissions",
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
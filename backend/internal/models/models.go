@@ -13,8 +13,49 @@ type User struct {
 	AvatarURL    string    `json:"avatar_url" db:"avatar_url"`
 	IsAdult      bool      `json:"is_adult" db:"is_adult"`
 	AdultMode    bool      `json:"adult_mode" db:"adult_mode"`
+	Role         string    `json:"role" db:"role"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// EmailVerifiedAt is set once the user follows a verification link
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	// MFAEnabled reports whether logging in needs a second factor
+	MFAEnabled bool `json:"mfa_enabled" db:"-"`
+
+	// Moderation state is never serialized: a shadow-banned user must not
+	// be able to tell from their own profile
+	AccountStatus  string     `json:"-" db:"account_status"`
+	SuspendedUntil *time.Time `json:"-" db:"suspended_until"`
+	StatusReason   string     `json:"-" db:"status_reason"`
+
+	// SessionsRevokedAt invalidates tokens issued before it
+	SessionsRevokedAt *time.Time `json:"-" db:"sessions_revoked_at"`
+	// DeletionScheduledAt is when the account will be erased, if its owner
+	// asked for that
+	DeletionScheduledAt *time.Time `json:"-" db:"deletion_scheduled_at"`
+
+	// Fields whose submitted text is held for review; not stored
+	PendingReview []string `json:"pending_review,omitempty" db:"-"`
+}
+
+// UserTOTP is a user's TOTP secret, encrypted, and its enrollment state
+type UserTOTP struct {
+	UserID       int64      `json:"-" db:"user_id"`
+	Secret       []byte     `json:"-" db:"secret"`
+	EnabledAt    *time.Time `json:"-" db:"enabled_at"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"-" db:"created_at"`
+}
+
+// AccountAction records a moderation decision on an account
+type AccountAction struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	Status      string     `json:"status" db:"status"`
+	Reason      string     `json:"reason" db:"reason"`
+	ModeratorID *int64     `json:"moderator_id,omitempty" db:"moderator_id"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 }
 
 // Video represents video metadata
@@ -30,6 +71,30 @@ type Video struct {
 	ViewCount      int64     `json:"view_count" db:"view_count"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+
+	// Set when a moderator force-ends the live stream; it cannot go live again
+	TerminatedAt *time.Time `json:"terminated_at,omitempty" db:"terminated_at"`
+	// Set when a moderator takes the video down; the row remains as a tombstone
+	TakenDownAt    *time.Time `json:"taken_down_at,omitempty" db:"taken_down_at"`
+	TakedownReason string     `json:"takedown_reason,omitempty" db:"takedown_reason"`
+
+	// Only the creator's subscribers may watch
+	SubscribersOnly bool `json:"subscribers_only" db:"subscribers_only"`
+	// Price of a ticket to watch, in coins; nil for free videos
+	TicketPriceCoins *int64 `json:"ticket_price_coins,omitempty" db:"ticket_price_coins"`
+	// Set when the creator cancels a ticketed event; tickets are refunded
+	CanceledAt   *time.Time `json:"canceled_at,omitempty" db:"canceled_at"`
+	CancelReason string     `json:"cancel_reason,omitempty" db:"cancel_reason"`
+	// Status is scheduled for streams announced in advance until they first
+	// go live, and published otherwise
+	Status      string     `json:"status" db:"status"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty" db:"scheduled_at"`
+	// Set, with stream_url withheld, when the viewer may not watch
+	Locked     bool   `json:"locked,omitempty" db:"-"`
+	LockReason string `json:"lock_reason,omitempty" db:"-"`
+
+	// Fields whose submitted text is held for review; not stored
+	PendingReview []string `json:"pending_review,omitempty" db:"-"`
 }
 
 // VideoWithEngagement extends Video with real-time engagement data
@@ -40,27 +105,806 @@ type VideoWithEngagement struct {
 	CommentCount int64 `json:"comment_count"`
 }
 
+// RoomSettings holds a creator's interaction rules for one stream
+type RoomSettings struct {
+	VideoID              int64     `json:"video_id" db:"video_id"`
+	SlowModeSeconds      int       `json:"slow_mode_seconds" db:"slow_mode_seconds"`
+	VerifiedOnly         bool      `json:"verified_only" db:"verified_only"`
+	MinAccountAgeMinutes int       `json:"min_account_age_minutes" db:"min_account_age_minutes"`
+	BannedWords          []string  `json:"banned_words" db:"banned_words"`
+	BannedPatterns       []string  `json:"banned_patterns" db:"banned_patterns"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ChannelBan bans a user from interacting with a creator's streams. A ban
+// with an expiry is a timeout.
+type ChannelBan struct {
+	ID        int64      `json:"id" db:"id"`
+	ChannelID int64      `json:"channel_id" db:"channel_id"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	IssuedBy  int64      `json:"issued_by" db:"issued_by"`
+	Reason    string     `json:"reason" db:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// RoomMessage is a chat message broadcast to a stream's viewers
+type RoomMessage struct {
+	VideoID  int64  `json:"video_id"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Text     string `json:"text"`
+	SentAt   int64  `json:"sent_at"`
+}
+
+// ModerationItem is user-supplied text held for human review. It is written
+// to its field only once approved.
+type ModerationItem struct {
+	ID         int64      `json:"id" db:"id"`
+	Field      string     `json:"field" db:"field"`
+	SubjectID  int64      `json:"subject_id" db:"subject_id"`
+	AuthorID   int64      `json:"author_id" db:"author_id"`
+	Text       string     `json:"text" db:"text"`
+	Reasons    []string   `json:"reasons" db:"reasons"`
+	Status     string     `json:"status" db:"status"`
+	ReviewerID *int64     `json:"reviewer_id,omitempty" db:"reviewer_id"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LedgerAccount is an account in the double-entry ledger. Amounts are in
+// integer minor units of the currency (cents, or whole coins).
+type LedgerAccount struct {
+	ID            int64     `json:"id" db:"id"`
+	OwnerID       *int64    `json:"owner_id,omitempty" db:"owner_id"`
+	Type          string    `json:"type" db:"type"`
+	Currency      string    `json:"currency" db:"currency"`
+	Balance       int64     `json:"balance" db:"balance"`
+	AllowNegative bool      `json:"-" db:"allow_negative"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// JournalEntry is a balanced set of postings recorded as one movement
+type JournalEntry struct {
+	ID             int64            `json:"id" db:"id"`
+	IdempotencyKey string           `json:"-" db:"idempotency_key"`
+	Kind           string           `json:"kind" db:"kind"`
+	Description    string           `json:"description" db:"description"`
+	Postings       []*LedgerPosting `json:"postings,omitempty"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+}
+
+// LedgerPosting moves an amount into (positive) or out of (negative) an account
+type LedgerPosting struct {
+	ID           int64     `json:"id" db:"id"`
+	EntryID      int64     `json:"entry_id" db:"entry_id"`
+	AccountID    int64     `json:"account_id" db:"account_id"`
+	Currency     string    `json:"currency" db:"currency"`
+	Amount       int64     `json:"amount" db:"amount"`
+	BalanceAfter int64     `json:"balance_after" db:"balance_after"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Wallet summarizes a user's balances
+type Wallet struct {
+	Coins    int64            `json:"coins"`
+	Accounts []*LedgerAccount `json:"accounts"`
+}
+
+// WalletTransaction is a posting on one of a user's accounts, as shown in
+// their transaction history
+type WalletTransaction struct {
+	EntryID      int64     `json:"entry_id"`
+	Kind         string    `json:"kind"`
+	Description  string    `json:"description"`
+	AccountType  string    `json:"account_type"`
+	Currency     string    `json:"currency"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balance_after"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Gift is an item in the gift catalog, priced in coins
+type Gift struct {
+	ID        int64  `json:"id" db:"id"`
+	Code      string `json:"code" db:"code"`
+	Name      string `json:"name" db:"name"`
+	CoinPrice int64  `json:"coin_price" db:"coin_price"`
+	IconURL   string `json:"icon_url" db:"icon_url"`
+	SortOrder int    `json:"-" db:"sort_order"`
+}
+
+// GiftTransaction records a gift sent to a creator during a stream
+type GiftTransaction struct {
+	ID            int64     `json:"id" db:"id"`
+	VideoID       int64     `json:"video_id" db:"video_id"`
+	GiftID        int64     `json:"gift_id" db:"gift_id"`
+	SenderID      int64     `json:"sender_id" db:"sender_id"`
+	RecipientID   int64     `json:"recipient_id" db:"recipient_id"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	Coins         int64     `json:"coins" db:"coins"`
+	EarningsCents int64     `json:"-" db:"earnings_cents"`
+	EntryID       int64     `json:"entry_id" db:"entry_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// GiftEvent is broadcast to stream overlays when a gift is sent
+type GiftEvent struct {
+	VideoID        int64  `json:"video_id"`
+	GiftID         int64  `json:"gift_id"`
+	GiftCode       string `json:"gift_code"`
+	GiftName       string `json:"gift_name"`
+	SenderID       int64  `json:"sender_id"`
+	SenderUsername string `json:"sender_username"`
+	Quantity       int    `json:"quantity"`
+	Coins          int64  `json:"coins"`
+	SentAt         int64  `json:"sent_at"`
+}
+
+// GiftLeaderboardEntry ranks a viewer by coins gifted during a stream
+type GiftLeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Coins    int64  `json:"coins"`
+}
+
+// PayoutAccount links a creator to their connected account at the payment
+// provider
+type PayoutAccount struct {
+	UserID            int64     `json:"user_id" db:"user_id"`
+	ProviderAccountID string    `json:"provider_account_id" db:"provider_account_id"`
+	DestinationLast4  string    `json:"destination_last4" db:"destination_last4"`
+	PayoutsEnabled    bool      `json:"payouts_enabled" db:"payouts_enabled"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Payout is a transfer of a creator's earnings to their bank. Its JSON form
+// mirrors the provider's payout object shown in the app; times are unix
+// seconds.
+type Payout struct {
+	ID          string `json:"id" db:"public_id"`
+	Amount      int64  `json:"amount" db:"amount"`
+	Currency    string `json:"currency" db:"currency"`
+	Status      string `json:"status" db:"status"`
+	Created     int64  `json:"created"`
+	ArrivalDate int64  `json:"arrival_date"`
+	Destination string `json:"destination" db:"destination"`
+
+	InternalID        int64      `json:"-" db:"id"`
+	UserID            int64      `json:"-" db:"user_id"`
+	ProviderAccountID string     `json:"-" db:"provider_account_id"`
+	ProviderPayoutID  string     `json:"-" db:"provider_payout_id"`
+	ArrivalAt         *time.Time `json:"-" db:"arrival_date"`
+	FailureCode       string     `json:"-" db:"failure_code"`
+	FailureMessage    string     `json:"-" db:"failure_message"`
+	EntryID           int64      `json:"-" db:"entry_id"`
+	CreatedAt         time.Time  `json:"-" db:"created_at"`
+}
+
+// WebhookEvent is an inbound payment provider event, stored as received
+type WebhookEvent struct {
+	ID          int64      `json:"id" db:"id"`
+	EventID     string     `json:"event_id" db:"event_id"`
+	Type        string     `json:"type" db:"type"`
+	Payload     string     `json:"payload" db:"payload"`
+	Status      string     `json:"status" db:"status"`
+	Attempts    int        `json:"attempts" db:"attempts"`
+	LastError   string     `json:"last_error,omitempty" db:"last_error"`
+	ReceivedAt  time.Time  `json:"received_at" db:"received_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty" db:"processed_at"`
+}
+
+// WebhookDeadLetter is a webhook event whose processing failed and is
+// waiting to be retried
+type WebhookDeadLetter struct {
+	Event       *WebhookEvent `json:"event"`
+	Attempts    int           `json:"attempts" db:"attempts"`
+	LastError   string        `json:"last_error" db:"last_error"`
+	NextRetryAt time.Time     `json:"next_retry_at" db:"next_retry_at"`
+	Exhausted   bool          `json:"exhausted" db:"exhausted"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+}
+
+// CoinPackage is a bundle of coins sold through the app stores
+type CoinPackage struct {
+	ID         int64  `json:"id" db:"id"`
+	ProductID  string `json:"product_id" db:"product_id"`
+	Coins      int64  `json:"coins" db:"coins"`
+	PriceCents int64  `json:"price_cents" db:"price_cents"`
+	Currency   string `json:"currency" db:"currency"`
+	SortOrder  int    `json:"-" db:"sort_order"`
+}
+
+// CoinPurchase is a store transaction that credited coins to a user
+type CoinPurchase struct {
+	ID            int64      `json:"id" db:"id"`
+	UserID        int64      `json:"user_id" db:"user_id"`
+	PackageID     int64      `json:"package_id" db:"package_id"`
+	Store         string     `json:"store" db:"store"`
+	TransactionID string     `json:"transaction_id" db:"transaction_id"`
+	ProductID     string     `json:"product_id" db:"product_id"`
+	Coins         int64      `json:"coins" db:"coins"`
+	PriceCents    int64      `json:"price_cents" db:"price_cents"`
+	Currency      string     `json:"currency" db:"currency"`
+	Status        string     `json:"status" db:"status"`
+	ReversedCoins int64      `json:"reversed_coins,omitempty" db:"reversed_coins"`
+	EntryID       int64      `json:"-" db:"entry_id"`
+	PurchasedAt   time.Time  `json:"purchased_at" db:"purchased_at"`
+	ReversedAt    *time.Time `json:"reversed_at,omitempty" db:"reversed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Playback is what a viewer needs to start watching a video
+type Playback struct {
+	VideoID   int64  `json:"video_id"`
+	StreamURL string `json:"stream_url"`
+	IsLive    bool   `json:"is_live"`
+}
+
+// EventTicket is a viewer's ticket to a ticketed stream
+type EventTicket struct {
+	ID         int64      `json:"id" db:"id"`
+	VideoID    int64      `json:"video_id" db:"video_id"`
+	UserID     int64      `json:"user_id" db:"user_id"`
+	PriceCoins int64      `json:"price_coins" db:"price_coins"`
+	ChargeID   string     `json:"-" db:"charge_id"`
+	Status     string     `json:"status" db:"status"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	RefundedAt *time.Time `json:"refunded_at,omitempty" db:"refunded_at"`
+}
+
+// StreamReminder is a viewer's request to be reminded before a scheduled
+// stream starts
+type StreamReminder struct {
+	VideoID     int64     `json:"video_id" db:"video_id"`
+	UserID      int64     `json:"user_id" db:"user_id"`
+	Title       string    `json:"title" db:"title"`
+	ScheduledAt time.Time `json:"scheduled_at" db:"scheduled_at"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Notification is an entry in a user's in-app inbox
+type Notification struct {
+	ID        int64             `json:"id" db:"id"`
+	UserID    int64             `json:"-" db:"user_id"`
+	Type      string            `json:"type" db:"type"`
+	Title     string            `json:"title" db:"title"`
+	Body      string            `json:"body" db:"body"`
+	Data      map[string]string `json:"data" db:"data"`
+	ReadAt    *time.Time        `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
+
+// NotificationInbox is a page of a user's inbox
+type NotificationInbox struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int64           `json:"unread_count"`
+}
+
+// NotificationPreference is how a user receives one type of notification
+type NotificationPreference struct {
+	Type  string `json:"type" db:"type" binding:"required"`
+	InApp bool   `json:"in_app" db:"in_app"`
+	Push  bool   `json:"push" db:"push"`
+	Email bool   `json:"email" db:"email"`
+}
+
+// PushDevice is a device registered for push notifications
+type PushDevice struct {
+	ID         int64     `json:"id" db:"id"`
+	UserID     int64     `json:"-" db:"user_id"`
+	Platform   string    `json:"platform" db:"platform"`
+	Token      string    `json:"-" db:"token"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// RevenueEvent records what one monetized action earned a creator. Refunds
+// are recorded as negative events.
+type RevenueEvent struct {
+	ID               int64     `json:"id" db:"id"`
+	CreatorID        int64     `json:"creator_id" db:"creator_id"`
+	Source           string    `json:"source" db:"source"`
+	SourceRef        string    `json:"-" db:"source_ref"`
+	GrossCoins       int64     `json:"gross_coins" db:"gross_coins"`
+	GrossCents       int64     `json:"gross_cents" db:"gross_cents"`
+	CreatorCents     int64     `json:"creator_cents" db:"creator_cents"`
+	PlatformFeeCents int64     `json:"platform_fee_cents" db:"platform_fee_cents"`
+	OccurredAt       time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// EarningsAmounts are revenue totals. Amounts are net of refunds, and
+// CreatorSharePercent is the creator's share of the gross value.
+type EarningsAmounts struct {
+	Sales               int64   `json:"sales"`
+	GrossCoins          int64   `json:"gross_coins"`
+	GrossCents          int64   `json:"gross_cents"`
+	CreatorCents        int64   `json:"creator_cents"`
+	PlatformFeeCents    int64   `json:"platform_fee_cents"`
+	CreatorSharePercent float64 `json:"creator_share_percent"`
+}
+
+// EarningsBucket is the revenue from one source in one day, week or month
+type EarningsBucket struct {
+	Start  string `json:"start"`
+	Source string `json:"source"`
+	EarningsAmounts
+}
+
+// EarningsSourceTotal is the revenue from one source over a report
+type EarningsSourceTotal struct {
+	Source string `json:"source"`
+	EarningsAmounts
+}
+
+// EarningsBalances are a creator's earnings not yet paid out
+type EarningsBalances struct {
+	Currency       string `json:"currency"`
+	PendingCents   int64  `json:"pending_cents"`
+	AvailableCents int64  `json:"available_cents"`
+}
+
+// EarningsReport is a creator's revenue over a date range
+type EarningsReport struct {
+	Period   string                 `json:"period"`
+	From     string                 `json:"from"`
+	To       string                 `json:"to"`
+	Balances EarningsBalances       `json:"balances"`
+	Totals   EarningsAmounts        `json:"totals"`
+	BySource []*EarningsSourceTotal `json:"by_source"`
+	Buckets  []*EarningsBucket      `json:"buckets"`
+}
+
+// SubscriptionTier is a level of paid support a creator offers
+type SubscriptionTier struct {
+	ID         int64     `json:"id" db:"id"`
+	CreatorID  int64     `json:"creator_id" db:"creator_id"`
+	Name       string    `json:"name" db:"name"`
+	PriceCoins int64     `json:"price_coins" db:"price_coins"`
+	Perks      []string  `json:"perks" db:"perks"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Subscription is a viewer's recurring support of a creator
+type Subscription struct {
+	ID                 int64             `json:"id" db:"id"`
+	SubscriberID       int64             `json:"subscriber_id" db:"subscriber_id"`
+	CreatorID          int64             `json:"creator_id" db:"creator_id"`
+	TierID             int64             `json:"tier_id" db:"tier_id"`
+	Tier               *SubscriptionTier `json:"tier,omitempty" db:"-"`
+	Status             string            `json:"status" db:"status"`
+	Periods            int               `json:"periods" db:"periods"`
+	CurrentPeriodStart time.Time         `json:"current_period_start" db:"current_period_start"`
+	CurrentPeriodEnd   time.Time         `json:"current_period_end" db:"current_period_end"`
+	GraceUntil         *time.Time        `json:"grace_until,omitempty" db:"grace_until"`
+	CanceledAt         *time.Time        `json:"canceled_at,omitempty" db:"canceled_at"`
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
+}
+
+// SubscriberBadge is shown on a subscriber's profile in the creator's channel
+type SubscriberBadge struct {
+	TierName string    `json:"tier_name"`
+	Since    time.Time `json:"since"`
+}
+
+// PublicProfile is what other users see of an account. IsSubscriber and
+// SubscriberBadge describe the user in the context of one creator's channel.
+type PublicProfile struct {
+	ID              int64            `json:"id"`
+	Username        string           `json:"username"`
+	DisplayName     string           `json:"display_name"`
+	Bio             string           `json:"bio"`
+	AvatarURL       string           `json:"avatar_url"`
+	CreatedAt       time.Time        `json:"created_at"`
+	IsSubscriber    bool             `json:"is_subscriber"`
+	SubscriberBadge *SubscriberBadge `json:"subscriber_badge,omitempty"`
+}
+
 // LoginRequest represents login credentials
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 }
 
 // RegisterRequest represents registration data
 type RegisterRequest struct {
 	Email       string `json:"email" binding:"required,email"`
 	Username    string `json:"username" binding:"required,min=3,max=30"`
-	Password    string `json:"password" binding:"required,min=8"`
+	Password    string `json:"password" binding:"required"`
 	DisplayName string `json:"display_name" binding:"required,min=1,max=50"`
 	IsAdult     bool   `json:"is_adult"`
 }
 
+// ForgotPasswordRequest asks for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required,max=128"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePasswordRequest sets a new password given the current one
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// EmailTokenRequest redeems a token from an email link
+type EmailTokenRequest struct {
+	Token string `json:"token" binding:"required,max=1024"`
+}
+
+// ChangeEmailRequest asks to move an account to a new email address
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required"`
+}
+
+// EmailChange is a pending change of a user's email address, applied once
+// both addresses have confirmed it
+type EmailChange struct {
+	ID             int64      `json:"id" db:"id"`
+	UserID         int64      `json:"-" db:"user_id"`
+	NewEmail       string     `json:"new_email" db:"new_email"`
+	OldConfirmedAt *time.Time `json:"old_confirmed_at" db:"old_confirmed_at"`
+	NewConfirmedAt *time.Time `json:"new_confirmed_at" db:"new_confirmed_at"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	// Completed is set once the new address has been applied
+	Completed bool `json:"completed" db:"-"`
+}
+
+// MFAChallenge is returned instead of a token when a login needs a second
+// factor
+type MFAChallenge struct {
+	MFARequired    bool      `json:"mfa_required"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	// Methods lists the second factors the challenge accepts
+	Methods []string `json:"methods"`
+}
+
+// MFALoginRequest completes a login with a second factor: either a TOTP
+// code or a recovery code
+type MFALoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required,max=128"`
+	Code           string `json:"code" binding:"omitempty,max=16"`
+	RecoveryCode   string `json:"recovery_code" binding:"omitempty,max=32"`
+}
+
+// MFACodeRequest carries a TOTP code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=16"`
+}
+
+// TOTPEnrollment is a new TOTP secret for the user to add to an
+// authenticator app
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodes are single-use codes that stand in for a TOTP code. They are
+// only shown when generated.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// Passkey is a WebAuthn credential a user can sign in with
+type Passkey struct {
+	ID     int64 `json:"id" db:"id"`
+	UserID int64 `json:"-" db:"user_id"`
+	// CredentialID is the authenticator's ID for the credential
+	CredentialID []byte `json:"-" db:"credential_id"`
+	// PublicKey is the credential's public key in PKIX form
+	PublicKey  []byte     `json:"-" db:"public_key"`
+	SignCount  int64      `json:"-" db:"sign_count"`
+	AAGUID     []byte     `json:"-" db:"aaguid"`
+	Name       string     `json:"name" db:"name"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PasskeyRP identifies the relying party passkeys are created for
+type PasskeyRP struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PasskeyUser describes the account a passkey is created for. ID is the
+// base64url user handle authenticators return on login.
+type PasskeyUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// PasskeyParam is a public key algorithm passkeys may use, as a COSE
+// algorithm number
+type PasskeyParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// PasskeyDescriptor refers to an existing credential by its base64url ID
+type PasskeyDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// PasskeyAuthenticatorSelection states the authenticators a passkey may be
+// created on
+type PasskeyAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// PasskeyCreationOptions are passed to navigator.credentials.create, in the
+// JSON form PublicKeyCredential.parseCreationOptionsFromJSON accepts
+type PasskeyCreationOptions struct {
+	Challenge              string                        `json:"challenge"`
+	RP                     PasskeyRP                     `json:"rp"`
+	User                   PasskeyUser                   `json:"user"`
+	PubKeyCredParams       []PasskeyParam                `json:"pubKeyCredParams"`
+	Timeout                int64                         `json:"timeout"`
+	ExcludeCredentials     []PasskeyDescriptor           `json:"excludeCredentials"`
+	AuthenticatorSelection PasskeyAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                        `json:"attestation"`
+}
+
+// PasskeyRequestOptions are passed to navigator.credentials.get, in the
+// JSON form PublicKeyCredential.parseRequestOptionsFromJSON accepts
+type PasskeyRequestOptions struct {
+	Challenge        string              `json:"challenge"`
+	RPID             string              `json:"rpId"`
+	Timeout          int64               `json:"timeout"`
+	AllowCredentials []PasskeyDescriptor `json:"allowCredentials"`
+	UserVerification string              `json:"userVerification"`
+}
+
+// PasskeyAttestationResponse is the authenticator's response to a passkey
+// being created, with base64url fields
+type PasskeyAttestationResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+	AttestationObject string `json:"attestationObject" binding:"required"`
+}
+
+// PasskeyRegistrationRequest is the credential navigator.credentials.create
+// returned, serialized with toJSON, and a name for it
+type PasskeyRegistrationRequest struct {
+	ID       string                     `json:"id" binding:"required"`
+	Type     string                     `json:"type" binding:"required,eq=public-key"`
+	Response PasskeyAttestationResponse `json:"response"`
+	Name     string                     `json:"name" binding:"max=100"`
+}
+
+// PasskeyAssertionResponse is the authenticator's response to a passkey
+// login, with base64url fields
+type PasskeyAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+	AuthenticatorData string `json:"authenticatorData" binding:"required"`
+	Signature         string `json:"signature" binding:"required"`
+	UserHandle        string `json:"userHandle"`
+}
+
+// PasskeyLoginRequest is the credential navigator.credentials.get returned,
+// serialized with toJSON
+type PasskeyLoginRequest struct {
+	ID       string                   `json:"id" binding:"required"`
+	Type     string                   `json:"type" binding:"required,eq=public-key"`
+	Response PasskeyAssertionResponse `json:"response"`
+}
+
+// UserIdentity is an account at an OpenID Connect provider linked to a user
+type UserIdentity struct {
+	ID       int64  `json:"id" db:"id"`
+	UserID   int64  `json:"-" db:"user_id"`
+	Provider string `json:"provider" db:"provider"`
+	// Subject is the provider's ID for the account
+	Subject   string    `json:"-" db:"subject"`
+	Email     string    `json:"email,omitempty" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// OIDCLoginRequest carries an ID token issued by a provider to one of the
+// apps. Nonce, when set, must match the token's nonce claim.
+type OIDCLoginRequest struct {
+	IDToken string `json:"id_token" binding:"required,max=8192"`
+	Nonce   string `json:"nonce" binding:"max=256"`
+}
+
+// Session is a device a user is signed in on. Each token names its session,
+// and revoking the session signs the device out.
+type Session struct {
+	ID     int64 `json:"id" db:"id"`
+	UserID int64 `json:"-" db:"user_id"`
+	// DeviceName is the name the app reported or one derived from the
+	// browser, such as "Chrome on Windows"
+	DeviceName string `json:"device_name" db:"device_name"`
+	Platform   string `json:"platform,omitempty" db:"platform"`
+	IP         string `json:"ip" db:"ip"`
+	// Location is the approximate location of the IP, when known
+	Location   string     `json:"location,omitempty" db:"location"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"-" db:"revoked_at"`
+	// Current marks the session of the request listing sessions
+	Current bool `json:"current" db:"-"`
+}
+
+// AccountDeletion is a pending request to delete an account
+type AccountDeletion struct {
+	// ScheduledAt is when the account will be erased unless the request is
+	// canceled first
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
 // AuthResponse represents authentication response
 type AuthResponse struct {
 	Token string `json:"token"`
 	User  *User  `json:"user"`
 }
 
+// UpdateProfileRequest represents changes to the current user's profile.
+// Omitted fields are left unchanged.
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,min=1,max=50"`
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,url"`
+	AdultMode   *bool   `json:"adult_mode"`
+}
+
+// CreateVideoRequest represents a new video or stream created by its owner
+type CreateVideoRequest struct {
+	Title          string `json:"title" binding:"required,min=1,max=255"`
+	Description    string `json:"description" binding:"max=5000"`
+	ThumbnailURL   string `json:"thumbnail_url" binding:"omitempty,url"`
+	IsAdultContent bool   `json:"is_adult_content"`
+	// SubscribersOnly restricts the video to the creator's subscribers
+	SubscribersOnly bool `json:"subscribers_only"`
+	// TicketPriceCoins makes the video a ticketed event
+	TicketPriceCoins *int64 `json:"ticket_price_coins" binding:"omitempty,min=1,max=1000000"`
+	// ScheduledAt announces the video as a stream starting at that time
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// UpdateVideoRequest represents changes to a video's metadata. Omitted
+// fields are left unchanged.
+type UpdateVideoRequest struct {
+	Title           *string `json:"title" binding:"omitempty,min=1,max=255"`
+	Description     *string `json:"description" binding:"omitempty,max=5000"`
+	ThumbnailURL    *string `json:"thumbnail_url" binding:"omitempty,url"`
+	IsAdultContent  *bool   `json:"is_adult_content"`
+	SubscribersOnly *bool   `json:"subscribers_only"`
+	// TicketPriceCoins changes the ticket price for future buyers; 0 makes
+	// the video free
+	TicketPriceCoins *int64 `json:"ticket_price_coins" binding:"omitempty,min=0,max=1000000"`
+	// ScheduledAt reschedules a stream that has not started yet
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// UpdateNotificationPreferencesRequest changes delivery preferences; types
+// not listed are left unchanged
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreference `json:"preferences" binding:"required,min=1,dive"`
+}
+
+// MarkNotificationsReadRequest marks inbox entries read, either those listed
+// or all of them
+type MarkNotificationsReadRequest struct {
+	IDs []int64 `json:"ids" binding:"max=100"`
+	All bool    `json:"all"`
+}
+
+// RegisterPushDeviceRequest registers a device token with APNs or FCM
+type RegisterPushDeviceRequest struct {
+	Platform string `json:"platform" binding:"required,oneof=apns fcm"`
+	Token    string `json:"token" binding:"required,max=4096"`
+}
+
+// SendGiftRequest represents a gift sent during a stream
+type SendGiftRequest struct {
+	GiftID   int64 `json:"gift_id" binding:"required"`
+	Quantity int   `json:"quantity" binding:"omitempty,min=1,max=100"`
+}
+
+// PayoutOnboarding is a link to the payment provider's onboarding for a
+// creator's payout account
+type PayoutOnboarding struct {
+	URL string `json:"url"`
+	// ExpiresAt is when the link stops working, in unix seconds
+	ExpiresAt int64          `json:"expires_at"`
+	Account   *PayoutAccount `json:"account"`
+}
+
+// PurchaseCoinsRequest represents an app store receipt submitted for coins
+type PurchaseCoinsRequest struct {
+	Store   string `json:"store" binding:"required,max=20"`
+	Receipt string `json:"receipt" binding:"required,max=65536"`
+}
+
+// CreateTierRequest represents a creator adding a subscription tier
+type CreateTierRequest struct {
+	Name       string   `json:"name" binding:"required,max=50"`
+	PriceCoins int64    `json:"price_coins" binding:"required,min=1,max=1000000"`
+	Perks      []string `json:"perks" binding:"max=10,dive,max=100"`
+}
+
+// UpdateTierRequest represents changes to a subscription tier; nil fields are
+// left unchanged. Price changes apply from subscribers' next renewal.
+type UpdateTierRequest struct {
+	Name       *string   `json:"name" binding:"omitempty,max=50"`
+	PriceCoins *int64    `json:"price_coins" binding:"omitempty,min=1,max=1000000"`
+	Perks      *[]string `json:"perks" binding:"omitempty,max=10,dive,max=100"`
+	Active     *bool     `json:"active"`
+}
+
+// SubscribeRequest represents subscribing to a creator's tier
+type SubscribeRequest struct {
+	TierID int64 `json:"tier_id" binding:"required"`
+}
+
+// CancelEventRequest represents a creator canceling a ticketed event
+type CancelEventRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// UpdateRoleRequest represents a role change made by an admin
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// GrantPermissionRequest represents an individual permission grant
+type GrantPermissionRequest struct {
+	Permission string `json:"permission" binding:"required"`
+}
+
+// AccountActionRequest represents a moderation action on an account
+type AccountActionRequest struct {
+	Reason string     `json:"reason" binding:"required,min=3,max=1000"`
+	Until  *time.Time `json:"until"`
+}
+
+// ContentActionRequest represents a moderation action on a stream or video
+type ContentActionRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=1000"`
+}
+
+// UpdateRoomSettingsRequest represents a change to a stream's room settings
+type UpdateRoomSettingsRequest struct {
+	SlowModeSeconds      int      `json:"slow_mode_seconds" binding:"min=0,max=3600"`
+	VerifiedOnly         bool     `json:"verified_only"`
+	MinAccountAgeMinutes int      `json:"min_account_age_minutes" binding:"min=0,max=525600"`
+	BannedWords          []string `json:"banned_words" binding:"max=500,dive,min=1,max=100"`
+	BannedPatterns       []string `json:"banned_patterns" binding:"max=50,dive,min=1,max=200"`
+}
+
+// RoomMessageRequest represents a chat message sent to a stream
+type RoomMessageRequest struct {
+	Text string `json:"text" binding:"required,min=1,max=500"`
+}
+
+// ChannelBanRequest represents a ban or, with a duration, a timeout
+type ChannelBanRequest struct {
+	UserID          int64  `json:"user_id" binding:"required"`
+	Reason          string `json:"reason" binding:"max=500"`
+	DurationSeconds int    `json:"duration_seconds" binding:"min=0,max=1209600"`
+}
+
+// ChannelModeratorRequest represents appointing a channel moderator
+type ChannelModeratorRequest struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -0,0 +1,199 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/textnorm"
+)
+
+// DefaultReviewWords are spam and scam phrases held for review when no
+// review list is configured
+var DefaultReviewWords = []string{
+	"free coins",
+	"free gifts",
+	"free followers",
+	"gift card",
+	"crypto giveaway",
+	"double your money",
+	"click the link",
+	"link in bio",
+	"dm me for",
+	"whatsapp me",
+	"telegram me",
+}
+
+// Link and phone heuristics run on folded text, so full-width and other
+// compatibility characters cannot be used to dodge them
+var (
+	linkPattern = regexp.MustCompile(
+		`\b(?:https?://|www\.)\S+` +
+			`|\b[a-z0-9][a-z0-9-]*\.(?:com|net|org|io|gg|tv|me|ly|co|xyz|info|biz|ru|tk|link|app|site|online|shop)\b` +
+			`|\b[a-z0-9][a-z0-9-]*\s*[(\[]?\s*dot\s*[)\]]?\s*(?:com|net|org|io)\b`)
+	shortenerPattern = regexp.MustCompile(`\b(?:bit\.ly|tinyurl\.com|t\.co|goo\.gl|is\.gd|ow\.ly|cutt\.ly|rb\.gy)\b`)
+	phonePattern     = regexp.MustCompile(
+		`\+?\(?\d{3}\)?[\s.\-]?\d{3}[\s.\-]?\d{4}\b` +
+			`|\+\d{1,3}[\s.\-]?\d(?:[\s.\-]?\d){6,13}`)
+)
+
+const (
+	// maxLinks is how many links a bio or description may carry before it
+	// looks like spam
+	maxLinks = 3
+	// minShoutingLetters is the shortest text checked for shouting
+	minShoutingLetters = 12
+	// maxRepeatedRunes is the longest run of one character allowed
+	maxRepeatedRunes = 5
+	// minRepeatedWords is how many times one word must appear, making up at
+	// least half of the text, to count as spam
+	minRepeatedWords = 4
+)
+
+// LocalClassifier screens text in-process with word lists and heuristics for
+// links, phone numbers and spam. Word lists are matched on whole words after
+// folding with textnorm, both as written and with leetspeak undone.
+type LocalClassifier struct {
+	rejectWords []string
+	reviewWords []string
+}
+
+// NewLocalClassifier creates a classifier that rejects text containing any of
+// rejectWords and holds text containing any of reviewWords
+func NewLocalClassifier(rejectWords, reviewWords []string) *LocalClassifier {
+	return &LocalClassifier{
+		rejectWords: rejectWords,
+		reviewWords: reviewWords,
+	}
+}
+
+// LoadWordList reads a word list with one word or phrase per line. Blank
+// lines and lines starting with # are skipped.
+func LoadWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open word list: %w", err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read word list: %w", err)
+	}
+
+	return words, nil
+}
+
+// Classify screens text for a field. Links and phone numbers are judged by
+// where they appear: a link makes a display name unacceptable but is normal
+// in a bio.
+func (c *LocalClassifier) Classify(_ context.Context, field Field, text string) (*Verdict, error) {
+	v := &Verdict{Decision: Allow}
+	folded := textnorm.Fold(text)
+	unleet := textnorm.UnLeet(text)
+
+	if containsAny(folded, unleet, c.rejectWords) {
+		v.flag(Reject, "blocked_word")
+	}
+	if containsAny(folded, unleet, c.reviewWords) {
+		v.flag(Hold, "flagged_word")
+	}
+
+	// Chat moves too fast for links or phone numbers to wait on review
+	if field != FieldChatMessage {
+		if links := len(linkPattern.FindAllString(folded, -1)); links > 0 {
+			switch field {
+			case FieldDisplayName:
+				v.flag(Reject, "link")
+			case FieldVideoTitle:
+				v.flag(Hold, "link")
+			default:
+				if links > maxLinks {
+					v.flag(Hold, "too_many_links")
+				}
+			}
+		}
+		if shortenerPattern.MatchString(folded) {
+			v.flag(Hold, "link_shortener")
+		}
+		if phonePattern.MatchString(folded) {
+			v.flag(Hold, "phone_number")
+		}
+	}
+
+	if isShouting(text) || hasRepeatedRunes(text) || hasRepeatedWords(folded) {
+		v.flag(Hold, "spam")
+	}
+
+	return v, nil
+}
+
+func containsAny(folded, unleet string, words []string) bool {
+	for _, word := range words {
+		if textnorm.ContainsPhrase(folded, word) || textnorm.ContainsPhrase(unleet, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// isShouting reports whether most letters in a long enough text are upper case
+func isShouting(text string) bool {
+	var letters, upper int
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= minShoutingLetters && upper*10 > letters*7
+}
+
+// hasRepeatedRunes reports whether any character other than a space repeats
+// more than maxRepeatedRunes times in a row
+func hasRepeatedRunes(text string) bool {
+	var prev rune
+	run := 0
+	for _, r := range text {
+		if r == prev && !unicode.IsSpace(r) {
+			run++
+			if run > maxRepeatedRunes {
+				return true
+			}
+			continue
+		}
+		prev, run = r, 1
+	}
+	return false
+}
+
+// hasRepeatedWords reports whether a single word dominates the text
+func hasRepeatedWords(folded string) bool {
+	words := textnorm.Words(folded)
+	if len(words) < minRepeatedWords {
+		return false
+	}
+
+	counts := make(map[string]int)
+	for _, w := range words {
+		counts[w]++
+		if counts[w] >= minRepeatedWords && counts[w]*2 >= len(words) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLocalClassifier(t *testing.T) {
+	classifier := NewLocalClassifier([]string{"slur"}, DefaultReviewWords)
+
+	tests := []struct {
+		name     string
+		field    Field
+		text     string
+		decision Decision
+	}{
+		{"Clean", FieldVideoTitle, "Late night coding stream", Allow},
+		{"RejectWord", FieldBio, "you are a slur", Reject},
+		{"RejectWordLeet", FieldBio, "you are a $lur", Reject},
+		{"RejectWordHomoglyph", FieldDisplayName, "ѕlur", Reject},
+		{"ReviewPhrase", FieldVideoDescription, "Get FREE coins now", Hold},
+		{"ReviewPhraseLeet", FieldVideoDescription, "get fr33 c0!ns", Hold},
+		{"LinkInDisplayName", FieldDisplayName, "visit example.com", Reject},
+		{"LinkInTitle", FieldVideoTitle, "Stream at https://example.com", Hold},
+		{"ObfuscatedLink", FieldVideoTitle, "example dot com", Hold},
+		{"LinkInBio", FieldBio, "My site: https://example.com", Allow},
+		{"TooManyLinks", FieldBio, "a.com b.com c.com d.com", Hold},
+		{"Shortener", FieldBio, "bit.ly/abc", Hold},
+		{"Phone", FieldBio, "call me 555-123-4567", Hold},
+		{"PhoneFullwidth", FieldBio, "call ５５５１２３４５６７", Hold},
+		{"Shouting", FieldVideoTitle, "BEST STREAM EVER WATCH NOW", Hold},
+		{"RepeatedRunes", FieldVideoTitle, "hellooooooooo", Hold},
+		{"RepeatedWords", FieldVideoDescription, "buy buy buy buy now", Hold},
+		{"LinkInChat", FieldChatMessage, "see example.com", Allow},
+		{"NumbersNotLeet", FieldVideoTitle, "Top 10 games of 2024", Allow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := classifier.Classify(context.Background(), tt.field, tt.text)
+			if err != nil {
+				t.Fatalf("Failed to classify: %v", err)
+			}
+			if verdict.Decision != tt.decision {
+				t.Errorf("Expected %s for %q, got %s (%v)", tt.decision, tt.text, verdict.Decision, verdict.Reasons)
+			}
+		})
+	}
+}
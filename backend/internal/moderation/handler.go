@@ -0,0 +1,133 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles review queue HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new moderation handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetQueue handles listing held items
+// @Summary List the moderation review queue
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Item status" default(pending)
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.ModerationItem
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /admin/moderation/queue [get]
+func (h *Handler) GetQueue(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	items, err := h.service.GetQueue(c.Request.Context(), c.DefaultQuery("status", string(StatusPending)), limit, offset)
+	if err != nil {
+		respondError(c, err, "Failed to get moderation queue")
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// ApproveItem handles approving held text, which is then published
+// @Summary Approve a held item
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Item ID"
+// @Success 200 {object} models.ModerationItem
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/moderation/queue/{id}/approve [post]
+func (h *Handler) ApproveItem(c *gin.Context) {
+	h.resolve(c, h.service.Approve)
+}
+
+// RejectItem handles rejecting held text
+// @Summary Reject a held item
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Item ID"
+// @Success 200 {object} models.ModerationItem
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/moderation/queue/{id}/reject [post]
+func (h *Handler) RejectItem(c *gin.Context) {
+	h.resolve(c, h.service.Decline)
+}
+
+func (h *Handler) resolve(c *gin.Context, decide func(ctx context.Context, id, reviewerID int64) (*models.ModerationItem, error)) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid item ID",
+		})
+		return
+	}
+
+	item, err := decide(c.Request.Context(), id, c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to review item")
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// RespondRejected writes a 422 response for text refused by the moderator and
+// reports whether err was such an error
+func RespondRejected(c *gin.Context, err error) bool {
+	var rejected *RejectedError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+		Error:   "content_rejected",
+		Message: rejected.Error(),
+	})
+	return true
+}
+
+// respondError maps moderation service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	switch err {
+	case ErrItemNotFound:
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	case ErrInvalidStatus:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_status",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: message,
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+)
+
+// Decision is the outcome of screening a piece of text
+type Decision string
+
+const (
+	Allow  Decision = "allow"
+	Hold   Decision = "hold"
+	Reject Decision = "reject"
+)
+
+// severity orders decisions so that the strictest one wins
+func (d Decision) severity() int {
+	switch d {
+	case Reject:
+		return 2
+	case Hold:
+		return 1
+	}
+	return 0
+}
+
+// Field identifies a piece of user-supplied text that is screened
+type Field string
+
+const (
+	FieldDisplayName      Field = "user.display_name"
+	FieldBio              Field = "user.bio"
+	FieldVideoTitle       Field = "video.title"
+	FieldVideoDescription Field = "video.description"
+	FieldChatMessage      Field = "chat.message"
+)
+
+// Name returns the field name without its resource prefix, as used in API
+// responses
+func (f Field) Name() string {
+	if i := strings.IndexByte(string(f), '.'); i >= 0 {
+		return string(f)[i+1:]
+	}
+	return string(f)
+}
+
+// Verdict is a moderator's decision on a piece of text and why it was made
+type Verdict struct {
+	Decision Decision
+	Reasons  []string
+}
+
+// flag records a reason and raises the decision if it is stricter
+func (v *Verdict) flag(decision Decision, reason string) {
+	if decision.severity() > v.Decision.severity() {
+		v.Decision = decision
+	}
+	for _, r := range v.Reasons {
+		if r == reason {
+			return
+		}
+	}
+	v.Reasons = append(v.Reasons, reason)
+}
+
+// Moderator classifies user-supplied text. LocalClassifier is the built-in
+// implementation; an external moderation service can be plugged in instead.
+type Moderator interface {
+	Classify(ctx context.Context, field Field, text string) (*Verdict, error)
+}
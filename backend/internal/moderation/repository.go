@@ -0,0 +1,162 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// applyQueries write approved text to the field it was submitted for. Only
+// fields listed here can be approved.
+var applyQueries = map[Field]string{
+	FieldDisplayName:      `UPDATE users SET display_name = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+	FieldBio:              `UPDATE users SET bio = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+	FieldVideoTitle:       `UPDATE videos SET title = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+	FieldVideoDescription: `UPDATE videos SET description = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`,
+}
+
+const itemColumns = `id, field, subject_id, COALESCE(author_id, 0), text, reasons, status, reviewer_id, reviewed_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanItem(row rowScanner) (*models.ModerationItem, error) {
+	item := &models.ModerationItem{}
+	var reasons []byte
+	err := row.Scan(
+		&item.ID,
+		&item.Field,
+		&item.SubjectID,
+		&item.AuthorID,
+		&item.Text,
+		&reasons,
+		&item.Status,
+		&item.ReviewerID,
+		&item.ReviewedAt,
+		&item.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(reasons, &item.Reasons); err != nil {
+		return nil, fmt.Errorf("failed to decode reasons: %w", err)
+	}
+
+	return item, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// EnqueueItem adds an item to the review queue, superseding any item still
+// pending for the same field and subject
+func (r *PostgresRepository) EnqueueItem(ctx context.Context, item *models.ModerationItem) error {
+	reasons, err := json.Marshal(item.Reasons)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE moderation_queue
+		SET status = 'superseded'
+		WHERE field = $1 AND subject_id = $2 AND status = 'pending'
+	`, item.Field, item.SubjectID)
+	if err != nil {
+		return fmt.Errorf("failed to supersede pending items: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO moderation_queue (field, subject_id, author_id, text, reasons, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, item.Field, item.SubjectID, item.AuthorID, item.Text, reasons, item.Status).Scan(&item.ID, &item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue item: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetItems retrieves queued items with a status, oldest first
+func (r *PostgresRepository) GetItems(ctx context.Context, status string, limit, offset int) ([]*models.ModerationItem, error) {
+	query := `
+		SELECT ` + itemColumns + `
+		FROM moderation_queue
+		WHERE status = $1
+		ORDER BY created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*models.ModerationItem, 0)
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ResolveItem records a review decision on a pending item, writing the text
+// to its field when approved. It returns sql.ErrNoRows if the item does not
+// exist or is no longer pending.
+func (r *PostgresRepository) ResolveItem(ctx context.Context, id, reviewerID int64, status string) (*models.ModerationItem, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE moderation_queue
+		SET status = $1, reviewer_id = $2, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND status = 'pending'
+		RETURNING ` + itemColumns
+
+	item, err := scanItem(tx.QueryRowContext(ctx, query, status, reviewerID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	if status == string(StatusApproved) {
+		apply, ok := applyQueries[Field(item.Field)]
+		if !ok {
+			return nil, fmt.Errorf("cannot apply text to field %q", item.Field)
+		}
+		if _, err := tx.ExecContext(ctx, apply, item.Text, item.SubjectID); err != nil {
+			return nil, fmt.Errorf("failed to apply approved text: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return item, nil
+}
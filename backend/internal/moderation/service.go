@@ -0,0 +1,193 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// ItemStatus is the review state of a held item
+type ItemStatus string
+
+const (
+	StatusPending    ItemStatus = "pending"
+	StatusApproved   ItemStatus = "approved"
+	StatusRejected   ItemStatus = "rejected"
+	StatusSuperseded ItemStatus = "superseded"
+)
+
+var (
+	ErrItemNotFound  = errors.New("moderation item not found or already reviewed")
+	ErrInvalidStatus = errors.New("invalid moderation status")
+)
+
+// RejectedError reports text refused by the moderator and why
+type RejectedError struct {
+	Field   Field
+	Reasons []string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s was rejected: %s", e.Field.Name(), strings.Join(e.Reasons, ", "))
+}
+
+// Repository defines the interface for the review queue
+type Repository interface {
+	EnqueueItem(ctx context.Context, item *models.ModerationItem) error
+	GetItems(ctx context.Context, status string, limit, offset int) ([]*models.ModerationItem, error)
+	ResolveItem(ctx context.Context, id, reviewerID int64, status string) (*models.ModerationItem, error)
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, msg *notifications.Message) error
+}
+
+// Service screens user-supplied text and manages the review queue
+type Service struct {
+	moderator Moderator
+	repo      Repository
+	notifier  Notifier
+}
+
+// NewService creates a new moderation service. Authors are notified through
+// notifier when their held text is reviewed.
+func NewService(moderator Moderator, repo Repository, notifier Notifier) *Service {
+	return &Service{
+		moderator: moderator,
+		repo:      repo,
+		notifier:  notifier,
+	}
+}
+
+// Check classifies text submitted for a field. Rejected text returns a
+// *RejectedError. Held text should not be shown; pass it to Hold once the
+// subject it belongs to has been saved.
+func (s *Service) Check(ctx context.Context, field Field, text string) (*Verdict, error) {
+	if strings.TrimSpace(text) == "" {
+		return &Verdict{Decision: Allow}, nil
+	}
+
+	verdict, err := s.moderator.Classify(ctx, field, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify %s: %w", field.Name(), err)
+	}
+	if verdict.Decision == Reject {
+		return verdict, &RejectedError{Field: field, Reasons: verdict.Reasons}
+	}
+
+	return verdict, nil
+}
+
+// Hold queues text for review. Approving it writes it to the field of the
+// subject (a user or video ID). Text still pending for the same field is
+// superseded.
+func (s *Service) Hold(ctx context.Context, field Field, subjectID, authorID int64, text string, verdict *Verdict) error {
+	item := &models.ModerationItem{
+		Field:     string(field),
+		SubjectID: subjectID,
+		AuthorID:  authorID,
+		Text:      text,
+		Reasons:   verdict.Reasons,
+		Status:    string(StatusPending),
+	}
+	if item.Reasons == nil {
+		item.Reasons = []string{}
+	}
+
+	return s.repo.EnqueueItem(ctx, item)
+}
+
+// Screen checks text replacing current as the value of a field and returns
+// the value to store now, and whether the text was held. Held text is queued
+// and the current value kept until it is approved.
+func (s *Service) Screen(ctx context.Context, field Field, subjectID, authorID int64, text, current string) (string, bool, error) {
+	if text == current {
+		return current, false, nil
+	}
+
+	verdict, err := s.Check(ctx, field, text)
+	if err != nil {
+		return "", false, err
+	}
+	if verdict.Decision != Hold {
+		return text, false, nil
+	}
+
+	if err := s.Hold(ctx, field, subjectID, authorID, text, verdict); err != nil {
+		return "", false, err
+	}
+	return current, true, nil
+}
+
+// GetQueue lists held items with the given status, oldest first
+func (s *Service) GetQueue(ctx context.Context, status string, limit, offset int) ([]*models.ModerationItem, error) {
+	switch ItemStatus(status) {
+	case StatusPending, StatusApproved, StatusRejected, StatusSuperseded:
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	items, err := s.repo.GetItems(ctx, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation queue: %w", err)
+	}
+	return items, nil
+}
+
+// Approve accepts a pending item and writes its text to the field it was
+// submitted for
+func (s *Service) Approve(ctx context.Context, id, reviewerID int64) (*models.ModerationItem, error) {
+	return s.resolve(ctx, id, reviewerID, StatusApproved)
+}
+
+// Decline rejects a pending item; the field keeps its current value
+func (s *Service) Decline(ctx context.Context, id, reviewerID int64) (*models.ModerationItem, error) {
+	return s.resolve(ctx, id, reviewerID, StatusRejected)
+}
+
+func (s *Service) resolve(ctx context.Context, id, reviewerID int64, status ItemStatus) (*models.ModerationItem, error) {
+	item, err := s.repo.ResolveItem(ctx, id, reviewerID, string(status))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrItemNotFound
+		}
+		return nil, fmt.Errorf("failed to resolve moderation item: %w", err)
+	}
+
+	s.notifyAuthor(ctx, item, status)
+	return item, nil
+}
+
+// notifyAuthor tells the author of a reviewed item the outcome. Failures are
+// logged: the review is already recorded.
+func (s *Service) notifyAuthor(ctx context.Context, item *models.ModerationItem, status ItemStatus) {
+	field := strings.ReplaceAll(Field(item.Field).Name(), "_", " ")
+	title := fmt.Sprintf("Your %s was approved", field)
+	body := "It is now visible to everyone."
+	if status == StatusRejected {
+		title = fmt.Sprintf("Your %s was not approved", field)
+		body = "It goes against our community guidelines, so the previous version is still shown."
+	}
+
+	err := s.notifier.Notify(ctx, item.AuthorID, &notifications.Message{
+		Type:  notifications.TypeModerationOutcome,
+		Title: title,
+		Body:  body,
+		Data: map[string]string{
+			"field":      item.Field,
+			"subject_id": strconv.FormatInt(item.SubjectID, 10),
+			"status":     item.Status,
+		},
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to notify user %d of moderation item %d: %v", item.AuthorID, item.ID, err)
+	}
+}
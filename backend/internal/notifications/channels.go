@@ -0,0 +1,44 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+)
+
+// Push platforms
+const (
+	PlatformAPNs = "apns"
+	PlatformFCM  = "fcm"
+)
+
+// ErrInvalidToken is returned by push senders for device tokens the push
+// service no longer accepts; the device is then unregistered
+var ErrInvalidToken = errors.New("device token is no longer valid")
+
+// PushMessage is a push notification to one device. It carries what both
+// APNs (alert title and body, custom keys) and FCM (notification and data
+// payloads) need.
+type PushMessage struct {
+	Platform string
+	Token    string
+	Title    string
+	Body     string
+	Data     map[string]string
+}
+
+// PushSender delivers push notifications through APNs or FCM
+type PushSender interface {
+	Send(ctx context.Context, message *PushMessage) error
+}
+
+// EmailMessage is a plain text email to one recipient
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// EmailSender delivers email
+type EmailSender interface {
+	Send(ctx context.Context, message *EmailMessage) error
+}
@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// FakePushSender is an in-process PushSender for development and tests. It
+// logs and records every message instead of sending it; tokens added with
+// Invalidate are refused with ErrInvalidToken.
+type FakePushSender struct {
+	mu      sync.Mutex
+	sent    []*PushMessage
+	invalid map[string]bool
+}
+
+// NewFakePushSender creates a new fake push sender
+func NewFakePushSender() *FakePushSender {
+	return &FakePushSender{invalid: make(map[string]bool)}
+}
+
+// Invalidate makes the push service refuse a device token
+func (f *FakePushSender) Invalidate(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.invalid[token] = true
+}
+
+// Sent returns the messages sent so far
+func (f *FakePushSender) Sent() []*PushMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*PushMessage(nil), f.sent...)
+}
+
+// Send records a push notification
+func (f *FakePushSender) Send(ctx context.Context, message *PushMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.invalid[message.Token] {
+		return ErrInvalidToken
+	}
+	copied := *message
+	f.sent = append(f.sent, &copied)
+	logger.InfoLogger.Printf("Push (%s): %s - %s", message.Platform, message.Title, message.Body)
+	return nil
+}
+
+// FakeEmailSender is an in-process EmailSender for development and tests. It
+// logs and records every message, body included, instead of sending it.
+type FakeEmailSender struct {
+	mu   sync.Mutex
+	sent []*EmailMessage
+}
+
+// NewFakeEmailSender creates a new fake email sender
+func NewFakeEmailSender() *FakeEmailSender {
+	return &FakeEmailSender{}
+}
+
+// Sent returns the messages sent so far
+func (f *FakeEmailSender) Sent() []*EmailMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*EmailMessage(nil), f.sent...)
+}
+
+// Send records an email
+func (f *FakeEmailSender) Send(ctx context.Context, message *EmailMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	copied := *message
+	f.sent = append(f.sent, &copied)
+	logger.InfoLogger.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// FileEmailSender is an EmailSender for local development that appends every
+// message to a file instead of sending it
+type FileEmailSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileEmailSender creates a new email sender writing to the given file
+func NewFileEmailSender(path string) *FileEmailSender {
+	return &FileEmailSender{path: path}
+}
+
+// Send appends an email to the file
+func (f *FileEmailSender) Send(ctx context.Context, message *EmailMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().UTC().Format(time.RFC1123Z), message.To, message.Subject, message.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
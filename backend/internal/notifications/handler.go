@@ -0,0 +1,227 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles notification HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new notification handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetInbox handles getting the current user's notifications
+// @Summary Get my notifications
+// @Description Newest first; pass the last ID seen as before_id for the next page
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit" default(20)
+// @Param before_id query int false "Only notifications older than this ID"
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} models.NotificationInbox
+// @Failure 401 {object} models.ErrorResponse
+// @Router /notifications [get]
+func (h *Handler) GetInbox(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	beforeID, _ := strconv.ParseInt(c.DefaultQuery("before_id", "0"), 10, 64)
+	unreadOnly := c.Query("unread") == "true"
+
+	inbox, err := h.service.GetInbox(c.Request.Context(), c.GetInt64("user_id"), beforeID, unreadOnly, limit)
+	if err != nil {
+		respondError(c, err, "Failed to get notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, inbox)
+}
+
+// MarkRead handles marking notifications read
+// @Summary Mark notifications read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MarkNotificationsReadRequest true "Notifications to mark"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /notifications/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	var req models.MarkNotificationsReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.MarkRead(c.Request.Context(), c.GetInt64("user_id"), &req); err != nil {
+		respondError(c, err, "Failed to mark notifications read")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Notifications marked read",
+	})
+}
+
+// GetPreferences handles getting the current user's delivery preferences
+// @Summary Get my notification preferences
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.NotificationPreference
+// @Failure 401 {object} models.ErrorResponse
+// @Router /notifications/preferences [get]
+func (h *Handler) GetPreferences(c *gin.Context) {
+	preferences, err := h.service.GetPreferences(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences handles changing the current user's delivery preferences
+// @Summary Update my notification preferences
+// @Description Types not listed are left unchanged
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateNotificationPreferencesRequest true "Preferences"
+// @Success 200 {array} models.NotificationPreference
+// @Failure 400 {object} models.ErrorResponse
+// @Router /notifications/preferences [put]
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to update preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// GetDevices handles getting the current user's push devices
+// @Summary Get my push devices
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.PushDevice
+// @Failure 401 {object} models.ErrorResponse
+// @Router /notifications/devices [get]
+func (h *Handler) GetDevices(c *gin.Context) {
+	devices, err := h.service.GetDevices(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get devices")
+		return
+	}
+
+	c.JSON(http.StatusOK, devices)
+}
+
+// RegisterDevice handles registering a device for push notifications
+// @Summary Register a push device
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.RegisterPushDeviceRequest true "Device token"
+// @Success 200 {object} models.PushDevice
+// @Failure 400 {object} models.ErrorResponse
+// @Router /notifications/devices [post]
+func (h *Handler) RegisterDevice(c *gin.Context) {
+	var req models.RegisterPushDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	device, err := h.service.RegisterDevice(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to register device")
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+// UnregisterDevice handles stopping push notifications to a device
+// @Summary Unregister a push device
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Device ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /notifications/devices/{id} [delete]
+func (h *Handler) UnregisterDevice(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid device ID",
+		})
+		return
+	}
+
+	if err := h.service.UnregisterDevice(c.Request.Context(), c.GetInt64("user_id"), id); err != nil {
+		respondError(c, err, "Failed to unregister device")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Device unregistered",
+	})
+}
+
+// respondError maps notification service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrUnknownType):
+		status, code = http.StatusBadRequest, "unknown_type"
+	case errors.Is(err, ErrNothingToMark):
+		status, code = http.StatusBadRequest, "invalid_request"
+	case errors.Is(err, ErrDeviceNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,310 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+const notificationColumns = `id, user_id, type, title, body, data, read_at, created_at`
+
+const deviceColumns = `id, user_id, platform, token, created_at, last_seen_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	notification := &models.Notification{}
+	var data []byte
+	err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.Title,
+		&notification.Body,
+		&data,
+		&notification.ReadAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &notification.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode notification data: %w", err)
+	}
+	return notification, nil
+}
+
+func scanDevice(row rowScanner) (*models.PushDevice, error) {
+	device := &models.PushDevice{}
+	err := row.Scan(
+		&device.ID,
+		&device.UserID,
+		&device.Platform,
+		&device.Token,
+		&device.CreatedAt,
+		&device.LastSeenAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// CreateNotifications stores a copy of the notification in the inbox of
+// each user
+func (r *PostgresRepository) CreateNotifications(ctx context.Context, userIDs []int64, notification *models.Notification) error {
+	data := notification.Data
+	if data == nil {
+		data = map[string]string{}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification data: %w", err)
+	}
+
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, data)
+		SELECT user_id, $2, $3, $4, $5
+		FROM unnest($1::bigint[]) AS user_id
+	`
+	_, err = r.db.ExecContext(ctx, query, userIDs, notification.Type, notification.Title, notification.Body, encoded)
+	return err
+}
+
+// GetNotifications retrieves a user's notifications, newest first, with IDs
+// below beforeID (0 for no bound)
+func (r *PostgresRepository) GetNotifications(ctx context.Context, userID, beforeID int64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1
+			AND ($2 = 0 OR id < $2)
+			AND (NOT $3 OR read_at IS NULL)
+		ORDER BY id DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, beforeID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread counts a user's unread notifications
+func (r *PostgresRepository) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks a user's listed notifications read; IDs of other users'
+// notifications are ignored
+func (r *PostgresRepository) MarkRead(ctx context.Context, userID int64, ids []int64) error {
+	query := `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE user_id = $1 AND id = ANY($2) AND read_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, ids)
+	return err
+}
+
+// MarkAllRead marks all of a user's notifications read
+func (r *PostgresRepository) MarkAllRead(ctx context.Context, userID int64) error {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// GetPreferences retrieves a user's stored preferences
+func (r *PostgresRepository) GetPreferences(ctx context.Context, userID int64) ([]*models.NotificationPreference, error) {
+	query := `SELECT type, in_app, push, email FROM notification_preferences WHERE user_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var preferences []*models.NotificationPreference
+	for rows.Next() {
+		preference := &models.NotificationPreference{}
+		if err := rows.Scan(&preference.Type, &preference.InApp, &preference.Push, &preference.Email); err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, rows.Err()
+}
+
+// GetPreferencesForType retrieves the stored preferences of the given users
+// for one type, keyed by user ID
+func (r *PostgresRepository) GetPreferencesForType(ctx context.Context, userIDs []int64, notificationType string) (map[int64]*models.NotificationPreference, error) {
+	query := `
+		SELECT user_id, type, in_app, push, email
+		FROM notification_preferences
+		WHERE user_id = ANY($1) AND type = $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userIDs, notificationType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	preferences := make(map[int64]*models.NotificationPreference)
+	for rows.Next() {
+		var userID int64
+		preference := &models.NotificationPreference{}
+		if err := rows.Scan(&userID, &preference.Type, &preference.InApp, &preference.Push, &preference.Email); err != nil {
+			return nil, err
+		}
+		preferences[userID] = preference
+	}
+
+	return preferences, rows.Err()
+}
+
+// UpsertPreferences stores a user's preferences for the listed types in one
+// transaction
+func (r *PostgresRepository) UpsertPreferences(ctx context.Context, userID int64, preferences []models.NotificationPreference) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_preferences (user_id, type, in_app, push, email)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, type) DO UPDATE
+		SET in_app = EXCLUDED.in_app, push = EXCLUDED.push, email = EXCLUDED.email
+	`
+	for _, preference := range preferences {
+		_, err := tx.ExecContext(ctx, query, userID, preference.Type, preference.InApp, preference.Push, preference.Email)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetDevices retrieves the push devices of the given users
+func (r *PostgresRepository) GetDevices(ctx context.Context, userIDs []int64) ([]*models.PushDevice, error) {
+	query := `SELECT ` + deviceColumns + ` FROM push_devices WHERE user_id = ANY($1) ORDER BY id`
+	return r.queryDevices(ctx, query, userIDs)
+}
+
+// GetDevicesByUser retrieves a user's push devices, most recently seen first
+func (r *PostgresRepository) GetDevicesByUser(ctx context.Context, userID int64) ([]*models.PushDevice, error) {
+	query := `SELECT ` + deviceColumns + ` FROM push_devices WHERE user_id = $1 ORDER BY last_seen_at DESC`
+	return r.queryDevices(ctx, query, userID)
+}
+
+// UpsertDevice registers a device token, moving it to the device's user if
+// another user registered it
+func (r *PostgresRepository) UpsertDevice(ctx context.Context, device *models.PushDevice) error {
+	query := `
+		INSERT INTO push_devices (user_id, platform, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, last_seen_at = NOW()
+		RETURNING id, created_at, last_seen_at
+	`
+	return r.db.QueryRowContext(ctx, query, device.UserID, device.Platform, device.Token).
+		Scan(&device.ID, &device.CreatedAt, &device.LastSeenAt)
+}
+
+// DeleteDevice deletes one of a user's devices. It returns ErrDeviceNotFound
+// if the user has no such device.
+func (r *PostgresRepository) DeleteDevice(ctx context.Context, userID, deviceID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM push_devices WHERE id = $1 AND user_id = $2`, deviceID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// DeleteDeviceByToken deletes the device with a token
+func (r *PostgresRepository) DeleteDeviceByToken(ctx context.Context, token string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM push_devices WHERE token = $1`, token)
+	return err
+}
+
+// GetEmails retrieves the email addresses of the given users, keyed by ID
+func (r *PostgresRepository) GetEmails(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, email FROM users WHERE id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var email string
+		if err := rows.Scan(&id, &email); err != nil {
+			return nil, err
+		}
+		emails[id] = email
+	}
+
+	return emails, rows.Err()
+}
+
+func (r *PostgresRepository) queryDevices(ctx context.Context, query string, args ...interface{}) ([]*models.PushDevice, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := make([]*models.PushDevice, 0)
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, rows.Err()
+}
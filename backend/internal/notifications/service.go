@@ -0,0 +1,346 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// Notification types
+const (
+	TypeCreatorLive       = "creator_live"
+	TypeStreamReminder    = "stream_reminder"
+	TypeStreamCanceled    = "stream_canceled"
+	TypeNewLogin          = "new_login"
+	TypeModerationOutcome = "moderation_outcome"
+	TypeAccountLocked     = "account_locked"
+)
+
+// defaults are the delivery preferences of users who have not changed them,
+// in the order types are listed
+var defaults = []models.NotificationPreference{
+	{Type: TypeCreatorLive, InApp: true, Push: true},
+	{Type: TypeStreamReminder, InApp: true, Push: true},
+	{Type: TypeStreamCanceled, InApp: true, Push: true},
+	{Type: TypeNewLogin, InApp: true, Push: true, Email: true},
+	{Type: TypeModerationOutcome, InApp: true, Email: true},
+	{Type: TypeAccountLocked, InApp: true, Push: true, Email: true},
+}
+
+// maxInboxPage bounds how many notifications one inbox page returns
+const maxInboxPage = 100
+
+var (
+	ErrUnknownType    = errors.New("unknown notification type")
+	ErrNothingToMark  = errors.New("ids or all is required")
+	ErrDeviceNotFound = errors.New("device not found")
+)
+
+// Message is a notification to deliver
+type Message struct {
+	Type  string
+	Title string
+	Body  string
+	// Data is passed to the client to act on the notification, such as the
+	// ID of the video to open
+	Data map[string]string
+}
+
+// Repository defines the interface for notification data access
+type Repository interface {
+	// CreateNotifications stores a copy of the notification in the inbox of
+	// each user
+	CreateNotifications(ctx context.Context, userIDs []int64, notification *models.Notification) error
+	GetNotifications(ctx context.Context, userID, beforeID int64, unreadOnly bool, limit int) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID int64, ids []int64) error
+	MarkAllRead(ctx context.Context, userID int64) error
+	GetPreferences(ctx context.Context, userID int64) ([]*models.NotificationPreference, error)
+	// GetPreferencesForType returns the stored preferences of the given users
+	// for one type, keyed by user ID
+	GetPreferencesForType(ctx context.Context, userIDs []int64, notificationType string) (map[int64]*models.NotificationPreference, error)
+	UpsertPreferences(ctx context.Context, userID int64, preferences []models.NotificationPreference) error
+	GetDevices(ctx context.Context, userIDs []int64) ([]*models.PushDevice, error)
+	GetDevicesByUser(ctx context.Context, userID int64) ([]*models.PushDevice, error)
+	UpsertDevice(ctx context.Context, device *models.PushDevice) error
+	DeleteDevice(ctx context.Context, userID, deviceID int64) error
+	DeleteDeviceByToken(ctx context.Context, token string) error
+	// GetEmails returns the email addresses of the given users, keyed by ID
+	GetEmails(ctx context.Context, userIDs []int64) (map[int64]string, error)
+}
+
+// Service delivers notifications to the inbox, push devices and email
+// according to each user's preferences
+type Service struct {
+	repo  Repository
+	push  PushSender
+	email EmailSender
+}
+
+// NewService creates a new notification service
+func NewService(repo Repository, push PushSender, email EmailSender) *Service {
+	return &Service{
+		repo:  repo,
+		push:  push,
+		email: email,
+	}
+}
+
+// Notify delivers a notification to one user
+func (s *Service) Notify(ctx context.Context, userID int64, msg *Message) error {
+	return s.NotifyMany(ctx, []int64{userID}, msg)
+}
+
+// NotifyMany delivers a notification to each user through the channels they
+// enabled for its type. Only failing to store inbox entries is returned;
+// push and email failures are logged so one bad device or address does not
+// hold up everyone else.
+func (s *Service) NotifyMany(ctx context.Context, userIDs []int64, msg *Message) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	fallback, ok := defaultFor(msg.Type)
+	if !ok {
+		return ErrUnknownType
+	}
+
+	stored, err := s.repo.GetPreferencesForType(ctx, userIDs, msg.Type)
+	if err != nil {
+		return fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	var inbox, pushTo, emailTo []int64
+	for _, userID := range userIDs {
+		preference := fallback
+		if p, ok := stored[userID]; ok {
+			preference = *p
+		}
+		if preference.InApp {
+			inbox = append(inbox, userID)
+		}
+		if preference.Push {
+			pushTo = append(pushTo, userID)
+		}
+		if preference.Email {
+			emailTo = append(emailTo, userID)
+		}
+	}
+
+	if len(inbox) > 0 {
+		notification := &models.Notification{
+			Type:  msg.Type,
+			Title: msg.Title,
+			Body:  msg.Body,
+			Data:  msg.Data,
+		}
+		if err := s.repo.CreateNotifications(ctx, inbox, notification); err != nil {
+			return fmt.Errorf("failed to store notifications: %w", err)
+		}
+	}
+	s.sendPush(ctx, pushTo, msg)
+	s.sendEmail(ctx, emailTo, msg)
+	return nil
+}
+
+// GetInbox retrieves a page of a user's notifications, newest first, before
+// the given notification ID (0 for the first page)
+func (s *Service) GetInbox(ctx context.Context, userID, beforeID int64, unreadOnly bool, limit int) (*models.NotificationInbox, error) {
+	if limit <= 0 || limit > maxInboxPage {
+		limit = maxInboxPage
+	}
+
+	notifications, err := s.repo.GetNotifications(ctx, userID, beforeID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return &models.NotificationInbox{
+		Notifications: notifications,
+		UnreadCount:   unread,
+	}, nil
+}
+
+// MarkRead marks the listed notifications, or all of them, read
+func (s *Service) MarkRead(ctx context.Context, userID int64, req *models.MarkNotificationsReadRequest) error {
+	var err error
+	switch {
+	case req.All:
+		err = s.repo.MarkAllRead(ctx, userID)
+	case len(req.IDs) > 0:
+		err = s.repo.MarkRead(ctx, userID, req.IDs)
+	default:
+		return ErrNothingToMark
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return nil
+}
+
+// GetPreferences retrieves a user's delivery preferences for every type
+func (s *Service) GetPreferences(ctx context.Context, userID int64) ([]*models.NotificationPreference, error) {
+	stored, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return merge(stored), nil
+}
+
+// UpdatePreferences changes a user's delivery preferences for the listed
+// types and returns the preferences for every type
+func (s *Service) UpdatePreferences(ctx context.Context, userID int64, req *models.UpdateNotificationPreferencesRequest) ([]*models.NotificationPreference, error) {
+	for _, preference := range req.Preferences {
+		if _, ok := defaultFor(preference.Type); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownType, preference.Type)
+		}
+	}
+
+	if err := s.repo.UpsertPreferences(ctx, userID, req.Preferences); err != nil {
+		return nil, fmt.Errorf("failed to update preferences: %w", err)
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+// RegisterDevice registers a device for push notifications. A token
+// registered by another user moves to this one.
+func (s *Service) RegisterDevice(ctx context.Context, userID int64, req *models.RegisterPushDeviceRequest) (*models.PushDevice, error) {
+	device := &models.PushDevice{
+		UserID:   userID,
+		Platform: req.Platform,
+		Token:    req.Token,
+	}
+	if err := s.repo.UpsertDevice(ctx, device); err != nil {
+		return nil, fmt.Errorf("failed to register device: %w", err)
+	}
+	return device, nil
+}
+
+// GetDevices retrieves the devices a user registered for push notifications
+func (s *Service) GetDevices(ctx context.Context, userID int64) ([]*models.PushDevice, error) {
+	devices, err := s.repo.GetDevicesByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	return devices, nil
+}
+
+// UnregisterDevice stops push notifications to one of a user's devices
+func (s *Service) UnregisterDevice(ctx context.Context, userID, deviceID int64) error {
+	if err := s.repo.DeleteDevice(ctx, userID, deviceID); err != nil {
+		if errors.Is(err, ErrDeviceNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to unregister device: %w", err)
+	}
+	return nil
+}
+
+// sendPush pushes a notification to every device of the given users,
+// unregistering devices whose tokens are no longer valid
+func (s *Service) sendPush(ctx context.Context, userIDs []int64, msg *Message) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	devices, err := s.repo.GetDevices(ctx, userIDs)
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to get push devices for %s notification: %v", msg.Type, err)
+		return
+	}
+
+	for _, device := range devices {
+		err := s.push.Send(ctx, &PushMessage{
+			Platform: device.Platform,
+			Token:    device.Token,
+			Title:    msg.Title,
+			Body:     msg.Body,
+			Data:     withType(msg),
+		})
+		if errors.Is(err, ErrInvalidToken) {
+			if err := s.repo.DeleteDeviceByToken(ctx, device.Token); err != nil {
+				logger.ErrorLogger.Printf("Failed to unregister device %d: %v", device.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			logger.ErrorLogger.Printf("Failed to push %s notification to device %d: %v", msg.Type, device.ID, err)
+		}
+	}
+}
+
+// sendEmail emails a notification to the given users
+func (s *Service) sendEmail(ctx context.Context, userIDs []int64, msg *Message) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	emails, err := s.repo.GetEmails(ctx, userIDs)
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to get email addresses for %s notification: %v", msg.Type, err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		address, ok := emails[userID]
+		if !ok {
+			continue
+		}
+		err := s.email.Send(ctx, &EmailMessage{
+			To:      address,
+			Subject: msg.Title,
+			Body:    msg.Body,
+		})
+		if err != nil {
+			logger.ErrorLogger.Printf("Failed to email %s notification to user %d: %v", msg.Type, userID, err)
+		}
+	}
+}
+
+// defaultFor returns the default preference for a type, and whether the
+// type exists
+func defaultFor(notificationType string) (models.NotificationPreference, bool) {
+	for _, preference := range defaults {
+		if preference.Type == notificationType {
+			return preference, true
+		}
+	}
+	return models.NotificationPreference{}, false
+}
+
+// merge overlays stored preferences on the defaults, dropping stored types
+// that no longer exist
+func merge(stored []*models.NotificationPreference) []*models.NotificationPreference {
+	byType := make(map[string]*models.NotificationPreference, len(stored))
+	for _, preference := range stored {
+		byType[preference.Type] = preference
+	}
+
+	merged := make([]*models.NotificationPreference, len(defaults))
+	for i, preference := range defaults {
+		if p, ok := byType[preference.Type]; ok {
+			merged[i] = p
+			continue
+		}
+		copied := preference
+		merged[i] = &copied
+	}
+	return merged
+}
+
+// withType adds the notification type to a message's data for clients to
+// route on
+func withType(msg *Message) map[string]string {
+	data := make(map[string]string, len(msg.Data)+1)
+	for k, v := range msg.Data {
+		data[k] = v
+	}
+	data["type"] = msg.Type
+	return data
+}
@@ -0,0 +1,206 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// memoryRepo is an in-memory Repository
+type memoryRepo struct {
+	inbox       map[int64][]*models.Notification
+	preferences map[int64]map[string]*models.NotificationPreference
+	devices     []*models.PushDevice
+	emails      map[int64]string
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		inbox:       make(map[int64][]*models.Notification),
+		preferences: make(map[int64]map[string]*models.NotificationPreference),
+		emails:      make(map[int64]string),
+	}
+}
+
+func (r *memoryRepo) CreateNotifications(ctx context.Context, userIDs []int64, notification *models.Notification) error {
+	for _, userID := range userIDs {
+		copied := *notification
+		copied.UserID = userID
+		r.inbox[userID] = append(r.inbox[userID], &copied)
+	}
+	return nil
+}
+
+func (r *memoryRepo) GetNotifications(ctx context.Context, userID, beforeID int64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	return r.inbox[userID], nil
+}
+
+func (r *memoryRepo) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	return int64(len(r.inbox[userID])), nil
+}
+
+func (r *memoryRepo) MarkRead(ctx context.Context, userID int64, ids []int64) error {
+	return nil
+}
+
+func (r *memoryRepo) MarkAllRead(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (r *memoryRepo) GetPreferences(ctx context.Context, userID int64) ([]*models.NotificationPreference, error) {
+	var preferences []*models.NotificationPreference
+	for _, preference := range r.preferences[userID] {
+		preferences = append(preferences, preference)
+	}
+	return preferences, nil
+}
+
+func (r *memoryRepo) GetPreferencesForType(ctx context.Context, userIDs []int64, notificationType string) (map[int64]*models.NotificationPreference, error) {
+	stored := make(map[int64]*models.NotificationPreference)
+	for _, userID := range userIDs {
+		if preference, ok := r.preferences[userID][notificationType]; ok {
+			stored[userID] = preference
+		}
+	}
+	return stored, nil
+}
+
+func (r *memoryRepo) UpsertPreferences(ctx context.Context, userID int64, preferences []models.NotificationPreference) error {
+	if r.preferences[userID] == nil {
+		r.preferences[userID] = make(map[string]*models.NotificationPreference)
+	}
+	for _, preference := range preferences {
+		copied := preference
+		r.preferences[userID][preference.Type] = &copied
+	}
+	return nil
+}
+
+func (r *memoryRepo) GetDevices(ctx context.Context, userIDs []int64) ([]*models.PushDevice, error) {
+	var devices []*models.PushDevice
+	for _, device := range r.devices {
+		for _, userID := range userIDs {
+			if device.UserID == userID {
+				devices = append(devices, device)
+			}
+		}
+	}
+	return devices, nil
+}
+
+func (r *memoryRepo) GetDevicesByUser(ctx context.Context, userID int64) ([]*models.PushDevice, error) {
+	return r.GetDevices(ctx, []int64{userID})
+}
+
+func (r *memoryRepo) UpsertDevice(ctx context.Context, device *models.PushDevice) error {
+	device.ID = int64(len(r.devices) + 1)
+	r.devices = append(r.devices, device)
+	return nil
+}
+
+func (r *memoryRepo) DeleteDevice(ctx context.Context, userID, deviceID int64) error {
+	return nil
+}
+
+func (r *memoryRepo) DeleteDeviceByToken(ctx context.Context, token string) error {
+	for i, device := range r.devices {
+		if device.Token == token {
+			r.devices = append(r.devices[:i], r.devices[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepo) GetEmails(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	return r.emails, nil
+}
+
+func TestNotifyMany(t *testing.T) {
+	logger.Init()
+	repo := newMemoryRepo()
+	push := NewFakePushSender()
+	email := NewFakeEmailSender()
+	svc := NewService(repo, push, email)
+	ctx := context.Background()
+
+	// User 1 keeps the defaults, user 2 only wants email and user 3 has a
+	// device the push service no longer accepts
+	for _, userID := range []int64{1, 2, 3} {
+		repo.emails[userID] = "user@example.com"
+	}
+	repo.devices = []*models.PushDevice{
+		{ID: 1, UserID: 1, Platform: PlatformFCM, Token: "device-1"},
+		{ID: 2, UserID: 2, Platform: PlatformAPNs, Token: "device-2"},
+		{ID: 3, UserID: 3, Platform: PlatformFCM, Token: "device-3"},
+	}
+	push.Invalidate("device-3")
+	if _, err := svc.UpdatePreferences(ctx, 2, &models.UpdateNotificationPreferencesRequest{
+		Preferences: []models.NotificationPreference{{Type: TypeCreatorLive, Email: true}},
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	msg := &Message{Type: TypeCreatorLive, Title: "Live now", Data: map[string]string{"video_id": "7"}}
+	if err := svc.NotifyMany(ctx, []int64{1, 2, 3}, msg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(repo.inbox[1]) != 1 || len(repo.inbox[3]) != 1 {
+		t.Errorf("Expected users with defaults to get an inbox entry")
+	}
+	if len(repo.inbox[2]) != 0 {
+		t.Errorf("Expected no inbox entry for the user who turned it off")
+	}
+
+	sent := push.Sent()
+	if len(sent) != 1 || sent[0].Token != "device-1" {
+		t.Fatalf("Expected one push to device-1, got %d", len(sent))
+	}
+	if sent[0].Data["type"] != TypeCreatorLive || sent[0].Data["video_id"] != "7" {
+		t.Errorf("Unexpected push data %v", sent[0].Data)
+	}
+	if len(email.Sent()) != 1 {
+		t.Errorf("Expected 1 email, got %d", len(email.Sent()))
+	}
+	if len(repo.devices) != 2 {
+		t.Errorf("Expected the invalid device to be unregistered, got %d devices", len(repo.devices))
+	}
+
+	if err := svc.Notify(ctx, 1, &Message{Type: "unknown"}); err != ErrUnknownType {
+		t.Errorf("Expected error %v, got %v", ErrUnknownType, err)
+	}
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	svc := NewService(newMemoryRepo(), NewFakePushSender(), NewFakeEmailSender())
+	ctx := context.Background()
+
+	preferences, err := svc.UpdatePreferences(ctx, 1, &models.UpdateNotificationPreferencesRequest{
+		Preferences: []models.NotificationPreference{{Type: TypeModerationOutcome, InApp: true}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(preferences) != len(defaults) {
+		t.Fatalf("Expected %d preferences, got %d", len(defaults), len(preferences))
+	}
+	for _, preference := range preferences {
+		if preference.Type == TypeModerationOutcome && preference.Email {
+			t.Errorf("Expected moderation email to be turned off")
+		}
+		if preference.Type == TypeNewLogin && !preference.Email {
+			t.Errorf("Expected untouched types to keep their defaults")
+		}
+	}
+
+	_, err = svc.UpdatePreferences(ctx, 1, &models.UpdateNotificationPreferencesRequest{
+		Preferences: []models.NotificationPreference{{Type: "marketing"}},
+	})
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Expected error %v, got %v", ErrUnknownType, err)
+	}
+}
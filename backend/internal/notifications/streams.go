@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// StreamNotifier tells viewers about streams they follow: when a creator
+// goes live and when a scheduled stream is about to start or is canceled
+type StreamNotifier struct {
+	service *Service
+}
+
+// NewStreamNotifier creates a stream notifier delivering through service
+func NewStreamNotifier(service *Service) *StreamNotifier {
+	return &StreamNotifier{service: service}
+}
+
+// StreamLive notifies viewers that a creator went live
+func (n *StreamNotifier) StreamLive(ctx context.Context, stream *models.Video, userIDs []int64) error {
+	return n.service.NotifyMany(ctx, userIDs, &Message{
+		Type:  TypeCreatorLive,
+		Title: "Live now",
+		Body:  stream.Title,
+		Data:  streamData(stream),
+	})
+}
+
+// StreamStarting reminds viewers that a scheduled stream is about to start
+func (n *StreamNotifier) StreamStarting(ctx context.Context, stream *models.Video, userIDs []int64) error {
+	return n.service.NotifyMany(ctx, userIDs, &Message{
+		Type:  TypeStreamReminder,
+		Title: "Starting soon",
+		Body:  fmt.Sprintf("%s starts at %s UTC", stream.Title, stream.ScheduledAt.UTC().Format("15:04")),
+		Data:  streamData(stream),
+	})
+}
+
+// StreamCanceled tells viewers that a scheduled stream was canceled
+func (n *StreamNotifier) StreamCanceled(ctx context.Context, stream *models.Video, userIDs []int64) error {
+	return n.service.NotifyMany(ctx, userIDs, &Message{
+		Type:  TypeStreamCanceled,
+		Title: "Stream canceled",
+		Body:  fmt.Sprintf("%s has been canceled", stream.Title),
+		Data:  streamData(stream),
+	})
+}
+
+func streamData(stream *models.Video) map[string]string {
+	return map[string]string{
+		"video_id":   strconv.FormatInt(stream.ID, 10),
+		"creator_id": strconv.FormatInt(stream.UserID, 10),
+	}
+}
@@ -0,0 +1,160 @@
+package payouts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrPayoutsDisabled is returned when paying out to an account that cannot
+// receive payouts
+var ErrPayoutsDisabled = fmt.Errorf("%w: payouts are disabled for this account", ErrPayoutRejected)
+
+// FakeProvider is an in-process PaymentProvider for development and tests.
+// Like a provider's test mode, accounts are created with payouts enabled and
+// a destination ending in 4242, and onboarding links lead straight to the
+// return URL; unknown accounts are also created on first use. Use AddAccount
+// to set up other accounts. Payouts stay pending until SetPayoutStatus moves
+// them on.
+type FakeProvider struct {
+	mu       sync.Mutex
+	accounts map[string]*ProviderAccount
+	payouts  map[string]*ProviderPayout
+	keys     map[string]string
+	seq      int
+}
+
+// NewFakeProvider creates a new fake payment provider
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		accounts: make(map[string]*ProviderAccount),
+		payouts:  make(map[string]*ProviderPayout),
+		keys:     make(map[string]string),
+	}
+}
+
+// AddAccount adds or replaces a connected account
+func (f *FakeProvider) AddAccount(account *ProviderAccount) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	copied := *account
+	f.accounts[account.ID] = &copied
+}
+
+// GetAccount retrieves a connected account
+func (f *FakeProvider) GetAccount(ctx context.Context, accountID string) (*ProviderAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	account := f.account(accountID)
+	if account == nil {
+		return nil, ErrProviderAccountNotFound
+	}
+	copied := *account
+	return &copied, nil
+}
+
+// CreateAccount creates a connected account that is ready for payouts
+func (f *FakeProvider) CreateAccount(ctx context.Context, params *AccountParams) (*ProviderAccount, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := f.keys[params.IdempotencyKey]; ok && params.IdempotencyKey != "" {
+		copied := *f.accounts[id]
+		return &copied, nil
+	}
+
+	f.seq++
+	account := f.account(fmt.Sprintf("acct_fake%016d", f.seq))
+	if params.IdempotencyKey != "" {
+		f.keys[params.IdempotencyKey] = account.ID
+	}
+
+	copied := *account
+	return &copied, nil
+}
+
+// CreateAccountLink returns the return URL, as onboarding is already done
+func (f *FakeProvider) CreateAccountLink(ctx context.Context, accountID, refreshURL, returnURL string) (*AccountLink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.accounts[accountID]; !ok {
+		return nil, ErrProviderAccountNotFound
+	}
+	return &AccountLink{URL: returnURL, ExpiresAt: time.Now().Add(5 * time.Minute).Unix()}, nil
+}
+
+// CreatePayout creates a pending payout arriving in two days
+func (f *FakeProvider) CreatePayout(ctx context.Context, params *PayoutParams) (*ProviderPayout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := f.keys[params.IdempotencyKey]; ok && params.IdempotencyKey != "" {
+		copied := *f.payouts[id]
+		return &copied, nil
+	}
+
+	account := f.account(params.AccountID)
+	if account == nil {
+		return nil, ErrProviderAccountNotFound
+	}
+	if !account.PayoutsEnabled {
+		return nil, ErrPayoutsDisabled
+	}
+
+	f.seq++
+	payout := &ProviderPayout{
+		ID:               fmt.Sprintf("po_fake%016d", f.seq),
+		AccountID:        account.ID,
+		Amount:           params.Amount,
+		Currency:         params.Currency,
+		Status:           StatusPending,
+		ArrivalDate:      time.Now().Add(48 * time.Hour).Unix(),
+		DestinationLast4: account.DestinationLast4,
+		Metadata:         params.Metadata,
+	}
+	f.payouts[payout.ID] = payout
+	if params.IdempotencyKey != "" {
+		f.keys[params.IdempotencyKey] = payout.ID
+	}
+
+	copied := *payout
+	return &copied, nil
+}
+
+// SetPayoutStatus moves a payout to a new status, as the provider would, and
+// returns the payout to deliver as a webhook update
+func (f *FakeProvider) SetPayoutStatus(payoutID, status, failureCode string) (*ProviderPayout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payout, ok := f.payouts[payoutID]
+	if !ok {
+		return nil, ErrProviderPayoutNotFound
+	}
+	payout.Status = status
+	payout.FailureCode = failureCode
+	if failureCode != "" {
+		payout.FailureMessage = "The bank rejected the payout"
+	}
+
+	copied := *payout
+	return &copied, nil
+}
+
+// account returns a connected account, creating a test mode account for
+// unknown IDs. Callers must hold f.mu.
+func (f *FakeProvider) account(id string) *ProviderAccount {
+	if id == "" {
+		return nil
+	}
+	if account, ok := f.accounts[id]; ok {
+		return account
+	}
+	account := &ProviderAccount{ID: id, PayoutsEnabled: true, DestinationLast4: "4242"}
+	f.accounts[id] = account
+	return account
+}
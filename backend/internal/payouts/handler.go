@@ -0,0 +1,106 @@
+package payouts
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles payout HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new payout handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetPayouts handles getting the current creator's payouts
+// @Summary Get payouts
+// @Tags payouts
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.Payout
+// @Failure 401 {object} models.ErrorResponse
+// @Router /payouts [get]
+func (h *Handler) GetPayouts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	// Limit maximum items per page
+	if limit > 100 {
+		limit = 100
+	}
+
+	payouts, err := h.service.GetPayouts(c.Request.Context(), c.GetInt64("user_id"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get payouts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, payouts)
+}
+
+// GetAccount handles getting the current creator's linked payout account
+// @Summary Get payout account
+// @Tags payouts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.PayoutAccount
+// @Failure 404 {object} models.ErrorResponse
+// @Router /payouts/account [get]
+func (h *Handler) GetAccount(c *gin.Context) {
+	account, err := h.service.GetAccount(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get payout account")
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// LinkAccount handles starting or resuming the current creator's onboarding
+// at the payment provider
+// @Summary Start payout onboarding
+// @Description Creates the creator's provider account on first use and returns a single-use link to the provider's onboarding
+// @Tags payouts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.PayoutOnboarding
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /payouts/account/onboarding [post]
+func (h *Handler) LinkAccount(c *gin.Context) {
+	onboarding, err := h.service.LinkAccount(c.Request.Context(), c.GetInt64("user_id"), c.GetString("user_email"))
+	if err != nil {
+		respondError(c, err, "Failed to start payout onboarding")
+		return
+	}
+
+	c.JSON(http.StatusOK, onboarding)
+}
+
+// respondError maps payout service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrAccountNotLinked):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: message,
+		})
+	}
+}
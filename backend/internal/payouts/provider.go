@@ -0,0 +1,92 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+)
+
+// Payout statuses, as reported by the payment provider
+const (
+	StatusPending   = "pending"
+	StatusInTransit = "in_transit"
+	StatusPaid      = "paid"
+	StatusFailed    = "failed"
+	// StatusCanceled is reported by the provider for payouts cancelled before
+	// they were sent; it is stored as failed
+	StatusCanceled = "canceled"
+)
+
+var (
+	ErrProviderAccountNotFound = errors.New("provider account not found")
+	ErrProviderPayoutNotFound  = errors.New("provider payout not found")
+	// ErrPayoutRejected is wrapped by providers' CreatePayout errors when the
+	// provider definitely did not create the payout, such as a declined
+	// request. Other errors, like timeouts and server errors, may have left
+	// a payout at the provider.
+	ErrPayoutRejected = errors.New("payout rejected by the provider")
+)
+
+// ProviderAccount is a creator's connected account at the payment provider
+type ProviderAccount struct {
+	ID             string
+	PayoutsEnabled bool
+	// DestinationLast4 is the last four digits of the bank account or card
+	// payouts are sent to
+	DestinationLast4 string
+}
+
+// AccountParams describes a connected account to create for a creator.
+// Creating an account again with the same idempotency key returns the
+// original account.
+type AccountParams struct {
+	Email          string
+	IdempotencyKey string
+	Metadata       map[string]string
+}
+
+// AccountLink is a single-use link to the provider's onboarding, where a
+// creator enters their identity and bank details for an account
+type AccountLink struct {
+	URL string
+	// ExpiresAt is when the link stops working, in unix seconds
+	ExpiresAt int64
+}
+
+// PayoutParams describes a payout to create. Creating a payout again with the
+// same idempotency key returns the original payout.
+type PayoutParams struct {
+	AccountID      string
+	Amount         int64
+	Currency       string
+	IdempotencyKey string
+	Metadata       map[string]string
+}
+
+// ProviderPayout is the provider's view of a payout. Provider webhooks
+// deliver these to report status changes.
+type ProviderPayout struct {
+	ID               string
+	AccountID        string
+	Amount           int64
+	Currency         string
+	Status           string
+	ArrivalDate      int64
+	DestinationLast4 string
+	FailureCode      string
+	FailureMessage   string
+	Metadata         map[string]string
+}
+
+// PaymentProvider sends money to creators. It is shaped after Stripe Connect
+// so a Stripe client can implement it directly. CreatePayout returns an
+// error wrapping ErrPayoutRejected or ErrProviderAccountNotFound only when
+// no payout was created.
+type PaymentProvider interface {
+	GetAccount(ctx context.Context, accountID string) (*ProviderAccount, error)
+	CreateAccount(ctx context.Context, params *AccountParams) (*ProviderAccount, error)
+	// CreateAccountLink returns an onboarding link for an account. The
+	// provider sends the creator to returnURL when they finish and to
+	// refreshURL if the link expired, to get a new one.
+	CreateAccountLink(ctx context.Context, accountID, refreshURL, returnURL string) (*AccountLink, error)
+	CreatePayout(ctx context.Context, params *PayoutParams) (*ProviderPayout, error)
+}
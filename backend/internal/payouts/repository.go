@@ -0,0 +1,329 @@
+package payouts
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+)
+
+const payoutColumns = `id, public_id, user_id, provider_account_id, COALESCE(provider_payout_id, ''),
+	amount, currency, status, destination, arrival_date, failure_code, failure_message, entry_id, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayout(row rowScanner) (*models.Payout, error) {
+	payout := &models.Payout{}
+	err := row.Scan(
+		&payout.InternalID,
+		&payout.ID,
+		&payout.UserID,
+		&payout.ProviderAccountID,
+		&payout.ProviderPayoutID,
+		&payout.Amount,
+		&payout.Currency,
+		&payout.Status,
+		&payout.Destination,
+		&payout.ArrivalAt,
+		&payout.FailureCode,
+		&payout.FailureMessage,
+		&payout.EntryID,
+		&payout.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	payout.Created = payout.CreatedAt.Unix()
+	if payout.ArrivalAt != nil {
+		payout.ArrivalDate = payout.ArrivalAt.Unix()
+	}
+	return payout, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// UpsertPayoutAccount links a user to a provider account, replacing any
+// account they linked before
+func (r *PostgresRepository) UpsertPayoutAccount(ctx context.Context, account *models.PayoutAccount) error {
+	query := `
+		INSERT INTO payout_accounts (user_id, provider_account_id, destination_last4, payouts_enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET provider_account_id = EXCLUDED.provider_account_id,
+			destination_last4 = EXCLUDED.destination_last4,
+			payouts_enabled = EXCLUDED.payouts_enabled
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		account.UserID,
+		account.ProviderAccountID,
+		account.DestinationLast4,
+		account.PayoutsEnabled,
+	).Scan(&account.CreatedAt, &account.UpdatedAt)
+}
+
+// GetPayoutAccount retrieves a user's linked payout account
+func (r *PostgresRepository) GetPayoutAccount(ctx context.Context, userID int64) (*models.PayoutAccount, error) {
+	query := `
+		SELECT user_id, provider_account_id, destination_last4, payouts_enabled, created_at, updated_at
+		FROM payout_accounts
+		WHERE user_id = $1
+	`
+
+	account := &models.PayoutAccount{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&account.UserID,
+		&account.ProviderAccountID,
+		&account.DestinationLast4,
+		&account.PayoutsEnabled,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// UpdatePayoutsEnabled records whether a provider account can receive payouts.
+// Unknown accounts are ignored.
+func (r *PostgresRepository) UpdatePayoutsEnabled(ctx context.Context, providerAccountID string, enabled bool, last4 string) error {
+	query := `
+		UPDATE payout_accounts
+		SET payouts_enabled = $2, destination_last4 = COALESCE(NULLIF($3, ''), destination_last4)
+		WHERE provider_account_id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, providerAccountID, enabled, last4)
+	return err
+}
+
+// GetMaturedEarnings retrieves each creator's pending earnings minus what was
+// credited after cutoff
+func (r *PostgresRepository) GetMaturedEarnings(ctx context.Context, cutoff time.Time) ([]*MaturedEarnings, error) {
+	query := `
+		SELECT a.owner_id, a.balance - COALESCE(SUM(p.amount) FILTER (WHERE p.amount > 0 AND p.created_at > $3), 0) AS matured
+		FROM ledger_accounts a
+		LEFT JOIN ledger_postings p ON p.account_id = a.id
+		WHERE a.type = $1 AND a.currency = $2 AND a.owner_id IS NOT NULL AND a.balance > 0
+		GROUP BY a.id
+		HAVING a.balance - COALESCE(SUM(p.amount) FILTER (WHERE p.amount > 0 AND p.created_at > $3), 0) > 0
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, wallet.AccountEarningsPending, wallet.CurrencyUSD, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matured []*MaturedEarnings
+	for rows.Next() {
+		m := &MaturedEarnings{}
+		if err := rows.Scan(&m.UserID, &m.Amount); err != nil {
+			return nil, err
+		}
+		matured = append(matured, m)
+	}
+
+	return matured, rows.Err()
+}
+
+// GetPayableBalances retrieves creators with payouts enabled whose available
+// earnings are at least minAmount
+func (r *PostgresRepository) GetPayableBalances(ctx context.Context, minAmount int64) ([]*PayableBalance, error) {
+	query := `
+		SELECT pa.user_id, pa.provider_account_id, pa.destination_last4, pa.payouts_enabled,
+			pa.created_at, pa.updated_at, a.balance
+		FROM payout_accounts pa
+		JOIN ledger_accounts a ON a.owner_id = pa.user_id
+		WHERE pa.payouts_enabled = TRUE AND a.type = $1 AND a.currency = $2 AND a.balance >= $3
+		ORDER BY pa.user_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, wallet.AccountEarningsAvailable, wallet.CurrencyUSD, minAmount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []*PayableBalance
+	for rows.Next() {
+		b := &PayableBalance{Account: &models.PayoutAccount{}}
+		err := rows.Scan(
+			&b.Account.UserID,
+			&b.Account.ProviderAccountID,
+			&b.Account.DestinationLast4,
+			&b.Account.PayoutsEnabled,
+			&b.Account.CreatedAt,
+			&b.Account.UpdatedAt,
+			&b.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+// CreatePayout posts a payout's journal entry and records the payout in one
+// transaction
+func (r *PostgresRepository) CreatePayout(ctx context.Context, payout *models.Payout, entry *models.JournalEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := wallet.Post(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	payout.EntryID = entry.ID
+	query := `
+		INSERT INTO payouts (public_id, user_id, provider_account_id, amount, currency, status, destination, entry_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		payout.ID,
+		payout.UserID,
+		payout.ProviderAccountID,
+		payout.Amount,
+		payout.Currency,
+		payout.Status,
+		payout.Destination,
+		payout.EntryID,
+	).Scan(&payout.InternalID, &payout.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert payout: %w", err)
+	}
+	payout.Created = payout.CreatedAt.Unix()
+
+	return tx.Commit()
+}
+
+// UpdatePayout saves a payout's provider details and status, posting entry in
+// the same transaction if it is not nil. It reports false without changing
+// anything if the payout is no longer in fromStatus.
+func (r *PostgresRepository) UpdatePayout(ctx context.Context, payout *models.Payout, fromStatus string, entry *models.JournalEntry) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE payouts
+		SET provider_payout_id = NULLIF($3, ''), status = $4, destination = $5, arrival_date = $6,
+			failure_code = $7, failure_message = $8
+		WHERE id = $1 AND status = $2
+	`
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		payout.InternalID,
+		fromStatus,
+		payout.ProviderPayoutID,
+		payout.Status,
+		payout.Destination,
+		payout.ArrivalAt,
+		payout.FailureCode,
+		payout.FailureMessage,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if entry != nil {
+		if _, err := wallet.Post(ctx, tx, entry); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// GetPayoutByProviderID retrieves a payout by the provider's payout ID
+func (r *PostgresRepository) GetPayoutByProviderID(ctx context.Context, providerPayoutID string) (*models.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE provider_payout_id = $1`
+	return scanPayout(r.db.QueryRowContext(ctx, query, providerPayoutID))
+}
+
+// GetPayout retrieves a payout by its public ID
+func (r *PostgresRepository) GetPayout(ctx context.Context, publicID string) (*models.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE public_id = $1`
+	return scanPayout(r.db.QueryRowContext(ctx, query, publicID))
+}
+
+// GetUnsubmittedPayouts retrieves pending payouts that have no provider
+// payout ID, oldest first
+func (r *PostgresRepository) GetUnsubmittedPayouts(ctx context.Context) ([]*models.Payout, error) {
+	query := `
+		SELECT ` + payoutColumns + `
+		FROM payouts
+		WHERE status = 'pending' AND provider_payout_id IS NULL
+		ORDER BY created_at, id
+	`
+	return r.queryPayouts(ctx, query)
+}
+
+// GetPayoutsByUser retrieves a user's payouts, newest first
+func (r *PostgresRepository) GetPayoutsByUser(ctx context.Context, userID int64, limit, offset int) ([]*models.Payout, error) {
+	query := `
+		SELECT ` + payoutColumns + `
+		FROM payouts
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	return r.queryPayouts(ctx, query, userID, limit, offset)
+}
+
+// queryPayouts runs a query selecting payoutColumns
+func (r *PostgresRepository) queryPayouts(ctx context.Context, query string, args ...interface{}) ([]*models.Payout, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payouts := make([]*models.Payout, 0)
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, payout)
+	}
+
+	return payouts, rows.Err()
+}
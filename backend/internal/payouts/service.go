@@ -0,0 +1,492 @@
+package payouts
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// Journal entry kinds for payouts
+const (
+	EntryKindRelease = "earnings_release"
+	EntryKindPayout  = "payout"
+	EntryKindPaid    = "payout_paid"
+	EntryKindFailed  = "payout_failed"
+)
+
+// payoutCurrency is the currency payouts are made in, in the provider's
+// lowercase form
+const payoutCurrency = "usd"
+
+var (
+	ErrPayoutNotFound   = errors.New("payout not found")
+	ErrAccountNotLinked = errors.New("no payout account is linked")
+	ErrPayoutConflict   = errors.New("payout was changed concurrently")
+)
+
+// transitions lists the statuses each payout status can move to. A paid
+// payout can still fail if the bank returns it.
+var transitions = map[string][]string{
+	StatusPending:   {StatusInTransit, StatusPaid, StatusFailed},
+	StatusInTransit: {StatusPaid, StatusFailed},
+	StatusPaid:      {StatusFailed},
+}
+
+// MaturedEarnings is a creator's pending earnings that are past the hold period
+type MaturedEarnings struct {
+	UserID int64
+	Amount int64
+}
+
+// PayableBalance is a creator's available earnings and where to send them
+type PayableBalance struct {
+	Account *models.PayoutAccount
+	Amount  int64
+}
+
+// Repository defines the interface for payout data access
+type Repository interface {
+	UpsertPayoutAccount(ctx context.Context, account *models.PayoutAccount) error
+	GetPayoutAccount(ctx context.Context, userID int64) (*models.PayoutAccount, error)
+	UpdatePayoutsEnabled(ctx context.Context, providerAccountID string, enabled bool, last4 string) error
+	GetMaturedEarnings(ctx context.Context, cutoff time.Time) ([]*MaturedEarnings, error)
+	GetPayableBalances(ctx context.Context, minAmount int64) ([]*PayableBalance, error)
+	CreatePayout(ctx context.Context, payout *models.Payout, entry *models.JournalEntry) error
+	UpdatePayout(ctx context.Context, payout *models.Payout, fromStatus string, entry *models.JournalEntry) (bool, error)
+	GetPayoutByProviderID(ctx context.Context, providerPayoutID string) (*models.Payout, error)
+	GetPayout(ctx context.Context, publicID string) (*models.Payout, error)
+	GetUnsubmittedPayouts(ctx context.Context) ([]*models.Payout, error)
+	GetPayoutsByUser(ctx context.Context, userID int64, limit, offset int) ([]*models.Payout, error)
+}
+
+// OnboardingURLs are the app pages the provider's onboarding sends creators
+// back to
+type OnboardingURLs struct {
+	// ReturnURL is opened when a creator finishes onboarding
+	ReturnURL string
+	// RefreshURL is opened when an onboarding link expired; it should start
+	// onboarding again
+	RefreshURL string
+}
+
+// Service schedules creator payouts and tracks them through the provider
+type Service struct {
+	repo           Repository
+	provider       PaymentProvider
+	wallet         *wallet.Service
+	minPayoutCents int64
+	holdPeriod     time.Duration
+	onboarding     OnboardingURLs
+}
+
+// NewService creates a new payout service. Earnings become payable after
+// holdDays, and are paid out once at least minPayoutCents are available.
+func NewService(repo Repository, provider PaymentProvider, wallet *wallet.Service, minPayoutCents, holdDays int, onboarding OnboardingURLs) *Service {
+	return &Service{
+		repo:           repo,
+		provider:       provider,
+		wallet:         wallet,
+		minPayoutCents: int64(minPayoutCents),
+		holdPeriod:     time.Duration(holdDays) * 24 * time.Hour,
+		onboarding:     onboarding,
+	}
+}
+
+// LinkAccount starts or resumes a creator's onboarding at the payment
+// provider. The provider creates the account, so its ID comes from the
+// provider rather than the creator and no one can claim another creator's
+// account. The account is saved before onboarding is done; provider account
+// updates report when it can receive payouts.
+func (s *Service) LinkAccount(ctx context.Context, userID int64, email string) (*models.PayoutOnboarding, error) {
+	account, err := s.repo.GetPayoutAccount(ctx, userID)
+	if err == sql.ErrNoRows {
+		account, err = s.createAccount(ctx, userID, email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payout account: %w", err)
+	}
+
+	link, err := s.provider.CreateAccountLink(ctx, account.ProviderAccountID, s.onboarding.RefreshURL, s.onboarding.ReturnURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create onboarding link: %w", err)
+	}
+
+	return &models.PayoutOnboarding{URL: link.URL, ExpiresAt: link.ExpiresAt, Account: account}, nil
+}
+
+// createAccount creates a creator's account at the provider and saves it.
+// The idempotency key makes a retry after a failed save return the same
+// account.
+func (s *Service) createAccount(ctx context.Context, userID int64, email string) (*models.PayoutAccount, error) {
+	providerAccount, err := s.provider.CreateAccount(ctx, &AccountParams{
+		Email:          email,
+		IdempotencyKey: fmt.Sprintf("payout_account:%d", userID),
+		Metadata:       map[string]string{"user_id": strconv.FormatInt(userID, 10)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider account: %w", err)
+	}
+
+	account := &models.PayoutAccount{
+		UserID:            userID,
+		ProviderAccountID: providerAccount.ID,
+		DestinationLast4:  providerAccount.DestinationLast4,
+		PayoutsEnabled:    providerAccount.PayoutsEnabled,
+	}
+	if err := s.repo.UpsertPayoutAccount(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to save payout account: %w", err)
+	}
+
+	return account, nil
+}
+
+// GetAccount retrieves a creator's linked payout account
+func (s *Service) GetAccount(ctx context.Context, userID int64) (*models.PayoutAccount, error) {
+	account, err := s.repo.GetPayoutAccount(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAccountNotLinked
+		}
+		return nil, fmt.Errorf("failed to get payout account: %w", err)
+	}
+	return account, nil
+}
+
+// GetPayouts retrieves a creator's payouts, newest first
+func (s *Service) GetPayouts(ctx context.Context, userID int64, limit, offset int) ([]*models.Payout, error) {
+	payouts, err := s.repo.GetPayoutsByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payouts: %w", err)
+	}
+	return payouts, nil
+}
+
+// ApplyAccountUpdate records a change to a connected account reported by the
+// provider, such as payouts being disabled pending verification
+func (s *Service) ApplyAccountUpdate(ctx context.Context, account *ProviderAccount) error {
+	if err := s.repo.UpdatePayoutsEnabled(ctx, account.ID, account.PayoutsEnabled, account.DestinationLast4); err != nil {
+		return fmt.Errorf("failed to update payout account: %w", err)
+	}
+	return nil
+}
+
+// ApplyPayoutUpdate moves a payout to the status reported by the provider.
+// Updates that arrive out of order and would move a payout backwards are
+// ignored. A payout whose provider ID was never saved, because the response
+// to creating it was lost, is found by the payout ID in its metadata.
+func (s *Service) ApplyPayoutUpdate(ctx context.Context, update *ProviderPayout) error {
+	payout, err := s.repo.GetPayoutByProviderID(ctx, update.ID)
+	if err == sql.ErrNoRows && update.Metadata["payout_id"] != "" {
+		payout, err = s.repo.GetPayout(ctx, update.Metadata["payout_id"])
+		if err == nil && payout.ProviderPayoutID != "" {
+			err = sql.ErrNoRows
+		}
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPayoutNotFound
+		}
+		return fmt.Errorf("failed to get payout: %w", err)
+	}
+	return s.apply(ctx, payout, update)
+}
+
+// SchedulePayouts resubmits payouts the provider may not have received,
+// releases earnings that are past the hold period, then pays out every
+// linked creator whose available earnings meet the minimum
+func (s *Service) SchedulePayouts(ctx context.Context) error {
+	if err := s.resubmitPayouts(ctx); err != nil {
+		return err
+	}
+	if err := s.releaseMaturedEarnings(ctx); err != nil {
+		return err
+	}
+
+	balances, err := s.repo.GetPayableBalances(ctx, s.minPayoutCents)
+	if err != nil {
+		return fmt.Errorf("failed to get payable balances: %w", err)
+	}
+
+	for _, balance := range balances {
+		if err := s.createPayout(ctx, balance.Account, balance.Amount); err != nil {
+			logger.ErrorLogger.Printf("Failed to pay out user %d: %v", balance.Account.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunScheduler schedules payouts every interval until ctx is cancelled
+func (s *Service) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SchedulePayouts(ctx); err != nil {
+				logger.ErrorLogger.Printf("Failed to schedule payouts: %v", err)
+			}
+		}
+	}
+}
+
+// CanTransition reports whether a payout can move from one status to another
+func CanTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseMaturedEarnings moves pending earnings older than the hold period to
+// the creators' available earnings. The cutoff is truncated to the hour so
+// that concurrent runs produce the same idempotency keys.
+func (s *Service) releaseMaturedEarnings(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.holdPeriod).Truncate(time.Hour)
+
+	matured, err := s.repo.GetMaturedEarnings(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to get matured earnings: %w", err)
+	}
+
+	for _, m := range matured {
+		pending, err := s.wallet.Account(ctx, m.UserID, wallet.AccountEarningsPending, wallet.CurrencyUSD)
+		if err != nil {
+			return err
+		}
+		available, err := s.wallet.Account(ctx, m.UserID, wallet.AccountEarningsAvailable, wallet.CurrencyUSD)
+		if err != nil {
+			return err
+		}
+
+		entry := &models.JournalEntry{
+			IdempotencyKey: fmt.Sprintf("%s:%d:%d", EntryKindRelease, m.UserID, cutoff.Unix()),
+			Kind:           EntryKindRelease,
+			Description:    fmt.Sprintf("Earnings before %s", cutoff.Format(time.RFC3339)),
+			Postings: []*models.LedgerPosting{
+				{AccountID: pending.ID, Currency: wallet.CurrencyUSD, Amount: -m.Amount},
+				{AccountID: available.ID, Currency: wallet.CurrencyUSD, Amount: m.Amount},
+			},
+		}
+		if err := s.wallet.Post(ctx, entry); err != nil {
+			logger.ErrorLogger.Printf("Failed to release earnings for user %d: %v", m.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// createPayout moves amount out of a creator's available earnings and asks
+// the provider to pay it
+func (s *Service) createPayout(ctx context.Context, account *models.PayoutAccount, amount int64) error {
+	publicID, err := newPayoutID()
+	if err != nil {
+		return err
+	}
+
+	available, err := s.wallet.Account(ctx, account.UserID, wallet.AccountEarningsAvailable, wallet.CurrencyUSD)
+	if err != nil {
+		return err
+	}
+	inFlight, err := s.wallet.PlatformAccount(ctx, wallet.PlatformPayoutsInFlight, wallet.CurrencyUSD)
+	if err != nil {
+		return err
+	}
+
+	payout := &models.Payout{
+		ID:                publicID,
+		UserID:            account.UserID,
+		ProviderAccountID: account.ProviderAccountID,
+		Amount:            amount,
+		Currency:          payoutCurrency,
+		Status:            StatusPending,
+		Destination:       destination(account.DestinationLast4),
+	}
+	entry := &models.JournalEntry{
+		IdempotencyKey: EntryKindPayout + ":" + publicID,
+		Kind:           EntryKindPayout,
+		Description:    "Payout " + publicID,
+		Postings: []*models.LedgerPosting{
+			{AccountID: available.ID, Currency: wallet.CurrencyUSD, Amount: -amount},
+			{AccountID: inFlight.ID, Currency: wallet.CurrencyUSD, Amount: amount},
+		},
+	}
+	if err := s.repo.CreatePayout(ctx, payout, entry); err != nil {
+		return fmt.Errorf("failed to create payout: %w", err)
+	}
+
+	return s.submitPayout(ctx, payout)
+}
+
+// resubmitPayouts submits again the pending payouts the provider has not
+// confirmed, after an error that left it unclear whether they were created
+func (s *Service) resubmitPayouts(ctx context.Context) error {
+	payouts, err := s.repo.GetUnsubmittedPayouts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get unsubmitted payouts: %w", err)
+	}
+
+	for _, payout := range payouts {
+		if err := s.submitPayout(ctx, payout); err != nil {
+			logger.ErrorLogger.Printf("Failed to resubmit payout %s: %v", payout.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// submitPayout asks the provider to pay a pending payout. The payout ID is
+// the idempotency key, so submitting a payout again returns the one the
+// provider already created. If the provider rejects it, the payout fails
+// and the earnings are returned for the next run; after any other error it
+// stays pending, holding the earnings, until it is resubmitted or a webhook
+// reports it.
+func (s *Service) submitPayout(ctx context.Context, payout *models.Payout) error {
+	providerPayout, err := s.provider.CreatePayout(ctx, &PayoutParams{
+		AccountID:      payout.ProviderAccountID,
+		Amount:         payout.Amount,
+		Currency:       payout.Currency,
+		IdempotencyKey: payout.ID,
+		Metadata:       map[string]string{"payout_id": payout.ID},
+	})
+	if err != nil {
+		if !errors.Is(err, ErrPayoutRejected) && !errors.Is(err, ErrProviderAccountNotFound) {
+			return fmt.Errorf("payout %s left pending after provider error: %w", payout.ID, err)
+		}
+		failure := &ProviderPayout{Status: StatusFailed, FailureCode: "provider_rejected", FailureMessage: err.Error()}
+		if applyErr := s.apply(ctx, payout, failure); applyErr != nil {
+			return fmt.Errorf("failed to fail payout %s: %w", payout.ID, applyErr)
+		}
+		return fmt.Errorf("provider rejected payout %s: %w", payout.ID, err)
+	}
+
+	return s.apply(ctx, payout, providerPayout)
+}
+
+// apply records a provider update on a payout, posting the ledger entry that
+// goes with its new status
+func (s *Service) apply(ctx context.Context, payout *models.Payout, update *ProviderPayout) error {
+	status := update.Status
+	if status == StatusCanceled {
+		status = StatusFailed
+	}
+
+	from := payout.Status
+	if status != from && !CanTransition(from, status) {
+		logger.WarnLogger.Printf("Ignoring payout %s update from %s to %s", payout.ID, from, status)
+		return nil
+	}
+
+	entry, err := s.statusEntry(ctx, payout, status)
+	if err != nil {
+		return err
+	}
+
+	payout.Status = status
+	if update.ID != "" {
+		payout.ProviderPayoutID = update.ID
+	}
+	if update.ArrivalDate > 0 {
+		arrival := time.Unix(update.ArrivalDate, 0)
+		payout.ArrivalAt = &arrival
+		payout.ArrivalDate = update.ArrivalDate
+	}
+	if update.DestinationLast4 != "" {
+		payout.Destination = destination(update.DestinationLast4)
+	}
+	if status == StatusFailed {
+		payout.FailureCode = update.FailureCode
+		payout.FailureMessage = update.FailureMessage
+	}
+
+	updated, err := s.repo.UpdatePayout(ctx, payout, from, entry)
+	if err != nil {
+		return fmt.Errorf("failed to update payout: %w", err)
+	}
+	if !updated {
+		return ErrPayoutConflict
+	}
+
+	return nil
+}
+
+// statusEntry builds the journal entry for a payout entering a status: paid
+// payouts leave the in-flight account, and failed payouts return to the
+// creator's available earnings. Other statuses move no money.
+func (s *Service) statusEntry(ctx context.Context, payout *models.Payout, status string) (*models.JournalEntry, error) {
+	if status == payout.Status || (status != StatusPaid && status != StatusFailed) {
+		return nil, nil
+	}
+
+	inFlight, err := s.wallet.PlatformAccount(ctx, wallet.PlatformPayoutsInFlight, wallet.CurrencyUSD)
+	if err != nil {
+		return nil, err
+	}
+	paid, err := s.wallet.PlatformAccount(ctx, wallet.PlatformPayoutsPaid, wallet.CurrencyUSD)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == StatusPaid {
+		return &models.JournalEntry{
+			IdempotencyKey: EntryKindPaid + ":" + payout.ID,
+			Kind:           EntryKindPaid,
+			Description:    "Payout " + payout.ID + " paid",
+			Postings: []*models.LedgerPosting{
+				{AccountID: inFlight.ID, Currency: wallet.CurrencyUSD, Amount: -payout.Amount},
+				{AccountID: paid.ID, Currency: wallet.CurrencyUSD, Amount: payout.Amount},
+			},
+		}, nil
+	}
+
+	available, err := s.wallet.Account(ctx, payout.UserID, wallet.AccountEarningsAvailable, wallet.CurrencyUSD)
+	if err != nil {
+		return nil, err
+	}
+	source := inFlight
+	if payout.Status == StatusPaid {
+		source = paid
+	}
+
+	return &models.JournalEntry{
+		IdempotencyKey: EntryKindFailed + ":" + payout.ID,
+		Kind:           EntryKindFailed,
+		Description:    "Payout " + payout.ID + " failed",
+		Postings: []*models.LedgerPosting{
+			{AccountID: source.ID, Currency: wallet.CurrencyUSD, Amount: -payout.Amount},
+			{AccountID: available.ID, Currency: wallet.CurrencyUSD, Amount: payout.Amount},
+		},
+	}, nil
+}
+
+// destination masks a bank account or card number for display
+func destination(last4 string) string {
+	if last4 == "" {
+		return ""
+	}
+	return strings.Repeat("*", 4) + last4
+}
+
+// newPayoutID generates a payout ID in the provider's po_ format
+func newPayoutID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate payout ID: %w", err)
+	}
+	return "po_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,305 @@
+package payouts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected bool
+	}{
+		{"PendingToInTransit", StatusPending, StatusInTransit, true},
+		{"PendingToPaid", StatusPending, StatusPaid, true},
+		{"InTransitToPaid", StatusInTransit, StatusPaid, true},
+		{"InTransitToFailed", StatusInTransit, StatusFailed, true},
+		{"PaidReturnedByBank", StatusPaid, StatusFailed, true},
+		{"PaidBackToInTransit", StatusPaid, StatusInTransit, false},
+		{"InTransitBackToPending", StatusInTransit, StatusPending, false},
+		{"FailedIsFinal", StatusFailed, StatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFakeProviderCreatePayout(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakeProvider()
+	provider.AddAccount(&ProviderAccount{ID: "acct_disabled"})
+
+	params := &PayoutParams{AccountID: "acct_creator", Amount: 2500, Currency: "usd", IdempotencyKey: "po_1"}
+	first, err := provider.CreatePayout(ctx, params)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.Status != StatusPending || first.DestinationLast4 != "4242" {
+		t.Errorf("Expected pending payout to 4242, got %s to %s", first.Status, first.DestinationLast4)
+	}
+
+	retried, err := provider.CreatePayout(ctx, params)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if retried.ID != first.ID {
+		t.Errorf("Expected retry to return payout %s, got %s", first.ID, retried.ID)
+	}
+
+	_, err = provider.CreatePayout(ctx, &PayoutParams{AccountID: "acct_disabled", Amount: 2500, Currency: "usd"})
+	if err != ErrPayoutsDisabled {
+		t.Errorf("Expected ErrPayoutsDisabled, got %v", err)
+	}
+
+	paid, err := provider.SetPayoutStatus(first.ID, StatusPaid, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if paid.Status != StatusPaid {
+		t.Errorf("Expected status paid, got %s", paid.Status)
+	}
+}
+
+func TestDestination(t *testing.T) {
+	if got := destination("4242"); got != "****4242" {
+		t.Errorf("Expected ****4242, got %s", got)
+	}
+	if got := destination(""); got != "" {
+		t.Errorf("Expected empty destination, got %s", got)
+	}
+}
+
+// flakyProvider fails CreatePayout with err, when set, after optionally
+// creating the payout anyway, as a provider that times out might
+type flakyProvider struct {
+	*FakeProvider
+	err     error
+	created bool
+}
+
+func (p *flakyProvider) CreatePayout(ctx context.Context, params *PayoutParams) (*ProviderPayout, error) {
+	if p.err == nil {
+		return p.FakeProvider.CreatePayout(ctx, params)
+	}
+	if p.created {
+		p.FakeProvider.CreatePayout(ctx, params)
+	}
+	return nil, p.err
+}
+
+// payoutRepo is an in-memory Repository holding payouts; the embedded
+// interface panics on methods it does not use
+type payoutRepo struct {
+	Repository
+	payouts map[string]*models.Payout
+	entries []*models.JournalEntry
+}
+
+func (r *payoutRepo) GetMaturedEarnings(ctx context.Context, cutoff time.Time) ([]*MaturedEarnings, error) {
+	return nil, nil
+}
+
+func (r *payoutRepo) GetPayableBalances(ctx context.Context, minAmount int64) ([]*PayableBalance, error) {
+	return nil, nil
+}
+
+func (r *payoutRepo) UpdatePayout(ctx context.Context, payout *models.Payout, fromStatus string, entry *models.JournalEntry) (bool, error) {
+	stored := r.payouts[payout.ID]
+	if stored.Status != fromStatus {
+		return false, nil
+	}
+	*stored = *payout
+	if entry != nil {
+		r.entries = append(r.entries, entry)
+	}
+	return true, nil
+}
+
+func (r *payoutRepo) GetPayout(ctx context.Context, publicID string) (*models.Payout, error) {
+	payout, ok := r.payouts[publicID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *payout
+	return &copied, nil
+}
+
+func (r *payoutRepo) GetPayoutByProviderID(ctx context.Context, providerPayoutID string) (*models.Payout, error) {
+	for _, payout := range r.payouts {
+		if payout.ProviderPayoutID == providerPayoutID {
+			copied := *payout
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *payoutRepo) GetUnsubmittedPayouts(ctx context.Context) ([]*models.Payout, error) {
+	var payouts []*models.Payout
+	for _, payout := range r.payouts {
+		if payout.Status == StatusPending && payout.ProviderPayoutID == "" {
+			copied := *payout
+			payouts = append(payouts, &copied)
+		}
+	}
+	return payouts, nil
+}
+
+// ledgerRepo is an in-memory wallet.Repository that only hands out accounts
+type ledgerRepo struct {
+	wallet.Repository
+	accounts int64
+}
+
+func (r *ledgerRepo) GetOrCreateAccount(ctx context.Context, ownerID *int64, accountType, currency string) (*models.LedgerAccount, error) {
+	r.accounts++
+	return &models.LedgerAccount{ID: r.accounts, Type: accountType, Currency: currency}, nil
+}
+
+func TestSubmitPayout(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	timeout := errors.New("context deadline exceeded")
+
+	tests := []struct {
+		name       string
+		err        error
+		created    bool
+		wantStatus string
+		wantFailed bool
+	}{
+		{"rejected", ErrPayoutsDisabled, false, StatusFailed, true},
+		{"timed out before creating", timeout, false, StatusPending, false},
+		{"timed out after creating", timeout, true, StatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &payoutRepo{payouts: map[string]*models.Payout{
+				"po_1": {ID: "po_1", UserID: 1, ProviderAccountID: "acct_creator", Amount: 2500, Currency: "usd", Status: StatusPending},
+			}}
+			provider := &flakyProvider{FakeProvider: NewFakeProvider(), err: tt.err, created: tt.created}
+			svc := NewService(repo, provider, wallet.NewService(&ledgerRepo{}), 1000, 7, OnboardingURLs{})
+
+			payout, _ := repo.GetPayout(ctx, "po_1")
+			if err := svc.submitPayout(ctx, payout); err == nil {
+				t.Fatalf("Expected an error")
+			}
+			if got := repo.payouts["po_1"].Status; got != tt.wantStatus {
+				t.Fatalf("Expected status %s, got %s", tt.wantStatus, got)
+			}
+			if got := len(repo.entries) == 1; got != tt.wantFailed {
+				t.Fatalf("Expected earnings returned %v, got %v", tt.wantFailed, got)
+			}
+			if tt.wantFailed {
+				return
+			}
+
+			// The next run submits the payout again with the same
+			// idempotency key, so at most one payout is made
+			provider.err = nil
+			if err := svc.SchedulePayouts(ctx); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			stored := repo.payouts["po_1"]
+			if stored.Status != StatusPending || stored.ProviderPayoutID != "po_fake0000000000000001" {
+				t.Errorf("Expected the payout to be submitted once, got %s as %q", stored.Status, stored.ProviderPayoutID)
+			}
+		})
+	}
+}
+
+func TestApplyPayoutUpdateByMetadata(t *testing.T) {
+	ctx := context.Background()
+	repo := &payoutRepo{payouts: map[string]*models.Payout{
+		"po_1": {ID: "po_1", UserID: 1, Amount: 2500, Status: StatusPending},
+	}}
+	svc := NewService(repo, NewFakeProvider(), wallet.NewService(&ledgerRepo{}), 1000, 7, OnboardingURLs{})
+
+	// The response creating the payout was lost, so only the webhook knows
+	// the provider's ID
+	update := &ProviderPayout{ID: "po_fake0000000000000001", Status: StatusPaid, Metadata: map[string]string{"payout_id": "po_1"}}
+	if err := svc.ApplyPayoutUpdate(ctx, update); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored := repo.payouts["po_1"]; stored.Status != StatusPaid || stored.ProviderPayoutID != update.ID {
+		t.Errorf("Expected the payout to be paid as %s, got %s as %q", update.ID, stored.Status, stored.ProviderPayoutID)
+	}
+
+	other := &ProviderPayout{ID: "po_fake0000000000000002", Status: StatusPaid, Metadata: map[string]string{"payout_id": "po_1"}}
+	if err := svc.ApplyPayoutUpdate(ctx, other); err != ErrPayoutNotFound {
+		t.Errorf("Expected a payout already matched to another provider payout to be refused, got %v", err)
+	}
+}
+
+// accountRepo is an in-memory Repository holding payout accounts
+type accountRepo struct {
+	Repository
+	accounts map[int64]*models.PayoutAccount
+	saves    int
+}
+
+func (r *accountRepo) GetPayoutAccount(ctx context.Context, userID int64) (*models.PayoutAccount, error) {
+	account, ok := r.accounts[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (r *accountRepo) UpsertPayoutAccount(ctx context.Context, account *models.PayoutAccount) error {
+	r.saves++
+	copied := *account
+	r.accounts[account.UserID] = &copied
+	return nil
+}
+
+func TestLinkAccount(t *testing.T) {
+	ctx := context.Background()
+	repo := &accountRepo{accounts: make(map[int64]*models.PayoutAccount)}
+	svc := NewService(repo, NewFakeProvider(), nil, 1000, 7, OnboardingURLs{
+		ReturnURL:  "https://app.example.com/payouts/done",
+		RefreshURL: "https://app.example.com/payouts/onboarding",
+	})
+
+	first, err := svc.LinkAccount(ctx, 1, "ada@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.URL == "" || first.Account.ProviderAccountID == "" {
+		t.Fatalf("Expected an onboarding link for a new account, got %+v", first)
+	}
+
+	// Onboarding again resumes the same account rather than making another
+	again, err := svc.LinkAccount(ctx, 1, "ada@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if again.Account.ProviderAccountID != first.Account.ProviderAccountID || repo.saves != 1 {
+		t.Errorf("Expected account %s to be reused, got %s after %d saves", first.Account.ProviderAccountID, again.Account.ProviderAccountID, repo.saves)
+	}
+
+	// Every creator gets their own account from the provider
+	other, err := svc.LinkAccount(ctx, 2, "grace@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if other.Account.ProviderAccountID == first.Account.ProviderAccountID {
+		t.Errorf("Expected creators to get separate accounts, both got %s", other.Account.ProviderAccountID)
+	}
+}
@@ -0,0 +1,68 @@
+package payouts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PayoutEventTypes are the provider webhook events that report payout status
+var PayoutEventTypes = []string{
+	"payout.created",
+	"payout.updated",
+	"payout.paid",
+	"payout.failed",
+	"payout.canceled",
+}
+
+// AccountEventType is the provider webhook event for connected account changes
+const AccountEventType = "account.updated"
+
+// payoutObject is the provider's payout object as sent in webhooks
+type payoutObject struct {
+	ID             string            `json:"id"`
+	Amount         int64             `json:"amount"`
+	Currency       string            `json:"currency"`
+	Status         string            `json:"status"`
+	ArrivalDate    int64             `json:"arrival_date"`
+	FailureCode    string            `json:"failure_code"`
+	FailureMessage string            `json:"failure_message"`
+	Metadata       map[string]string `json:"metadata"`
+}
+
+// accountObject is the provider's connected account object as sent in webhooks
+type accountObject struct {
+	ID             string `json:"id"`
+	PayoutsEnabled bool   `json:"payouts_enabled"`
+}
+
+// HandlePayoutEvent applies a payout webhook. A payout the provider reports
+// that is not known here, by its ID or the payout ID in its metadata,
+// returns ErrPayoutNotFound, so the event is retried.
+func (s *Service) HandlePayoutEvent(ctx context.Context, object json.RawMessage) error {
+	var payout payoutObject
+	if err := json.Unmarshal(object, &payout); err != nil {
+		return fmt.Errorf("failed to decode payout: %w", err)
+	}
+
+	return s.ApplyPayoutUpdate(ctx, &ProviderPayout{
+		ID:             payout.ID,
+		Amount:         payout.Amount,
+		Currency:       payout.Currency,
+		Status:         payout.Status,
+		ArrivalDate:    payout.ArrivalDate,
+		FailureCode:    payout.FailureCode,
+		FailureMessage: payout.FailureMessage,
+		Metadata:       payout.Metadata,
+	})
+}
+
+// HandleAccountEvent applies a connected account webhook
+func (s *Service) HandleAccountEvent(ctx context.Context, object json.RawMessage) error {
+	var account accountObject
+	if err := json.Unmarshal(object, &account); err != nil {
+		return fmt.Errorf("failed to decode account: %w", err)
+	}
+
+	return s.ApplyAccountUpdate(ctx, &ProviderAccount{ID: account.ID, PayoutsEnabled: account.PayoutsEnabled})
+}
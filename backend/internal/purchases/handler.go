@@ -0,0 +1,101 @@
+package purchases
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles coin purchase HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new coin purchase handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetPackages handles getting the coin packages on sale
+// @Summary Get coin packages
+// @Tags wallet
+// @Produce json
+// @Success 200 {array} models.CoinPackage
+// @Router /coin-packages [get]
+func (h *Handler) GetPackages(c *gin.Context) {
+	packages, err := h.service.GetPackages(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get coin packages",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, packages)
+}
+
+// Purchase handles redeeming an app store receipt for coins
+// @Summary Purchase coins
+// @Description Submitting the same receipt again returns the original purchase with 200
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PurchaseCoinsRequest true "Store receipt"
+// @Success 201 {object} models.CoinPurchase
+// @Success 200 {object} models.CoinPurchase
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /wallet/purchases [post]
+func (h *Handler) Purchase(c *gin.Context) {
+	var req models.PurchaseCoinsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	purchase, replayed, err := h.service.Purchase(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to purchase coins")
+		return
+	}
+
+	if replayed {
+		c.JSON(http.StatusOK, purchase)
+		return
+	}
+	c.JSON(http.StatusCreated, purchase)
+}
+
+// respondError maps coin purchase errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrInvalidReceipt), errors.Is(err, ErrUnsupportedStore):
+		status, code = http.StatusBadRequest, "invalid_receipt"
+	case errors.Is(err, ErrPackageNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrReceiptAlreadyUsed):
+		status, code = http.StatusConflict, "receipt_already_used"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
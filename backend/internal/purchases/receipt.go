@@ -0,0 +1,61 @@
+package purchases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Stores that sell coins
+const (
+	StoreApple  = "apple"
+	StoreGoogle = "google"
+)
+
+var (
+	ErrInvalidReceipt   = errors.New("invalid receipt")
+	ErrUnsupportedStore = errors.New("unsupported store")
+)
+
+// Receipt is a store transaction confirmed by the store
+type Receipt struct {
+	Store         string
+	TransactionID string
+	ProductID     string
+	PurchasedAt   time.Time
+}
+
+// ReceiptValidator confirms a receipt with the store that issued it
+type ReceiptValidator interface {
+	Validate(ctx context.Context, store, receipt string) (*Receipt, error)
+}
+
+// FakeValidator is an in-process ReceiptValidator for development and tests.
+// It accepts receipts of the form "fake:<product_id>:<transaction_id>" from
+// any supported store.
+type FakeValidator struct{}
+
+// NewFakeValidator creates a new fake receipt validator
+func NewFakeValidator() *FakeValidator {
+	return &FakeValidator{}
+}
+
+// Validate parses a fake receipt
+func (v *FakeValidator) Validate(ctx context.Context, store, receipt string) (*Receipt, error) {
+	if store != StoreApple && store != StoreGoogle {
+		return nil, ErrUnsupportedStore
+	}
+
+	parts := strings.Split(receipt, ":")
+	if len(parts) != 3 || parts[0] != "fake" || parts[1] == "" || parts[2] == "" {
+		return nil, ErrInvalidReceipt
+	}
+
+	return &Receipt{
+		Store:         store,
+		TransactionID: parts[2],
+		ProductID:     parts[1],
+		PurchasedAt:   time.Now(),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package purchases
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFakeValidator(t *testing.T) {
+	tests := []struct {
+		name          string
+		store         string
+		receipt       string
+		expectedErr   error
+		productID     string
+		transactionID string
+	}{
+		{"Apple", StoreApple, "fake:halo.coins.100:1000000001", nil, "halo.coins.100", "1000000001"},
+		{"Google", StoreGoogle, "fake:halo.coins.500:GPA.1234", nil, "halo.coins.500", "GPA.1234"},
+		{"UnknownStore", "steam", "fake:halo.coins.100:1", ErrUnsupportedStore, "", ""},
+		{"NotFake", StoreApple, "MIIT8wYJKoZIhvcNAQcCoIIT5DCCE", ErrInvalidReceipt, "", ""},
+		{"MissingTransaction", StoreApple, "fake:halo.coins.100:", ErrInvalidReceipt, "", ""},
+	}
+
+	validator := NewFakeValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt, err := validator.Validate(context.Background(), tt.store, tt.receipt)
+			if err != tt.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if receipt.ProductID != tt.productID || receipt.TransactionID != tt.transactionID {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.productID, tt.transactionID, receipt.ProductID, receipt.TransactionID)
+			}
+		})
+	}
+}
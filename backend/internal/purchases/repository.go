@@ -0,0 +1,205 @@
+package purchases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+)
+
+const purchaseColumns = `id, user_id, package_id, store, transaction_id, product_id, coins, price_cents, currency,
+	status, reversed_coins, entry_id, purchased_at, reversed_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPurchase(row rowScanner, purchase *models.CoinPurchase) error {
+	return row.Scan(
+		&purchase.ID,
+		&purchase.UserID,
+		&purchase.PackageID,
+		&purchase.Store,
+		&purchase.TransactionID,
+		&purchase.ProductID,
+		&purchase.Coins,
+		&purchase.PriceCents,
+		&purchase.Currency,
+		&purchase.Status,
+		&purchase.ReversedCoins,
+		&purchase.EntryID,
+		&purchase.PurchasedAt,
+		&purchase.ReversedAt,
+		&purchase.CreatedAt,
+	)
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetPackages retrieves the active coin packages
+func (r *PostgresRepository) GetPackages(ctx context.Context) ([]*models.CoinPackage, error) {
+	query := `
+		SELECT id, product_id, coins, price_cents, currency, sort_order
+		FROM coin_packages
+		WHERE active = TRUE
+		ORDER BY sort_order, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	packages := make([]*models.CoinPackage, 0)
+	for rows.Next() {
+		pkg := &models.CoinPackage{}
+		if err := rows.Scan(&pkg.ID, &pkg.ProductID, &pkg.Coins, &pkg.PriceCents, &pkg.Currency, &pkg.SortOrder); err != nil {
+			return nil, err
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, rows.Err()
+}
+
+// GetPackageByProductID retrieves an active coin package by store product ID
+func (r *PostgresRepository) GetPackageByProductID(ctx context.Context, productID string) (*models.CoinPackage, error) {
+	query := `
+		SELECT id, product_id, coins, price_cents, currency, sort_order
+		FROM coin_packages
+		WHERE product_id = $1 AND active = TRUE
+	`
+
+	pkg := &models.CoinPackage{}
+	err := r.db.QueryRowContext(ctx, query, productID).
+		Scan(&pkg.ID, &pkg.ProductID, &pkg.Coins, &pkg.PriceCents, &pkg.Currency, &pkg.SortOrder)
+	if err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// CreatePurchase posts a purchase's journal entry and records the purchase in
+// one transaction. If the entry was already posted, purchase is filled from
+// the existing record and replayed is true.
+func (r *PostgresRepository) CreatePurchase(ctx context.Context, purchase *models.CoinPurchase, entry *models.JournalEntry) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	replayed, err := wallet.Post(ctx, tx, entry)
+	if err != nil {
+		return false, err
+	}
+
+	if replayed {
+		query := `SELECT ` + purchaseColumns + ` FROM coin_purchases WHERE entry_id = $1`
+		if err := scanPurchase(tx.QueryRowContext(ctx, query, entry.ID), purchase); err != nil {
+			return false, fmt.Errorf("failed to get replayed purchase: %w", err)
+		}
+		return true, nil
+	}
+
+	purchase.EntryID = entry.ID
+	query := `
+		INSERT INTO coin_purchases (user_id, package_id, store, transaction_id, product_id, coins, price_cents, currency, status, entry_id, purchased_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		purchase.UserID,
+		purchase.PackageID,
+		purchase.Store,
+		purchase.TransactionID,
+		purchase.ProductID,
+		purchase.Coins,
+		purchase.PriceCents,
+		purchase.Currency,
+		purchase.Status,
+		purchase.EntryID,
+		purchase.PurchasedAt,
+	).Scan(&purchase.ID, &purchase.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert purchase: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit purchase: %w", err)
+	}
+
+	return false, nil
+}
+
+// GetPurchaseByTransaction retrieves a purchase by its store transaction
+func (r *PostgresRepository) GetPurchaseByTransaction(ctx context.Context, store, transactionID string) (*models.CoinPurchase, error) {
+	query := `SELECT ` + purchaseColumns + ` FROM coin_purchases WHERE store = $1 AND transaction_id = $2`
+
+	purchase := &models.CoinPurchase{}
+	if err := scanPurchase(r.db.QueryRowContext(ctx, query, store, transactionID), purchase); err != nil {
+		return nil, err
+	}
+	return purchase, nil
+}
+
+// ReversePurchase marks a completed purchase refunded or charged back. With
+// the purchase and both coin accounts locked, it passes the user's coin
+// balance to clawback and posts the entry it returns, if not nil, in the
+// same transaction. It reports false without changing anything if the
+// purchase was already reversed.
+func (r *PostgresRepository) ReversePurchase(ctx context.Context, purchase *models.CoinPurchase, coinsAccountID, issuedAccountID int64, clawback func(coinsBalance int64) *models.JournalEntry) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM coin_purchases WHERE id = $1 AND status = 'completed' FOR UPDATE`, purchase.ID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock purchase: %w", err)
+	}
+
+	accounts, err := wallet.LockAccounts(ctx, tx, coinsAccountID, issuedAccountID)
+	if err != nil {
+		return false, err
+	}
+
+	var entryID *int64
+	if entry := clawback(accounts[coinsAccountID].Balance); entry != nil {
+		if _, err := wallet.Post(ctx, tx, entry); err != nil {
+			return false, err
+		}
+		entryID = &entry.ID
+	}
+
+	query := `
+		UPDATE coin_purchases
+		SET status = $2, reversed_coins = $3, reversal_entry_id = $4, reversed_at = NOW()
+		WHERE id = $1
+		RETURNING reversed_at
+	`
+	err = tx.QueryRowContext(ctx, query, purchase.ID, purchase.Status, purchase.ReversedCoins, entryID).Scan(&purchase.ReversedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,191 @@
+package purchases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// Journal entry kinds for coin purchases
+const (
+	EntryKindPurchase = "coin_purchase"
+	EntryKindReversal = "coin_purchase_reversal"
+)
+
+// Purchase statuses
+const (
+	StatusCompleted   = "completed"
+	StatusRefunded    = "refunded"
+	StatusChargedBack = "charged_back"
+)
+
+var (
+	ErrPackageNotFound    = errors.New("coin package not found")
+	ErrPurchaseNotFound   = errors.New("purchase not found")
+	ErrReceiptAlreadyUsed = errors.New("receipt was already redeemed by another account")
+	ErrInvalidReversal    = errors.New("invalid reversal status")
+)
+
+// Repository defines the interface for coin purchase data access
+type Repository interface {
+	GetPackages(ctx context.Context) ([]*models.CoinPackage, error)
+	GetPackageByProductID(ctx context.Context, productID string) (*models.CoinPackage, error)
+	CreatePurchase(ctx context.Context, purchase *models.CoinPurchase, entry *models.JournalEntry) (bool, error)
+	GetPurchaseByTransaction(ctx context.Context, store, transactionID string) (*models.CoinPurchase, error)
+	ReversePurchase(ctx context.Context, purchase *models.CoinPurchase, coinsAccountID, issuedAccountID int64, clawback func(coinsBalance int64) *models.JournalEntry) (bool, error)
+}
+
+// Service sells coins through the app stores
+type Service struct {
+	repo      Repository
+	validator ReceiptValidator
+	wallet    *wallet.Service
+}
+
+// NewService creates a new coin purchase service
+func NewService(repo Repository, validator ReceiptValidator, wallet *wallet.Service) *Service {
+	return &Service{
+		repo:      repo,
+		validator: validator,
+		wallet:    wallet,
+	}
+}
+
+// GetPackages retrieves the coin packages on sale
+func (s *Service) GetPackages(ctx context.Context) ([]*models.CoinPackage, error) {
+	packages, err := s.repo.GetPackages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coin packages: %w", err)
+	}
+	return packages, nil
+}
+
+// Purchase validates a store receipt and credits its coins. Each store
+// transaction is credited once: submitting the same receipt again returns the
+// original purchase with replayed set, and a receipt redeemed by another
+// account is refused.
+func (s *Service) Purchase(ctx context.Context, userID int64, req *models.PurchaseCoinsRequest) (purchase *models.CoinPurchase, replayed bool, err error) {
+	receipt, err := s.validator.Validate(ctx, req.Store, req.Receipt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pkg, err := s.repo.GetPackageByProductID(ctx, receipt.ProductID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, ErrPackageNotFound
+		}
+		return nil, false, fmt.Errorf("failed to get coin package: %w", err)
+	}
+
+	coins, err := s.wallet.Account(ctx, userID, wallet.AccountCoins, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, false, err
+	}
+	issued, err := s.wallet.PlatformAccount(ctx, wallet.PlatformCoinsIssued, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, false, err
+	}
+
+	purchase = &models.CoinPurchase{
+		UserID:        userID,
+		PackageID:     pkg.ID,
+		Store:         receipt.Store,
+		TransactionID: receipt.TransactionID,
+		ProductID:     pkg.ProductID,
+		Coins:         pkg.Coins,
+		PriceCents:    pkg.PriceCents,
+		Currency:      pkg.Currency,
+		Status:        StatusCompleted,
+		PurchasedAt:   receipt.PurchasedAt,
+	}
+	entry := &models.JournalEntry{
+		IdempotencyKey: fmt.Sprintf("%s:%s:%s", EntryKindPurchase, receipt.Store, receipt.TransactionID),
+		Kind:           EntryKindPurchase,
+		Description:    fmt.Sprintf("%d coins (%s)", pkg.Coins, pkg.ProductID),
+		Postings: []*models.LedgerPosting{
+			{AccountID: issued.ID, Currency: wallet.CurrencyCoin, Amount: -pkg.Coins},
+			{AccountID: coins.ID, Currency: wallet.CurrencyCoin, Amount: pkg.Coins},
+		},
+	}
+
+	replayed, err = s.repo.CreatePurchase(ctx, purchase, entry)
+	if err != nil {
+		if errors.Is(err, wallet.ErrIdempotencyConflict) {
+			return nil, false, ErrReceiptAlreadyUsed
+		}
+		return nil, false, fmt.Errorf("failed to create purchase: %w", err)
+	}
+	if replayed && purchase.UserID != userID {
+		return nil, false, ErrReceiptAlreadyUsed
+	}
+
+	return purchase, replayed, nil
+}
+
+// Reverse takes back the coins of a refunded or charged-back purchase. Coins
+// already spent cannot be recovered, so at most the buyer's current balance
+// is debited; the amount taken back is recorded on the purchase. Purchases
+// that were already reversed are left alone.
+func (s *Service) Reverse(ctx context.Context, store, transactionID, status string) error {
+	if status != StatusRefunded && status != StatusChargedBack {
+		return ErrInvalidReversal
+	}
+
+	purchase, err := s.repo.GetPurchaseByTransaction(ctx, store, transactionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPurchaseNotFound
+		}
+		return fmt.Errorf("failed to get purchase: %w", err)
+	}
+	if purchase.Status != StatusCompleted {
+		return nil
+	}
+
+	coins, err := s.wallet.Account(ctx, purchase.UserID, wallet.AccountCoins, wallet.CurrencyCoin)
+	if err != nil {
+		return err
+	}
+	issued, err := s.wallet.PlatformAccount(ctx, wallet.PlatformCoinsIssued, wallet.CurrencyCoin)
+	if err != nil {
+		return err
+	}
+
+	purchase.Status = status
+	// The clawback is worked out from the balance read with the account
+	// locked, so coins spent meanwhile are not taken twice
+	clawback := func(coinsBalance int64) *models.JournalEntry {
+		purchase.ReversedCoins = min(purchase.Coins, coinsBalance)
+		if purchase.ReversedCoins == 0 {
+			return nil
+		}
+		return &models.JournalEntry{
+			IdempotencyKey: fmt.Sprintf("%s:%s:%s", EntryKindReversal, store, transactionID),
+			Kind:           EntryKindReversal,
+			Description:    fmt.Sprintf("%s of %d coins (%s)", status, purchase.Coins, purchase.ProductID),
+			Postings: []*models.LedgerPosting{
+				{AccountID: coins.ID, Currency: wallet.CurrencyCoin, Amount: -purchase.ReversedCoins},
+				{AccountID: issued.ID, Currency: wallet.CurrencyCoin, Amount: purchase.ReversedCoins},
+			},
+		}
+	}
+
+	reversed, err := s.repo.ReversePurchase(ctx, purchase, coins.ID, issued.ID, clawback)
+	if err != nil {
+		return fmt.Errorf("failed to reverse purchase: %w", err)
+	}
+	if !reversed {
+		return nil
+	}
+
+	if shortfall := purchase.Coins - purchase.ReversedCoins; shortfall > 0 {
+		logger.WarnLogger.Printf("Purchase %d %s with %d coins already spent by user %d", purchase.ID, status, shortfall, purchase.UserID)
+	}
+	return nil
+}
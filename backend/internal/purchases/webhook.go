@@ -0,0 +1,37 @@
+package purchases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Provider webhook events that reverse a coin purchase
+const (
+	RefundEventType     = "coin_purchase.refunded"
+	ChargebackEventType = "coin_purchase.charged_back"
+)
+
+// transactionObject identifies a store transaction in webhooks
+type transactionObject struct {
+	Store         string `json:"store"`
+	TransactionID string `json:"transaction_id"`
+}
+
+// HandleRefundEvent reverses a refunded purchase
+func (s *Service) HandleRefundEvent(ctx context.Context, object json.RawMessage) error {
+	return s.handleReversal(ctx, object, StatusRefunded)
+}
+
+// HandleChargebackEvent reverses a charged-back purchase
+func (s *Service) HandleChargebackEvent(ctx context.Context, object json.RawMessage) error {
+	return s.handleReversal(ctx, object, StatusChargedBack)
+}
+
+func (s *Service) handleReversal(ctx context.Context, object json.RawMessage, status string) error {
+	var tx transactionObject
+	if err := json.Unmarshal(object, &tx); err != nil {
+		return fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	return s.Reverse(ctx, tx.Store, tx.TransactionID, status)
+}
@@ -0,0 +1,95 @@
+package room
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/textnorm"
+)
+
+// Filter matches messages against a room's banned words and patterns. Both
+// the message and the word list are folded with textnorm first, so
+// lookalike characters, accents and zero-width characters don't slip through.
+type Filter struct {
+	words    []string
+	patterns []*regexp.Regexp
+}
+
+// NewFilter compiles a filter. Patterns are case-insensitive and are matched
+// against the folded message.
+func NewFilter(words, patterns []string) (*Filter, error) {
+	f := &Filter{words: words}
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+	return f, nil
+}
+
+// Blocked reports whether the message contains a banned word or matches a
+// banned pattern
+func (f *Filter) Blocked(message string) bool {
+	for _, word := range f.words {
+		if textnorm.ContainsPhrase(message, word) {
+			return true
+		}
+	}
+
+	if len(f.patterns) > 0 {
+		folded := textnorm.Fold(message)
+		for _, re := range f.patterns {
+			if re.MatchString(folded) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// maxCachedFilters bounds the filter cache; it is simply emptied when full,
+// since filters for streams that are still live are rebuilt on their next message
+const maxCachedFilters = 10000
+
+// filterCache keeps compiled filters per video until its settings change, so
+// patterns are not recompiled for every message
+type filterCache struct {
+	mu      sync.Mutex
+	entries map[int64]cachedFilter
+}
+
+type cachedFilter struct {
+	updatedAt time.Time
+	filter    *Filter
+}
+
+func newFilterCache() *filterCache {
+	return &filterCache{entries: make(map[int64]cachedFilter)}
+}
+
+// get returns the compiled filter for the settings, compiling it if the
+// cached one is missing or stale
+func (fc *filterCache) get(settings *models.RoomSettings) (*Filter, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if entry, ok := fc.entries[settings.VideoID]; ok && entry.updatedAt.Equal(settings.UpdatedAt) {
+		return entry.filter, nil
+	}
+
+	filter, err := NewFilter(settings.BannedWords, settings.BannedPatterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(fc.entries) >= maxCachedFilters {
+		fc.entries = make(map[int64]cachedFilter)
+	}
+	fc.entries[settings.VideoID] = cachedFilter{updatedAt: settings.UpdatedAt, filter: filter}
+	return filter, nil
+}
@@ -0,0 +1,41 @@
+package room
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	filter, err := NewFilter([]string{"scam", "free coins"}, []string{`b+a+d+w+o+r+d+`})
+	if err != nil {
+		t.Fatalf("Failed to create filter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		blocked bool
+	}{
+		{"Clean", "great stream tonight", false},
+		{"BannedWord", "this is a scam", true},
+		{"Phrase", "get FREE   coins here", true},
+		{"PartialWordAllowed", "scampi for dinner", false},
+		{"Cyrillic", "this is a ѕсаm", true},
+		{"Fullwidth", "ｓｃａｍ", true},
+		{"Accents", "scàm", true},
+		{"ZeroWidth", "sc\u200bam", true},
+		{"Pattern", "baaaadword", true},
+		{"PatternHomoglyph", "bаdwоrd", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Blocked(tt.message); got != tt.blocked {
+				t.Errorf("Expected blocked=%v for %q, got %v", tt.blocked, tt.message, got)
+			}
+		})
+	}
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		if _, err := NewFilter(nil, []string{"(unclosed"}); err == nil {
+			t.Error("Expected error for invalid pattern")
+		}
+	})
+}
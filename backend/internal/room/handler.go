@@ -0,0 +1,356 @@
+package room
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles live room HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new room handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RequireParticipation rejects interactions with a stream from users who are
+// banned, timed out or fail the room's rules. It runs after AuthMiddleware on
+// routes with an :id video parameter. Joining as a viewer is not an
+// interaction, so the live_viewers metric is let through.
+func (h *Handler) RequireParticipation(c *gin.Context) {
+	if c.Param("metric") == "live_viewers" {
+		c.Next()
+		return
+	}
+
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		c.Abort()
+		return
+	}
+
+	if err := h.service.CheckParticipation(c.Request.Context(), videoID, c.GetInt64("user_id")); err != nil {
+		respondError(c, err, "Failed to check room rules")
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// SendMessage handles sending a chat message to a stream
+// @Summary Send a chat message
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.RoomMessageRequest true "Message"
+// @Success 201 {object} models.RoomMessage
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /videos/{id}/messages [post]
+func (h *Handler) SendMessage(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RoomMessageRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	message, err := h.service.SendMessage(
+		c.Request.Context(),
+		videoID,
+		c.GetInt64("user_id"),
+		c.GetString("user_username"),
+		req.Text,
+	)
+	if err != nil {
+		respondError(c, err, "Failed to send message")
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// GetSettings handles getting a stream's room settings
+// @Summary Get room settings
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Success 200 {object} models.RoomSettings
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /videos/{id}/room [get]
+func (h *Handler) GetSettings(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(c.Request.Context(), videoID, c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get room settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handles replacing a stream's room settings
+// @Summary Update room settings
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.UpdateRoomSettingsRequest true "Settings"
+// @Success 200 {object} models.RoomSettings
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /videos/{id}/room [put]
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoomSettingsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), videoID, c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to update room settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// BanUser handles banning or timing out a user from the stream's channel
+// @Summary Ban or time out a user
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.ChannelBanRequest true "Ban (set duration_seconds for a timeout)"
+// @Success 201 {object} models.ChannelBan
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /videos/{id}/room/bans [post]
+func (h *Handler) BanUser(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChannelBanRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	ban, err := h.service.BanUser(c.Request.Context(), videoID, c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to ban user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, ban)
+}
+
+// UnbanUser handles lifting bans and timeouts for a user
+// @Summary Lift a ban or timeout
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /videos/{id}/room/bans/{user_id} [delete]
+func (h *Handler) UnbanUser(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnbanUser(c.Request.Context(), videoID, c.GetInt64("user_id"), userID); err != nil {
+		respondError(c, err, "Failed to lift ban")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Ban lifted",
+	})
+}
+
+// AddModerator handles appointing a channel moderator
+// @Summary Appoint a channel moderator
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.ChannelModeratorRequest true "Moderator"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /videos/{id}/room/moderators [post]
+func (h *Handler) AddModerator(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChannelModeratorRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.AddModerator(c.Request.Context(), videoID, c.GetInt64("user_id"), req.UserID); err != nil {
+		respondError(c, err, "Failed to add moderator")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Moderator added",
+	})
+}
+
+// RemoveModerator handles removing a channel moderator
+// @Summary Remove a channel moderator
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /videos/{id}/room/moderators/{user_id} [delete]
+func (h *Handler) RemoveModerator(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveModerator(c.Request.Context(), videoID, c.GetInt64("user_id"), userID); err != nil {
+		respondError(c, err, "Failed to remove moderator")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Moderator removed",
+	})
+}
+
+func parseVideoID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid video ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func parseUserID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid user ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondError maps room service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	var banned *BannedError
+	if errors.As(err, &banned) {
+		code := "banned"
+		if banned.Ban.ExpiresAt != nil {
+			code = "timed_out"
+		}
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   code,
+			Message: banned.Error(),
+		})
+		return
+	}
+
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrVideoNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrNotRoomModerator), errors.Is(err, ErrNotChannelOwner):
+		status, code = http.StatusForbidden, "forbidden"
+	case errors.Is(err, ErrVerifiedOnly):
+		status, code = http.StatusForbidden, "verified_only"
+	case errors.Is(err, ErrAccountTooNew):
+		status, code = http.StatusForbidden, "account_too_new"
+	case errors.Is(err, ErrCannotTarget):
+		status, code = http.StatusBadRequest, "invalid_target"
+	case errors.Is(err, ErrInvalidPattern):
+		status, code = http.StatusBadRequest, "invalid_pattern"
+	case errors.Is(err, ErrMessageBlocked):
+		status, code = http.StatusUnprocessableEntity, "message_blocked"
+	case errors.Is(err, ErrSlowMode):
+		status, code = http.StatusTooManyRequests, "slow_mode"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
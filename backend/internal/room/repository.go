@@ -0,0 +1,207 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetChannelID retrieves the creator who owns a video
+func (r *PostgresRepository) GetChannelID(ctx context.Context, videoID int64) (int64, error) {
+	var channelID int64
+	err := r.db.QueryRowContext(ctx, `SELECT user_id FROM videos WHERE id = $1`, videoID).Scan(&channelID)
+	return channelID, err
+}
+
+// GetParticipant retrieves the account details that room rules depend on
+func (r *PostgresRepository) GetParticipant(ctx context.Context, userID int64) (*Participant, error) {
+	query := `SELECT id, created_at, email_verified_at IS NOT NULL FROM users WHERE id = $1`
+
+	p := &Participant{}
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&p.UserID, &p.CreatedAt, &p.Verified); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetSettings retrieves the room settings for a video, or defaults if none are set
+func (r *PostgresRepository) GetSettings(ctx context.Context, videoID int64) (*models.RoomSettings, error) {
+	query := `
+		SELECT video_id, slow_mode_seconds, verified_only, min_account_age_minutes, banned_words, banned_patterns, updated_at
+		FROM room_settings
+		WHERE video_id = $1
+	`
+
+	settings := &models.RoomSettings{}
+	var words, patterns []byte
+	err := r.db.QueryRowContext(ctx, query, videoID).Scan(
+		&settings.VideoID,
+		&settings.SlowModeSeconds,
+		&settings.VerifiedOnly,
+		&settings.MinAccountAgeMinutes,
+		&words,
+		&patterns,
+		&settings.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return &models.RoomSettings{VideoID: videoID, BannedWords: []string{}, BannedPatterns: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(words, &settings.BannedWords); err != nil {
+		return nil, fmt.Errorf("failed to decode banned words: %w", err)
+	}
+	if err := json.Unmarshal(patterns, &settings.BannedPatterns); err != nil {
+		return nil, fmt.Errorf("failed to decode banned patterns: %w", err)
+	}
+
+	return settings, nil
+}
+
+// UpsertSettings creates or replaces the room settings for a video
+func (r *PostgresRepository) UpsertSettings(ctx context.Context, settings *models.RoomSettings) error {
+	words, err := json.Marshal(settings.BannedWords)
+	if err != nil {
+		return err
+	}
+	patterns, err := json.Marshal(settings.BannedPatterns)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO room_settings (video_id, slow_mode_seconds, verified_only, min_account_age_minutes, banned_words, banned_patterns)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (video_id) DO UPDATE
+		SET slow_mode_seconds = EXCLUDED.slow_mode_seconds,
+		    verified_only = EXCLUDED.verified_only,
+		    min_account_age_minutes = EXCLUDED.min_account_age_minutes,
+		    banned_words = EXCLUDED.banned_words,
+		    banned_patterns = EXCLUDED.banned_patterns
+		RETURNING updated_at
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		settings.VideoID,
+		settings.SlowModeSeconds,
+		settings.VerifiedOnly,
+		settings.MinAccountAgeMinutes,
+		words,
+		patterns,
+	).Scan(&settings.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save room settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetActiveBan retrieves the longest-running ban or timeout in force for a
+// user in a channel. It returns sql.ErrNoRows if there is none.
+func (r *PostgresRepository) GetActiveBan(ctx context.Context, channelID, userID int64) (*models.ChannelBan, error) {
+	query := `
+		SELECT id, channel_id, user_id, COALESCE(issued_by, 0), reason, expires_at, created_at
+		FROM channel_bans
+		WHERE channel_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > $3)
+		ORDER BY expires_at DESC NULLS FIRST
+		LIMIT 1
+	`
+
+	ban := &models.ChannelBan{}
+	err := r.db.QueryRowContext(ctx, query, channelID, userID, time.Now()).Scan(
+		&ban.ID,
+		&ban.ChannelID,
+		&ban.UserID,
+		&ban.IssuedBy,
+		&ban.Reason,
+		&ban.ExpiresAt,
+		&ban.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ban, nil
+}
+
+// CreateBan records a ban or timeout
+func (r *PostgresRepository) CreateBan(ctx context.Context, ban *models.ChannelBan) error {
+	query := `
+		INSERT INTO channel_bans (channel_id, user_id, issued_by, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, ban.ChannelID, ban.UserID, ban.IssuedBy, ban.Reason, ban.ExpiresAt).
+		Scan(&ban.ID, &ban.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create ban: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBans lifts every ban and timeout for a user in a channel
+func (r *PostgresRepository) DeleteBans(ctx context.Context, channelID, userID int64) error {
+	query := `DELETE FROM channel_bans WHERE channel_id = $1 AND user_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, channelID, userID); err != nil {
+		return fmt.Errorf("failed to delete bans: %w", err)
+	}
+
+	return nil
+}
+
+// IsChannelModerator reports whether a user moderates a channel
+func (r *PostgresRepository) IsChannelModerator(ctx context.Context, channelID, userID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM channel_moderators WHERE channel_id = $1 AND user_id = $2)`
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, channelID, userID).Scan(&exists)
+	return exists, err
+}
+
+// AddChannelModerator appoints a channel moderator, ignoring duplicates
+func (r *PostgresRepository) AddChannelModerator(ctx context.Context, channelID, userID int64) error {
+	query := `
+		INSERT INTO channel_moderators (channel_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (channel_id, user_id) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, channelID, userID); err != nil {
+		return fmt.Errorf("failed to add channel moderator: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveChannelModerator removes a channel moderator
+func (r *PostgresRepository) RemoveChannelModerator(ctx context.Context, channelID, userID int64) error {
+	query := `DELETE FROM channel_moderators WHERE channel_id = $1 AND user_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, channelID, userID); err != nil {
+		return fmt.Errorf("failed to remove channel moderator: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package room
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/database"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/moderation"
+)
+
+var (
+	ErrVideoNotFound    = errors.New("video not found")
+	ErrNotRoomModerator = errors.New("not a moderator of this room")
+	ErrNotChannelOwner  = errors.New("only the creator can do this")
+	ErrCannotTarget     = errors.New("cannot act on the channel owner")
+	ErrVerifiedOnly     = errors.New("room is limited to verified accounts")
+	ErrAccountTooNew    = errors.New("account is too new to participate")
+	ErrSlowMode         = errors.New("slow mode is on")
+	ErrMessageBlocked   = errors.New("message contains blocked content")
+	ErrInvalidPattern   = errors.New("invalid banned pattern")
+)
+
+// BannedError reports a ban or timeout in force, including its expiry
+type BannedError struct {
+	Ban *models.ChannelBan
+}
+
+func (e *BannedError) Error() string {
+	if e.Ban.ExpiresAt != nil {
+		return fmt.Sprintf("timed out until %s", e.Ban.ExpiresAt.Format(time.RFC3339))
+	}
+	return "banned from this channel"
+}
+
+// Participant holds the account details room rules depend on
+type Participant struct {
+	UserID    int64
+	CreatedAt time.Time
+	Verified  bool
+}
+
+// Repository defines the interface for room data access
+type Repository interface {
+	GetChannelID(ctx context.Context, videoID int64) (int64, error)
+	GetParticipant(ctx context.Context, userID int64) (*Participant, error)
+	GetSettings(ctx context.Context, videoID int64) (*models.RoomSettings, error)
+	UpsertSettings(ctx context.Context, settings *models.RoomSettings) error
+	GetActiveBan(ctx context.Context, channelID, userID int64) (*models.ChannelBan, error)
+	CreateBan(ctx context.Context, ban *models.ChannelBan) error
+	DeleteBans(ctx context.Context, channelID, userID int64) error
+	IsChannelModerator(ctx context.Context, channelID, userID int64) (bool, error)
+	AddChannelModerator(ctx context.Context, channelID, userID int64) error
+	RemoveChannelModerator(ctx context.Context, channelID, userID int64) error
+}
+
+// Service handles creator moderation of live rooms
+type Service struct {
+	repo       Repository
+	redis      *database.RedisClient
+	moderation *moderation.Service
+	filters    *filterCache
+}
+
+// NewService creates a new room service
+func NewService(repo Repository, redis *database.RedisClient, moderation *moderation.Service) *Service {
+	return &Service{
+		repo:       repo,
+		redis:      redis,
+		moderation: moderation,
+		filters:    newFilterCache(),
+	}
+}
+
+// CheckParticipation reports whether a user may interact with a stream,
+// applying channel bans and timeouts and the room's verified-only and
+// account-age rules. The creator and channel moderators are always allowed.
+func (s *Service) CheckParticipation(ctx context.Context, videoID, userID int64) error {
+	_, err := s.checkParticipation(ctx, videoID, userID)
+	return err
+}
+
+// SendMessage screens a chat message and broadcasts it to the stream's
+// viewers. On top of the participation rules, slow mode and the banned-word
+// filter apply to everyone but the creator and channel moderators. The
+// platform moderator applies to everyone; chat cannot wait on review, so only
+// its rejections block a message.
+func (s *Service) SendMessage(ctx context.Context, videoID, userID int64, username, text string) (*models.RoomMessage, error) {
+	settings, err := s.checkParticipation(ctx, videoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.moderation.Check(ctx, moderation.FieldChatMessage, text); err != nil {
+		var rejected *moderation.RejectedError
+		if errors.As(err, &rejected) {
+			return nil, ErrMessageBlocked
+		}
+		return nil, err
+	}
+
+	// settings is nil for the creator and channel moderators
+	if settings != nil {
+		filter, err := s.filters.get(settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile room filter: %w", err)
+		}
+		if filter.Blocked(text) {
+			return nil, ErrMessageBlocked
+		}
+
+		if settings.SlowModeSeconds > 0 {
+			key := fmt.Sprintf("room:%d:slow:%d", videoID, userID)
+			ok, err := s.redis.SetNX(ctx, key, 1, time.Duration(settings.SlowModeSeconds)*time.Second).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply slow mode: %w", err)
+			}
+			if !ok {
+				return nil, ErrSlowMode
+			}
+		}
+	}
+
+	message := &models.RoomMessage{
+		VideoID:  videoID,
+		UserID:   userID,
+		Username: username,
+		Text:     text,
+		SentAt:   time.Now().Unix(),
+	}
+
+	if err := s.redis.PublishEvent(ctx, fmt.Sprintf("video:%d:chat", videoID), message); err != nil {
+		return nil, fmt.Errorf("failed to broadcast message: %w", err)
+	}
+	if err := s.redis.IncrementEngagement(ctx, videoID, "comments"); err != nil {
+		return nil, fmt.Errorf("failed to count message: %w", err)
+	}
+
+	return message, nil
+}
+
+// GetSettings retrieves a room's settings for its creator or a channel moderator
+func (s *Service) GetSettings(ctx context.Context, videoID, actorID int64) (*models.RoomSettings, error) {
+	if _, err := s.authorize(ctx, videoID, actorID, false); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSettings(ctx, videoID)
+}
+
+// UpdateSettings replaces a room's settings. Patterns are validated before
+// they are saved.
+func (s *Service) UpdateSettings(ctx context.Context, videoID, actorID int64, req *models.UpdateRoomSettingsRequest) (*models.RoomSettings, error) {
+	if _, err := s.authorize(ctx, videoID, actorID, false); err != nil {
+		return nil, err
+	}
+
+	if _, err := NewFilter(req.BannedWords, req.BannedPatterns); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+
+	settings := &models.RoomSettings{
+		VideoID:              videoID,
+		SlowModeSeconds:      req.SlowModeSeconds,
+		VerifiedOnly:         req.VerifiedOnly,
+		MinAccountAgeMinutes: req.MinAccountAgeMinutes,
+		BannedWords:          nonNil(req.BannedWords),
+		BannedPatterns:       nonNil(req.BannedPatterns),
+	}
+
+	if err := s.repo.UpsertSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// BanUser bans a user from the stream's channel, or times them out when a
+// duration is given
+func (s *Service) BanUser(ctx context.Context, videoID, actorID int64, req *models.ChannelBanRequest) (*models.ChannelBan, error) {
+	channelID, err := s.authorize(ctx, videoID, actorID, false)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == channelID {
+		return nil, ErrCannotTarget
+	}
+
+	ban := &models.ChannelBan{
+		ChannelID: channelID,
+		UserID:    req.UserID,
+		IssuedBy:  actorID,
+		Reason:    req.Reason,
+	}
+	if req.DurationSeconds > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.DurationSeconds) * time.Second)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.CreateBan(ctx, ban); err != nil {
+		return nil, err
+	}
+
+	return ban, nil
+}
+
+// UnbanUser lifts every ban and timeout for a user in the stream's channel
+func (s *Service) UnbanUser(ctx context.Context, videoID, actorID, userID int64) error {
+	channelID, err := s.authorize(ctx, videoID, actorID, false)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteBans(ctx, channelID, userID)
+}
+
+// AddModerator appoints a channel moderator. Only the creator may do this.
+func (s *Service) AddModerator(ctx context.Context, videoID, actorID, userID int64) error {
+	channelID, err := s.authorize(ctx, videoID, actorID, true)
+	if err != nil {
+		return err
+	}
+	if userID == channelID {
+		return ErrCannotTarget
+	}
+	return s.repo.AddChannelModerator(ctx, channelID, userID)
+}
+
+// RemoveModerator removes a channel moderator. Only the creator may do this.
+func (s *Service) RemoveModerator(ctx context.Context, videoID, actorID, userID int64) error {
+	channelID, err := s.authorize(ctx, videoID, actorID, true)
+	if err != nil {
+		return err
+	}
+	return s.repo.RemoveChannelModerator(ctx, channelID, userID)
+}
+
+// checkParticipation applies the participation rules and returns the room
+// settings, or nil settings if the user moderates the room and is exempt
+func (s *Service) checkParticipation(ctx context.Context, videoID, userID int64) (*models.RoomSettings, error) {
+	channelID, err := s.channelID(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	isModerator, err := s.isModerator(ctx, channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isModerator {
+		return nil, nil
+	}
+
+	ban, err := s.repo.GetActiveBan(ctx, channelID, userID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check bans: %w", err)
+	}
+	if ban != nil {
+		return nil, &BannedError{Ban: ban}
+	}
+
+	settings, err := s.repo.GetSettings(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room settings: %w", err)
+	}
+
+	if settings.VerifiedOnly || settings.MinAccountAgeMinutes > 0 {
+		participant, err := s.repo.GetParticipant(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get participant: %w", err)
+		}
+		if settings.VerifiedOnly && !participant.Verified {
+			return nil, ErrVerifiedOnly
+		}
+		minAge := time.Duration(settings.MinAccountAgeMinutes) * time.Minute
+		if time.Since(participant.CreatedAt) < minAge {
+			return nil, ErrAccountTooNew
+		}
+	}
+
+	return settings, nil
+}
+
+// authorize checks that the actor is the creator of the stream's channel or,
+// unless ownerOnly, one of its moderators, and returns the channel ID
+func (s *Service) authorize(ctx context.Context, videoID, actorID int64, ownerOnly bool) (int64, error) {
+	channelID, err := s.channelID(ctx, videoID)
+	if err != nil {
+		return 0, err
+	}
+
+	if actorID == channelID {
+		return channelID, nil
+	}
+	if ownerOnly {
+		return 0, ErrNotChannelOwner
+	}
+
+	isModerator, err := s.repo.IsChannelModerator(ctx, channelID, actorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check channel moderator: %w", err)
+	}
+	if !isModerator {
+		return 0, ErrNotRoomModerator
+	}
+
+	return channelID, nil
+}
+
+func (s *Service) channelID(ctx context.Context, videoID int64) (int64, error) {
+	channelID, err := s.repo.GetChannelID(ctx, videoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrVideoNotFound
+		}
+		return 0, fmt.Errorf("failed to get video owner: %w", err)
+	}
+	return channelID, nil
+}
+
+func (s *Service) isModerator(ctx context.Context, channelID, userID int64) (bool, error) {
+	if userID == channelID {
+		return true, nil
+	}
+	isModerator, err := s.repo.IsChannelModerator(ctx, channelID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check channel moderator: %w", err)
+	}
+	return isModerator, nil
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
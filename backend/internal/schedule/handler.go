@@ -0,0 +1,127 @@
+package schedule
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles stream reminder HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new schedule handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Remind handles asking to be reminded before a scheduled stream starts
+// @Summary Remind me of a scheduled stream
+// @Description Asking again has no effect
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /videos/{id}/reminder [post]
+func (h *Handler) Remind(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Remind(c.Request.Context(), videoID, c.GetInt64("user_id")); err != nil {
+		respondError(c, err, "Failed to create reminder")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "You will be reminded before the stream starts",
+	})
+}
+
+// CancelReminder handles removing a reminder for a scheduled stream
+// @Summary Cancel a stream reminder
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /videos/{id}/reminder [delete]
+func (h *Handler) CancelReminder(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelReminder(c.Request.Context(), videoID, c.GetInt64("user_id")); err != nil {
+		respondError(c, err, "Failed to cancel reminder")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Reminder canceled",
+	})
+}
+
+// GetReminders handles getting the current user's stream reminders
+// @Summary Get my stream reminders
+// @Tags videos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.StreamReminder
+// @Failure 401 {object} models.ErrorResponse
+// @Router /reminders [get]
+func (h *Handler) GetReminders(c *gin.Context) {
+	reminders, err := h.service.GetReminders(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get reminders")
+		return
+	}
+
+	c.JSON(http.StatusOK, reminders)
+}
+
+func parseVideoID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid video ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps schedule service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrVideoNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrNotScheduled):
+		status, code = http.StatusConflict, "not_scheduled"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,271 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// streamColumns is the column list matching scanStream
+const streamColumns = `v.id, v.user_id, v.title, v.is_live, v.status, v.scheduled_at, v.taken_down_at, v.canceled_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStream(row rowScanner) (*models.Video, error) {
+	stream := &models.Video{}
+	err := row.Scan(
+		&stream.ID,
+		&stream.UserID,
+		&stream.Title,
+		&stream.IsLive,
+		&stream.Status,
+		&stream.ScheduledAt,
+		&stream.TakenDownAt,
+		&stream.CanceledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetStream retrieves the fields of a video that scheduling depends on, as
+// seen by the given viewer. Streams of shadow-banned creators are visible
+// only to the creator.
+func (r *PostgresRepository) GetStream(ctx context.Context, videoID, viewerID int64) (*models.Video, error) {
+	query := `
+		SELECT ` + streamColumns + `
+		FROM videos v
+		JOIN users u ON u.id = v.user_id
+		WHERE v.id = $1 AND (u.account_status IN ('active', 'suspended') OR v.user_id = $2)
+	`
+	return scanStream(r.db.QueryRowContext(ctx, query, videoID, viewerID))
+}
+
+// CreateReminder records a reminder, doing nothing if it exists
+func (r *PostgresRepository) CreateReminder(ctx context.Context, videoID, userID int64) error {
+	query := `
+		INSERT INTO stream_reminders (video_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (video_id, user_id) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, videoID, userID)
+	return err
+}
+
+// DeleteReminder deletes a reminder
+func (r *PostgresRepository) DeleteReminder(ctx context.Context, videoID, userID int64) error {
+	query := `DELETE FROM stream_reminders WHERE video_id = $1 AND user_id = $2`
+	_, err := r.db.ExecContext(ctx, query, videoID, userID)
+	return err
+}
+
+// GetRemindersByUser retrieves a user's reminders for streams that are still
+// scheduled, soonest first
+func (r *PostgresRepository) GetRemindersByUser(ctx context.Context, userID int64) ([]*models.StreamReminder, error) {
+	query := `
+		SELECT sr.video_id, sr.user_id, v.title, v.scheduled_at, sr.created_at
+		FROM stream_reminders sr
+		JOIN videos v ON v.id = sr.video_id
+		WHERE sr.user_id = $1
+			AND v.status = 'scheduled' AND v.canceled_at IS NULL AND v.taken_down_at IS NULL
+		ORDER BY v.scheduled_at, v.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := make([]*models.StreamReminder, 0)
+	for rows.Next() {
+		reminder := &models.StreamReminder{}
+		err := rows.Scan(
+			&reminder.VideoID,
+			&reminder.UserID,
+			&reminder.Title,
+			&reminder.ScheduledAt,
+			&reminder.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+// GetReminderUserIDs retrieves the users who asked to be reminded of a stream
+func (r *PostgresRepository) GetReminderUserIDs(ctx context.Context, videoID int64) ([]int64, error) {
+	query := `SELECT user_id FROM stream_reminders WHERE video_id = $1 ORDER BY user_id`
+
+	rows, err := r.db.QueryContext(ctx, query, videoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// GetDueStreams retrieves scheduled streams of creators in good standing
+// that start before the given time and have not been reminded
+func (r *PostgresRepository) GetDueStreams(ctx context.Context, before time.Time, limit int) ([]*models.Video, error) {
+	query := `
+		SELECT ` + streamColumns + `
+		FROM videos v
+		JOIN users u ON u.id = v.user_id
+		WHERE v.status = 'scheduled' AND v.scheduled_at <= $1 AND v.reminded_at IS NULL
+			AND v.canceled_at IS NULL AND v.taken_down_at IS NULL
+			AND u.account_status IN ('active', 'suspended')
+		ORDER BY v.scheduled_at
+		LIMIT $2
+	`
+	return r.queryStreams(ctx, query, before, limit)
+}
+
+// MarkReminded records that a stream's viewers were reminded for the given
+// start time
+func (r *PostgresRepository) MarkReminded(ctx context.Context, videoID int64, scheduledAt time.Time) error {
+	query := `
+		UPDATE videos
+		SET reminded_at = NOW()
+		WHERE id = $1 AND scheduled_at = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, videoID, scheduledAt)
+	return err
+}
+
+// GetExpiredStreams retrieves scheduled streams that were due to start
+// before the given time and never went live
+func (r *PostgresRepository) GetExpiredStreams(ctx context.Context, before time.Time, limit int) ([]*models.Video, error) {
+	query := `
+		SELECT ` + streamColumns + `
+		FROM videos v
+		WHERE v.status = 'scheduled' AND v.scheduled_at <= $1
+			AND v.is_live = FALSE AND v.canceled_at IS NULL
+		ORDER BY v.scheduled_at
+		LIMIT $2
+	`
+	return r.queryStreams(ctx, query, before, limit)
+}
+
+// CancelStream cancels a scheduled stream that has not gone live. It returns
+// sql.ErrNoRows if the stream went live or is already canceled.
+func (r *PostgresRepository) CancelStream(ctx context.Context, videoID int64, reason string) error {
+	query := `
+		UPDATE videos
+		SET canceled_at = NOW(), cancel_reason = $2
+		WHERE id = $1 AND status = 'scheduled' AND is_live = FALSE AND canceled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, videoID, reason)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetNewlyLiveStreams retrieves streams of creators in good standing that
+// went live after the given time and have not been announced
+func (r *PostgresRepository) GetNewlyLiveStreams(ctx context.Context, since time.Time, limit int) ([]*models.Video, error) {
+	query := `
+		SELECT ` + streamColumns + `
+		FROM videos v
+		JOIN users u ON u.id = v.user_id
+		WHERE v.is_live = TRUE AND v.live_notified_at IS NULL AND v.live_started_at > $1
+			AND v.taken_down_at IS NULL
+			AND u.account_status IN ('active', 'suspended')
+		ORDER BY v.live_started_at
+		LIMIT $2
+	`
+	return r.queryStreams(ctx, query, since, limit)
+}
+
+// GetLiveAudience retrieves the creator's current subscribers and the
+// viewers who asked to be reminded of the stream
+func (r *PostgresRepository) GetLiveAudience(ctx context.Context, videoID, creatorID int64, now time.Time) ([]int64, error) {
+	query := `
+		SELECT subscriber_id FROM subscriptions
+		WHERE creator_id = $2
+			AND (status IN ('active', 'grace') OR (status = 'canceled' AND current_period_end > $3))
+		UNION
+		SELECT user_id FROM stream_reminders WHERE video_id = $1
+		ORDER BY 1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, videoID, creatorID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// MarkLiveNotified records that a stream's audience was told it went live
+func (r *PostgresRepository) MarkLiveNotified(ctx context.Context, videoID int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE videos SET live_notified_at = NOW() WHERE id = $1`, videoID)
+	return err
+}
+
+func (r *PostgresRepository) queryStreams(ctx context.Context, query string, args ...interface{}) ([]*models.Video, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var streams []*models.Video
+	for rows.Next() {
+		stream, err := scanStream(rows)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+
+	return streams, rows.Err()
+}
@@ -0,0 +1,238 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/video"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// CancelReason is recorded on scheduled streams that never went live
+const CancelReason = "The stream did not start"
+
+// batchSize bounds how many streams one scheduler pass handles
+const batchSize = 100
+
+// liveNoticeWindow is how soon after going live the audience must be told;
+// streams that went live longer ago are not announced
+const liveNoticeWindow = 10 * time.Minute
+
+var (
+	ErrVideoNotFound = errors.New("video not found")
+	ErrNotScheduled  = errors.New("this stream is not scheduled")
+)
+
+// Repository defines the interface for scheduled stream data access
+type Repository interface {
+	GetStream(ctx context.Context, videoID, viewerID int64) (*models.Video, error)
+	CreateReminder(ctx context.Context, videoID, userID int64) error
+	DeleteReminder(ctx context.Context, videoID, userID int64) error
+	GetRemindersByUser(ctx context.Context, userID int64) ([]*models.StreamReminder, error)
+	GetReminderUserIDs(ctx context.Context, videoID int64) ([]int64, error)
+	// GetDueStreams returns scheduled streams starting before the given time
+	// whose viewers have not been reminded yet
+	GetDueStreams(ctx context.Context, before time.Time, limit int) ([]*models.Video, error)
+	// MarkReminded records that a stream's viewers were reminded, unless it
+	// was rescheduled in the meantime
+	MarkReminded(ctx context.Context, videoID int64, scheduledAt time.Time) error
+	// GetExpiredStreams returns scheduled streams that were due to start
+	// before the given time and never went live
+	GetExpiredStreams(ctx context.Context, before time.Time, limit int) ([]*models.Video, error)
+	// CancelStream cancels a scheduled stream that has not gone live. It
+	// returns sql.ErrNoRows if the stream went live or was canceled.
+	CancelStream(ctx context.Context, videoID int64, reason string) error
+	// GetNewlyLiveStreams returns live streams that went live after the given
+	// time and have not been announced
+	GetNewlyLiveStreams(ctx context.Context, since time.Time, limit int) ([]*models.Video, error)
+	// GetLiveAudience returns the users to tell that a stream went live: the
+	// creator's subscribers and viewers who asked to be reminded
+	GetLiveAudience(ctx context.Context, videoID, creatorID int64, now time.Time) ([]int64, error)
+	MarkLiveNotified(ctx context.Context, videoID int64) error
+}
+
+// Notifier tells viewers about the streams they follow
+type Notifier interface {
+	StreamLive(ctx context.Context, stream *models.Video, userIDs []int64) error
+	StreamStarting(ctx context.Context, stream *models.Video, userIDs []int64) error
+	StreamCanceled(ctx context.Context, stream *models.Video, userIDs []int64) error
+}
+
+// Service manages stream reminders and the lifecycle of scheduled streams
+type Service struct {
+	repo         Repository
+	notifier     Notifier
+	reminderLead time.Duration
+	cancelAfter  time.Duration
+}
+
+// NewService creates a new schedule service. Viewers are reminded
+// reminderLead before a stream starts, and streams that have not gone live
+// cancelAfter their start time are canceled.
+func NewService(repo Repository, notifier Notifier, reminderLead, cancelAfter time.Duration) *Service {
+	return &Service{
+		repo:         repo,
+		notifier:     notifier,
+		reminderLead: reminderLead,
+		cancelAfter:  cancelAfter,
+	}
+}
+
+// Remind asks for the user to be reminded before a scheduled stream starts.
+// Asking again has no effect.
+func (s *Service) Remind(ctx context.Context, videoID, userID int64) error {
+	stream, err := s.getStream(ctx, videoID, userID)
+	if err != nil {
+		return err
+	}
+	if stream.Status != video.StatusScheduled || stream.CanceledAt != nil {
+		return ErrNotScheduled
+	}
+
+	if err := s.repo.CreateReminder(ctx, videoID, userID); err != nil {
+		return fmt.Errorf("failed to create reminder: %w", err)
+	}
+	return nil
+}
+
+// CancelReminder removes the user's reminder for a stream, if any
+func (s *Service) CancelReminder(ctx context.Context, videoID, userID int64) error {
+	if err := s.repo.DeleteReminder(ctx, videoID, userID); err != nil {
+		return fmt.Errorf("failed to delete reminder: %w", err)
+	}
+	return nil
+}
+
+// GetReminders retrieves the user's reminders for upcoming streams, soonest
+// first
+func (s *Service) GetReminders(ctx context.Context, userID int64) ([]*models.StreamReminder, error) {
+	reminders, err := s.repo.GetRemindersByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// ProcessSchedule announces streams that went live, reminds viewers of
+// streams about to start and cancels streams that never went live. Tickets
+// to canceled streams are refunded by the ticket service.
+func (s *Service) ProcessSchedule(ctx context.Context, now time.Time) error {
+	live, err := s.repo.GetNewlyLiveStreams(ctx, now.Add(-liveNoticeWindow), batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get live streams: %w", err)
+	}
+	for _, stream := range live {
+		if err := s.announce(ctx, stream, now); err != nil {
+			logger.ErrorLogger.Printf("Failed to announce video %d: %v", stream.ID, err)
+		}
+	}
+
+	due, err := s.repo.GetDueStreams(ctx, now.Add(s.reminderLead), batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get due streams: %w", err)
+	}
+	for _, stream := range due {
+		if err := s.remind(ctx, stream); err != nil {
+			logger.ErrorLogger.Printf("Failed to send reminders for video %d: %v", stream.ID, err)
+		}
+	}
+
+	expired, err := s.repo.GetExpiredStreams(ctx, now.Add(-s.cancelAfter), batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get expired streams: %w", err)
+	}
+	for _, stream := range expired {
+		if err := s.cancel(ctx, stream); err != nil {
+			logger.ErrorLogger.Printf("Failed to cancel video %d: %v", stream.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunScheduler processes the schedule every interval until ctx is cancelled
+func (s *Service) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ProcessSchedule(ctx, time.Now()); err != nil {
+				logger.ErrorLogger.Printf("Failed to process stream schedule: %v", err)
+			}
+		}
+	}
+}
+
+// announce tells a stream's audience that it went live. Announcements that
+// fail to send are retried on the next pass.
+func (s *Service) announce(ctx context.Context, stream *models.Video, now time.Time) error {
+	userIDs, err := s.repo.GetLiveAudience(ctx, stream.ID, stream.UserID, now)
+	if err != nil {
+		return fmt.Errorf("failed to get audience: %w", err)
+	}
+	if len(userIDs) > 0 {
+		if err := s.notifier.StreamLive(ctx, stream, userIDs); err != nil {
+			return err
+		}
+	}
+	return s.repo.MarkLiveNotified(ctx, stream.ID)
+}
+
+// remind notifies a stream's viewers that it is about to start. Reminders
+// that fail to send are retried on the next pass.
+func (s *Service) remind(ctx context.Context, stream *models.Video) error {
+	userIDs, err := s.repo.GetReminderUserIDs(ctx, stream.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get reminders: %w", err)
+	}
+	if len(userIDs) > 0 {
+		if err := s.notifier.StreamStarting(ctx, stream, userIDs); err != nil {
+			return err
+		}
+	}
+	return s.repo.MarkReminded(ctx, stream.ID, *stream.ScheduledAt)
+}
+
+// cancel cancels a stream that never went live and tells its viewers.
+// Notification failures are logged: the stream is already canceled.
+func (s *Service) cancel(ctx context.Context, stream *models.Video) error {
+	if err := s.repo.CancelStream(ctx, stream.ID, CancelReason); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	userIDs, err := s.repo.GetReminderUserIDs(ctx, stream.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get reminders: %w", err)
+	}
+	if len(userIDs) > 0 {
+		if err := s.notifier.StreamCanceled(ctx, stream, userIDs); err != nil {
+			logger.ErrorLogger.Printf("Failed to notify viewers of canceled video %d: %v", stream.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) getStream(ctx context.Context, videoID, viewerID int64) (*models.Video, error) {
+	stream, err := s.repo.GetStream(ctx, videoID, viewerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	if stream.TakenDownAt != nil {
+		return nil, ErrVideoNotFound
+	}
+	return stream, nil
+}
@@ -0,0 +1,192 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/video"
+)
+
+// memoryRepo is an in-memory Repository
+type memoryRepo struct {
+	videos    map[int64]*models.Video
+	reminded  map[int64]time.Time
+	reminders map[int64][]int64
+}
+
+func newMemoryRepo(videos ...*models.Video) *memoryRepo {
+	repo := &memoryRepo{
+		videos:    make(map[int64]*models.Video),
+		reminded:  make(map[int64]time.Time),
+		reminders: make(map[int64][]int64),
+	}
+	for _, v := range videos {
+		repo.videos[v.ID] = v
+	}
+	return repo
+}
+
+func (r *memoryRepo) GetStream(ctx context.Context, videoID, viewerID int64) (*models.Video, error) {
+	v, ok := r.videos[videoID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *v
+	return &copied, nil
+}
+
+func (r *memoryRepo) CreateReminder(ctx context.Context, videoID, userID int64) error {
+	for _, id := range r.reminders[videoID] {
+		if id == userID {
+			return nil
+		}
+	}
+	r.reminders[videoID] = append(r.reminders[videoID], userID)
+	return nil
+}
+
+func (r *memoryRepo) DeleteReminder(ctx context.Context, videoID, userID int64) error {
+	return nil
+}
+
+func (r *memoryRepo) GetRemindersByUser(ctx context.Context, userID int64) ([]*models.StreamReminder, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) GetReminderUserIDs(ctx context.Context, videoID int64) ([]int64, error) {
+	return r.reminders[videoID], nil
+}
+
+func (r *memoryRepo) GetDueStreams(ctx context.Context, before time.Time, limit int) ([]*models.Video, error) {
+	var streams []*models.Video
+	for _, v := range r.videos {
+		if _, ok := r.reminded[v.ID]; ok {
+			continue
+		}
+		if r.scheduled(v) && !v.ScheduledAt.After(before) {
+			streams = append(streams, v)
+		}
+	}
+	return streams, nil
+}
+
+func (r *memoryRepo) MarkReminded(ctx context.Context, videoID int64, scheduledAt time.Time) error {
+	r.reminded[videoID] = scheduledAt
+	return nil
+}
+
+func (r *memoryRepo) GetExpiredStreams(ctx context.Context, before time.Time, limit int) ([]*models.Video, error) {
+	var streams []*models.Video
+	for _, v := range r.videos {
+		if r.scheduled(v) && !v.IsLive && !v.ScheduledAt.After(before) {
+			streams = append(streams, v)
+		}
+	}
+	return streams, nil
+}
+
+func (r *memoryRepo) CancelStream(ctx context.Context, videoID int64, reason string) error {
+	v := r.videos[videoID]
+	if !r.scheduled(v) || v.IsLive {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	v.CanceledAt = &now
+	v.CancelReason = reason
+	return nil
+}
+
+func (r *memoryRepo) GetNewlyLiveStreams(ctx context.Context, since time.Time, limit int) ([]*models.Video, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) GetLiveAudience(ctx context.Context, videoID, creatorID int64, now time.Time) ([]int64, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) MarkLiveNotified(ctx context.Context, videoID int64) error {
+	return nil
+}
+
+func (r *memoryRepo) scheduled(v *models.Video) bool {
+	return v.Status == video.StatusScheduled && v.CanceledAt == nil
+}
+
+// recordingNotifier records the notifications sent per video
+type recordingNotifier struct {
+	starting map[int64][]int64
+	canceled map[int64][]int64
+}
+
+func (n *recordingNotifier) StreamLive(ctx context.Context, stream *models.Video, userIDs []int64) error {
+	return nil
+}
+
+func (n *recordingNotifier) StreamStarting(ctx context.Context, stream *models.Video, userIDs []int64) error {
+	n.starting[stream.ID] = userIDs
+	return nil
+}
+
+func (n *recordingNotifier) StreamCanceled(ctx context.Context, stream *models.Video, userIDs []int64) error {
+	n.canceled[stream.ID] = userIDs
+	return nil
+}
+
+func TestProcessSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		ts := now.Add(d)
+		return &ts
+	}
+
+	repo := newMemoryRepo(
+		&models.Video{ID: 1, Status: video.StatusScheduled, ScheduledAt: at(10 * time.Minute)},
+		&models.Video{ID: 2, Status: video.StatusScheduled, ScheduledAt: at(2 * time.Hour)},
+		&models.Video{ID: 3, Status: video.StatusScheduled, ScheduledAt: at(-time.Hour)},
+		&models.Video{ID: 4, Status: video.StatusPublished, ScheduledAt: at(-time.Hour)},
+	)
+	repo.reminded[3] = *repo.videos[3].ScheduledAt
+	notifier := &recordingNotifier{starting: make(map[int64][]int64), canceled: make(map[int64][]int64)}
+	svc := NewService(repo, notifier, 15*time.Minute, 30*time.Minute)
+	ctx := context.Background()
+
+	for _, id := range []int64{1, 2, 3} {
+		if err := svc.Remind(ctx, id, 20); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := svc.Remind(ctx, 4, 20); err != ErrNotScheduled {
+		t.Errorf("Expected error %v, got %v", ErrNotScheduled, err)
+	}
+
+	if err := svc.ProcessSchedule(ctx, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := notifier.starting[1]; !ok {
+		t.Errorf("Expected a reminder for the stream starting soon")
+	}
+	if _, ok := notifier.starting[2]; ok {
+		t.Errorf("Expected no reminder for the later stream")
+	}
+	if repo.videos[3].CanceledAt == nil || repo.videos[3].CancelReason != CancelReason {
+		t.Errorf("Expected the stream that never started to be canceled")
+	}
+	if _, ok := notifier.canceled[3]; !ok {
+		t.Errorf("Expected viewers of the canceled stream to be notified")
+	}
+	if repo.videos[4].CanceledAt != nil {
+		t.Errorf("Expected the published video to be left alone")
+	}
+
+	delete(notifier.starting, 1)
+	if err := svc.ProcessSchedule(ctx, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := notifier.starting[1]; ok {
+		t.Errorf("Expected viewers to be reminded once")
+	}
+}
@@ -0,0 +1,230 @@
+package subscriptions
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles subscription HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new subscription handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetTiers handles getting a creator's subscription tiers
+// @Summary Get a creator's subscription tiers
+// @Tags subscriptions
+// @Produce json
+// @Param user_id path int true "Creator ID"
+// @Success 200 {array} models.SubscriptionTier
+// @Failure 400 {object} models.ErrorResponse
+// @Router /users/{user_id}/tiers [get]
+func (h *Handler) GetTiers(c *gin.Context) {
+	creatorID, ok := parseID(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	tiers, err := h.service.GetTiers(c.Request.Context(), creatorID)
+	if err != nil {
+		respondError(c, err, "Failed to get tiers")
+		return
+	}
+
+	c.JSON(http.StatusOK, tiers)
+}
+
+// CreateTier handles a creator adding a subscription tier
+// @Summary Create a subscription tier
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateTierRequest true "Tier"
+// @Success 201 {object} models.SubscriptionTier
+// @Failure 400 {object} models.ErrorResponse
+// @Router /subscriptions/tiers [post]
+func (h *Handler) CreateTier(c *gin.Context) {
+	var req models.CreateTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tier, err := h.service.CreateTier(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to create tier")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tier)
+}
+
+// UpdateTier handles a creator editing one of their tiers
+// @Summary Update a subscription tier
+// @Description Price changes apply from each subscriber's next renewal. Deactivated tiers take no new subscribers and are not renewed.
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tier ID"
+// @Param request body models.UpdateTierRequest true "Changes"
+// @Success 200 {object} models.SubscriptionTier
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /subscriptions/tiers/{id} [patch]
+func (h *Handler) UpdateTier(c *gin.Context) {
+	id, ok := parseID(c, "id", "Invalid tier ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	tier, err := h.service.UpdateTier(c.Request.Context(), id, c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to update tier")
+		return
+	}
+
+	c.JSON(http.StatusOK, tier)
+}
+
+// GetSubscriptions handles getting the current user's subscriptions
+// @Summary Get my subscriptions
+// @Tags subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Subscription
+// @Failure 401 {object} models.ErrorResponse
+// @Router /subscriptions [get]
+func (h *Handler) GetSubscriptions(c *gin.Context) {
+	subs, err := h.service.GetSubscriptions(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get subscriptions")
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// Subscribe handles subscribing to a creator's tier
+// @Summary Subscribe to a creator
+// @Description Charges the tier's price in coins for the first 30-day period
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SubscribeRequest true "Tier"
+// @Success 201 {object} models.Subscription
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 402 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /subscriptions [post]
+func (h *Handler) Subscribe(c *gin.Context) {
+	var req models.SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.service.Subscribe(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		respondError(c, err, "Failed to subscribe")
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+// Cancel handles canceling a subscription
+// @Summary Cancel a subscription
+// @Description The subscription stays active until the end of the paid period
+// @Tags subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} models.Subscription
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /subscriptions/{id} [delete]
+func (h *Handler) Cancel(c *gin.Context) {
+	id, ok := parseID(c, "id", "Invalid subscription ID")
+	if !ok {
+		return
+	}
+
+	sub, err := h.service.Cancel(c.Request.Context(), id, c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to cancel subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+func parseID(c *gin.Context, param, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: message,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps subscription service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrTierNotFound), errors.Is(err, ErrSubscriptionNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrNotTierOwner):
+		status, code = http.StatusForbidden, "forbidden"
+	case errors.Is(err, ErrSelfSubscribe):
+		status, code = http.StatusBadRequest, "self_subscribe"
+	case errors.Is(err, ErrAlreadySubscribed):
+		status, code = http.StatusConflict, "already_subscribed"
+	case errors.Is(err, wallet.ErrInsufficientFunds):
+		status, code = http.StatusPaymentRequired, "insufficient_coins"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,364 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/earnings"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+)
+
+const tierColumns = `t.id, t.creator_id, t.name, t.price_coins, t.perks, t.active, t.created_at, t.updated_at`
+
+const subscriptionColumns = `s.id, s.subscriber_id, s.creator_id, s.tier_id, s.status, s.periods,
+	s.current_period_start, s.current_period_end, s.grace_until, s.canceled_at, s.created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTier(row rowScanner) (*models.SubscriptionTier, error) {
+	tier := &models.SubscriptionTier{}
+	var perks []byte
+	err := row.Scan(
+		&tier.ID,
+		&tier.CreatorID,
+		&tier.Name,
+		&tier.PriceCoins,
+		&perks,
+		&tier.Active,
+		&tier.CreatedAt,
+		&tier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(perks, &tier.Perks); err != nil {
+		return nil, fmt.Errorf("failed to decode perks: %w", err)
+	}
+	return tier, nil
+}
+
+// scanSubscription scans subscriptionColumns followed by tierColumns
+func scanSubscription(row rowScanner) (*models.Subscription, error) {
+	sub := &models.Subscription{Tier: &models.SubscriptionTier{}}
+	var perks []byte
+	err := row.Scan(
+		&sub.ID,
+		&sub.SubscriberID,
+		&sub.CreatorID,
+		&sub.TierID,
+		&sub.Status,
+		&sub.Periods,
+		&sub.CurrentPeriodStart,
+		&sub.CurrentPeriodEnd,
+		&sub.GraceUntil,
+		&sub.CanceledAt,
+		&sub.CreatedAt,
+		&sub.Tier.ID,
+		&sub.Tier.CreatorID,
+		&sub.Tier.Name,
+		&sub.Tier.PriceCoins,
+		&perks,
+		&sub.Tier.Active,
+		&sub.Tier.CreatedAt,
+		&sub.Tier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(perks, &sub.Tier.Perks); err != nil {
+		return nil, fmt.Errorf("failed to decode perks: %w", err)
+	}
+	return sub, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// CreateTier creates a new subscription tier
+func (r *PostgresRepository) CreateTier(ctx context.Context, tier *models.SubscriptionTier) error {
+	perks, err := json.Marshal(tier.Perks)
+	if err != nil {
+		return fmt.Errorf("failed to encode perks: %w", err)
+	}
+
+	query := `
+		INSERT INTO subscription_tiers (creator_id, name, price_coins, perks, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, tier.CreatorID, tier.Name, tier.PriceCoins, perks, tier.Active).
+		Scan(&tier.ID, &tier.CreatedAt, &tier.UpdatedAt)
+}
+
+// UpdateTier updates a subscription tier
+func (r *PostgresRepository) UpdateTier(ctx context.Context, tier *models.SubscriptionTier) error {
+	perks, err := json.Marshal(tier.Perks)
+	if err != nil {
+		return fmt.Errorf("failed to encode perks: %w", err)
+	}
+
+	query := `
+		UPDATE subscription_tiers
+		SET name = $1, price_coins = $2, perks = $3, active = $4
+		WHERE id = $5
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, tier.Name, tier.PriceCoins, perks, tier.Active, tier.ID).
+		Scan(&tier.UpdatedAt)
+}
+
+// GetTier retrieves a subscription tier by ID
+func (r *PostgresRepository) GetTier(ctx context.Context, id int64) (*models.SubscriptionTier, error) {
+	query := `SELECT ` + tierColumns + ` FROM subscription_tiers t WHERE t.id = $1`
+	return scanTier(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetTiersByCreator retrieves a creator's active tiers, cheapest first
+func (r *PostgresRepository) GetTiersByCreator(ctx context.Context, creatorID int64) ([]*models.SubscriptionTier, error) {
+	query := `
+		SELECT ` + tierColumns + `
+		FROM subscription_tiers t
+		WHERE t.creator_id = $1 AND t.active = TRUE
+		ORDER BY t.price_coins, t.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := make([]*models.SubscriptionTier, 0)
+	for rows.Next() {
+		tier, err := scanTier(rows)
+		if err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+
+	return tiers, rows.Err()
+}
+
+// CreateSubscription posts the first period's journal entry and records the
+// subscription and its revenue event in one transaction
+func (r *PostgresRepository) CreateSubscription(ctx context.Context, sub *models.Subscription, entry *models.JournalEntry, revenue *models.RevenueEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := wallet.Post(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO subscriptions (subscriber_id, creator_id, tier_id, status, periods, current_period_start, current_period_end)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		sub.SubscriberID,
+		sub.CreatorID,
+		sub.TierID,
+		sub.Status,
+		sub.Periods,
+		sub.CurrentPeriodStart,
+		sub.CurrentPeriodEnd,
+	).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert subscription: %w", err)
+	}
+
+	if err := earnings.Record(ctx, tx, revenue); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit subscription: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSubscription saves a subscription if it is still in fromStatus after
+// fromPeriods periods, posting entry and recording revenue in the same
+// transaction if they are not nil. It returns false if the subscription was
+// changed in the meantime.
+func (r *PostgresRepository) UpdateSubscription(ctx context.Context, sub *models.Subscription, fromStatus string, fromPeriods int, entry *models.JournalEntry, revenue *models.RevenueEvent) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE subscriptions
+		SET status = $1, periods = $2, current_period_start = $3, current_period_end = $4,
+			grace_until = $5, canceled_at = $6
+		WHERE id = $7 AND status = $8 AND periods = $9
+	`
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		sub.Status,
+		sub.Periods,
+		sub.CurrentPeriodStart,
+		sub.CurrentPeriodEnd,
+		sub.GraceUntil,
+		sub.CanceledAt,
+		sub.ID,
+		fromStatus,
+		fromPeriods,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if entry != nil {
+		if _, err := wallet.Post(ctx, tx, entry); err != nil {
+			return false, err
+		}
+	}
+	if revenue != nil {
+		if err := earnings.Record(ctx, tx, revenue); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit subscription: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetSubscription retrieves a subscription by ID
+func (r *PostgresRepository) GetSubscription(ctx context.Context, id int64) (*models.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `, ` + tierColumns + `
+		FROM subscriptions s
+		JOIN subscription_tiers t ON t.id = s.tier_id
+		WHERE s.id = $1
+	`
+	return scanSubscription(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetLiveSubscription retrieves a subscriber's unexpired subscription to a
+// creator
+func (r *PostgresRepository) GetLiveSubscription(ctx context.Context, subscriberID, creatorID int64) (*models.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `, ` + tierColumns + `
+		FROM subscriptions s
+		JOIN subscription_tiers t ON t.id = s.tier_id
+		WHERE s.subscriber_id = $1 AND s.creator_id = $2 AND s.status <> 'expired'
+	`
+	return scanSubscription(r.db.QueryRowContext(ctx, query, subscriberID, creatorID))
+}
+
+// GetSubscriptionsBySubscriber retrieves a user's subscriptions, newest first
+func (r *PostgresRepository) GetSubscriptionsBySubscriber(ctx context.Context, subscriberID int64) ([]*models.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `, ` + tierColumns + `
+		FROM subscriptions s
+		JOIN subscription_tiers t ON t.id = s.tier_id
+		WHERE s.subscriber_id = $1
+		ORDER BY s.id DESC
+	`
+	return r.querySubscriptions(ctx, query, subscriberID)
+}
+
+// GetDueSubscriptions retrieves subscriptions whose period has ended and
+// those in grace, which are retried on every run
+func (r *PostgresRepository) GetDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]*models.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `, ` + tierColumns + `
+		FROM subscriptions s
+		JOIN subscription_tiers t ON t.id = s.tier_id
+		WHERE (s.status IN ('active', 'canceled') AND s.current_period_end <= $1)
+			OR s.status = 'grace'
+		ORDER BY s.current_period_end
+		LIMIT $2
+	`
+	return r.querySubscriptions(ctx, query, now, limit)
+}
+
+// IsSubscriber reports whether a user has a subscription to a creator that
+// is active, in grace, or canceled but still within its paid period
+func (r *PostgresRepository) IsSubscriber(ctx context.Context, creatorID, userID int64, now time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM subscriptions
+			WHERE creator_id = $1 AND subscriber_id = $2
+				AND (status IN ('active', 'grace') OR (status = 'canceled' AND current_period_end > $3))
+		)
+	`
+
+	var subscribed bool
+	err := r.db.QueryRowContext(ctx, query, creatorID, userID, now).Scan(&subscribed)
+	return subscribed, err
+}
+
+// GetBadge retrieves the tier name of a user's current subscription to a
+// creator and when their subscription began
+func (r *PostgresRepository) GetBadge(ctx context.Context, creatorID, userID int64, now time.Time) (*models.SubscriberBadge, error) {
+	query := `
+		SELECT t.name, s.created_at
+		FROM subscriptions s
+		JOIN subscription_tiers t ON t.id = s.tier_id
+		WHERE s.creator_id = $1 AND s.subscriber_id = $2
+			AND (s.status IN ('active', 'grace') OR (s.status = 'canceled' AND s.current_period_end > $3))
+	`
+
+	badge := &models.SubscriberBadge{}
+	err := r.db.QueryRowContext(ctx, query, creatorID, userID, now).Scan(&badge.TierName, &badge.Since)
+	if err != nil {
+		return nil, err
+	}
+	return badge, nil
+}
+
+func (r *PostgresRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]*models.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := make([]*models.Subscription, 0)
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
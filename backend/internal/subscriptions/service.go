@@ -0,0 +1,412 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/earnings"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/gifts"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// Subscription statuses
+const (
+	// StatusActive subscriptions renew at the end of each period
+	StatusActive = "active"
+	// StatusCanceled subscriptions run to the end of the paid period
+	StatusCanceled = "canceled"
+	// StatusGrace subscriptions failed to renew and are retried until their
+	// grace period ends; the subscriber keeps their perks meanwhile
+	StatusGrace = "grace"
+	// StatusExpired subscriptions have ended
+	StatusExpired = "expired"
+)
+
+// EntryKind is the journal entry kind for subscription payments
+const EntryKind = "subscription"
+
+// Period is how long one payment keeps a subscription active
+const Period = 30 * 24 * time.Hour
+
+var (
+	ErrTierNotFound         = errors.New("subscription tier not found")
+	ErrNotTierOwner         = errors.New("only the creator can edit this tier")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrSelfSubscribe        = errors.New("creators cannot subscribe to themselves")
+	ErrAlreadySubscribed    = errors.New("already subscribed to this creator")
+)
+
+// Repository defines the interface for subscription data access
+type Repository interface {
+	CreateTier(ctx context.Context, tier *models.SubscriptionTier) error
+	UpdateTier(ctx context.Context, tier *models.SubscriptionTier) error
+	GetTier(ctx context.Context, id int64) (*models.SubscriptionTier, error)
+	GetTiersByCreator(ctx context.Context, creatorID int64) ([]*models.SubscriptionTier, error)
+	CreateSubscription(ctx context.Context, sub *models.Subscription, entry *models.JournalEntry, revenue *models.RevenueEvent) error
+	UpdateSubscription(ctx context.Context, sub *models.Subscription, fromStatus string, fromPeriods int, entry *models.JournalEntry, revenue *models.RevenueEvent) (bool, error)
+	GetSubscription(ctx context.Context, id int64) (*models.Subscription, error)
+	GetLiveSubscription(ctx context.Context, subscriberID, creatorID int64) (*models.Subscription, error)
+	GetSubscriptionsBySubscriber(ctx context.Context, subscriberID int64) ([]*models.Subscription, error)
+	GetDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]*models.Subscription, error)
+	IsSubscriber(ctx context.Context, creatorID, userID int64, now time.Time) (bool, error)
+	GetBadge(ctx context.Context, creatorID, userID int64, now time.Time) (*models.SubscriberBadge, error)
+}
+
+// Service handles creator subscriptions, paid in coins each period
+type Service struct {
+	repo                 Repository
+	wallet               *wallet.Service
+	centsPer100Coins     int64
+	coinValueCentsPer100 int64
+	gracePeriod          time.Duration
+}
+
+// NewService creates a new subscription service. centsPer100Coins is what the
+// creator earns per 100 coins of subscriptions, out of the
+// coinValueCentsPer100 those coins are worth; renewals that fail are retried
+// for graceDays.
+func NewService(repo Repository, wallet *wallet.Service, centsPer100Coins, coinValueCentsPer100, graceDays int) *Service {
+	return &Service{
+		repo:                 repo,
+		wallet:               wallet,
+		centsPer100Coins:     int64(centsPer100Coins),
+		coinValueCentsPer100: int64(coinValueCentsPer100),
+		gracePeriod:          time.Duration(graceDays) * 24 * time.Hour,
+	}
+}
+
+// CreateTier adds a subscription tier to the creator's channel
+func (s *Service) CreateTier(ctx context.Context, creatorID int64, req *models.CreateTierRequest) (*models.SubscriptionTier, error) {
+	tier := &models.SubscriptionTier{
+		CreatorID:  creatorID,
+		Name:       req.Name,
+		PriceCoins: req.PriceCoins,
+		Perks:      req.Perks,
+		Active:     true,
+	}
+	if tier.Perks == nil {
+		tier.Perks = []string{}
+	}
+
+	if err := s.repo.CreateTier(ctx, tier); err != nil {
+		return nil, fmt.Errorf("failed to create tier: %w", err)
+	}
+	return tier, nil
+}
+
+// UpdateTier applies changes to one of the creator's tiers. Deactivated tiers
+// take no new subscribers and are not renewed.
+func (s *Service) UpdateTier(ctx context.Context, id, creatorID int64, req *models.UpdateTierRequest) (*models.SubscriptionTier, error) {
+	tier, err := s.getTier(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tier.CreatorID != creatorID {
+		return nil, ErrNotTierOwner
+	}
+
+	if req.Name != nil {
+		tier.Name = *req.Name
+	}
+	if req.PriceCoins != nil {
+		tier.PriceCoins = *req.PriceCoins
+	}
+	if req.Perks != nil {
+		tier.Perks = *req.Perks
+	}
+	if req.Active != nil {
+		tier.Active = *req.Active
+	}
+
+	if err := s.repo.UpdateTier(ctx, tier); err != nil {
+		return nil, fmt.Errorf("failed to update tier: %w", err)
+	}
+	return tier, nil
+}
+
+// GetTiers retrieves a creator's active tiers, cheapest first
+func (s *Service) GetTiers(ctx context.Context, creatorID int64) ([]*models.SubscriptionTier, error) {
+	tiers, err := s.repo.GetTiersByCreator(ctx, creatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tiers: %w", err)
+	}
+	return tiers, nil
+}
+
+// Subscribe charges the first period of a tier and starts the subscription.
+// Subscribing again to the tier of a canceled subscription resumes it
+// without charging.
+func (s *Service) Subscribe(ctx context.Context, subscriberID int64, req *models.SubscribeRequest) (*models.Subscription, error) {
+	tier, err := s.getTier(ctx, req.TierID)
+	if err != nil {
+		return nil, err
+	}
+	if !tier.Active {
+		return nil, ErrTierNotFound
+	}
+	if tier.CreatorID == subscriberID {
+		return nil, ErrSelfSubscribe
+	}
+
+	existing, err := s.repo.GetLiveSubscription(ctx, subscriberID, tier.CreatorID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if existing != nil {
+		if existing.Status != StatusCanceled || existing.TierID != tier.ID {
+			return nil, ErrAlreadySubscribed
+		}
+		resumed := *existing
+		resumed.Status = StatusActive
+		resumed.CanceledAt = nil
+		return s.update(ctx, &resumed, existing, nil, nil)
+	}
+
+	now := time.Now()
+	sub := &models.Subscription{
+		SubscriberID:       subscriberID,
+		CreatorID:          tier.CreatorID,
+		TierID:             tier.ID,
+		Tier:               tier,
+		Status:             StatusActive,
+		Periods:            1,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now.Add(Period),
+	}
+
+	key := fmt.Sprintf("%s:%d:%d:%d", EntryKind, subscriberID, tier.ID, now.Unix())
+	entry, err := s.chargeEntry(ctx, sub, tier, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateSubscription(ctx, sub, entry, s.revenue(sub, tier, key)); err != nil {
+		if errors.Is(err, wallet.ErrInsufficientFunds) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// Cancel stops a subscription from renewing; it stays active until the end
+// of the paid period. A subscription in grace has no paid period left and
+// ends immediately.
+func (s *Service) Cancel(ctx context.Context, id, subscriberID int64) (*models.Subscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if sub.SubscriberID != subscriberID {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	now := time.Now()
+	canceled := *sub
+	canceled.CanceledAt = &now
+	switch sub.Status {
+	case StatusActive:
+		canceled.Status = StatusCanceled
+	case StatusGrace:
+		canceled.Status = StatusExpired
+	default:
+		return sub, nil
+	}
+
+	return s.update(ctx, &canceled, sub, nil, nil)
+}
+
+// GetSubscriptions retrieves a user's subscriptions with their tiers
+func (s *Service) GetSubscriptions(ctx context.Context, subscriberID int64) ([]*models.Subscription, error) {
+	subs, err := s.repo.GetSubscriptionsBySubscriber(ctx, subscriberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// IsSubscriber reports whether a user currently has a creator's perks
+func (s *Service) IsSubscriber(ctx context.Context, creatorID, userID int64) (bool, error) {
+	return s.repo.IsSubscriber(ctx, creatorID, userID, time.Now())
+}
+
+// Badge returns the badge a user shows in a creator's channel, or nil if
+// they are not a subscriber
+func (s *Service) Badge(ctx context.Context, creatorID, userID int64) (*models.SubscriberBadge, error) {
+	badge, err := s.repo.GetBadge(ctx, creatorID, userID, time.Now())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return badge, err
+}
+
+// ProcessRenewals renews subscriptions whose period has ended, moves those
+// that cannot be paid into grace, and expires canceled subscriptions and
+// those whose grace period is over
+func (s *Service) ProcessRenewals(ctx context.Context) error {
+	now := time.Now()
+	subs, err := s.repo.GetDueSubscriptions(ctx, now, 500)
+	if err != nil {
+		return fmt.Errorf("failed to get due subscriptions: %w", err)
+	}
+
+	for _, sub := range subs {
+		if err := s.renew(ctx, sub, now); err != nil {
+			logger.ErrorLogger.Printf("Failed to renew subscription %d: %v", sub.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunRenewals processes renewals every interval until ctx is cancelled
+func (s *Service) RunRenewals(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ProcessRenewals(ctx); err != nil {
+				logger.ErrorLogger.Printf("Failed to process subscription renewals: %v", err)
+			}
+		}
+	}
+}
+
+func (s *Service) renew(ctx context.Context, sub *models.Subscription, now time.Time) error {
+	next := *sub
+
+	if sub.Status == StatusCanceled || (sub.Status == StatusGrace && sub.GraceUntil != nil && now.After(*sub.GraceUntil)) {
+		next.Status = StatusExpired
+		_, err := s.update(ctx, &next, sub, nil, nil)
+		return err
+	}
+
+	tier := sub.Tier
+	if !tier.Active {
+		next.Status = StatusExpired
+		_, err := s.update(ctx, &next, sub, nil, nil)
+		return err
+	}
+
+	next.Status = StatusActive
+	next.Periods = sub.Periods + 1
+	next.GraceUntil = nil
+	next.CurrentPeriodStart, next.CurrentPeriodEnd = NextPeriod(sub, now)
+
+	key := fmt.Sprintf("%s:%d:%d", EntryKind, sub.ID, next.Periods)
+	entry, err := s.chargeEntry(ctx, &next, tier, key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.update(ctx, &next, sub, entry, s.revenue(&next, tier, key))
+	if !errors.Is(err, wallet.ErrInsufficientFunds) {
+		return err
+	}
+	if sub.Status == StatusGrace {
+		return nil
+	}
+
+	graceUntil := sub.CurrentPeriodEnd.Add(s.gracePeriod)
+	grace := *sub
+	grace.Status = StatusGrace
+	grace.GraceUntil = &graceUntil
+	_, err = s.update(ctx, &grace, sub, nil, nil)
+	return err
+}
+
+// NextPeriod returns the period a renewal pays for. Renewals keep the billing
+// anchor; a subscription recovering from grace starts a new period now.
+func NextPeriod(sub *models.Subscription, now time.Time) (time.Time, time.Time) {
+	start := sub.CurrentPeriodEnd
+	if sub.Status == StatusGrace {
+		start = now
+	}
+	return start, start.Add(Period)
+}
+
+// update saves a subscription that was in the state of from, posting entry
+// and recording revenue in the same transaction if they are not nil
+func (s *Service) update(ctx context.Context, sub, from *models.Subscription, entry *models.JournalEntry, revenue *models.RevenueEvent) (*models.Subscription, error) {
+	updated, err := s.repo.UpdateSubscription(ctx, sub, from.Status, from.Periods, entry, revenue)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInsufficientFunds) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	if !updated {
+		return nil, fmt.Errorf("subscription %d was changed concurrently", sub.ID)
+	}
+	return sub, nil
+}
+
+func (s *Service) getTier(ctx context.Context, id int64) (*models.SubscriptionTier, error) {
+	tier, err := s.repo.GetTier(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTierNotFound
+		}
+		return nil, fmt.Errorf("failed to get tier: %w", err)
+	}
+	return tier, nil
+}
+
+// revenue builds the revenue event for one period, keyed like its journal
+// entry
+func (s *Service) revenue(sub *models.Subscription, tier *models.SubscriptionTier, key string) *models.RevenueEvent {
+	return earnings.NewEvent(sub.CreatorID, earnings.SourceSubscription, key, tier.PriceCoins, gifts.Earnings(tier.PriceCoins, s.centsPer100Coins), s.coinValueCentsPer100)
+}
+
+// chargeEntry builds the journal entry for one period: the subscriber's coins
+// go to the platform, and the creator's share is credited to their pending
+// earnings
+func (s *Service) chargeEntry(ctx context.Context, sub *models.Subscription, tier *models.SubscriptionTier, key string) (*models.JournalEntry, error) {
+	subscriberCoins, err := s.wallet.Account(ctx, sub.SubscriberID, wallet.AccountCoins, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, err
+	}
+	redeemed, err := s.wallet.PlatformAccount(ctx, wallet.PlatformCoinsRedeemed, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.JournalEntry{
+		IdempotencyKey: key,
+		Kind:           EntryKind,
+		Description:    fmt.Sprintf("%s subscription to user %d, period %d", tier.Name, sub.CreatorID, sub.Periods),
+		Postings: []*models.LedgerPosting{
+			{AccountID: subscriberCoins.ID, Currency: wallet.CurrencyCoin, Amount: -tier.PriceCoins},
+			{AccountID: redeemed.ID, Currency: wallet.CurrencyCoin, Amount: tier.PriceCoins},
+		},
+	}
+
+	if earnings := gifts.Earnings(tier.PriceCoins, s.centsPer100Coins); earnings > 0 {
+		pending, err := s.wallet.Account(ctx, sub.CreatorID, wallet.AccountEarningsPending, wallet.CurrencyUSD)
+		if err != nil {
+			return nil, err
+		}
+		funding, err := s.wallet.PlatformAccount(ctx, wallet.PlatformCreatorEarnings, wallet.CurrencyUSD)
+		if err != nil {
+			return nil, err
+		}
+		entry.Postings = append(entry.Postings,
+			&models.LedgerPosting{AccountID: funding.ID, Currency: wallet.CurrencyUSD, Amount: -earnings},
+			&models.LedgerPosting{AccountID: pending.ID, Currency: wallet.CurrencyUSD, Amount: earnings},
+		)
+	}
+
+	return entry, nil
+}
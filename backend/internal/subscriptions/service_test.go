@@ -0,0 +1,90 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+func TestNextPeriod(t *testing.T) {
+	end := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := end.Add(36 * time.Hour)
+
+	tests := []struct {
+		name      string
+		status    string
+		wantStart time.Time
+	}{
+		{"active renewal keeps anchor", StatusActive, end},
+		{"grace recovery starts now", StatusGrace, now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &models.Subscription{Status: tt.status, CurrentPeriodEnd: end}
+			start, periodEnd := NextPeriod(sub, now)
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("Expected start %v, got %v", tt.wantStart, start)
+			}
+			if want := tt.wantStart.Add(Period); !periodEnd.Equal(want) {
+				t.Errorf("Expected end %v, got %v", want, periodEnd)
+			}
+		})
+	}
+}
+
+// cancelRepo is a Repository holding one subscription; only the methods
+// Cancel uses are implemented
+type cancelRepo struct {
+	Repository
+	sub *models.Subscription
+}
+
+func (r *cancelRepo) GetSubscription(ctx context.Context, id int64) (*models.Subscription, error) {
+	if r.sub == nil || r.sub.ID != id {
+		return nil, sql.ErrNoRows
+	}
+	sub := *r.sub
+	return &sub, nil
+}
+
+func (r *cancelRepo) UpdateSubscription(ctx context.Context, sub *models.Subscription, fromStatus string, fromPeriods int, entry *models.JournalEntry, revenue *models.RevenueEvent) (bool, error) {
+	if r.sub.Status != fromStatus || r.sub.Periods != fromPeriods {
+		return false, nil
+	}
+	r.sub = sub
+	return true, nil
+}
+
+func TestCancel(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		subscriberID int64
+		wantStatus   string
+		wantErr      error
+	}{
+		{"active runs to period end", StatusActive, 1, StatusCanceled, nil},
+		{"grace ends immediately", StatusGrace, 1, StatusExpired, nil},
+		{"already canceled is unchanged", StatusCanceled, 1, StatusCanceled, nil},
+		{"other users cannot cancel", StatusActive, 2, StatusActive, ErrSubscriptionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &cancelRepo{sub: &models.Subscription{ID: 7, SubscriberID: 1, Status: tt.status, Periods: 1}}
+			svc := NewService(repo, nil, 50, 100, 3)
+
+			_, err := svc.Cancel(context.Background(), 7, tt.subscriberID)
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if repo.sub.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, repo.sub.Status)
+			}
+		})
+	}
+}
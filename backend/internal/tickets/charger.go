@@ -0,0 +1,47 @@
+package tickets
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrChargeDeclined = errors.New("charge declined")
+	ErrChargeNotFound = errors.New("charge not found")
+)
+
+// ChargeParams describes a charge to a buyer on behalf of a creator. Creating
+// a charge again with the same idempotency key returns the original charge.
+type ChargeParams struct {
+	BuyerID        int64
+	CreatorID      int64
+	Amount         int64
+	Currency       string
+	IdempotencyKey string
+	Description    string
+}
+
+// Charge is a completed charge. CreatorCents is what the creator earns from
+// it, in US cents.
+type Charge struct {
+	ID           string
+	Amount       int64
+	Currency     string
+	CreatorCents int64
+}
+
+// RefundParams describes a full refund of a charge. Refunding a charge again
+// has no further effect.
+type RefundParams struct {
+	ChargeID  string
+	BuyerID   int64
+	CreatorID int64
+	Amount    int64
+	Currency  string
+}
+
+// Charger takes payment for tickets and refunds it
+type Charger interface {
+	Charge(ctx context.Context, params *ChargeParams) (*Charge, error)
+	Refund(ctx context.Context, params *RefundParams) error
+}
@@ -0,0 +1,85 @@
+package tickets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeCharger is an in-process Charger for development and tests. Every
+// charge succeeds unless the buyer was added with Decline. Creators earn
+// nothing from fake charges.
+type FakeCharger struct {
+	mu       sync.Mutex
+	charges  map[string]*Charge
+	refunded map[string]bool
+	keys     map[string]string
+	declined map[int64]bool
+	seq      int
+}
+
+// NewFakeCharger creates a new fake charger
+func NewFakeCharger() *FakeCharger {
+	return &FakeCharger{
+		charges:  make(map[string]*Charge),
+		refunded: make(map[string]bool),
+		keys:     make(map[string]string),
+		declined: make(map[int64]bool),
+	}
+}
+
+// Decline makes every charge to a buyer fail
+func (f *FakeCharger) Decline(buyerID int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.declined[buyerID] = true
+}
+
+// Charge records a successful charge
+func (f *FakeCharger) Charge(ctx context.Context, params *ChargeParams) (*Charge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := f.keys[params.IdempotencyKey]; ok && params.IdempotencyKey != "" {
+		copied := *f.charges[id]
+		return &copied, nil
+	}
+	if f.declined[params.BuyerID] {
+		return nil, ErrChargeDeclined
+	}
+
+	f.seq++
+	charge := &Charge{
+		ID:       fmt.Sprintf("ch_fake%016d", f.seq),
+		Amount:   params.Amount,
+		Currency: params.Currency,
+	}
+	f.charges[charge.ID] = charge
+	if params.IdempotencyKey != "" {
+		f.keys[params.IdempotencyKey] = charge.ID
+	}
+
+	copied := *charge
+	return &copied, nil
+}
+
+// Refund marks a charge as refunded
+func (f *FakeCharger) Refund(ctx context.Context, params *RefundParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.charges[params.ChargeID]; !ok {
+		return ErrChargeNotFound
+	}
+	f.refunded[params.ChargeID] = true
+	return nil
+}
+
+// Refunded reports whether a charge has been refunded
+func (f *FakeCharger) Refunded(chargeID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.refunded[chargeID]
+}
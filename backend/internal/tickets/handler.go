@@ -0,0 +1,163 @@
+package tickets
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles ticket HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new ticket handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// BuyTicket handles buying a ticket to a ticketed stream
+// @Summary Buy a ticket
+// @Description Buying a ticket already held returns it with 200
+// @Tags tickets
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Success 201 {object} models.EventTicket
+// @Success 200 {object} models.EventTicket
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 402 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /videos/{id}/tickets [post]
+func (h *Handler) BuyTicket(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	ticket, replayed, err := h.service.BuyTicket(c.Request.Context(), videoID, c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to buy ticket")
+		return
+	}
+
+	if replayed {
+		c.JSON(http.StatusOK, ticket)
+		return
+	}
+	c.JSON(http.StatusCreated, ticket)
+}
+
+// CancelEvent handles a creator canceling a ticketed event or scheduled stream
+// @Summary Cancel a ticketed event or scheduled stream
+// @Description Ends the stream if it is live and refunds every ticket
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.CancelEventRequest false "Reason"
+// @Success 200 {object} models.Video
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /videos/{id}/cancel [post]
+func (h *Handler) CancelEvent(c *gin.Context) {
+	videoID, ok := parseVideoID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CancelEventRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	video, err := h.service.CancelEvent(c.Request.Context(), videoID, c.GetInt64("user_id"), req.Reason)
+	if err != nil {
+		respondError(c, err, "Failed to cancel event")
+		return
+	}
+
+	c.JSON(http.StatusOK, video)
+}
+
+// GetTickets handles getting the current user's tickets
+// @Summary Get my tickets
+// @Tags tickets
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.EventTicket
+// @Failure 401 {object} models.ErrorResponse
+// @Router /tickets [get]
+func (h *Handler) GetTickets(c *gin.Context) {
+	tickets, err := h.service.GetTickets(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		respondError(c, err, "Failed to get tickets")
+		return
+	}
+
+	c.JSON(http.StatusOK, tickets)
+}
+
+func parseVideoID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid video ID",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondError maps ticket service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrVideoNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrNotVideoOwner):
+		status, code = http.StatusForbidden, "forbidden"
+	case errors.Is(err, ErrNotTicketed):
+		status, code = http.StatusBadRequest, "not_ticketed"
+	case errors.Is(err, ErrSelfTicket):
+		status, code = http.StatusBadRequest, "self_ticket"
+	case errors.Is(err, ErrEventCanceled):
+		status, code = http.StatusConflict, "event_canceled"
+	case errors.Is(err, ErrEventEnded):
+		status, code = http.StatusConflict, "event_ended"
+	case errors.Is(err, wallet.ErrInsufficientFunds):
+		status, code = http.StatusPaymentRequired, "insufficient_coins"
+	case errors.Is(err, ErrChargeDeclined):
+		status, code = http.StatusPaymentRequired, "charge_declined"
+	case errors.Is(err, wallet.ErrIdempotencyConflict):
+		status, code = http.StatusConflict, "idempotency_conflict"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,250 @@
+package tickets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/earnings"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+const ticketColumns = `id, video_id, user_id, price_coins, charge_id, status, created_at, refunded_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTicket(row rowScanner) (*models.EventTicket, error) {
+	ticket := &models.EventTicket{}
+	err := row.Scan(
+		&ticket.ID,
+		&ticket.VideoID,
+		&ticket.UserID,
+		&ticket.PriceCoins,
+		&ticket.ChargeID,
+		&ticket.Status,
+		&ticket.CreatedAt,
+		&ticket.RefundedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetEvent retrieves the fields of a video that ticket sales depend on
+func (r *PostgresRepository) GetEvent(ctx context.Context, videoID int64) (*models.Video, error) {
+	query := `
+		SELECT id, user_id, title, is_live, ticket_price_coins, terminated_at, taken_down_at, canceled_at, cancel_reason, status
+		FROM videos
+		WHERE id = $1
+	`
+
+	video := &models.Video{}
+	err := r.db.QueryRowContext(ctx, query, videoID).Scan(
+		&video.ID,
+		&video.UserID,
+		&video.Title,
+		&video.IsLive,
+		&video.TicketPriceCoins,
+		&video.TerminatedAt,
+		&video.TakenDownAt,
+		&video.CanceledAt,
+		&video.CancelReason,
+		&video.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return video, nil
+}
+
+// CancelEvent marks a video canceled and ends its stream. It returns
+// sql.ErrNoRows if the video is already canceled.
+func (r *PostgresRepository) CancelEvent(ctx context.Context, videoID int64, reason string) error {
+	query := `
+		UPDATE videos
+		SET canceled_at = NOW(), cancel_reason = $2, is_live = FALSE
+		WHERE id = $1 AND canceled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, videoID, reason)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetTicket retrieves a user's active ticket to a video
+func (r *PostgresRepository) GetTicket(ctx context.Context, videoID, userID int64) (*models.EventTicket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM event_tickets
+		WHERE video_id = $1 AND user_id = $2 AND status = 'active'
+	`
+	return scanTicket(r.db.QueryRowContext(ctx, query, videoID, userID))
+}
+
+// CreateTicket records a ticket and its revenue event in one transaction. If
+// the user already has a ticket to the video, ticket is filled from it and
+// replayed is true.
+func (r *PostgresRepository) CreateTicket(ctx context.Context, ticket *models.EventTicket, revenue *models.RevenueEvent) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO event_tickets (video_id, user_id, price_coins, charge_id, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (video_id, user_id) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		ticket.VideoID,
+		ticket.UserID,
+		ticket.PriceCoins,
+		ticket.ChargeID,
+		ticket.Status,
+	).Scan(&ticket.ID, &ticket.CreatedAt)
+	if err == nil {
+		if err := earnings.Record(ctx, tx, revenue); err != nil {
+			return false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return false, fmt.Errorf("failed to commit ticket: %w", err)
+		}
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	query = `SELECT ` + ticketColumns + ` FROM event_tickets WHERE video_id = $1 AND user_id = $2`
+	existing, err := scanTicket(tx.QueryRowContext(ctx, query, ticket.VideoID, ticket.UserID))
+	if err != nil {
+		return false, fmt.Errorf("failed to get existing ticket: %w", err)
+	}
+	*ticket = *existing
+	return true, nil
+}
+
+// GetTicketsByUser retrieves a user's tickets, newest first
+func (r *PostgresRepository) GetTicketsByUser(ctx context.Context, userID int64) ([]*models.EventTicket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM event_tickets
+		WHERE user_id = $1
+		ORDER BY id DESC
+	`
+	return r.queryTickets(ctx, query, userID)
+}
+
+// HeldTickets reports which of the given videos a user holds an active
+// ticket for
+func (r *PostgresRepository) HeldTickets(ctx context.Context, userID int64, videoIDs []int64) (map[int64]bool, error) {
+	query := `
+		SELECT video_id
+		FROM event_tickets
+		WHERE user_id = $1 AND video_id = ANY($2) AND status = 'active'
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, videoIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	held := make(map[int64]bool)
+	for rows.Next() {
+		var videoID int64
+		if err := rows.Scan(&videoID); err != nil {
+			return nil, err
+		}
+		held[videoID] = true
+	}
+
+	return held, rows.Err()
+}
+
+// GetRefundableTickets retrieves active tickets to canceled events; a
+// videoID of 0 matches every canceled event
+func (r *PostgresRepository) GetRefundableTickets(ctx context.Context, videoID int64, limit int) ([]*models.EventTicket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM event_tickets
+		WHERE status = 'active'
+			AND ($1 = 0 OR video_id = $1)
+			AND video_id IN (SELECT id FROM videos WHERE canceled_at IS NOT NULL)
+		ORDER BY id
+		LIMIT $2
+	`
+	return r.queryTickets(ctx, query, videoID, limit)
+}
+
+// MarkRefunded marks a ticket refunded and reverses its revenue event in one
+// transaction
+func (r *PostgresRepository) MarkRefunded(ctx context.Context, ticket *models.EventTicket) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE event_tickets
+		SET status = 'refunded', refunded_at = NOW()
+		WHERE id = $1 AND status = 'active'
+	`
+	if _, err := tx.ExecContext(ctx, query, ticket.ID); err != nil {
+		return err
+	}
+
+	if err := earnings.Reverse(ctx, tx, earnings.SourceTicket, ticket.ChargeID, "refund:"+ticket.ChargeID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) queryTickets(ctx context.Context, query string, args ...interface{}) ([]*models.EventTicket, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := make([]*models.EventTicket, 0)
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, rows.Err()
+}
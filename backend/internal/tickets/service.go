@@ -0,0 +1,278 @@
+package tickets
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/earnings"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/video"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// Ticket statuses
+const (
+	StatusActive   = "active"
+	StatusRefunded = "refunded"
+)
+
+var (
+	ErrVideoNotFound = errors.New("video not found")
+	ErrNotTicketed   = errors.New("this video does not sell tickets")
+	ErrEventCanceled = errors.New("this event has been canceled")
+	ErrEventEnded    = errors.New("this event has ended")
+	ErrSelfTicket    = errors.New("creators cannot buy tickets to their own events")
+	ErrNotVideoOwner = errors.New("only the creator can cancel this event")
+)
+
+// Repository defines the interface for ticket data access
+type Repository interface {
+	GetEvent(ctx context.Context, videoID int64) (*models.Video, error)
+	CancelEvent(ctx context.Context, videoID int64, reason string) error
+	GetTicket(ctx context.Context, videoID, userID int64) (*models.EventTicket, error)
+	CreateTicket(ctx context.Context, ticket *models.EventTicket, revenue *models.RevenueEvent) (bool, error)
+	GetTicketsByUser(ctx context.Context, userID int64) ([]*models.EventTicket, error)
+	HeldTickets(ctx context.Context, userID int64, videoIDs []int64) (map[int64]bool, error)
+	GetRefundableTickets(ctx context.Context, videoID int64, limit int) ([]*models.EventTicket, error)
+	MarkRefunded(ctx context.Context, ticket *models.EventTicket) error
+}
+
+// Service sells tickets to ticketed streams
+type Service struct {
+	repo                 Repository
+	charger              Charger
+	control              video.StreamControl
+	coinValueCentsPer100 int64
+}
+
+// NewService creates a new ticket service. control disconnects the
+// broadcaster and viewers of live events that are canceled, and
+// coinValueCentsPer100 values ticket revenue.
+func NewService(repo Repository, charger Charger, control video.StreamControl, coinValueCentsPer100 int) *Service {
+	return &Service{
+		repo:                 repo,
+		charger:              charger,
+		control:              control,
+		coinValueCentsPer100: int64(coinValueCentsPer100),
+	}
+}
+
+// BuyTicket charges the viewer the event's ticket price. Buying a ticket
+// already held returns it with replayed set.
+func (s *Service) BuyTicket(ctx context.Context, videoID, userID int64) (ticket *models.EventTicket, replayed bool, err error) {
+	event, err := s.getEvent(ctx, videoID)
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case event.CanceledAt != nil:
+		return nil, false, ErrEventCanceled
+	case event.TicketPriceCoins == nil:
+		return nil, false, ErrNotTicketed
+	case event.TerminatedAt != nil:
+		return nil, false, ErrEventEnded
+	case event.UserID == userID:
+		return nil, false, ErrSelfTicket
+	}
+
+	existing, err := s.repo.GetTicket(ctx, videoID, userID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	if existing != nil {
+		return existing, true, nil
+	}
+
+	price := *event.TicketPriceCoins
+	charge, err := s.charger.Charge(ctx, &ChargeParams{
+		BuyerID:        userID,
+		CreatorID:      event.UserID,
+		Amount:         price,
+		Currency:       wallet.CurrencyCoin,
+		IdempotencyKey: fmt.Sprintf("ticket:%d:%d", videoID, userID),
+		Description:    fmt.Sprintf("Ticket to %q", event.Title),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	ticket = &models.EventTicket{
+		VideoID:    videoID,
+		UserID:     userID,
+		PriceCoins: price,
+		ChargeID:   charge.ID,
+		Status:     StatusActive,
+	}
+	revenue := earnings.NewEvent(event.UserID, earnings.SourceTicket, charge.ID, price, charge.CreatorCents, s.coinValueCentsPer100)
+	replayed, err = s.repo.CreateTicket(ctx, ticket, revenue)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create ticket: %w", err)
+	}
+
+	return ticket, replayed, nil
+}
+
+// GetTickets retrieves a user's tickets, newest first
+func (s *Service) GetTickets(ctx context.Context, userID int64) ([]*models.EventTicket, error) {
+	tickets, err := s.repo.GetTicketsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+// HeldTickets reports which of the given videos a user holds an active
+// ticket for
+func (s *Service) HeldTickets(ctx context.Context, userID int64, videoIDs []int64) (map[int64]bool, error) {
+	return s.repo.HeldTickets(ctx, userID, videoIDs)
+}
+
+// CancelEvent cancels a ticketed event or scheduled stream for its creator,
+// ending the stream if it is live, and refunds every ticket. Refunds that
+// fail are retried by RunRefunds.
+func (s *Service) CancelEvent(ctx context.Context, videoID, creatorID int64, reason string) (*models.Video, error) {
+	event, err := s.getEvent(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if event.UserID != creatorID {
+		return nil, ErrNotVideoOwner
+	}
+	if event.TicketPriceCoins == nil && event.Status != video.StatusScheduled {
+		return nil, ErrNotTicketed
+	}
+	if event.CanceledAt != nil {
+		return nil, ErrEventCanceled
+	}
+
+	if err := s.repo.CancelEvent(ctx, videoID, reason); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEventCanceled
+		}
+		return nil, fmt.Errorf("failed to cancel event: %w", err)
+	}
+
+	if event.IsLive {
+		s.disconnect(ctx, videoID, reason)
+	}
+	if err := s.refundEvent(ctx, event); err != nil {
+		logger.ErrorLogger.Printf("Failed to refund tickets for video %d: %v", videoID, err)
+	}
+
+	now := time.Now()
+	event.IsLive = false
+	event.CanceledAt = &now
+	event.CancelReason = reason
+	return event, nil
+}
+
+// ProcessRefunds refunds the remaining tickets of canceled events
+func (s *Service) ProcessRefunds(ctx context.Context) error {
+	tickets, err := s.repo.GetRefundableTickets(ctx, 0, 500)
+	if err != nil {
+		return fmt.Errorf("failed to get refundable tickets: %w", err)
+	}
+
+	events := make(map[int64]*models.Video)
+	for _, ticket := range tickets {
+		event, ok := events[ticket.VideoID]
+		if !ok {
+			// Taken-down events are still refunded
+			if event, err = s.repo.GetEvent(ctx, ticket.VideoID); err != nil {
+				return fmt.Errorf("failed to get video: %w", err)
+			}
+			events[ticket.VideoID] = event
+		}
+		if err := s.refund(ctx, event, ticket); err != nil {
+			logger.ErrorLogger.Printf("Failed to refund ticket %d: %v", ticket.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunRefunds processes refunds every interval until ctx is cancelled
+func (s *Service) RunRefunds(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ProcessRefunds(ctx); err != nil {
+				logger.ErrorLogger.Printf("Failed to process ticket refunds: %v", err)
+			}
+		}
+	}
+}
+
+// disconnect ends a canceled live stream outside the database. Failures are
+// logged: the event is already canceled, so it cannot be resumed.
+func (s *Service) disconnect(ctx context.Context, videoID int64, reason string) {
+	if reason == "" {
+		reason = "event canceled"
+	}
+	if err := s.control.DropPublisher(ctx, videoID, reason); err != nil {
+		logger.ErrorLogger.Printf("Failed to drop publisher for video %d: %v", videoID, err)
+	}
+	if err := s.control.CloseConnections(ctx, videoID, reason); err != nil {
+		logger.ErrorLogger.Printf("Failed to close connections for video %d: %v", videoID, err)
+	}
+}
+
+// refundEvent refunds the active tickets of a canceled event, stopping at the
+// first failure
+func (s *Service) refundEvent(ctx context.Context, event *models.Video) error {
+	for {
+		tickets, err := s.repo.GetRefundableTickets(ctx, event.ID, 100)
+		if err != nil {
+			return err
+		}
+		if len(tickets) == 0 {
+			return nil
+		}
+		for _, ticket := range tickets {
+			if err := s.refund(ctx, event, ticket); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Service) refund(ctx context.Context, event *models.Video, ticket *models.EventTicket) error {
+	err := s.charger.Refund(ctx, &RefundParams{
+		ChargeID:  ticket.ChargeID,
+		BuyerID:   ticket.UserID,
+		CreatorID: event.UserID,
+		Amount:    ticket.PriceCoins,
+		Currency:  wallet.CurrencyCoin,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refund charge %s: %w", ticket.ChargeID, err)
+	}
+	if err := s.repo.MarkRefunded(ctx, ticket); err != nil {
+		return fmt.Errorf("failed to mark ticket refunded: %w", err)
+	}
+	return nil
+}
+
+// getEvent retrieves a video that has not been taken down
+func (s *Service) getEvent(ctx context.Context, videoID int64) (*models.Video, error) {
+	event, err := s.repo.GetEvent(ctx, videoID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	if event.TakenDownAt != nil {
+		return nil, ErrVideoNotFound
+	}
+	return event, nil
+}
@@ -0,0 +1,216 @@
+package tickets
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// memoryRepo is an in-memory Repository
+type memoryRepo struct {
+	videos  map[int64]*models.Video
+	tickets []*models.EventTicket
+}
+
+func newMemoryRepo(videos ...*models.Video) *memoryRepo {
+	repo := &memoryRepo{videos: make(map[int64]*models.Video)}
+	for _, video := range videos {
+		repo.videos[video.ID] = video
+	}
+	return repo
+}
+
+func (r *memoryRepo) GetEvent(ctx context.Context, videoID int64) (*models.Video, error) {
+	video, ok := r.videos[videoID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *video
+	return &copied, nil
+}
+
+func (r *memoryRepo) CancelEvent(ctx context.Context, videoID int64, reason string) error {
+	video := r.videos[videoID]
+	if video.CanceledAt != nil {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	video.CanceledAt = &now
+	video.CancelReason = reason
+	video.IsLive = false
+	return nil
+}
+
+func (r *memoryRepo) GetTicket(ctx context.Context, videoID, userID int64) (*models.EventTicket, error) {
+	for _, ticket := range r.tickets {
+		if ticket.VideoID == videoID && ticket.UserID == userID && ticket.Status == StatusActive {
+			return ticket, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *memoryRepo) CreateTicket(ctx context.Context, ticket *models.EventTicket, revenue *models.RevenueEvent) (bool, error) {
+	ticket.ID = int64(len(r.tickets) + 1)
+	r.tickets = append(r.tickets, ticket)
+	return false, nil
+}
+
+func (r *memoryRepo) GetTicketsByUser(ctx context.Context, userID int64) ([]*models.EventTicket, error) {
+	var tickets []*models.EventTicket
+	for _, ticket := range r.tickets {
+		if ticket.UserID == userID {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+func (r *memoryRepo) HeldTickets(ctx context.Context, userID int64, videoIDs []int64) (map[int64]bool, error) {
+	held := make(map[int64]bool)
+	for _, id := range videoIDs {
+		if _, err := r.GetTicket(ctx, id, userID); err == nil {
+			held[id] = true
+		}
+	}
+	return held, nil
+}
+
+func (r *memoryRepo) GetRefundableTickets(ctx context.Context, videoID int64, limit int) ([]*models.EventTicket, error) {
+	var tickets []*models.EventTicket
+	for _, ticket := range r.tickets {
+		video := r.videos[ticket.VideoID]
+		if ticket.Status == StatusActive && video.CanceledAt != nil && (videoID == 0 || ticket.VideoID == videoID) {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+func (r *memoryRepo) MarkRefunded(ctx context.Context, ticket *models.EventTicket) error {
+	now := time.Now()
+	ticket.Status = StatusRefunded
+	ticket.RefundedAt = &now
+	return nil
+}
+
+// nopControl is a video.StreamControl that records dropped publishers
+type nopControl struct {
+	dropped []int64
+}
+
+func (c *nopControl) DropPublisher(ctx context.Context, videoID int64, reason string) error {
+	c.dropped = append(c.dropped, videoID)
+	return nil
+}
+
+func (c *nopControl) CloseConnections(ctx context.Context, videoID int64, reason string) error {
+	return nil
+}
+
+func TestBuyTicket(t *testing.T) {
+	price := int64(300)
+	now := time.Now()
+	repo := newMemoryRepo(
+		&models.Video{ID: 1, UserID: 10, Title: "Concert", TicketPriceCoins: &price},
+		&models.Video{ID: 2, UserID: 10, Title: "Free stream"},
+		&models.Video{ID: 3, UserID: 10, Title: "Canceled", TicketPriceCoins: &price, CanceledAt: &now},
+		&models.Video{ID: 4, UserID: 10, Title: "Ended", TicketPriceCoins: &price, TerminatedAt: &now},
+	)
+	svc := NewService(repo, NewFakeCharger(), &nopControl{}, 100)
+
+	tests := []struct {
+		name         string
+		videoID      int64
+		userID       int64
+		wantErr      error
+		wantReplayed bool
+	}{
+		{"buys a ticket", 1, 20, nil, false},
+		{"buying again returns the ticket", 1, 20, nil, true},
+		{"free videos sell no tickets", 2, 20, ErrNotTicketed, false},
+		{"canceled events sell no tickets", 3, 20, ErrEventCanceled, false},
+		{"ended events sell no tickets", 4, 20, ErrEventEnded, false},
+		{"creators cannot buy their own", 1, 10, ErrSelfTicket, false},
+		{"unknown video", 99, 20, ErrVideoNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket, replayed, err := svc.BuyTicket(context.Background(), tt.videoID, tt.userID)
+			if err != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if replayed != tt.wantReplayed {
+				t.Errorf("Expected replayed %v, got %v", tt.wantReplayed, replayed)
+			}
+			if ticket.PriceCoins != price {
+				t.Errorf("Expected price %d, got %d", price, ticket.PriceCoins)
+			}
+		})
+	}
+
+	if len(repo.tickets) != 1 {
+		t.Errorf("Expected 1 ticket, got %d", len(repo.tickets))
+	}
+}
+
+func TestBuyTicketDeclined(t *testing.T) {
+	price := int64(300)
+	charger := NewFakeCharger()
+	charger.Decline(20)
+	svc := NewService(newMemoryRepo(&models.Video{ID: 1, UserID: 10, TicketPriceCoins: &price}), charger, &nopControl{}, 100)
+
+	if _, _, err := svc.BuyTicket(context.Background(), 1, 20); err != ErrChargeDeclined {
+		t.Errorf("Expected error %v, got %v", ErrChargeDeclined, err)
+	}
+}
+
+func TestCancelEventRefundsTickets(t *testing.T) {
+	price := int64(300)
+	repo := newMemoryRepo(&models.Video{ID: 1, UserID: 10, TicketPriceCoins: &price, IsLive: true})
+	charger := NewFakeCharger()
+	control := &nopControl{}
+	svc := NewService(repo, charger, control, 100)
+	ctx := context.Background()
+
+	for _, userID := range []int64{20, 21} {
+		if _, _, err := svc.BuyTicket(ctx, 1, userID); err != nil {
+			t.Fatalf("Expected no error buying ticket, got %v", err)
+		}
+	}
+
+	if _, err := svc.CancelEvent(ctx, 1, 20, "weather"); err != ErrNotVideoOwner {
+		t.Errorf("Expected error %v, got %v", ErrNotVideoOwner, err)
+	}
+
+	video, err := svc.CancelEvent(ctx, 1, 10, "weather")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if video.IsLive || video.CanceledAt == nil {
+		t.Errorf("Expected canceled event to be ended")
+	}
+	if len(control.dropped) != 1 {
+		t.Errorf("Expected the publisher to be dropped, got %v", control.dropped)
+	}
+
+	for _, ticket := range repo.tickets {
+		if ticket.Status != StatusRefunded {
+			t.Errorf("Expected ticket %d to be refunded, got %s", ticket.ID, ticket.Status)
+		}
+		if !charger.Refunded(ticket.ChargeID) {
+			t.Errorf("Expected charge %s to be refunded", ticket.ChargeID)
+		}
+	}
+
+	if _, err := svc.CancelEvent(ctx, 1, 10, "again"); err != ErrEventCanceled {
+		t.Errorf("Expected error %v, got %v", ErrEventCanceled, err)
+	}
+}
@@ -0,0 +1,144 @@
+package tickets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/gifts"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/wallet"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// Journal entry kinds for ticket charges
+const (
+	EntryKindTicket         = "ticket"
+	EntryKindRefund         = "ticket_refund"
+	EntryKindEarningsRefund = "ticket_refund_earnings"
+)
+
+// WalletCharger charges tickets in coins from the buyer's wallet, crediting
+// the creator's pending earnings in the same journal entry
+type WalletCharger struct {
+	wallet           *wallet.Service
+	centsPer100Coins int64
+}
+
+// NewWalletCharger creates a charger that pays creators centsPer100Coins for
+// every 100 coins of tickets sold
+func NewWalletCharger(wallet *wallet.Service, centsPer100Coins int) *WalletCharger {
+	return &WalletCharger{wallet: wallet, centsPer100Coins: int64(centsPer100Coins)}
+}
+
+// Charge debits the buyer's coins. The charge ID is derived from the journal
+// entry, so retrying with the same idempotency key returns the same charge.
+func (c *WalletCharger) Charge(ctx context.Context, params *ChargeParams) (*Charge, error) {
+	if params.Currency != wallet.CurrencyCoin {
+		return nil, fmt.Errorf("%w: unsupported currency %s", ErrChargeDeclined, params.Currency)
+	}
+
+	buyerCoins, err := c.wallet.Account(ctx, params.BuyerID, wallet.AccountCoins, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, err
+	}
+	redeemed, err := c.wallet.PlatformAccount(ctx, wallet.PlatformCoinsRedeemed, wallet.CurrencyCoin)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.JournalEntry{
+		IdempotencyKey: params.IdempotencyKey,
+		Kind:           EntryKindTicket,
+		Description:    params.Description,
+		Postings: []*models.LedgerPosting{
+			{AccountID: buyerCoins.ID, Currency: wallet.CurrencyCoin, Amount: -params.Amount},
+			{AccountID: redeemed.ID, Currency: wallet.CurrencyCoin, Amount: params.Amount},
+		},
+	}
+
+	earnings := gifts.Earnings(params.Amount, c.centsPer100Coins)
+	if earnings > 0 {
+		postings, err := c.earningsPostings(ctx, params.CreatorID, earnings)
+		if err != nil {
+			return nil, err
+		}
+		entry.Postings = append(entry.Postings, postings...)
+	}
+
+	if err := c.wallet.Post(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &Charge{
+		ID:           fmt.Sprintf("ch_wallet_%d", entry.ID),
+		Amount:       params.Amount,
+		Currency:     params.Currency,
+		CreatorCents: earnings,
+	}, nil
+}
+
+// Refund returns the buyer's coins and takes the creator's earnings back from
+// their pending balance. Earnings that have already left the pending balance
+// are absorbed by the platform and logged.
+func (c *WalletCharger) Refund(ctx context.Context, params *RefundParams) error {
+	buyerCoins, err := c.wallet.Account(ctx, params.BuyerID, wallet.AccountCoins, wallet.CurrencyCoin)
+	if err != nil {
+		return err
+	}
+	redeemed, err := c.wallet.PlatformAccount(ctx, wallet.PlatformCoinsRedeemed, wallet.CurrencyCoin)
+	if err != nil {
+		return err
+	}
+
+	err = c.wallet.Post(ctx, &models.JournalEntry{
+		IdempotencyKey: fmt.Sprintf("%s:%s", EntryKindRefund, params.ChargeID),
+		Kind:           EntryKindRefund,
+		Description:    fmt.Sprintf("Refund of ticket charge %s", params.ChargeID),
+		Postings: []*models.LedgerPosting{
+			{AccountID: redeemed.ID, Currency: wallet.CurrencyCoin, Amount: -params.Amount},
+			{AccountID: buyerCoins.ID, Currency: wallet.CurrencyCoin, Amount: params.Amount},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	earnings := gifts.Earnings(params.Amount, c.centsPer100Coins)
+	if earnings <= 0 {
+		return nil
+	}
+
+	postings, err := c.earningsPostings(ctx, params.CreatorID, -earnings)
+	if err != nil {
+		return err
+	}
+	err = c.wallet.Post(ctx, &models.JournalEntry{
+		IdempotencyKey: fmt.Sprintf("%s:%s", EntryKindEarningsRefund, params.ChargeID),
+		Kind:           EntryKindEarningsRefund,
+		Description:    fmt.Sprintf("Earnings reversed for ticket charge %s", params.ChargeID),
+		Postings:       postings,
+	})
+	if errors.Is(err, wallet.ErrInsufficientFunds) {
+		logger.WarnLogger.Printf("Creator %d has no pending earnings to reverse for ticket charge %s; %d cents absorbed", params.CreatorID, params.ChargeID, earnings)
+		return nil
+	}
+	return err
+}
+
+// earningsPostings moves amount cents from the platform's creator earnings
+// to the creator's pending earnings; a negative amount moves it back
+func (c *WalletCharger) earningsPostings(ctx context.Context, creatorID, amount int64) ([]*models.LedgerPosting, error) {
+	pending, err := c.wallet.Account(ctx, creatorID, wallet.AccountEarningsPending, wallet.CurrencyUSD)
+	if err != nil {
+		return nil, err
+	}
+	funding, err := c.wallet.PlatformAccount(ctx, wallet.PlatformCreatorEarnings, wallet.CurrencyUSD)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.LedgerPosting{
+		{AccountID: funding.ID, Currency: wallet.CurrencyUSD, Amount: -amount},
+		{AccountID: pending.ID, Currency: wallet.CurrencyUSD, Amount: amount},
+	}, nil
+}
@@ -0,0 +1,96 @@
+package video
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// Lock reasons
+const (
+	// LockSubscribersOnly is the lock reason for subscriber-only videos
+	LockSubscribersOnly = "subscribers_only"
+	// LockTicketRequired is the lock reason for ticketed events the viewer
+	// has no ticket for
+	LockTicketRequired = "ticket_required"
+)
+
+// Entitlements reports what gated content a viewer may watch
+type Entitlements interface {
+	IsSubscriber(ctx context.Context, creatorID, userID int64) (bool, error)
+}
+
+// TicketHolders reports which ticketed videos a viewer holds tickets for
+type TicketHolders interface {
+	HeldTickets(ctx context.Context, userID int64, videoIDs []int64) (map[int64]bool, error)
+}
+
+// gate withholds the stream URLs of videos the viewer may not watch, leaving
+// their metadata as a teaser. Creators can always watch their own videos, and
+// anonymous viewers are never subscribers or ticket holders. Subscriptions
+// are looked up once per creator and tickets once for all videos.
+func (s *Service) gate(ctx context.Context, videos []*models.Video, viewerID int64) error {
+	held, err := s.heldTickets(ctx, videos, viewerID)
+	if err != nil {
+		return err
+	}
+
+	subscribed := make(map[int64]bool)
+	for _, video := range videos {
+		if video.UserID == viewerID {
+			continue
+		}
+
+		if video.SubscribersOnly {
+			ok, checked := subscribed[video.UserID]
+			if !checked && viewerID != 0 {
+				ok, err = s.entitlements.IsSubscriber(ctx, video.UserID, viewerID)
+				if err != nil {
+					return fmt.Errorf("failed to check subscription: %w", err)
+				}
+				subscribed[video.UserID] = ok
+			}
+			if !ok {
+				lock(video, LockSubscribersOnly)
+				continue
+			}
+		}
+
+		if video.TicketPriceCoins != nil && !held[video.ID] {
+			lock(video, LockTicketRequired)
+		}
+	}
+	return nil
+}
+
+// heldTickets looks up the viewer's tickets to the ticketed videos they do
+// not own
+func (s *Service) heldTickets(ctx context.Context, videos []*models.Video, viewerID int64) (map[int64]bool, error) {
+	if viewerID == 0 {
+		return nil, nil
+	}
+
+	var ids []int64
+	for _, video := range videos {
+		if video.TicketPriceCoins != nil && video.UserID != viewerID {
+			ids = append(ids, video.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	held, err := s.tickets.HeldTickets(ctx, viewerID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tickets: %w", err)
+	}
+	return held, nil
+}
+
+// lock marks a video as locked for the viewer and withholds its stream
+func lock(video *models.Video, reason string) {
+	video.StreamURL = ""
+	video.Locked = true
+	video.LockReason = reason
+}
@@ -0,0 +1,110 @@
+package video
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// EndStream handles force-ending a live stream
+// @Summary Force-end a live stream
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.ContentActionRequest true "Reason"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /admin/videos/{id}/end [post]
+func (h *Handler) EndStream(c *gin.Context) {
+	id, req, ok := bindContentAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.EndStream(c.Request.Context(), id, c.GetInt64("user_id"), req.Reason); err != nil {
+		if err == ErrStreamNotLive {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "not_live",
+				Message: "Video is not a live stream",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to end stream",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Stream ended",
+	})
+}
+
+// TakedownVideo handles replacing a video with a tombstone
+// @Summary Take down a video
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.ContentActionRequest true "Reason"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/videos/{id}/takedown [post]
+func (h *Handler) TakedownVideo(c *gin.Context) {
+	id, req, ok := bindContentAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.TakedownVideo(c.Request.Context(), id, c.GetInt64("user_id"), req.Reason); err != nil {
+		if err == ErrVideoNotFound {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Video not found or already taken down",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to take down video",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Message: "Video taken down",
+	})
+}
+
+// bindContentAction parses the video ID and reason, writing a 400 response on failure
+func bindContentAction(c *gin.Context) (int64, *models.ContentActionRequest, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid video ID",
+		})
+		return 0, nil, false
+	}
+
+	var req models.ContentActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return 0, nil, false
+	}
+
+	return id, &req, true
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/moderation"
 	"github.com/gin-gonic/gin"
 )
 
@@ -38,7 +39,7 @@ func (h *Handler) GetVideo(c *gin.Context) {
 		return
 	}
 
-	video, err := h.service.GetVideoByID(c.Request.Context(), id)
+	video, err := h.service.GetVideoByID(c.Request.Context(), id, c.GetInt64("user_id"))
 	if err != nil {
 		if err == ErrVideoNotFound {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{
@@ -57,27 +58,92 @@ func (h *Handler) GetVideo(c *gin.Context) {
 	c.JSON(http.StatusOK, video)
 }
 
+// GetPlayback handles getting the stream URL to play a video
+// @Summary Get a video's playback URL
+// @Description Subscriber-only videos need an active subscription and ticketed events a ticket
+// @Tags videos
+// @Produce json
+// @Param id path int true "Video ID"
+// @Success 200 {object} models.Playback
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 402 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 410 {object} models.ErrorResponse
+// @Router /videos/{id}/playback [get]
+func (h *Handler) GetPlayback(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid video ID",
+		})
+		return
+	}
+
+	playback, err := h.service.GetPlayback(c.Request.Context(), id, c.GetInt64("user_id"))
+	if err != nil {
+		switch err {
+		case ErrVideoNotFound:
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Video not found",
+			})
+		case ErrTicketRequired:
+			c.JSON(http.StatusPaymentRequired, models.ErrorResponse{
+				Error:   LockTicketRequired,
+				Message: err.Error(),
+			})
+		case ErrSubscribersOnly:
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   LockSubscribersOnly,
+				Message: err.Error(),
+			})
+		case ErrEventCanceled:
+			c.JSON(http.StatusGone, models.ErrorResponse{
+				Error:   "event_canceled",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to get playback",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, playback)
+}
+
 // GetVideos handles getting a list of videos
 // @Summary Get videos
 // @Tags videos
 // @Produce json
 // @Param limit query int false "Limit" default(20)
 // @Param offset query int false "Offset" default(0)
-// @Param live query bool false "Filter by live status"
+// @Param live query bool false "Only live streams"
+// @Param upcoming query bool false "Only scheduled streams, soonest first"
 // @Success 200 {array} models.VideoWithEngagement
 // @Failure 400 {object} models.ErrorResponse
 // @Router /videos [get]
 func (h *Handler) GetVideos(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	isLive := c.Query("live") == "true"
+	listing := ListingRecent
+	switch {
+	case c.Query("live") == "true":
+		listing = ListingLive
+	case c.Query("upcoming") == "true":
+		listing = ListingUpcoming
+	}
 
 	// Limit maximum items per page for performance
 	if limit > 100 {
 		limit = 100
 	}
 
-	videos, err := h.service.GetVideos(c.Request.Context(), limit, offset, isLive)
+	videos, err := h.service.GetVideos(c.Request.Context(), limit, offset, listing, c.GetInt64("user_id"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "internal_error",
@@ -89,6 +155,128 @@ func (h *Handler) GetVideos(c *gin.Context) {
 	c.JSON(http.StatusOK, videos)
 }
 
+// CreateVideo handles creating a video owned by the current user
+// @Summary Create a video
+// @Description A title or description held for review is listed in pending_review and a placeholder is shown until it is approved
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateVideoRequest true "Video"
+// @Success 201 {object} models.Video
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Router /videos [post]
+func (h *Handler) CreateVideo(c *gin.Context) {
+	var req models.CreateVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	video, err := h.service.CreateVideo(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		if moderation.RespondRejected(c, err) {
+			return
+		}
+		if err == ErrInvalidSchedule {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_schedule",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to create video",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, video)
+}
+
+// UpdateVideo handles updating a video's metadata
+// @Summary Update a video
+// @Description A title or description held for review is listed in pending_review and the current value is kept until it is approved
+// @Tags videos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Video ID"
+// @Param request body models.UpdateVideoRequest true "Video changes"
+// @Success 200 {object} models.Video
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Router /videos/{id} [patch]
+func (h *Handler) UpdateVideo(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid video ID",
+		})
+		return
+	}
+
+	var req models.UpdateVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	video, err := h.service.UpdateVideo(c.Request.Context(), id, c.GetInt64("user_id"), &req)
+	if err != nil {
+		if moderation.RespondRejected(c, err) {
+			return
+		}
+		switch err {
+		case ErrVideoNotFound:
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "not_found",
+				Message: "Video not found",
+			})
+		case ErrNotVideoOwner:
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "forbidden",
+				Message: err.Error(),
+			})
+		case ErrEventCanceled:
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "event_canceled",
+				Message: err.Error(),
+			})
+		case ErrInvalidSchedule:
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_schedule",
+				Message: err.Error(),
+			})
+		case ErrNotScheduled:
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Error:   "not_scheduled",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to update video",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, video)
+}
+
 // GetUserVideos handles getting videos for a specific user
 // @Summary Get user videos
 // @Tags videos
@@ -118,7 +306,7 @@ func (h *Handler) GetUserVideos(c *gin.Context) {
 		limit = 100
 	}
 
-	videos, err := h.service.GetVideosByUserID(c.Request.Context(), userID, limit, offset)
+	videos, err := h.service.GetVideosByUserID(c.Request.Context(), userID, c.GetInt64("user_id"), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "internal_error",
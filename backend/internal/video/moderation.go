@@ -0,0 +1,102 @@
+package video
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/notifications"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+var (
+	ErrStreamNotLive = errors.New("stream is not live")
+)
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, msg *notifications.Message) error
+}
+
+// EndStream immediately ends a live stream for a policy violation. The video
+// is marked terminated so it cannot go live again, and the realtime and
+// ingest layers are told to drop everyone connected to it.
+func (s *Service) EndStream(ctx context.Context, videoID, moderatorID int64, reason string) error {
+	creatorID, err := s.repo.TerminateStream(ctx, videoID, moderatorID, reason)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrStreamNotLive
+		}
+		return fmt.Errorf("failed to end stream: %w", err)
+	}
+
+	s.disconnect(ctx, videoID, reason)
+	s.notifyCreator(ctx, creatorID, videoID, "Your stream was ended by a moderator", reason)
+	return nil
+}
+
+// TakedownVideo replaces a video with a tombstone for a policy violation,
+// ending the stream first if it is live
+func (s *Service) TakedownVideo(ctx context.Context, videoID, moderatorID int64, reason string) error {
+	creatorID, wasLive, err := s.repo.TakedownVideo(ctx, videoID, moderatorID, reason)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrVideoNotFound
+		}
+		return fmt.Errorf("failed to take down video: %w", err)
+	}
+
+	if wasLive {
+		s.disconnect(ctx, videoID, reason)
+	}
+	s.notifyCreator(ctx, creatorID, videoID, "Your video was removed by a moderator", reason)
+	return nil
+}
+
+// notifyCreator tells a creator that moderators acted on their video.
+// Failures are logged: the action is already recorded.
+func (s *Service) notifyCreator(ctx context.Context, creatorID, videoID int64, title, reason string) {
+	err := s.notifier.Notify(ctx, creatorID, &notifications.Message{
+		Type:  notifications.TypeModerationOutcome,
+		Title: title,
+		Body:  "Reason: " + reason,
+		Data: map[string]string{
+			"video_id": strconv.FormatInt(videoID, 10),
+		},
+	})
+	if err != nil {
+		logger.ErrorLogger.Printf("Failed to notify user %d of moderation of video %d: %v", creatorID, videoID, err)
+	}
+}
+
+// disconnect drops the publisher and viewers of a stream that has been ended
+// in the database. Failures are logged rather than returned: the stream is
+// already marked terminated, so the ingest layer will refuse to resume it.
+func (s *Service) disconnect(ctx context.Context, videoID int64, reason string) {
+	if err := s.control.DropPublisher(ctx, videoID, reason); err != nil {
+		logger.ErrorLogger.Printf("Failed to drop publisher for video %d: %v", videoID, err)
+	}
+	if err := s.control.CloseConnections(ctx, videoID, reason); err != nil {
+		logger.ErrorLogger.Printf("Failed to close connections for video %d: %v", videoID, err)
+	}
+	if err := s.redis.SetEngagement(ctx, videoID, "live_viewers", 0); err != nil {
+		logger.WarnLogger.Printf("Failed to reset live viewers for video %d: %v", videoID, err)
+	}
+}
+
+// tombstone strips the content of a taken-down video, keeping only what is
+// needed to render a "removed" placeholder
+func tombstone(video *models.Video) *models.Video {
+	return &models.Video{
+		ID:             video.ID,
+		UserID:         video.UserID,
+		CreatedAt:      video.CreatedAt,
+		UpdatedAt:      video.UpdatedAt,
+		TerminatedAt:   video.TerminatedAt,
+		TakenDownAt:    video.TakenDownAt,
+		TakedownReason: video.TakedownReason,
+	}
+}
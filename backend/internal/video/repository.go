@@ -8,27 +8,27 @@ import (
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
 )
 
-// PostgresRepository implements the Repository interface for PostgreSQL
-type PostgresRepository struct {
-	db *sql.DB
-}
+// videoColumns is the column list matching scanVideo, qualified for joins
+const videoColumns = `v.id, v.user_id, v.title, v.description, v.thumbnail_url, v.stream_url, v.is_live,
+	v.is_adult_content, v.view_count, v.created_at, v.updated_at, v.terminated_at, v.taken_down_at, v.takedown_reason, v.subscribers_only,
+	v.ticket_price_coins, v.canceled_at, v.cancel_reason, v.status, v.scheduled_at`
 
-// NewPostgresRepository creates a new PostgreSQL repository
-func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+// visibleTo restricts results to creators in good standing, except that
+// creators always see their own videos. Shadow-banned creators are therefore
+// visible only to themselves. The viewer ID is bound to the given placeholder.
+func visibleTo(placeholder string) string {
+	return `(u.account_status IN ('active', 'suspended') OR v.user_id = ` + placeholder + `)`
 }
 
-// GetVideoByID retrieves a video by ID
-func (r *PostgresRepository) GetVideoByID(ctx context.Context, id int64) (*models.Video, error) {
-	query := `
-		SELECT id, user_id, title, description, thumbnail_url, stream_url, is_live, 
-		       is_adult_content, view_count, created_at, updated_at
-		FROM videos
-		WHERE id = $1
-	`
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanVideo scans a row selected with videoColumns into a video
+func scanVideo(row rowScanner) (*models.Video, error) {
 	video := &models.Video{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := row.Scan(
 		&video.ID,
 		&video.UserID,
 		&video.Title,
@@ -40,112 +40,123 @@ func (r *PostgresRepository) GetVideoByID(ctx context.Context, id int64) (*model
 		&video.ViewCount,
 		&video.CreatedAt,
 		&video.UpdatedAt,
+		&video.TerminatedAt,
+		&video.TakenDownAt,
+		&video.TakedownReason,
+		&video.SubscribersOnly,
+		&video.TicketPriceCoins,
+		&video.CanceledAt,
+		&video.CancelReason,
+		&video.Status,
+		&video.ScheduledAt,
 	)
-
 	if err != nil {
 		return nil, err
 	}
-
 	return video, nil
 }
 
-// GetVideos retrieves a list of videos
-func (r *PostgresRepository) GetVideos(ctx context.Context, limit, offset int, isLive bool) ([]*models.Video, error) {
-	query := `
-		SELECT id, user_id, title, description, thumbnail_url, stream_url, is_live, 
-		       is_adult_content, view_count, created_at, updated_at
-		FROM videos
-		WHERE is_live = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, isLive, limit, offset)
-	if err != nil {
-		return nil, err
-	}
+// scanVideos scans all rows selected with videoColumns
+func scanVideos(rows *sql.Rows) ([]*models.Video, error) {
 	defer rows.Close()
 
 	var videos []*models.Video
 	for rows.Next() {
-		video := &models.Video{}
-		err := rows.Scan(
-			&video.ID,
-			&video.UserID,
-			&video.Title,
-			&video.Description,
-			&video.ThumbnailURL,
-			&video.StreamURL,
-			&video.IsLive,
-			&video.IsAdultContent,
-			&video.ViewCount,
-			&video.CreatedAt,
-			&video.UpdatedAt,
-		)
+		video, err := scanVideo(rows)
 		if err != nil {
 			return nil, err
 		}
 		videos = append(videos, video)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
 	return videos, nil
 }
 
-// GetVideosByUserID retrieves videos for a specific user
-func (r *PostgresRepository) GetVideosByUserID(ctx context.Context, userID int64, limit, offset int) ([]*models.Video, error) {
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetVideoByID retrieves a video by ID as seen by the given viewer (0 for anonymous).
+// Taken-down videos are returned so callers can show a tombstone.
+func (r *PostgresRepository) GetVideoByID(ctx context.Context, id, viewerID int64) (*models.Video, error) {
 	query := `
-		SELECT id, user_id, title, description, thumbnail_url, stream_url, is_live, 
-		       is_adult_content, view_count, created_at, updated_at
-		FROM videos
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		SELECT ` + videoColumns + `
+		FROM videos v
+		JOIN users u ON u.id = v.user_id
+		WHERE v.id = $1 AND ` + visibleTo("$2")
+
+	return scanVideo(r.db.QueryRowContext(ctx, query, id, viewerID))
+}
+
+// listingFilters holds the conditions and ordering of each listing
+var listingFilters = map[string]struct {
+	where   string
+	orderBy string
+}{
+	ListingRecent:   {"v.is_live = FALSE AND v.status = 'published'", "v.created_at DESC"},
+	ListingLive:     {"v.is_live = TRUE", "v.created_at DESC"},
+	ListingUpcoming: {"v.status = 'scheduled' AND v.canceled_at IS NULL", "v.scheduled_at, v.id"},
+}
+
+// GetVideos retrieves a listing of videos as seen by the given viewer (0 for anonymous)
+func (r *PostgresRepository) GetVideos(ctx context.Context, limit, offset int, listing string, viewerID int64) ([]*models.Video, error) {
+	filter, ok := listingFilters[listing]
+	if !ok {
+		return nil, fmt.Errorf("unknown listing %q", listing)
+	}
+
+	query := `
+		SELECT ` + videoColumns + `
+		FROM videos v
+		JOIN users u ON u.id = v.user_id
+		WHERE ` + filter.where + ` AND v.taken_down_at IS NULL AND ` + visibleTo("$3") + `
+		ORDER BY ` + filter.orderBy + `
+		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, limit, offset, viewerID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var videos []*models.Video
-	for rows.Next() {
-		video := &models.Video{}
-		err := rows.Scan(
-			&video.ID,
-			&video.UserID,
-			&video.Title,
-			&video.Description,
-			&video.ThumbnailURL,
-			&video.StreamURL,
-			&video.IsLive,
-			&video.IsAdultContent,
-			&video.ViewCount,
-			&video.CreatedAt,
-			&video.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		videos = append(videos, video)
-	}
+	return scanVideos(rows)
+}
 
-	if err = rows.Err(); err != nil {
+// GetVideosByUserID retrieves videos for a specific user as seen by the given viewer (0 for anonymous)
+func (r *PostgresRepository) GetVideosByUserID(ctx context.Context, userID, viewerID int64, limit, offset int) ([]*models.Video, error) {
+	query := `
+		SELECT ` + videoColumns + `
+		FROM videos v
+		JOIN users u ON u.id = v.user_id
+		WHERE v.user_id = $1 AND v.taken_down_at IS NULL AND ` + visibleTo("$4") + `
+		ORDER BY v.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset, viewerID)
+	if err != nil {
 		return nil, err
 	}
 
-	return videos, nil
+	return scanVideos(rows)
 }
 
 // CreateVideo creates a new video
 func (r *PostgresRepository) CreateVideo(ctx context.Context, video *models.Video) error {
 	query := `
-		INSERT INTO videos (user_id, title, description, thumbnail_url, stream_url, is_live, is_adult_content, view_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO videos (user_id, title, description, thumbnail_url, stream_url, is_live, is_adult_content, view_count, created_at, updated_at, subscribers_only, ticket_price_coins,
+		                    status, scheduled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
@@ -162,6 +173,10 @@ func (r *PostgresRepository) CreateVideo(ctx context.Context, video *models.Vide
 		video.ViewCount,
 		video.CreatedAt,
 		video.UpdatedAt,
+		video.SubscribersOnly,
+		video.TicketPriceCoins,
+		video.Status,
+		video.ScheduledAt,
 	).Scan(&video.ID)
 
 	if err != nil {
@@ -171,13 +186,16 @@ func (r *PostgresRepository) CreateVideo(ctx context.Context, video *models.Vide
 	return nil
 }
 
-// UpdateVideo updates a video
+// UpdateVideo updates a video. Rescheduling a stream clears its reminders so
+// they are sent again before the new start time.
 func (r *PostgresRepository) UpdateVideo(ctx context.Context, video *models.Video) error {
 	query := `
 		UPDATE videos
-		SET title = $1, description = $2, thumbnail_url = $3, stream_url = $4, 
-		    is_live = $5, is_adult_content = $6, view_count = $7, updated_at = $8
-		WHERE id = $9
+		SET title = $1, description = $2, thumbnail_url = $3, stream_url = $4,
+		    is_live = $5, is_adult_content = $6, view_count = $7, updated_at = $8, subscribers_only = $9,
+		    ticket_price_coins = $10, scheduled_at = $11,
+		    reminded_at = CASE WHEN scheduled_at IS DISTINCT FROM $11 THEN NULL ELSE reminded_at END
+		WHERE id = $12
 	`
 
 	_, err := r.db.ExecContext(
@@ -191,6 +209,9 @@ func (r *PostgresRepository) UpdateVideo(ctx context.Context, video *models.Vide
 		video.IsAdultContent,
 		video.ViewCount,
 		video.UpdatedAt,
+		video.SubscribersOnly,
+		video.TicketPriceCoins,
+		video.ScheduledAt,
 		video.ID,
 	)
 
@@ -200,3 +221,77 @@ func (r *PostgresRepository) UpdateVideo(ctx context.Context, video *models.Vide
 
 	return nil
 }
+
+// TerminateStream ends a live stream on behalf of a moderator and returns
+// the video's creator. It returns sql.ErrNoRows if the video is not
+// currently live.
+func (r *PostgresRepository) TerminateStream(ctx context.Context, id, moderatorID int64, reason string) (int64, error) {
+	query := `
+		UPDATE videos
+		SET is_live = FALSE, terminated_at = NOW(), terminated_by = $2, terminated_reason = $3
+		WHERE id = $1 AND is_live = TRUE
+		RETURNING user_id
+	`
+
+	var creatorID int64
+	err := r.db.QueryRowContext(ctx, query, id, moderatorID, reason).Scan(&creatorID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to terminate stream: %w", err)
+	}
+	return creatorID, err
+}
+
+// TakedownVideo replaces a video with a tombstone on behalf of a moderator,
+// ending the stream first if it is live. It returns the video's creator and
+// whether the video was live, or sql.ErrNoRows if the video does not exist
+// or is already taken down.
+func (r *PostgresRepository) TakedownVideo(ctx context.Context, id, moderatorID int64, reason string) (int64, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var creatorID int64
+	var wasLive bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id, is_live FROM videos WHERE id = $1 AND taken_down_at IS NULL FOR UPDATE
+	`, id).Scan(&creatorID, &wasLive)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if wasLive {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE videos
+			SET is_live = FALSE, terminated_at = NOW(), terminated_by = $2, terminated_reason = $3
+			WHERE id = $1
+		`, id, moderatorID, reason)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to terminate stream: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE videos
+		SET taken_down_at = NOW(), taken_down_by = $2, takedown_reason = $3
+		WHERE id = $1
+	`, id, moderatorID, reason)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to take down video: %w", err)
+	}
+
+	return creatorID, wasLive, tx.Commit()
+}
+
+// expectRow returns sql.ErrNoRows when an update matched nothing
+func expectRow(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -5,42 +5,87 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/database"
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/moderation"
 	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
 )
 
+// Video statuses
+const (
+	// StatusScheduled is the status of streams announced in advance that
+	// have not gone live yet
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+)
+
+// Video listings
+const (
+	ListingRecent   = "recent"
+	ListingLive     = "live"
+	ListingUpcoming = "upcoming"
+)
+
 var (
 	ErrVideoNotFound = errors.New("video not found")
+	ErrNotVideoOwner = errors.New("only the creator can edit this video")
+	ErrEventCanceled = errors.New("this event has been canceled")
+	// ErrInvalidSchedule and ErrNotScheduled are returned for scheduling
+	// changes that cannot be made
+	ErrInvalidSchedule = errors.New("scheduled_at must be in the future")
+	ErrNotScheduled    = errors.New("only streams that have not started can be rescheduled")
+	// ErrSubscribersOnly and ErrTicketRequired are returned for playback of
+	// videos the viewer may not watch
+	ErrSubscribersOnly = errors.New("this video is for subscribers only")
+	ErrTicketRequired  = errors.New("a ticket is required to watch this event")
 )
 
-// Repository defines the interface for video data access
+// Repository defines the interface for video data access. Reads take the
+// ID of the viewing user (0 for anonymous) so that content from
+// shadow-banned creators is only returned to its owner.
 type Repository interface {
-	GetVideoByID(ctx context.Context, id int64) (*models.Video, error)
-	GetVideos(ctx context.Context, limit, offset int, isLive bool) ([]*models.Video, error)
-	GetVideosByUserID(ctx context.Context, userID int64, limit, offset int) ([]*models.Video, error)
+	GetVideoByID(ctx context.Context, id, viewerID int64) (*models.Video, error)
+	GetVideos(ctx context.Context, limit, offset int, listing string, viewerID int64) ([]*models.Video, error)
+	GetVideosByUserID(ctx context.Context, userID, viewerID int64, limit, offset int) ([]*models.Video, error)
 	CreateVideo(ctx context.Context, video *models.Video) error
 	UpdateVideo(ctx context.Context, video *models.Video) error
+	TerminateStream(ctx context.Context, id, moderatorID int64, reason string) (int64, error)
+	TakedownVideo(ctx context.Context, id, moderatorID int64, reason string) (int64, bool, error)
 }
 
 // Service handles video business logic
 type Service struct {
-	repo  Repository
-	redis *database.RedisClient
+	repo         Repository
+	redis        *database.RedisClient
+	control      StreamControl
+	moderation   *moderation.Service
+	entitlements Entitlements
+	tickets      TicketHolders
+	notifier     Notifier
 }
 
 // NewService creates a new video service
-func NewService(repo Repository, redis *database.RedisClient) *Service {
+func NewService(repo Repository, redis *database.RedisClient, control StreamControl, moderation *moderation.Service, entitlements Entitlements, tickets TicketHolders, notifier Notifier) *Service {
 	return &Service{
-		repo:  repo,
-		redis: redis,
+		repo:         repo,
+		redis:        redis,
+		control:      control,
+		moderation:   moderation,
+		entitlements: entitlements,
+		tickets:      tickets,
+		notifier:     notifier,
 	}
 }
 
-// GetVideoByID retrieves a video by ID with engagement data
-func (s *Service) GetVideoByID(ctx context.Context, id int64) (*models.VideoWithEngagement, error) {
-	video, err := s.repo.GetVideoByID(ctx, id)
+// untitled stands in for a title held for review until it is approved
+const untitled = "Untitled"
+
+// GetVideoByID retrieves a video by ID with engagement data. Videos the
+// viewer may not watch come back locked, without their stream URL.
+func (s *Service) GetVideoByID(ctx context.Context, id, viewerID int64) (*models.VideoWithEngagement, error) {
+	video, err := s.repo.GetVideoByID(ctx, id, viewerID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrVideoNotFound
@@ -48,91 +93,255 @@ func (s *Service) GetVideoByID(ctx context.Context, id int64) (*models.VideoWith
 		return nil, fmt.Errorf("failed to get video: %w", err)
 	}
 
-	// Get engagement data from Redis
-	engagement, err := s.redis.GetMultipleEngagements(ctx, id)
+	if video.TakenDownAt != nil {
+		return &models.VideoWithEngagement{Video: *tombstone(video)}, nil
+	}
+
+	if err := s.gate(ctx, []*models.Video{video}, viewerID); err != nil {
+		return nil, err
+	}
+
+	return s.withEngagement(ctx, video), nil
+}
+
+// GetPlayback returns the stream URL of a video the viewer may watch
+func (s *Service) GetPlayback(ctx context.Context, id, viewerID int64) (*models.Playback, error) {
+	video, err := s.repo.GetVideoByID(ctx, id, viewerID)
 	if err != nil {
-		// Log error but don't fail the request - engagement is non-critical
-		logger.WarnLogger.Printf("Failed to get engagement data for video %d: %v", id, err)
-		engagement = map[string]int64{
-			"live_viewers": 0,
-			"likes":        0,
-			"comments":     0,
+		if err == sql.ErrNoRows {
+			return nil, ErrVideoNotFound
 		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	if video.TakenDownAt != nil {
+		return nil, ErrVideoNotFound
+	}
+	if video.CanceledAt != nil {
+		return nil, ErrEventCanceled
 	}
 
-	return &models.VideoWithEngagement{
-		Video:        *video,
-		LiveViewers:  engagement["live_viewers"],
-		LikeCount:    engagement["likes"],
-		CommentCount: engagement["comments"],
+	if err := s.gate(ctx, []*models.Video{video}, viewerID); err != nil {
+		return nil, err
+	}
+	switch video.LockReason {
+	case LockSubscribersOnly:
+		return nil, ErrSubscribersOnly
+	case LockTicketRequired:
+		return nil, ErrTicketRequired
+	}
+
+	return &models.Playback{
+		VideoID:   video.ID,
+		StreamURL: video.StreamURL,
+		IsLive:    video.IsLive,
 	}, nil
 }
 
-// GetVideos retrieves a list of videos with engagement data
-func (s *Service) GetVideos(ctx context.Context, limit, offset int, isLive bool) ([]*models.VideoWithEngagement, error) {
-	videos, err := s.repo.GetVideos(ctx, limit, offset, isLive)
+// GetVideos retrieves a listing of videos with engagement data: recent
+// videos, live streams, or scheduled streams in order of their start time
+func (s *Service) GetVideos(ctx context.Context, limit, offset int, listing string, viewerID int64) ([]*models.VideoWithEngagement, error) {
+	videos, err := s.repo.GetVideos(ctx, limit, offset, listing, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get videos: %w", err)
 	}
+	if err := s.gate(ctx, videos, viewerID); err != nil {
+		return nil, err
+	}
 
 	// Enrich with engagement data
 	result := make([]*models.VideoWithEngagement, len(videos))
 	for i, video := range videos {
-		engagement, err := s.redis.GetMultipleEngagements(ctx, video.ID)
-		if err != nil {
-			// Log error but don't fail the request - engagement is non-critical
-			logger.WarnLogger.Printf("Failed to get engagement data for video %d: %v", video.ID, err)
-			engagement = map[string]int64{
-				"live_viewers": 0,
-				"likes":        0,
-				"comments":     0,
-			}
-		}
-
-		result[i] = &models.VideoWithEngagement{
-			Video:        *video,
-			LiveViewers:  engagement["live_viewers"],
-			LikeCount:    engagement["likes"],
-			CommentCount: engagement["comments"],
-		}
+		result[i] = s.withEngagement(ctx, video)
 	}
 
 	return result, nil
 }
 
 // GetVideosByUserID retrieves videos for a specific user
-func (s *Service) GetVideosByUserID(ctx context.Context, userID int64, limit, offset int) ([]*models.VideoWithEngagement, error) {
-	videos, err := s.repo.GetVideosByUserID(ctx, userID, limit, offset)
+func (s *Service) GetVideosByUserID(ctx context.Context, userID, viewerID int64, limit, offset int) ([]*models.VideoWithEngagement, error) {
+	videos, err := s.repo.GetVideosByUserID(ctx, userID, viewerID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user videos: %w", err)
 	}
+	if err := s.gate(ctx, videos, viewerID); err != nil {
+		return nil, err
+	}
 
 	// Enrich with engagement data
 	result := make([]*models.VideoWithEngagement, len(videos))
 	for i, video := range videos {
-		engagement, err := s.redis.GetMultipleEngagements(ctx, video.ID)
+		result[i] = s.withEngagement(ctx, video)
+	}
+
+	return result, nil
+}
+
+// CreateVideo creates a video owned by the user. The title and description are
+// screened first: rejected text fails the request, while held text is queued
+// for review and a placeholder shown until it is approved.
+func (s *Service) CreateVideo(ctx context.Context, userID int64, req *models.CreateVideoRequest) (*models.Video, error) {
+	titleVerdict, err := s.moderation.Check(ctx, moderation.FieldVideoTitle, req.Title)
+	if err != nil {
+		return nil, err
+	}
+	descriptionVerdict, err := s.moderation.Check(ctx, moderation.FieldVideoDescription, req.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	status := StatusPublished
+	if req.ScheduledAt != nil {
+		if !req.ScheduledAt.After(now) {
+			return nil, ErrInvalidSchedule
+		}
+		status = StatusScheduled
+	}
+
+	video := &models.Video{
+		UserID:           userID,
+		Title:            req.Title,
+		Description:      req.Description,
+		ThumbnailURL:     req.ThumbnailURL,
+		IsAdultContent:   req.IsAdultContent,
+		SubscribersOnly:  req.SubscribersOnly,
+		TicketPriceCoins: req.TicketPriceCoins,
+		Status:           status,
+		ScheduledAt:      req.ScheduledAt,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if titleVerdict.Decision == moderation.Hold {
+		video.Title = untitled
+	}
+	if descriptionVerdict.Decision == moderation.Hold {
+		video.Description = ""
+	}
+
+	if err := s.repo.CreateVideo(ctx, video); err != nil {
+		return nil, err
+	}
+
+	held := []struct {
+		field   moderation.Field
+		text    string
+		verdict *moderation.Verdict
+	}{
+		{moderation.FieldVideoTitle, req.Title, titleVerdict},
+		{moderation.FieldVideoDescription, req.Description, descriptionVerdict},
+	}
+	for _, h := range held {
+		if h.verdict.Decision != moderation.Hold {
+			continue
+		}
+		if err := s.moderation.Hold(ctx, h.field, video.ID, userID, h.text, h.verdict); err != nil {
+			return nil, fmt.Errorf("failed to hold %s: %w", h.field.Name(), err)
+		}
+		video.PendingReview = append(video.PendingReview, h.field.Name())
+	}
+
+	return video, nil
+}
+
+// UpdateVideo applies changes to a video's metadata for its creator. Held
+// text is queued for review and the current value kept until it is approved.
+func (s *Service) UpdateVideo(ctx context.Context, id, userID int64, req *models.UpdateVideoRequest) (*models.Video, error) {
+	video, err := s.repo.GetVideoByID(ctx, id, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	if video.TakenDownAt != nil {
+		return nil, ErrVideoNotFound
+	}
+	if video.UserID != userID {
+		return nil, ErrNotVideoOwner
+	}
+
+	screened := []struct {
+		field moderation.Field
+		text  *string
+		value *string
+	}{
+		{moderation.FieldVideoTitle, req.Title, &video.Title},
+		{moderation.FieldVideoDescription, req.Description, &video.Description},
+	}
+	var held []string
+	for _, f := range screened {
+		if f.text == nil {
+			continue
+		}
+		value, isHeld, err := s.moderation.Screen(ctx, f.field, video.ID, userID, *f.text, *f.value)
 		if err != nil {
-			// Log error but don't fail the request - engagement is non-critical
-			logger.WarnLogger.Printf("Failed to get engagement data for video %d: %v", video.ID, err)
-			engagement = map[string]int64{
-				"live_viewers": 0,
-				"likes":        0,
-				"comments":     0,
-			}
+			return nil, err
+		}
+		*f.value = value
+		if isHeld {
+			held = append(held, f.field.Name())
 		}
+	}
 
-		result[i] = &models.VideoWithEngagement{
-			Video:        *video,
-			LiveViewers:  engagement["live_viewers"],
-			LikeCount:    engagement["likes"],
-			CommentCount: engagement["comments"],
+	if req.ThumbnailURL != nil {
+		video.ThumbnailURL = *req.ThumbnailURL
+	}
+	if req.IsAdultContent != nil {
+		video.IsAdultContent = *req.IsAdultContent
+	}
+	if req.SubscribersOnly != nil {
+		video.SubscribersOnly = *req.SubscribersOnly
+	}
+	if req.TicketPriceCoins != nil {
+		if video.CanceledAt != nil {
+			return nil, ErrEventCanceled
+		}
+		video.TicketPriceCoins = req.TicketPriceCoins
+		if *req.TicketPriceCoins == 0 {
+			video.TicketPriceCoins = nil
 		}
 	}
+	if req.ScheduledAt != nil {
+		if video.Status != StatusScheduled || video.CanceledAt != nil {
+			return nil, ErrNotScheduled
+		}
+		if !req.ScheduledAt.After(time.Now()) {
+			return nil, ErrInvalidSchedule
+		}
+		video.ScheduledAt = req.ScheduledAt
+	}
+	video.UpdatedAt = time.Now()
 
-	return result, nil
+	if err := s.repo.UpdateVideo(ctx, video); err != nil {
+		return nil, err
+	}
+
+	video.PendingReview = held
+	return video, nil
 }
 
 // IncrementEngagement increments an engagement metric for a video
 func (s *Service) IncrementEngagement(ctx context.Context, videoID int64, metric string) error {
 	return s.redis.IncrementEngagement(ctx, videoID, metric)
 }
+
+// withEngagement attaches real-time engagement counters from Redis to a video
+func (s *Service) withEngagement(ctx context.Context, video *models.Video) *models.VideoWithEngagement {
+	engagement, err := s.redis.GetMultipleEngagements(ctx, video.ID)
+	if err != nil {
+		// Log error but don't fail the request - engagement is non-critical
+		logger.WarnLogger.Printf("Failed to get engagement data for video %d: %v", video.ID, err)
+		engagement = map[string]int64{
+			"live_viewers": 0,
+			"likes":        0,
+			"comments":     0,
+		}
+	}
+
+	return &models.VideoWithEngagement{
+		Video:        *video,
+		LiveViewers:  engagement["live_viewers"],
+		LikeCount:    engagement["likes"],
+		CommentCount: engagement["comments"],
+	}
+}
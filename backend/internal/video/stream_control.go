@@ -0,0 +1,63 @@
+package video
+
+import (
+	"context"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/database"
+)
+
+const (
+	// StreamControlChannel is the Redis channel the realtime gateway and the
+	// ingest layer subscribe to for moderation commands
+	StreamControlChannel = "streams:control"
+
+	ControlDropPublisher    = "drop_publisher"
+	ControlCloseConnections = "close_connections"
+)
+
+// StreamControl signals the layers outside this service that hold live
+// connections for a stream
+type StreamControl interface {
+	// DropPublisher tells the ingest layer to disconnect the broadcaster
+	DropPublisher(ctx context.Context, videoID int64, reason string) error
+	// CloseConnections tells the realtime layer to disconnect every viewer
+	CloseConnections(ctx context.Context, videoID int64, reason string) error
+}
+
+// StreamControlEvent is the message published on StreamControlChannel
+type StreamControlEvent struct {
+	Action  string `json:"action"`
+	VideoID int64  `json:"video_id"`
+	Reason  string `json:"reason"`
+	SentAt  int64  `json:"sent_at"`
+}
+
+// RedisStreamControl implements StreamControl over Redis pub/sub
+type RedisStreamControl struct {
+	redis *database.RedisClient
+}
+
+// NewRedisStreamControl creates a new Redis-backed stream control
+func NewRedisStreamControl(redis *database.RedisClient) *RedisStreamControl {
+	return &RedisStreamControl{redis: redis}
+}
+
+// DropPublisher publishes a drop_publisher command for the stream
+func (r *RedisStreamControl) DropPublisher(ctx context.Context, videoID int64, reason string) error {
+	return r.publish(ctx, ControlDropPublisher, videoID, reason)
+}
+
+// CloseConnections publishes a close_connections command for the stream
+func (r *RedisStreamControl) CloseConnections(ctx context.Context, videoID int64, reason string) error {
+	return r.publish(ctx, ControlCloseConnections, videoID, reason)
+}
+
+func (r *RedisStreamControl) publish(ctx context.Context, action string, videoID int64, reason string) error {
+	return r.redis.PublishEvent(ctx, StreamControlChannel, StreamControlEvent{
+		Action:  action,
+		VideoID: videoID,
+		Reason:  reason,
+		SentAt:  time.Now().Unix(),
+	})
+}
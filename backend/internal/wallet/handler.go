@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles wallet HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new wallet handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetWallet handles getting the current user's balances
+// @Summary Get wallet balances
+// @Tags wallet
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.Wallet
+// @Failure 401 {object} models.ErrorResponse
+// @Router /wallet [get]
+func (h *Handler) GetWallet(c *gin.Context) {
+	wallet, err := h.service.GetWallet(c.Request.Context(), c.GetInt64("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get wallet",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+// GetTransactions handles getting the current user's transaction history
+// @Summary Get wallet transactions
+// @Tags wallet
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.WalletTransaction
+// @Failure 401 {object} models.ErrorResponse
+// @Router /wallet/transactions [get]
+func (h *Handler) GetTransactions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	// Limit maximum items per page
+	if limit > 100 {
+		limit = 100
+	}
+
+	transactions, err := h.service.GetTransactions(c.Request.Context(), c.GetInt64("user_id"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get transactions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
@@ -0,0 +1,282 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// Currencies. Amounts are integer minor units: cents for USD, whole coins
+// for COIN.
+const (
+	CurrencyCoin = "COIN"
+	CurrencyUSD  = "USD"
+)
+
+// Account types held by users
+const (
+	// AccountCoins holds a viewer's spendable coins
+	AccountCoins = "coins"
+	// AccountEarningsPending holds creator earnings not yet payable
+	AccountEarningsPending = "earnings_pending"
+	// AccountEarningsAvailable holds creator earnings that can be paid out
+	AccountEarningsAvailable = "earnings_available"
+)
+
+// Platform account types
+const (
+	// PlatformCoinsIssued funds coins bought through the app stores
+	PlatformCoinsIssued = "coins_issued"
+	// PlatformCoinsRedeemed receives coins spent on gifts and other items
+	PlatformCoinsRedeemed = "coins_redeemed"
+	// PlatformCreatorEarnings funds creator earnings; its negative balance is
+	// what the platform owes creators in total
+	PlatformCreatorEarnings = "creator_earnings"
+	// PlatformPayoutsInFlight holds payouts sent to the provider but not yet paid
+	PlatformPayoutsInFlight = "payouts_in_flight"
+	// PlatformPayoutsPaid accumulates payouts that reached creators' banks
+	PlatformPayoutsPaid = "payouts_paid"
+)
+
+var (
+	ErrInvalidEntry        = errors.New("invalid journal entry")
+	ErrUnbalancedEntry     = errors.New("journal entry does not balance")
+	ErrCurrencyMismatch    = errors.New("posting currency does not match account")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrIdempotencyConflict = errors.New("idempotency key was used for a different entry")
+)
+
+// Validate checks that an entry has an idempotency key and kind, at least two
+// postings, no zero amounts, and postings that sum to zero in each currency
+func Validate(entry *models.JournalEntry) error {
+	if entry.IdempotencyKey == "" || entry.Kind == "" {
+		return fmt.Errorf("%w: idempotency key and kind are required", ErrInvalidEntry)
+	}
+	if len(entry.Postings) < 2 {
+		return fmt.Errorf("%w: at least two postings are required", ErrInvalidEntry)
+	}
+
+	sums := make(map[string]int64)
+	for _, p := range entry.Postings {
+		if p.Amount == 0 {
+			return fmt.Errorf("%w: posting amounts must be non-zero", ErrInvalidEntry)
+		}
+		if p.Currency == "" {
+			return fmt.Errorf("%w: posting currency is required", ErrInvalidEntry)
+		}
+		sums[p.Currency] += p.Amount
+	}
+	for currency, sum := range sums {
+		if sum != 0 {
+			return fmt.Errorf("%w: %s postings sum to %d", ErrUnbalancedEntry, currency, sum)
+		}
+	}
+
+	return nil
+}
+
+// Post records a journal entry within tx, updating account balances. Accounts
+// are locked in ID order so concurrent entries cannot deadlock. If an entry
+// with the same idempotency key exists and has the same postings, entry is
+// filled from it and replayed is true; if its postings differ,
+// ErrIdempotencyConflict is returned. Other packages call this to move money
+// in the same transaction as their own writes.
+func Post(ctx context.Context, tx *sql.Tx, entry *models.JournalEntry) (replayed bool, err error) {
+	if err := Validate(entry); err != nil {
+		return false, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO journal_entries (idempotency_key, kind, description)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO NOTHING
+		RETURNING id, created_at
+	`, entry.IdempotencyKey, entry.Kind, entry.Description).Scan(&entry.ID, &entry.CreatedAt)
+	if err == sql.ErrNoRows {
+		return true, replay(ctx, tx, entry)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to insert journal entry: %w", err)
+	}
+
+	accounts, err := lockAccounts(ctx, tx, entry.Postings)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range entry.Postings {
+		account := accounts[p.AccountID]
+		if account.Currency != p.Currency {
+			return false, fmt.Errorf("%w: account %d holds %s", ErrCurrencyMismatch, account.ID, account.Currency)
+		}
+		account.Balance += p.Amount
+		if account.Balance < 0 && !account.AllowNegative {
+			return false, ErrInsufficientFunds
+		}
+
+		p.EntryID = entry.ID
+		p.BalanceAfter = account.Balance
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO ledger_postings (entry_id, account_id, currency, amount, balance_after)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+		`, p.EntryID, p.AccountID, p.Currency, p.Amount, p.BalanceAfter).Scan(&p.ID, &p.CreatedAt)
+		if err != nil {
+			return false, fmt.Errorf("failed to insert posting: %w", err)
+		}
+	}
+
+	for _, account := range accounts {
+		_, err := tx.ExecContext(ctx, `UPDATE ledger_accounts SET balance = $1 WHERE id = $2`, account.Balance, account.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to update balance: %w", err)
+		}
+	}
+
+	return false, nil
+}
+
+// EnsureAccount returns an owner's account of a type and currency, creating
+// it if needed. A nil owner means a platform account, which may go negative
+// (coin issuance, for example, is a running debit).
+func EnsureAccount(ctx context.Context, tx *sql.Tx, ownerID *int64, accountType, currency string) (*models.LedgerAccount, error) {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO ledger_accounts (owner_id, type, currency, allow_negative)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT ON CONSTRAINT ledger_accounts_owner_type_currency DO NOTHING
+	`, ownerID, accountType, currency, ownerID == nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+
+	query := `
+		SELECT ` + accountColumns + `
+		FROM ledger_accounts
+		WHERE owner_id IS NOT DISTINCT FROM $1 AND type = $2 AND currency = $3
+	`
+
+	account, err := scanAccount(tx.QueryRowContext(ctx, query, ownerID, accountType, currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return account, nil
+}
+
+// lockAccounts locks the accounts touched by postings, in ID order
+func lockAccounts(ctx context.Context, tx *sql.Tx, postings []*models.LedgerPosting) (map[int64]*models.LedgerAccount, error) {
+	ids := make([]int64, 0, len(postings))
+	for _, p := range postings {
+		ids = append(ids, p.AccountID)
+	}
+	return LockAccounts(ctx, tx, ids...)
+}
+
+// LockAccounts locks accounts within tx, in ID order like Post, and returns
+// them by ID. Other packages call this to read balances that an entry posted
+// later in tx depends on.
+func LockAccounts(ctx context.Context, tx *sql.Tx, accountIDs ...int64) (map[int64]*models.LedgerAccount, error) {
+	ids := make([]int64, 0, len(accountIDs))
+	seen := make(map[int64]bool)
+	for _, id := range accountIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]*models.LedgerAccount, len(ids))
+	for _, id := range ids {
+		query := `SELECT ` + accountColumns + ` FROM ledger_accounts WHERE id = $1 FOR UPDATE`
+		account, err := scanAccount(tx.QueryRowContext(ctx, query, id))
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: account %d does not exist", ErrInvalidEntry, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock account: %w", err)
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// replay fills entry from the existing entry with its idempotency key,
+// checking that the postings are the same
+func replay(ctx context.Context, tx *sql.Tx, entry *models.JournalEntry) error {
+	existing := &models.JournalEntry{}
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, idempotency_key, kind, description, created_at
+		FROM journal_entries
+		WHERE idempotency_key = $1
+	`, entry.IdempotencyKey).Scan(&existing.ID, &existing.IdempotencyKey, &existing.Kind, &existing.Description, &existing.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get existing entry: %w", err)
+	}
+
+	existing.Postings, err = getPostings(ctx, tx, existing.ID)
+	if err != nil {
+		return err
+	}
+
+	if existing.Kind != entry.Kind || !samePostings(existing.Postings, entry.Postings) {
+		return ErrIdempotencyConflict
+	}
+
+	*entry = *existing
+	return nil
+}
+
+func getPostings(ctx context.Context, tx *sql.Tx, entryID int64) ([]*models.LedgerPosting, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, entry_id, account_id, currency, amount, balance_after, created_at
+		FROM ledger_postings
+		WHERE entry_id = $1
+		ORDER BY id
+	`, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get postings: %w", err)
+	}
+	defer rows.Close()
+
+	var postings []*models.LedgerPosting
+	for rows.Next() {
+		p := &models.LedgerPosting{}
+		if err := rows.Scan(&p.ID, &p.EntryID, &p.AccountID, &p.Currency, &p.Amount, &p.BalanceAfter, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		postings = append(postings, p)
+	}
+
+	return postings, rows.Err()
+}
+
+// samePostings reports whether two sets of postings move the same amounts
+// in and out of the same accounts
+func samePostings(a, b []*models.LedgerPosting) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	type key struct {
+		accountID int64
+		currency  string
+		amount    int64
+	}
+	counts := make(map[key]int)
+	for _, p := range a {
+		counts[key{p.AccountID, p.Currency, p.Amount}]++
+	}
+	for _, p := range b {
+		k := key{p.AccountID, p.Currency, p.Amount}
+		if counts[k] == 0 {
+			return false
+		}
+		counts[k]--
+	}
+	return true
+}
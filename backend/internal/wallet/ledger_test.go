@@ -0,0 +1,100 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+func TestValidate(t *testing.T) {
+	posting := func(accountID int64, currency string, amount int64) *models.LedgerPosting {
+		return &models.LedgerPosting{AccountID: accountID, Currency: currency, Amount: amount}
+	}
+
+	tests := []struct {
+		name     string
+		entry    *models.JournalEntry
+		expected error
+	}{
+		{
+			name: "Balanced",
+			entry: &models.JournalEntry{IdempotencyKey: "k", Kind: "transfer", Postings: []*models.LedgerPosting{
+				posting(1, CurrencyCoin, -100), posting(2, CurrencyCoin, 100),
+			}},
+		},
+		{
+			name: "BalancedPerCurrency",
+			entry: &models.JournalEntry{IdempotencyKey: "k", Kind: "purchase", Postings: []*models.LedgerPosting{
+				posting(1, CurrencyCoin, -500), posting(2, CurrencyCoin, 500),
+				posting(3, CurrencyUSD, -499), posting(4, CurrencyUSD, 499),
+			}},
+		},
+		{
+			name: "Unbalanced",
+			entry: &models.JournalEntry{IdempotencyKey: "k", Kind: "transfer", Postings: []*models.LedgerPosting{
+				posting(1, CurrencyCoin, -100), posting(2, CurrencyCoin, 90),
+			}},
+			expected: ErrUnbalancedEntry,
+		},
+		{
+			name: "CrossCurrency",
+			entry: &models.JournalEntry{IdempotencyKey: "k", Kind: "transfer", Postings: []*models.LedgerPosting{
+				posting(1, CurrencyCoin, -100), posting(2, CurrencyUSD, 100),
+			}},
+			expected: ErrUnbalancedEntry,
+		},
+		{
+			name: "SinglePosting",
+			entry: &models.JournalEntry{IdempotencyKey: "k", Kind: "transfer", Postings: []*models.LedgerPosting{
+				posting(1, CurrencyCoin, 0),
+			}},
+			expected: ErrInvalidEntry,
+		},
+		{
+			name: "ZeroAmount",
+			entry: &models.JournalEntry{IdempotencyKey: "k", Kind: "transfer", Postings: []*models.LedgerPosting{
+				posting(1, CurrencyCoin, 0), posting(2, CurrencyCoin, 0),
+			}},
+			expected: ErrInvalidEntry,
+		},
+		{
+			name: "MissingKey",
+			entry: &models.JournalEntry{Kind: "transfer", Postings: []*models.LedgerPosting{
+				posting(1, CurrencyCoin, -100), posting(2, CurrencyCoin, 100),
+			}},
+			expected: ErrInvalidEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.entry)
+			if tt.expected == nil && err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tt.expected != nil && !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestSamePostings(t *testing.T) {
+	a := []*models.LedgerPosting{
+		{AccountID: 1, Currency: CurrencyCoin, Amount: -100},
+		{AccountID: 2, Currency: CurrencyCoin, Amount: 100},
+	}
+	reordered := []*models.LedgerPosting{a[1], a[0]}
+	different := []*models.LedgerPosting{
+		{AccountID: 1, Currency: CurrencyCoin, Amount: -100},
+		{AccountID: 3, Currency: CurrencyCoin, Amount: 100},
+	}
+
+	if !samePostings(a, reordered) {
+		t.Error("Expected reordered postings to match")
+	}
+	if samePostings(a, different) {
+		t.Error("Expected postings to a different account not to match")
+	}
+}
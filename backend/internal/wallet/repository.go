@@ -0,0 +1,146 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+const accountColumns = `id, owner_id, type, currency, balance, allow_negative, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAccount(row rowScanner) (*models.LedgerAccount, error) {
+	account := &models.LedgerAccount{}
+	err := row.Scan(
+		&account.ID,
+		&account.OwnerID,
+		&account.Type,
+		&account.Currency,
+		&account.Balance,
+		&account.AllowNegative,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// GetOrCreateAccount returns an owner's account, creating it if needed
+func (r *PostgresRepository) GetOrCreateAccount(ctx context.Context, ownerID *int64, accountType, currency string) (*models.LedgerAccount, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	account, err := EnsureAccount(ctx, tx, ownerID, accountType, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return account, tx.Commit()
+}
+
+// GetAccountsByOwner retrieves all of a user's accounts
+func (r *PostgresRepository) GetAccountsByOwner(ctx context.Context, ownerID int64) ([]*models.LedgerAccount, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM ledger_accounts
+		WHERE owner_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make([]*models.LedgerAccount, 0)
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// PostEntry records a journal entry in its own transaction
+func (r *PostgresRepository) PostEntry(ctx context.Context, entry *models.JournalEntry) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	replayed, err := Post(ctx, tx, entry)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit journal entry: %w", err)
+	}
+
+	return replayed, nil
+}
+
+// GetTransactions retrieves postings on a user's accounts, newest first
+func (r *PostgresRepository) GetTransactions(ctx context.Context, ownerID int64, limit, offset int) ([]*models.WalletTransaction, error) {
+	query := `
+		SELECT e.id, e.kind, e.description, a.type, p.currency, p.amount, p.balance_after, p.created_at
+		FROM ledger_postings p
+		JOIN ledger_accounts a ON a.id = p.account_id
+		JOIN journal_entries e ON e.id = p.entry_id
+		WHERE a.owner_id = $1
+		ORDER BY p.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]*models.WalletTransaction, 0)
+	for rows.Next() {
+		t := &models.WalletTransaction{}
+		err := rows.Scan(
+			&t.EntryID,
+			&t.Kind,
+			&t.Description,
+			&t.AccountType,
+			&t.Currency,
+			&t.Amount,
+			&t.BalanceAfter,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
@@ -0,0 +1,80 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+// Repository defines the interface for ledger data access
+type Repository interface {
+	GetOrCreateAccount(ctx context.Context, ownerID *int64, accountType, currency string) (*models.LedgerAccount, error)
+	GetAccountsByOwner(ctx context.Context, ownerID int64) ([]*models.LedgerAccount, error)
+	PostEntry(ctx context.Context, entry *models.JournalEntry) (bool, error)
+	GetTransactions(ctx context.Context, ownerID int64, limit, offset int) ([]*models.WalletTransaction, error)
+}
+
+// Service handles wallets and the ledger behind them
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new wallet service
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Account returns a user's account of a type and currency, creating it if needed
+func (s *Service) Account(ctx context.Context, userID int64, accountType, currency string) (*models.LedgerAccount, error) {
+	account, err := s.repo.GetOrCreateAccount(ctx, &userID, accountType, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return account, nil
+}
+
+// PlatformAccount returns a platform account, creating it if needed
+func (s *Service) PlatformAccount(ctx context.Context, accountType, currency string) (*models.LedgerAccount, error) {
+	account, err := s.repo.GetOrCreateAccount(ctx, nil, accountType, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform account: %w", err)
+	}
+	return account, nil
+}
+
+// Post records a journal entry. Posting an entry again with the same
+// idempotency key and postings returns the original entry.
+func (s *Service) Post(ctx context.Context, entry *models.JournalEntry) error {
+	if err := Validate(entry); err != nil {
+		return err
+	}
+	_, err := s.repo.PostEntry(ctx, entry)
+	return err
+}
+
+// GetWallet retrieves a user's balances
+func (s *Service) GetWallet(ctx context.Context, userID int64) (*models.Wallet, error) {
+	accounts, err := s.repo.GetAccountsByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	wallet := &models.Wallet{Accounts: accounts}
+	for _, account := range accounts {
+		if account.Type == AccountCoins && account.Currency == CurrencyCoin {
+			wallet.Coins = account.Balance
+		}
+	}
+
+	return wallet, nil
+}
+
+// GetTransactions retrieves a user's transaction history, newest first
+func (s *Service) GetTransactions(ctx context.Context, userID int64, limit, offset int) ([]*models.WalletTransaction, error) {
+	transactions, err := s.repo.GetTransactions(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	return transactions, nil
+}
@@ -0,0 +1,131 @@
+package webhooks
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxPayloadBytes bounds the size of an inbound webhook
+const maxPayloadBytes = 1 << 20
+
+// Handler handles webhook HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new webhook handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ReceivePaymentEvent handles a webhook from the payment provider
+// @Summary Receive a payment provider webhook
+// @Description The raw body must be signed in the Stripe-Signature header
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param Stripe-Signature header string true "t=<unix>,v1=<signature>"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} models.ErrorResponse
+// @Router /webhooks/payments [post]
+func (h *Handler) ReceivePaymentEvent(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPayloadBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to read webhook body",
+		})
+		return
+	}
+
+	event, err := h.service.Receive(c.Request.Context(), payload, c.GetHeader(SignatureHeader))
+	if err != nil {
+		respondError(c, err, "Failed to receive webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true, "status": event.Status})
+}
+
+// GetDeadLetters handles listing webhook events whose processing failed
+// @Summary List failed webhook events
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.WebhookDeadLetter
+// @Failure 403 {object} models.ErrorResponse
+// @Router /admin/webhooks/dead-letters [get]
+func (h *Handler) GetDeadLetters(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	// Limit maximum items per page
+	if limit > 100 {
+		limit = 100
+	}
+
+	letters, err := h.service.GetDeadLetters(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to get dead letters",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, letters)
+}
+
+// ReplayEvent handles processing a stored webhook event again
+// @Summary Replay a webhook event
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param event_id path string true "Provider event ID"
+// @Success 200 {object} models.WebhookEvent
+// @Failure 404 {object} models.ErrorResponse
+// @Router /admin/webhooks/events/{event_id}/replay [post]
+func (h *Handler) ReplayEvent(c *gin.Context) {
+	event, err := h.service.Replay(c.Request.Context(), c.Param("event_id"))
+	if err != nil {
+		respondError(c, err, "Failed to replay webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// respondError maps webhook service errors to HTTP responses
+func respondError(c *gin.Context, err error, message string) {
+	status, code := http.StatusInternalServerError, "internal_error"
+	switch {
+	case errors.Is(err, ErrMissingSignature), errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrSignatureTooOld):
+		status, code = http.StatusBadRequest, "invalid_signature"
+	case errors.Is(err, ErrInvalidEvent):
+		status, code = http.StatusBadRequest, "invalid_event"
+	case errors.Is(err, ErrEventNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, ErrWebhooksNotEnabled):
+		status, code = http.StatusServiceUnavailable, "webhooks_disabled"
+	}
+
+	if status == http.StatusInternalServerError {
+		c.JSON(status, models.ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Message: err.Error(),
+	})
+}
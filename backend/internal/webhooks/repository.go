@@ -0,0 +1,224 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+)
+
+const eventColumns = `e.id, e.event_id, e.type, e.payload, e.status, e.attempts, e.last_error, e.received_at, e.processed_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEvent(row rowScanner, extra ...interface{}) (*models.WebhookEvent, error) {
+	event := &models.WebhookEvent{}
+	dest := append([]interface{}{
+		&event.ID,
+		&event.EventID,
+		&event.Type,
+		&event.Payload,
+		&event.Status,
+		&event.Attempts,
+		&event.LastError,
+		&event.ReceivedAt,
+		&event.ProcessedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// PostgresRepository implements the Repository interface for PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgreSQL repository
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// SaveEvent stores an event unless one with the same event ID exists. event is
+// filled from the stored row either way; created reports whether it is new.
+func (r *PostgresRepository) SaveEvent(ctx context.Context, event *models.WebhookEvent) (bool, error) {
+	query := `
+		INSERT INTO webhook_events (event_id, type, payload)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO NOTHING
+		RETURNING id
+	`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, event.EventID, event.Type, event.Payload).Scan(&id)
+	created := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	stored, err := r.GetEvent(ctx, event.EventID)
+	if err != nil {
+		return false, err
+	}
+	*event = *stored
+	return created, nil
+}
+
+// GetEvent retrieves an event by the provider's event ID
+func (r *PostgresRepository) GetEvent(ctx context.Context, eventID string) (*models.WebhookEvent, error) {
+	query := `SELECT ` + eventColumns + ` FROM webhook_events e WHERE e.event_id = $1`
+	return scanEvent(r.db.QueryRowContext(ctx, query, eventID))
+}
+
+// ClaimEvent leases an unprocessed event to the caller and counts the
+// attempt. It reports false if the event is processed or leased elsewhere.
+func (r *PostgresRepository) ClaimEvent(ctx context.Context, id int64, lease time.Duration) (int, bool, error) {
+	query := `
+		UPDATE webhook_events
+		SET attempts = attempts + 1, locked_until = NOW() + make_interval(secs => $2)
+		WHERE id = $1 AND status <> 'processed' AND (locked_until IS NULL OR locked_until < NOW())
+		RETURNING attempts
+	`
+
+	var attempts int
+	err := r.db.QueryRowContext(ctx, query, id, lease.Seconds()).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return attempts, true, nil
+}
+
+// MarkProcessed records that an event was processed and removes it from the
+// dead-letter table
+func (r *PostgresRepository) MarkProcessed(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_events
+		SET status = 'processed', processed_at = NOW(), last_error = '', locked_until = NULL
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_dead_letters WHERE event_ref = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkFailed records a failed attempt and schedules the event in the
+// dead-letter table for another attempt after retryIn, unless exhausted
+func (r *PostgresRepository) MarkFailed(ctx context.Context, id int64, attempts int, lastError string, retryIn time.Duration, exhausted bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_events
+		SET status = 'failed', last_error = $2, locked_until = NULL
+		WHERE id = $1
+	`, id, lastError)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_dead_letters (event_ref, attempts, last_error, next_retry_at, exhausted)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4), $5)
+		ON CONFLICT (event_ref) DO UPDATE
+		SET attempts = EXCLUDED.attempts,
+			last_error = EXCLUDED.last_error,
+			next_retry_at = EXCLUDED.next_retry_at,
+			exhausted = EXCLUDED.exhausted
+	`, id, attempts, lastError, retryIn.Seconds(), exhausted)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetEvent makes an event claimable again for a manual replay
+func (r *PostgresRepository) ResetEvent(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_events
+		SET status = 'pending', locked_until = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// GetDueDeadLetters retrieves failed events that are due for a retry
+func (r *PostgresRepository) GetDueDeadLetters(ctx context.Context, limit int) ([]*models.WebhookEvent, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM webhook_dead_letters d
+		JOIN webhook_events e ON e.id = d.event_ref
+		WHERE d.exhausted = FALSE AND d.next_retry_at <= NOW()
+		ORDER BY d.next_retry_at
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.WebhookEvent
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetDeadLetters retrieves the dead-letter table, most recently failed first
+func (r *PostgresRepository) GetDeadLetters(ctx context.Context, limit, offset int) ([]*models.WebhookDeadLetter, error) {
+	query := `
+		SELECT ` + eventColumns + `, d.attempts, d.last_error, d.next_retry_at, d.exhausted, d.created_at
+		FROM webhook_dead_letters d
+		JOIN webhook_events e ON e.id = d.event_ref
+		ORDER BY d.updated_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := make([]*models.WebhookDeadLetter, 0)
+	for rows.Next() {
+		letter := &models.WebhookDeadLetter{}
+		letter.Event, err = scanEvent(rows, &letter.Attempts, &letter.LastError, &letter.NextRetryAt, &letter.Exhausted, &letter.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, rows.Err()
+}
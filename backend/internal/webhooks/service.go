@@ -0,0 +1,244 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/internal/models"
+	"github.com/DopestT/HALO-Go-Live-Be-Seen/backend/pkg/logger"
+)
+
+// Event statuses
+const (
+	StatusPending   = "pending"
+	StatusProcessed = "processed"
+	StatusFailed    = "failed"
+)
+
+// processingLease is how long a worker may hold an event before another
+// worker can claim it, in case the first one crashed
+const processingLease = 5 * time.Minute
+
+// Retry backoff doubles from the first delay up to the maximum
+const (
+	firstRetryDelay = time.Minute
+	maxRetryDelay   = 6 * time.Hour
+)
+
+var (
+	ErrInvalidEvent  = errors.New("invalid webhook event")
+	ErrEventNotFound = errors.New("webhook event not found")
+)
+
+// Event is the envelope of a provider webhook
+type Event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// Processor applies the object of one type of event. Events can be delivered
+// and replayed more than once, so processors must be idempotent.
+type Processor func(ctx context.Context, object json.RawMessage) error
+
+// Repository defines the interface for webhook data access
+type Repository interface {
+	SaveEvent(ctx context.Context, event *models.WebhookEvent) (bool, error)
+	GetEvent(ctx context.Context, eventID string) (*models.WebhookEvent, error)
+	ClaimEvent(ctx context.Context, id int64, lease time.Duration) (int, bool, error)
+	MarkProcessed(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, attempts int, lastError string, retryIn time.Duration, exhausted bool) error
+	ResetEvent(ctx context.Context, id int64) error
+	GetDueDeadLetters(ctx context.Context, limit int) ([]*models.WebhookEvent, error)
+	GetDeadLetters(ctx context.Context, limit, offset int) ([]*models.WebhookDeadLetter, error)
+}
+
+// Service receives provider webhooks and hands them to processors
+type Service struct {
+	repo        Repository
+	secret      string
+	tolerance   time.Duration
+	maxAttempts int
+	processors  map[string]Processor
+}
+
+// NewService creates a new webhook service. Signatures older than tolerance
+// are rejected, and events that fail maxAttempts times stop being retried.
+func NewService(repo Repository, secret string, tolerance time.Duration, maxAttempts int) *Service {
+	return &Service{
+		repo:        repo,
+		secret:      secret,
+		tolerance:   tolerance,
+		maxAttempts: maxAttempts,
+		processors:  make(map[string]Processor),
+	}
+}
+
+// Handle registers the processor for an event type. Events without a
+// processor are recorded and marked processed.
+func (s *Service) Handle(eventType string, processor Processor) {
+	s.processors[eventType] = processor
+}
+
+// Receive verifies and stores a webhook, then processes it. Deliveries of an
+// event that was already processed are acknowledged without processing it
+// again. Processing failures are not returned: the event is stored and will
+// be retried from the dead-letter table.
+func (s *Service) Receive(ctx context.Context, payload []byte, signature string) (*models.WebhookEvent, error) {
+	if err := Verify(payload, signature, s.secret, s.tolerance, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if event.ID == "" || event.Type == "" {
+		return nil, fmt.Errorf("%w: id and type are required", ErrInvalidEvent)
+	}
+
+	stored := &models.WebhookEvent{
+		EventID: event.ID,
+		Type:    event.Type,
+		Payload: string(payload),
+	}
+	if _, err := s.repo.SaveEvent(ctx, stored); err != nil {
+		return nil, fmt.Errorf("failed to save webhook event: %w", err)
+	}
+	if stored.Status == StatusProcessed {
+		return stored, nil
+	}
+
+	if err := s.process(ctx, stored); err != nil {
+		logger.ErrorLogger.Printf("Failed to process webhook %s: %v", stored.EventID, err)
+	}
+	return stored, nil
+}
+
+// Replay processes a stored event again, whatever its status, and returns it
+// with the outcome recorded
+func (s *Service) Replay(ctx context.Context, eventID string) (*models.WebhookEvent, error) {
+	event, err := s.repo.GetEvent(ctx, eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEventNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook event: %w", err)
+	}
+
+	if err := s.repo.ResetEvent(ctx, event.ID); err != nil {
+		return nil, fmt.Errorf("failed to reset webhook event: %w", err)
+	}
+	if err := s.process(ctx, event); err != nil {
+		logger.WarnLogger.Printf("Replay of webhook %s failed: %v", eventID, err)
+	}
+
+	event, err = s.repo.GetEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook event: %w", err)
+	}
+	return event, nil
+}
+
+// GetDeadLetters retrieves failed events, most recently failed first
+func (s *Service) GetDeadLetters(ctx context.Context, limit, offset int) ([]*models.WebhookDeadLetter, error) {
+	letters, err := s.repo.GetDeadLetters(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+// RetryDeadLetters processes failed events that are due for another attempt
+func (s *Service) RetryDeadLetters(ctx context.Context) error {
+	events, err := s.repo.GetDueDeadLetters(ctx, 100)
+	if err != nil {
+		return fmt.Errorf("failed to get due dead letters: %w", err)
+	}
+
+	for _, event := range events {
+		if err := s.process(ctx, event); err != nil {
+			logger.ErrorLogger.Printf("Failed to retry webhook %s: %v", event.EventID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunRetrier retries dead letters every interval until ctx is cancelled
+func (s *Service) RunRetrier(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RetryDeadLetters(ctx); err != nil {
+				logger.ErrorLogger.Printf("Failed to retry webhooks: %v", err)
+			}
+		}
+	}
+}
+
+// RetryDelay is how long to wait before the next attempt after a failed one
+func RetryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// process claims an event and runs its processor. An event held by another
+// worker is skipped. Failures are recorded in the dead-letter table and
+// returned.
+func (s *Service) process(ctx context.Context, stored *models.WebhookEvent) error {
+	attempts, claimed, err := s.repo.ClaimEvent(ctx, stored.ID, processingLease)
+	if err != nil {
+		return fmt.Errorf("failed to claim webhook event: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	processErr := s.dispatch(ctx, stored)
+	if processErr == nil {
+		if err := s.repo.MarkProcessed(ctx, stored.ID); err != nil {
+			return fmt.Errorf("failed to mark webhook event processed: %w", err)
+		}
+		stored.Status = StatusProcessed
+		return nil
+	}
+
+	exhausted := attempts >= s.maxAttempts
+	if err := s.repo.MarkFailed(ctx, stored.ID, attempts, processErr.Error(), RetryDelay(attempts), exhausted); err != nil {
+		return fmt.Errorf("failed to dead-letter webhook event: %w", err)
+	}
+	stored.Status = StatusFailed
+	return processErr
+}
+
+func (s *Service) dispatch(ctx context.Context, stored *models.WebhookEvent) error {
+	processor, ok := s.processors[stored.Type]
+	if !ok {
+		return nil
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(stored.Payload), &event); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return processor(ctx, event.Data.Object)
+}
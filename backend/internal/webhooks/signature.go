@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the provider's signature of a webhook payload
+const SignatureHeader = "Stripe-Signature"
+
+var (
+	ErrMissingSignature   = errors.New("missing webhook signature")
+	ErrInvalidSignature   = errors.New("invalid webhook signature")
+	ErrSignatureTooOld    = errors.New("webhook timestamp is outside the tolerance")
+	ErrWebhooksNotEnabled = errors.New("webhook secret is not configured")
+)
+
+// Sign returns a signature header for payload in the provider's
+// t=<unix>,v1=<hex HMAC-SHA256 of "t.payload"> format
+func Sign(payload []byte, secret string, timestamp time.Time) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + computeSignature(t, payload, secret)
+}
+
+// Verify checks a signature header against payload. The header may carry
+// several v1 signatures while secrets are being rotated; any match is
+// accepted. Signatures older or newer than tolerance are rejected so that
+// captured requests cannot be replayed later.
+func Verify(payload []byte, header, secret string, tolerance time.Duration, now time.Time) error {
+	if secret == "" {
+		return ErrWebhooksNotEnabled
+	}
+	if header == "" {
+		return ErrMissingSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := []byte(computeSignature(timestamp, payload, secret))
+	matched := false
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return ErrInvalidSignature
+	}
+
+	age := now.Sub(time.Unix(unix, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrSignatureTooOld
+	}
+
+	return nil
+}
+
+func computeSignature(timestamp string, payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
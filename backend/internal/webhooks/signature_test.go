@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"payout.paid"}`)
+	now := time.Unix(1700000000, 0)
+	tolerance := 5 * time.Minute
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		header   string
+		secret   string
+		expected error
+	}{
+		{"Valid", payload, Sign(payload, secret, now), secret, nil},
+		{"WithinTolerance", payload, Sign(payload, secret, now.Add(-4*time.Minute)), secret, nil},
+		{"RotatedSecret", payload, Sign(payload, "whsec_old", now) + ",v1=" + computeSignature("1700000000", payload, secret), secret, nil},
+		{"TooOld", payload, Sign(payload, secret, now.Add(-6*time.Minute)), secret, ErrSignatureTooOld},
+		{"FromTheFuture", payload, Sign(payload, secret, now.Add(6*time.Minute)), secret, ErrSignatureTooOld},
+		{"TamperedPayload", []byte(`{"id":"evt_1","type":"payout.failed"}`), Sign(payload, secret, now), secret, ErrInvalidSignature},
+		{"WrongSecret", payload, Sign(payload, "whsec_other", now), secret, ErrInvalidSignature},
+		{"MissingTimestamp", payload, "v1=" + computeSignature("1700000000", payload, secret), secret, ErrInvalidSignature},
+		{"MissingHeader", payload, "", secret, ErrMissingSignature},
+		{"NoSecret", payload, Sign(payload, "", now), "", ErrWebhooksNotEnabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.payload, tt.header, tt.secret, tolerance, now); err != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{20, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := RetryDelay(tt.attempts); got != tt.expected {
+			t.Errorf("Expected %v after %d attempts, got %v", tt.expected, tt.attempts, got)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+-- Add moderation state to users
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS account_status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (account_status IN ('active', 'suspended', 'banned', 'shadow_banned')),
+    ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '';
+
+-- Create account action history (kept for appeals)
+CREATE TABLE IF NOT EXISTS account_actions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    moderator_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_account_status ON users(account_status);
+CREATE INDEX IF NOT EXISTS idx_account_actions_user_id ON account_actions(user_id, created_at DESC);
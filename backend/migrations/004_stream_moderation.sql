@@ -0,0 +1,15 @@
+-- Add moderator kill switch and takedown tombstones to videos
+ALTER TABLE videos
+    ADD COLUMN IF NOT EXISTS terminated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS terminated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS terminated_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS taken_down_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS taken_down_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS takedown_reason TEXT NOT NULL DEFAULT '';
+
+-- A stream ended by a moderator can never be flipped back to live, whichever
+-- service writes the row
+ALTER TABLE videos
+    ADD CONSTRAINT videos_terminated_not_live CHECK (NOT (is_live AND terminated_at IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS idx_videos_taken_down_at ON videos(taken_down_at) WHERE taken_down_at IS NOT NULL;
@@ -0,0 +1,38 @@
+-- Verified-only rooms rely on a verified email; the column stays NULL until
+-- the verification flow sets it
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Create per-stream room settings
+CREATE TABLE IF NOT EXISTS room_settings (
+    video_id BIGINT PRIMARY KEY REFERENCES videos(id) ON DELETE CASCADE,
+    slow_mode_seconds INTEGER NOT NULL DEFAULT 0 CHECK (slow_mode_seconds >= 0),
+    verified_only BOOLEAN NOT NULL DEFAULT FALSE,
+    min_account_age_minutes INTEGER NOT NULL DEFAULT 0 CHECK (min_account_age_minutes >= 0),
+    banned_words JSONB NOT NULL DEFAULT '[]',
+    banned_patterns JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create channel moderators appointed by a creator
+CREATE TABLE IF NOT EXISTS channel_moderators (
+    channel_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (channel_id, user_id)
+);
+
+-- Create channel bans; a ban with an expiry is a timeout
+CREATE TABLE IF NOT EXISTS channel_bans (
+    id BIGSERIAL PRIMARY KEY,
+    channel_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issued_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_bans_lookup ON channel_bans(channel_id, user_id);
+
+CREATE TRIGGER update_room_settings_updated_at BEFORE UPDATE ON room_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,18 @@
+-- Create queue of user-supplied text held for human review. Held text is not
+-- shown until approved, at which point it is written to the target field.
+CREATE TABLE IF NOT EXISTS moderation_queue (
+    id BIGSERIAL PRIMARY KEY,
+    field VARCHAR(50) NOT NULL,
+    subject_id BIGINT NOT NULL,
+    author_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    reasons JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'superseded')),
+    reviewer_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_queue_status ON moderation_queue(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_moderation_queue_subject ON moderation_queue(field, subject_id) WHERE status = 'pending';
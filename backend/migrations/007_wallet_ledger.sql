@@ -0,0 +1,78 @@
+-- Double-entry ledger. Every movement of coins or money is a journal entry
+-- made of postings whose amounts, in integer minor units, sum to zero per
+-- currency. Account balances are the running sum of their postings.
+
+-- Create ledger accounts; platform accounts have no owner
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id BIGINT REFERENCES users(id) ON DELETE RESTRICT,
+    type VARCHAR(50) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0,
+    allow_negative BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT ledger_accounts_owner_type_currency UNIQUE NULLS NOT DISTINCT (owner_id, type, currency),
+    CONSTRAINT ledger_accounts_non_negative CHECK (allow_negative OR balance >= 0)
+);
+
+CREATE TRIGGER update_ledger_accounts_updated_at BEFORE UPDATE ON ledger_accounts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create journal entries; the idempotency key makes retried writes safe
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    kind VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create postings; ledger rows are never updated or deleted
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES journal_entries(id) ON DELETE RESTRICT,
+    account_id BIGINT NOT NULL REFERENCES ledger_accounts(id) ON DELETE RESTRICT,
+    currency VARCHAR(10) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    balance_after BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry ON ledger_postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account ON ledger_postings(account_id, id DESC);
+
+-- Reject entries whose postings do not balance per currency, checked at
+-- commit so that an entry's postings can be inserted one at a time
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM ledger_postings
+        WHERE entry_id = NEW.entry_id
+        GROUP BY currency
+        HAVING SUM(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- Keep the ledger append-only
+CREATE OR REPLACE FUNCTION reject_ledger_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger rows are append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER ledger_postings_append_only BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER journal_entries_append_only BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW EXECUTE FUNCTION reject_ledger_change();
@@ -0,0 +1,46 @@
+-- Create gift catalog
+CREATE TABLE IF NOT EXISTS gifts (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    coin_price BIGINT NOT NULL CHECK (coin_price > 0),
+    icon_url TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO gifts (code, name, coin_price, sort_order) VALUES
+    ('heart', 'Heart', 1, 1),
+    ('rose', 'Rose', 10, 2),
+    ('halo', 'Halo', 100, 3),
+    ('comet', 'Comet', 500, 4),
+    ('supernova', 'Supernova', 1000, 5)
+ON CONFLICT (code) DO NOTHING;
+
+-- Create gifts sent during streams; each is backed by one journal entry
+CREATE TABLE IF NOT EXISTS gift_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    video_id BIGINT NOT NULL REFERENCES videos(id) ON DELETE RESTRICT,
+    gift_id BIGINT NOT NULL REFERENCES gifts(id) ON DELETE RESTRICT,
+    sender_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    recipient_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    coins BIGINT NOT NULL CHECK (coins > 0),
+    earnings_cents BIGINT NOT NULL CHECK (earnings_cents >= 0),
+    entry_id BIGINT NOT NULL UNIQUE REFERENCES journal_entries(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_gift_transactions_video ON gift_transactions(video_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_gift_transactions_recipient ON gift_transactions(recipient_id, created_at DESC);
+
+-- Create final gift standings, persisted once a stream has ended
+CREATE TABLE IF NOT EXISTS gift_leaderboards (
+    video_id BIGINT NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    coins BIGINT NOT NULL,
+    persisted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (video_id, user_id)
+);
@@ -0,0 +1,37 @@
+-- Create creators' connected accounts at the payment provider
+CREATE TABLE IF NOT EXISTS payout_accounts (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE RESTRICT,
+    provider_account_id VARCHAR(255) NOT NULL UNIQUE,
+    destination_last4 VARCHAR(4) NOT NULL DEFAULT '',
+    payouts_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_payout_accounts_updated_at BEFORE UPDATE ON payout_accounts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create payouts; status follows the provider's payout lifecycle
+CREATE TABLE IF NOT EXISTS payouts (
+    id BIGSERIAL PRIMARY KEY,
+    public_id VARCHAR(40) NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    provider_account_id VARCHAR(255) NOT NULL,
+    provider_payout_id VARCHAR(255) UNIQUE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'in_transit', 'paid', 'failed')),
+    destination VARCHAR(20) NOT NULL DEFAULT '',
+    arrival_date TIMESTAMP,
+    failure_code VARCHAR(100) NOT NULL DEFAULT '',
+    failure_message TEXT NOT NULL DEFAULT '',
+    entry_id BIGINT NOT NULL REFERENCES journal_entries(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payouts_user ON payouts(user_id, created_at DESC);
+
+CREATE TRIGGER update_payouts_updated_at BEFORE UPDATE ON payouts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
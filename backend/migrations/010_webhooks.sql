@@ -0,0 +1,32 @@
+-- Store inbound provider webhook events verbatim so they can be replayed
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL UNIQUE,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processed', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    locked_until TIMESTAMP,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_type ON webhook_events(type, received_at DESC);
+
+-- Create the dead-letter table of events whose processing failed
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    event_ref BIGINT PRIMARY KEY REFERENCES webhook_events(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_retry_at TIMESTAMP NOT NULL,
+    exhausted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_due ON webhook_dead_letters(next_retry_at) WHERE exhausted = FALSE;
+
+CREATE TRIGGER update_webhook_dead_letters_updated_at BEFORE UPDATE ON webhook_dead_letters
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
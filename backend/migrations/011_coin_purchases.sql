@@ -0,0 +1,41 @@
+-- Create the catalog of coin packages sold through the app stores
+CREATE TABLE IF NOT EXISTS coin_packages (
+    id BIGSERIAL PRIMARY KEY,
+    product_id VARCHAR(100) NOT NULL UNIQUE,
+    coins BIGINT NOT NULL CHECK (coins > 0),
+    price_cents BIGINT NOT NULL CHECK (price_cents > 0),
+    currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO coin_packages (product_id, coins, price_cents, sort_order) VALUES
+    ('halo.coins.100', 100, 99, 1),
+    ('halo.coins.500', 500, 499, 2),
+    ('halo.coins.1200', 1200, 999, 3)
+ON CONFLICT (product_id) DO NOTHING;
+
+-- Create coin purchases; each store transaction is credited once
+CREATE TABLE IF NOT EXISTS coin_purchases (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    package_id BIGINT NOT NULL REFERENCES coin_packages(id),
+    store VARCHAR(20) NOT NULL,
+    transaction_id VARCHAR(255) NOT NULL,
+    product_id VARCHAR(100) NOT NULL,
+    coins BIGINT NOT NULL CHECK (coins > 0),
+    price_cents BIGINT NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'completed'
+        CHECK (status IN ('completed', 'refunded', 'charged_back')),
+    reversed_coins BIGINT NOT NULL DEFAULT 0,
+    entry_id BIGINT NOT NULL UNIQUE REFERENCES journal_entries(id) ON DELETE RESTRICT,
+    reversal_entry_id BIGINT REFERENCES journal_entries(id) ON DELETE RESTRICT,
+    purchased_at TIMESTAMP NOT NULL,
+    reversed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (store, transaction_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_purchases_user ON coin_purchases(user_id, created_at DESC);
@@ -0,0 +1,45 @@
+-- Create subscription tiers offered by creators, priced in coins per period
+CREATE TABLE IF NOT EXISTS subscription_tiers (
+    id BIGSERIAL PRIMARY KEY,
+    creator_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    price_coins BIGINT NOT NULL CHECK (price_coins > 0),
+    perks JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (creator_id, name)
+);
+
+CREATE TRIGGER update_subscription_tiers_updated_at BEFORE UPDATE ON subscription_tiers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create subscriptions. Canceled subscriptions run to the end of the paid
+-- period; subscriptions whose renewal failed stay in grace until grace_until.
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    subscriber_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    creator_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tier_id BIGINT NOT NULL REFERENCES subscription_tiers(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'canceled', 'grace', 'expired')),
+    periods INTEGER NOT NULL DEFAULT 1,
+    current_period_start TIMESTAMP NOT NULL,
+    current_period_end TIMESTAMP NOT NULL,
+    grace_until TIMESTAMP,
+    canceled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A subscriber has at most one live subscription per creator
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_live
+    ON subscriptions(subscriber_id, creator_id) WHERE status <> 'expired';
+CREATE INDEX IF NOT EXISTS idx_subscriptions_due
+    ON subscriptions(current_period_end) WHERE status <> 'expired';
+
+CREATE TRIGGER update_subscriptions_updated_at BEFORE UPDATE ON subscriptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Let creators restrict streams and VODs to their subscribers
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS subscribers_only BOOLEAN NOT NULL DEFAULT FALSE;
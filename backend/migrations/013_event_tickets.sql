@@ -0,0 +1,24 @@
+-- Let creators charge for a stream. A NULL price means the video is free;
+-- canceled_at is set when the creator cancels a ticketed event.
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS ticket_price_coins BIGINT CHECK (ticket_price_coins > 0);
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS cancel_reason TEXT NOT NULL DEFAULT '';
+
+-- Create event tickets. charge_id identifies the charge at the charger that
+-- took payment; refunded tickets keep their row for the audit trail.
+CREATE TABLE IF NOT EXISTS event_tickets (
+    id BIGSERIAL PRIMARY KEY,
+    video_id BIGINT NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    price_coins BIGINT NOT NULL CHECK (price_coins > 0),
+    charge_id VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'refunded')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    refunded_at TIMESTAMP,
+    UNIQUE (video_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_tickets_user_id ON event_tickets(user_id);
+CREATE INDEX IF NOT EXISTS idx_event_tickets_refundable
+    ON event_tickets(video_id) WHERE status = 'active';
@@ -0,0 +1,35 @@
+-- Create revenue events: one row per monetized action that earns a creator
+-- money. Values are fixed when the event happens; refunds are recorded as
+-- negative events referencing the original.
+CREATE TABLE IF NOT EXISTS revenue_events (
+    id BIGSERIAL PRIMARY KEY,
+    creator_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('gift', 'subscription', 'ticket')),
+    source_ref VARCHAR(255) NOT NULL,
+    gross_coins BIGINT NOT NULL,
+    gross_cents BIGINT NOT NULL,
+    creator_cents BIGINT NOT NULL,
+    platform_fee_cents BIGINT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source, source_ref)
+);
+
+CREATE INDEX IF NOT EXISTS idx_revenue_events_creator ON revenue_events(creator_id, occurred_at);
+
+CREATE TRIGGER revenue_events_append_only BEFORE UPDATE OR DELETE ON revenue_events
+    FOR EACH ROW EXECUTE FUNCTION reject_ledger_change();
+
+-- Create revenue rollups, kept up to date in the same statement that records
+-- each event. Buckets start on the first day of their day, ISO week or month.
+CREATE TABLE IF NOT EXISTS revenue_rollups (
+    creator_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    granularity VARCHAR(10) NOT NULL CHECK (granularity IN ('day', 'week', 'month')),
+    bucket_start DATE NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    sales BIGINT NOT NULL DEFAULT 0,
+    gross_coins BIGINT NOT NULL DEFAULT 0,
+    gross_cents BIGINT NOT NULL DEFAULT 0,
+    creator_cents BIGINT NOT NULL DEFAULT 0,
+    platform_fee_cents BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (creator_id, granularity, bucket_start, source)
+);
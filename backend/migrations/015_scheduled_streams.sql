@@ -0,0 +1,39 @@
+-- Let creators announce streams in advance. Scheduled videos become
+-- published when they first go live; reminded_at is set once RSVP'd viewers
+-- have been reminded and cleared when the stream is rescheduled.
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('scheduled', 'published'));
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP;
+
+ALTER TABLE videos ADD CONSTRAINT videos_scheduled_at_check
+    CHECK (status <> 'scheduled' OR scheduled_at IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_videos_upcoming
+    ON videos(scheduled_at) WHERE status = 'scheduled' AND canceled_at IS NULL;
+
+-- Publish scheduled videos as soon as they go live, whichever layer sets
+-- is_live
+CREATE OR REPLACE FUNCTION publish_scheduled_video()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.is_live AND NEW.status = 'scheduled' THEN
+        NEW.status = 'published';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER publish_scheduled_videos BEFORE INSERT OR UPDATE OF is_live ON videos
+    FOR EACH ROW EXECUTE FUNCTION publish_scheduled_video();
+
+-- Create stream reminders: viewers who asked to be notified when a scheduled
+-- stream is about to start
+CREATE TABLE IF NOT EXISTS stream_reminders (
+    video_id BIGINT NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (video_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stream_reminders_user_id ON stream_reminders(user_id);
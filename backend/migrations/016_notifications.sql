@@ -0,0 +1,77 @@
+-- Create the in-app notification inbox
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Create per-type delivery preferences. Types without a row use the
+-- defaults built into the notification service.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    push BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type)
+);
+
+CREATE TRIGGER update_notification_preferences_updated_at BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the devices push notifications are sent to. A token belongs to one
+-- user at a time: registering it again moves it to the new user.
+CREATE TABLE IF NOT EXISTS push_devices (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(10) NOT NULL CHECK (platform IN ('apns', 'fcm')),
+    token VARCHAR(4096) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id);
+
+-- Create the devices each user has logged in from, identified by a hash of
+-- the user agent, to alert users of logins from new devices
+CREATE TABLE IF NOT EXISTS login_devices (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_hash VARCHAR(64) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    last_ip VARCHAR(45) NOT NULL DEFAULT '',
+    first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, device_hash)
+);
+
+-- Record when streams go live so the creator's audience can be told.
+-- live_notified_at is set once they were notified and cleared each time the
+-- video goes live again.
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS live_started_at TIMESTAMP;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS live_notified_at TIMESTAMP;
+
+CREATE OR REPLACE FUNCTION stamp_live_start()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.is_live AND (TG_OP = 'INSERT' OR NOT OLD.is_live) THEN
+        NEW.live_started_at = NOW();
+        NEW.live_notified_at = NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER stamp_video_live_start BEFORE INSERT OR UPDATE OF is_live ON videos
+    FOR EACH ROW EXECUTE FUNCTION stamp_live_start();
+
+CREATE INDEX IF NOT EXISTS idx_videos_live_unnotified
+    ON videos(live_started_at) WHERE is_live = TRUE AND live_notified_at IS NULL;
@@ -0,0 +1,15 @@
+-- Throttle verification email resends
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verification_sent_at TIMESTAMP;
+
+-- Create pending email changes. The new address is applied, and counts as
+-- verified, once both the old and the new address have confirmed the
+-- change; a new request replaces the pending one.
+CREATE TABLE IF NOT EXISTS email_changes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    new_email VARCHAR(255) NOT NULL,
+    old_confirmed_at TIMESTAMP,
+    new_confirmed_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,29 @@
+-- Create passkeys: WebAuthn credentials users sign in with. public_key is
+-- in PKIX form; sign_count is the authenticator's counter, which must move
+-- forward on every login unless the authenticator does not count.
+CREATE TABLE IF NOT EXISTS passkeys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    aaguid BYTEA,
+    name VARCHAR(100) NOT NULL,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys(user_id);
+
+-- Create passkey ceremony challenges, stored as SHA-256 hashes and usable
+-- once. Login challenges have no user.
+CREATE TABLE IF NOT EXISTS passkey_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    challenge_hash CHAR(64) NOT NULL UNIQUE,
+    purpose VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkey_challenges_expires_at ON passkey_challenges(expires_at);
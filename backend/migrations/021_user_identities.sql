@@ -0,0 +1,16 @@
+-- Create identities: accounts at OpenID Connect providers (Apple, Google)
+-- that users sign in with. A user links at most one account per provider.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+-- Record the method a user signed in with before an MFA challenge, so the
+-- amr claim names it once the challenge is answered
+ALTER TABLE mfa_challenges ADD COLUMN IF NOT EXISTS method VARCHAR(10) NOT NULL DEFAULT 'pwd';
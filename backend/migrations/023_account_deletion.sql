@@ -0,0 +1,10 @@
+-- Schedule account deletion: an account is erased once its
+-- deletion_scheduled_at passes, unless the user cancels first. Erased
+-- accounts keep an anonymized row, marked by deleted_at, since wallet,
+-- gift and payout records must keep pointing at it.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_due ON users(deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,5 @@
+-- Payouts left pending without a provider payout ID, after a provider
+-- error that may or may not have created them, are resubmitted on each
+-- scheduler run with the same idempotency key.
+CREATE INDEX IF NOT EXISTS idx_payouts_unsubmitted ON payouts(created_at)
+    WHERE status = 'pending' AND provider_payout_id IS NULL;
@@ -0,0 +1,5 @@
+-- Record who last changed each user's role, as user_permissions records
+-- who granted each permission
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role_changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS role_changed_at TIMESTAMP;
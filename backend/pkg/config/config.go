@@ -4,15 +4,22 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	CORS     CORSConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Redis         RedisConfig
+	JWT           JWTConfig
+	Accounts      AccountsConfig
+	CORS          CORSConfig
+	Moderation    ModerationConfig
+	Monetization  MonetizationConfig
+	Payments      PaymentsConfig
+	Streams       StreamsConfig
+	Notifications NotificationsConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -21,6 +28,10 @@ type ServerConfig struct {
 	ReadTimeout  int
 	WriteTimeout int
 	IdleTimeout  int
+	// TrustedProxies are the IPs and CIDRs of proxies whose X-Forwarded-For
+	// headers give the client IP; by default none are trusted and the
+	// connection's address is used
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds PostgreSQL configuration
@@ -51,19 +62,154 @@ type JWTConfig struct {
 	ExpirationHours int
 }
 
+// AccountsConfig holds configuration for the account email flows. The URLs
+// are the pages email links open; the token is appended as the token query
+// parameter.
+type AccountsConfig struct {
+	PasswordResetURL        string
+	PasswordResetTTLMinutes int
+	EmailVerificationURL    string
+	EmailChangeURL          string
+	EmailLinkTTLHours       int
+	// VerificationResendSeconds is the shortest time between two
+	// verification emails to the same user
+	VerificationResendSeconds int
+	// MFAIssuer names the service in authenticator apps
+	MFAIssuer string
+	// RecentMFAMinutes is how long after verifying a second factor routes
+	// that demand one accept the session
+	RecentMFAMinutes int
+	// WebAuthnRPID is the domain passkeys are bound to, and WebAuthnOrigins
+	// the origins passkeys may be used from
+	WebAuthnRPID    string
+	WebAuthnRPName  string
+	WebAuthnOrigins []string
+	// GoogleClientIDs and AppleClientIDs are the apps' client IDs at the
+	// identity providers; a provider is off without any
+	GoogleClientIDs []string
+	AppleClientIDs  []string
+	// LoginMaxFailures failed logins to an account, or LoginIPMaxFailures
+	// from one IP, within an hour lock logins out for LoginLockoutMinutes
+	LoginMaxFailures    int
+	LoginIPMaxFailures  int
+	LoginLockoutMinutes int
+	// MFAMaxFailures wrong second factors from a user within an hour, at
+	// login or step-up, lock their second factor out for LoginLockoutMinutes
+	MFAMaxFailures int
+	// LocationHeaders are the headers a CDN or proxy in front of the API
+	// reports the approximate location of client IPs in, such as
+	// CF-IPCity,CF-IPCountry
+	LocationHeaders []string
+	// DeletionGraceDays is how long after a deletion request an account is
+	// erased
+	DeletionGraceDays int
+	// PasswordMinLength and PasswordMinEntropyBits are what new passwords
+	// need; BreachedPasswordsDir, if set, holds a breached password list
+	// split into SHA-1 prefix files that new passwords are screened against
+	PasswordMinLength      int
+	PasswordMinEntropyBits int
+	BreachedPasswordsDir   string
+	// PasswordHashAlgorithm is argon2id or bcrypt; hashes made with another
+	// algorithm or other parameters are replaced at the next login
+	PasswordHashAlgorithm string
+	BcryptCost            int
+	Argon2MemoryKiB       int
+	Argon2Iterations      int
+	Argon2Parallelism     int
+}
+
 // CORSConfig holds CORS configuration
 type CORSConfig struct {
 	AllowedOrigins string
 }
 
+// ModerationConfig holds the word lists used by the local text classifier.
+// Each file has one word or phrase per line. Without a reject list nothing is
+// rejected for its wording; without a review list a built-in list of spam
+// phrases is used.
+type ModerationConfig struct {
+	RejectWordsFile string
+	ReviewWordsFile string
+}
+
+// MonetizationConfig holds how creator earnings are valued
+type MonetizationConfig struct {
+	// GiftCentsPer100Coins is what a creator earns, in US cents, for every
+	// 100 coins of gifts received
+	GiftCentsPer100Coins int
+	// SubscriptionCentsPer100Coins is what a creator earns, in US cents, for
+	// every 100 coins of subscriptions
+	SubscriptionCentsPer100Coins int
+	// SubscriptionGraceDays is how long a subscription whose renewal could
+	// not be paid keeps its perks while the renewal is retried
+	SubscriptionGraceDays int
+	// TicketCentsPer100Coins is what a creator earns, in US cents, for every
+	// 100 coins of event tickets sold
+	TicketCentsPer100Coins int
+	// CoinValueCentsPer100Coins is what 100 coins are worth to buyers, in US
+	// cents; it values gross revenue in creator earnings reports
+	CoinValueCentsPer100Coins int
+}
+
+// PaymentsConfig holds payment provider and payout configuration
+type PaymentsConfig struct {
+	// Provider selects the payment provider; only "fake" is built in
+	Provider string
+	// MinPayoutCents is the smallest available balance that is paid out
+	MinPayoutCents int
+	// EarningsHoldDays is how long earnings stay pending before they can be
+	// paid out, leaving time for refunds and chargebacks
+	EarningsHoldDays int
+	// OnboardingReturnURL and OnboardingRefreshURL are the app pages the
+	// provider's payout onboarding returns to when finished or expired
+	OnboardingReturnURL  string
+	OnboardingRefreshURL string
+	// ReceiptValidator selects how app store receipts are validated; only
+	// "fake" is built in
+	ReceiptValidator string
+	// TicketCharger selects how event tickets are paid for: "wallet" charges
+	// the buyer's coins, "fake" accepts every charge without moving money
+	TicketCharger string
+	// WebhookSecret signs inbound provider webhooks; webhooks are rejected
+	// while it is empty
+	WebhookSecret string
+	// WebhookToleranceSeconds is how old a webhook signature may be
+	WebhookToleranceSeconds int
+	// WebhookMaxAttempts is how many times a failed webhook is processed
+	// before it stays in the dead-letter table for manual replay
+	WebhookMaxAttempts int
+}
+
+// StreamsConfig holds how scheduled streams are handled
+type StreamsConfig struct {
+	// ReminderLeadMinutes is how long before a scheduled stream starts
+	// viewers who asked to be reminded are notified
+	ReminderLeadMinutes int
+	// ScheduledGraceMinutes is how long after its start time a scheduled
+	// stream that has not gone live is canceled
+	ScheduledGraceMinutes int
+}
+
+// NotificationsConfig holds how notifications are delivered outside the app
+type NotificationsConfig struct {
+	// PushSender selects how push notifications are sent to APNs and FCM;
+	// only "fake" is built in
+	PushSender string
+	// EmailSender selects how email is sent: "fake" logs messages and
+	// "file" appends them to EmailOutboxFile
+	EmailSender     string
+	EmailOutboxFile string
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	config := &Config{
 		Server: ServerConfig{
-			Port:         getEnv("SERVER_PORT", "8080"),
-			ReadTimeout:  getEnvAsInt("SERVER_READ_TIMEOUT", 10),
-			WriteTimeout: getEnvAsInt("SERVER_WRITE_TIMEOUT", 10),
-			IdleTimeout:  getEnvAsInt("SERVER_IDLE_TIMEOUT", 120),
+			Port:           getEnv("SERVER_PORT", "8080"),
+			ReadTimeout:    getEnvAsInt("SERVER_READ_TIMEOUT", 10),
+			WriteTimeout:   getEnvAsInt("SERVER_WRITE_TIMEOUT", 10),
+			IdleTimeout:    getEnvAsInt("SERVER_IDLE_TIMEOUT", 120),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES", ""),
 		},
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "localhost"),
@@ -87,9 +233,70 @@ func Load() (*Config, error) {
 			SecretKey:       getEnv("JWT_SECRET_KEY", "your-secret-key-change-in-production"),
 			ExpirationHours: getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
 		},
+		Accounts: AccountsConfig{
+			PasswordResetURL:          getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+			PasswordResetTTLMinutes:   getEnvAsInt("PASSWORD_RESET_TTL_MINUTES", 30),
+			EmailVerificationURL:      getEnv("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+			EmailChangeURL:            getEnv("EMAIL_CHANGE_URL", "http://localhost:3000/confirm-email-change"),
+			EmailLinkTTLHours:         getEnvAsInt("EMAIL_LINK_TTL_HOURS", 48),
+			VerificationResendSeconds: getEnvAsInt("EMAIL_VERIFICATION_RESEND_SECONDS", 60),
+			MFAIssuer:                 getEnv("MFA_ISSUER", "HALO"),
+			RecentMFAMinutes:          getEnvAsInt("MFA_RECENT_MINUTES", 15),
+			WebAuthnRPID:              getEnv("WEBAUTHN_RP_ID", "localhost"),
+			WebAuthnRPName:            getEnv("WEBAUTHN_RP_NAME", "HALO"),
+			WebAuthnOrigins:           getEnvAsList("WEBAUTHN_ORIGINS", "http://localhost:3000"),
+			GoogleClientIDs:           getEnvAsList("GOOGLE_CLIENT_IDS", ""),
+			AppleClientIDs:            getEnvAsList("APPLE_CLIENT_IDS", ""),
+			LoginMaxFailures:          getEnvAsInt("LOGIN_MAX_FAILURES", 10),
+			LoginIPMaxFailures:        getEnvAsInt("LOGIN_IP_MAX_FAILURES", 100),
+			LoginLockoutMinutes:       getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15),
+			MFAMaxFailures:            getEnvAsInt("MFA_MAX_FAILURES", 10),
+			LocationHeaders:           getEnvAsList("LOCATION_HEADERS", ""),
+			DeletionGraceDays:         getEnvAsInt("ACCOUNT_DELETION_GRACE_DAYS", 30),
+			PasswordMinLength:         getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			PasswordMinEntropyBits:    getEnvAsInt("PASSWORD_MIN_ENTROPY_BITS", 40),
+			BreachedPasswordsDir:      getEnv("BREACHED_PASSWORDS_DIR", ""),
+			PasswordHashAlgorithm:     getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:                getEnvAsInt("BCRYPT_COST", 10),
+			Argon2MemoryKiB:           getEnvAsInt("ARGON2_MEMORY_KIB", 19456),
+			Argon2Iterations:          getEnvAsInt("ARGON2_ITERATIONS", 2),
+			Argon2Parallelism:         getEnvAsInt("ARGON2_PARALLELISM", 1),
+		},
 		CORS: CORSConfig{
 			AllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "*"),
 		},
+		Moderation: ModerationConfig{
+			RejectWordsFile: getEnv("MODERATION_REJECT_WORDS_FILE", ""),
+			ReviewWordsFile: getEnv("MODERATION_REVIEW_WORDS_FILE", ""),
+		},
+		Monetization: MonetizationConfig{
+			GiftCentsPer100Coins:         getEnvAsInt("GIFT_CENTS_PER_100_COINS", 50),
+			SubscriptionCentsPer100Coins: getEnvAsInt("SUBSCRIPTION_CENTS_PER_100_COINS", 50),
+			SubscriptionGraceDays:        getEnvAsInt("SUBSCRIPTION_GRACE_DAYS", 3),
+			TicketCentsPer100Coins:       getEnvAsInt("TICKET_CENTS_PER_100_COINS", 50),
+			CoinValueCentsPer100Coins:    getEnvAsInt("COIN_VALUE_CENTS_PER_100_COINS", 100),
+		},
+		Payments: PaymentsConfig{
+			Provider:                getEnv("PAYMENT_PROVIDER", "fake"),
+			MinPayoutCents:          getEnvAsInt("PAYOUT_MIN_CENTS", 1000),
+			EarningsHoldDays:        getEnvAsInt("EARNINGS_HOLD_DAYS", 7),
+			OnboardingReturnURL:     getEnv("PAYOUT_ONBOARDING_RETURN_URL", "http://localhost:3000/payouts/onboarding/done"),
+			OnboardingRefreshURL:    getEnv("PAYOUT_ONBOARDING_REFRESH_URL", "http://localhost:3000/payouts/onboarding"),
+			ReceiptValidator:        getEnv("RECEIPT_VALIDATOR", "fake"),
+			TicketCharger:           getEnv("TICKET_CHARGER", "wallet"),
+			WebhookSecret:           getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+			WebhookToleranceSeconds: getEnvAsInt("PAYMENT_WEBHOOK_TOLERANCE_SECONDS", 300),
+			WebhookMaxAttempts:      getEnvAsInt("PAYMENT_WEBHOOK_MAX_ATTEMPTS", 8),
+		},
+		Streams: StreamsConfig{
+			ReminderLeadMinutes:   getEnvAsInt("STREAM_REMINDER_LEAD_MINUTES", 15),
+			ScheduledGraceMinutes: getEnvAsInt("SCHEDULED_STREAM_GRACE_MINUTES", 30),
+		},
+		Notifications: NotificationsConfig{
+			PushSender:      getEnv("PUSH_SENDER", "fake"),
+			EmailSender:     getEnv("EMAIL_SENDER", "fake"),
+			EmailOutboxFile: getEnv("EMAIL_OUTBOX_FILE", "outbox.log"),
+		},
 	}
 
 	if err := config.validate(); err != nil {
@@ -107,6 +314,19 @@ func (c *Config) validate() error {
 	if c.JWT.SecretKey == "your-secret-key-change-in-production" {
 		return fmt.Errorf("JWT_SECRET_KEY must be set in production")
 	}
+	switch c.Accounts.PasswordHashAlgorithm {
+	case "argon2id":
+		if c.Accounts.Argon2MemoryKiB < 1024 || c.Accounts.Argon2Iterations < 1 ||
+			c.Accounts.Argon2Parallelism < 1 || c.Accounts.Argon2Parallelism > 255 {
+			return fmt.Errorf("ARGON2_MEMORY_KIB must be at least 1024, ARGON2_ITERATIONS at least 1 and ARGON2_PARALLELISM from 1 to 255")
+		}
+	case "bcrypt":
+		if c.Accounts.BcryptCost < 10 || c.Accounts.BcryptCost > 31 {
+			return fmt.Errorf("BCRYPT_COST must be from 10 to 31")
+		}
+	default:
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be argon2id or bcrypt")
+	}
 	return nil
 }
 
@@ -127,6 +347,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsList gets a comma-separated environment variable as a list or
+// returns a default value
+func getEnvAsList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // GetDSN returns the PostgreSQL connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -50,4 +50,16 @@ func TestValidate(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for default JWT_SECRET_KEY")
 	}
+
+	// Test unknown password hash algorithm
+	cfg = &Config{
+		Database: DatabaseConfig{Password: "test"},
+		JWT:      JWTConfig{SecretKey: "test-key"},
+		Accounts: AccountsConfig{PasswordHashAlgorithm: "md5"},
+	}
+
+	err = cfg.validate()
+	if err == nil {
+		t.Error("Expected error for unknown PASSWORD_HASH_ALGORITHM")
+	}
 }
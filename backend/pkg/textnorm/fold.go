@@ -0,0 +1,89 @@
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// homoglyphs maps Cyrillic and Greek letters that render like Latin letters
+// to the Latin letter they imitate. Keys are lowercase.
+var homoglyphs = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	'і': 'i', 'ї': 'i', 'ј': 'j', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+}
+
+// decompose splits characters into base letters and combining marks,
+// folding compatibility forms (fullwidth, ligatures, circled letters) on the
+// way, then drops the marks
+var decompose = transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// Fold normalizes text for matching against word lists. It folds
+// compatibility forms, strips accents and invisible formatting characters
+// (such as zero-width spaces), maps common homoglyphs to Latin and
+// lowercases the result. The output is not meant for display.
+func Fold(s string) string {
+	decomposed, _, err := transform.String(decompose, s)
+	if err != nil {
+		decomposed = s
+	}
+
+	var b strings.Builder
+	b.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if latin, ok := homoglyphs[r]; ok {
+			r = latin
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Words folds text and splits it into words on anything that is not a letter
+// or digit
+func Words(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ContainsPhrase reports whether the folded words of text contain the folded
+// words of phrase as a contiguous run. Matching whole words avoids flagging
+// innocent words that merely contain a banned one.
+func ContainsPhrase(text, phrase string) bool {
+	needle := strings.Join(Words(phrase), " ")
+	if needle == "" {
+		return false
+	}
+	return strings.Contains(" "+strings.Join(Words(text), " ")+" ", " "+needle+" ")
+}
+
+// leet maps digits and symbols commonly substituted for letters
+var leet = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '9': 'g',
+	'@': 'a', '$': 's', '!': 'i', '|': 'l', '+': 't',
+}
+
+// UnLeet folds text and replaces leetspeak substitutions with the letters
+// they stand for, so "fr33 c0!ns" matches "free coins". Genuine numbers are
+// mangled too, so only use the result for matching.
+func UnLeet(s string) string {
+	return strings.Map(func(r rune) rune {
+		if letter, ok := leet[r]; ok {
+			return letter
+		}
+		return r
+	}, Fold(s))
+}